@@ -199,6 +199,10 @@ type CacheMeta struct {
 	CacheKeyRegex          string `bson:"cache_key_regex" json:"cache_key_regex"`
 	CacheOnlyResponseCodes []int  `bson:"cache_response_codes" json:"cache_response_codes"`
 	Timeout                int64  `bson:"timeout" json:"timeout"`
+	// CacheKeyTemplate replaces the consumer in the cache key with the expanded
+	// context variables and session metadata, e.g. `$tyk_context.jwt_claims_tenant`.
+	// Responses aren't cached for the requests missing a variable of the template.
+	CacheKeyTemplate string `bson:"cache_key_template" json:"cache_key_template"`
 }
 
 type RequestInputType string
@@ -307,6 +311,50 @@ type CircuitBreakerMeta struct {
 	DisableHalfOpenState bool    `bson:"disable_half_open_state" json:"disable_half_open_state"`
 }
 
+// RetryPolicyMeta configures retries of failed upstream requests for an endpoint.
+type RetryPolicyMeta struct {
+	Disabled bool   `bson:"disabled" json:"disabled"`
+	Path     string `bson:"path" json:"path"`
+	Method   string `bson:"method" json:"method"`
+	// MaxAttempts is the total number of upstream attempts, including the first one.
+	MaxAttempts int `bson:"max_attempts" json:"max_attempts"`
+	// RetryOnStatusCodes lists the upstream response codes which are retried.
+	RetryOnStatusCodes []int `bson:"retry_on_status_codes" json:"retry_on_status_codes"`
+	// RetryOnErrors lists the network errors which are retried, any of
+	// `connect_failure`, `reset` and `timeout`.
+	RetryOnErrors []string `bson:"retry_on_errors" json:"retry_on_errors"`
+	// RetryNonIdempotent allows retrying POST and PATCH requests.
+	RetryNonIdempotent bool `bson:"retry_non_idempotent" json:"retry_non_idempotent"`
+	// BaseBackoff is the backoff before the first retry, doubled for each further retry.
+	BaseBackoff tyktime.ReadableDuration `bson:"base_backoff,omitempty" json:"base_backoff,omitempty"`
+	// MaxBackoff caps the backoff between retries.
+	MaxBackoff tyktime.ReadableDuration `bson:"max_backoff,omitempty" json:"max_backoff,omitempty"`
+}
+
+const (
+	// RetryOnConnectFailure retries requests which failed to connect to the upstream.
+	RetryOnConnectFailure = "connect_failure"
+	// RetryOnReset retries requests whose connection was closed before a response arrived.
+	RetryOnReset = "reset"
+	// RetryOnTimeout retries requests which timed out waiting for the upstream.
+	RetryOnTimeout = "timeout"
+)
+
+// HedgingMeta configures hedged upstream requests for a read-only endpoint.
+// When the first upstream hasn't responded in time, a second request is sent
+// to another load balanced target and the first response wins.
+type HedgingMeta struct {
+	Disabled bool   `bson:"disabled" json:"disabled"`
+	Path     string `bson:"path" json:"path"`
+	Method   string `bson:"method" json:"method"`
+	// Delay is the time to wait for the first response before hedging. With
+	// Percentile set, it is used until enough latencies are observed.
+	Delay tyktime.ReadableDuration `bson:"delay" json:"delay"`
+	// Percentile hedges once the first request is slower than this percentile
+	// of the endpoint's recently observed latencies, e.g. 95.
+	Percentile float64 `bson:"percentile,omitempty" json:"percentile,omitempty"`
+}
+
 type StringRegexMap struct {
 	MatchPattern string `bson:"match_rx" json:"match_rx"`
 	Reverse      bool   `bson:"reverse" json:"reverse"`
@@ -422,6 +470,8 @@ type ExtendedPathsSet struct {
 	TransformResponseHeader []HeaderInjectionMeta `bson:"transform_response_headers" json:"transform_response_headers,omitempty"`
 	HardTimeouts            []HardTimeoutMeta     `bson:"hard_timeouts" json:"hard_timeouts,omitempty"`
 	CircuitBreaker          []CircuitBreakerMeta  `bson:"circuit_breakers" json:"circuit_breakers,omitempty"`
+	RetryPolicy             []RetryPolicyMeta     `bson:"retry_policies" json:"retry_policies,omitempty"`
+	Hedging                 []HedgingMeta         `bson:"hedging" json:"hedging,omitempty"`
 	URLRewrite              []URLRewriteMeta      `bson:"url_rewrites" json:"url_rewrites,omitempty"`
 	Virtual                 []VirtualMeta         `bson:"virtual" json:"virtual,omitempty"`
 	SizeLimit               []RequestSizeMeta     `bson:"size_limits" json:"size_limits,omitempty"`
@@ -572,6 +622,42 @@ type SessionProviderMeta struct {
 type EventHandlerTriggerConfig struct {
 	Handler     TykEventHandlerName    `bson:"handler_name" json:"handler_name"`
 	HandlerMeta map[string]interface{} `bson:"handler_meta" json:"handler_meta"`
+	// Filter restricts the events passed to the handler.
+	Filter *EventFilter `bson:"filter,omitempty" json:"filter,omitempty"`
+	// Aggregate replaces the events with one summary per group and window.
+	Aggregate *EventAggregation `bson:"aggregate,omitempty" json:"aggregate,omitempty"`
+}
+
+// EventFilter matches events on their metadata. The handler only receives the
+// events matching all the configured criteria, events without the metadata a
+// criterion requires don't match it.
+type EventFilter struct {
+	// OrgIDs matches the events of these organisations.
+	OrgIDs []string `bson:"org_ids,omitempty" json:"org_ids,omitempty"`
+	// APIIDs matches the events of these APIs.
+	APIIDs []string `bson:"api_ids,omitempty" json:"api_ids,omitempty"`
+	// KeyHashes matches the events of the keys with these hashes.
+	KeyHashes []string `bson:"key_hashes,omitempty" json:"key_hashes,omitempty"`
+	// Hosts matches the host checker events of these hosts.
+	Hosts []string `bson:"hosts,omitempty" json:"hosts,omitempty"`
+	// MinTriggerPercentage matches the TriggerExceeded events reaching this quota usage percentage.
+	MinTriggerPercentage int64 `bson:"min_trigger_percentage,omitempty" json:"min_trigger_percentage,omitempty"`
+}
+
+// Event aggregation groups.
+const (
+	EventGroupByKey  = "key"
+	EventGroupByAPI  = "api"
+	EventGroupByOrg  = "org"
+	EventGroupByHost = "host"
+)
+
+// EventAggregation configures the summarisation of the events fired in a window.
+type EventAggregation struct {
+	// Window is the length of the aggregation window in seconds.
+	Window int64 `bson:"window" json:"window"`
+	// GroupBy sends a summary per key, api, org or host. All the events are summarised together when empty.
+	GroupBy string `bson:"group_by,omitempty" json:"group_by,omitempty"`
 }
 
 type EventHandlerMetaConfig struct {
@@ -634,6 +720,38 @@ type CacheOptions struct {
 	EnableUpstreamCacheControl bool     `bson:"enable_upstream_cache_control" json:"enable_upstream_cache_control"`
 	CacheControlTTLHeader      string   `bson:"cache_control_ttl_header" json:"cache_control_ttl_header"`
 	CacheByHeaders             []string `bson:"cache_by_headers" json:"cache_by_headers"`
+	// StaleWhileRevalidate is the number of seconds an expired entry is served
+	// while a background request refreshes it, see RFC 5861.
+	StaleWhileRevalidate int64 `bson:"stale_while_revalidate" json:"stale_while_revalidate"`
+	// StaleIfError is the number of seconds an expired entry is served when
+	// the upstream fails, see RFC 5861.
+	StaleIfError int64 `bson:"stale_if_error" json:"stale_if_error"`
+	// EnableRequestCoalescing sends only one request per cache entry to the
+	// upstream on a cache miss, the concurrent requests wait for its response.
+	EnableRequestCoalescing bool `bson:"enable_request_coalescing" json:"enable_request_coalescing"`
+	// DistributedRequestCoalescing coalesces cache misses across the gateways
+	// of the cluster with a Redis lock.
+	DistributedRequestCoalescing bool `bson:"distributed_request_coalescing" json:"distributed_request_coalescing"`
+	// RequestCoalescingTimeout is the number of seconds a request waits for a
+	// coalesced response before going to the upstream. Defaults to 10.
+	RequestCoalescingTimeout int64 `bson:"request_coalescing_timeout" json:"request_coalescing_timeout"`
+	// CacheTagHeaders are the upstream response headers listing the tags of a
+	// cached response, such as Surrogate-Key or Cache-Tag. Tags are separated
+	// by spaces or commas.
+	CacheTagHeaders []string `bson:"cache_tag_headers" json:"cache_tag_headers"`
+	// CacheTagPaths tags the cached responses of the requests matching a path.
+	CacheTagPaths []CacheTagPath `bson:"cache_tag_paths" json:"cache_tag_paths"`
+	// GenerateWeakETags adds a weak ETag computed from the body to the cached
+	// responses of upstreams which don't send one.
+	GenerateWeakETags bool `bson:"generate_weak_etags" json:"generate_weak_etags"`
+}
+
+// CacheTagPath tags the cached responses of the requests matching a path
+// template. Tags can use the named segments of the template, e.g. the path
+// `/users/{id}` with the tag `user-{id}`.
+type CacheTagPath struct {
+	Path string   `bson:"path" json:"path"`
+	Tags []string `bson:"tags" json:"tags"`
 }
 
 type ResponseProcessor struct {
@@ -802,6 +920,13 @@ type APIDefinition struct {
 	ConfigDataDisabled                   bool                   `bson:"config_data_disabled" json:"config_data_disabled"`
 	TagHeaders                           []string               `bson:"tag_headers" json:"tag_headers"`
 	GlobalRateLimit                      GlobalRateLimit        `bson:"global_rate_limit" json:"global_rate_limit"`
+	RateLimitDimensions                  []RateLimitDimension   `bson:"rate_limit_dimensions" json:"rate_limit_dimensions,omitempty"`
+	ConcurrencyLimit                     ConcurrencyLimit       `bson:"concurrency_limit" json:"concurrency_limit"`
+	LoadShedding                         LoadShedding           `bson:"load_shedding" json:"load_shedding"`
+	MCPAggregation                       MCPAggregation         `bson:"mcp_aggregation" json:"mcp_aggregation"`
+	MCPToolValidation                    MCPToolValidation      `bson:"mcp_tool_validation" json:"mcp_tool_validation"`
+	MCPContentGuard                      MCPContentGuard        `bson:"mcp_content_guard" json:"mcp_content_guard"`
+	MCPStdio                             MCPStdio               `bson:"mcp_stdio" json:"mcp_stdio"`
 	StripAuthData                        bool                   `bson:"strip_auth_data" json:"strip_auth_data"`
 	EnableDetailedRecording              bool                   `bson:"enable_detailed_recording" json:"enable_detailed_recording"`
 	GraphQL                              GraphQLConfig          `bson:"graphql" json:"graphql"`
@@ -1069,6 +1194,169 @@ type GlobalRateLimit struct {
 	Per      float64 `bson:"per" json:"per"`
 }
 
+// RateLimitDimension configures an API rate limit keyed on request attributes.
+// Key is built from context variables, e.g. `$tyk_context.remote_addr` or
+// `$tyk_context.headers_X_Tenant_Id-$tyk_context.jwt_claims_sub`, and each
+// distinct value gets its own rate limit. Requests missing a variable of the
+// key are limited per client, by key or IP, instead. Context variables must be
+// enabled on the API.
+type RateLimitDimension struct {
+	Disabled bool    `bson:"disabled" json:"disabled"`
+	Name     string  `bson:"name" json:"name"`
+	Key      string  `bson:"key" json:"key"`
+	Rate     float64 `bson:"rate" json:"rate"`
+	Per      float64 `bson:"per" json:"per"`
+}
+
+// Valid will return true if the rate limit dimension should be applied.
+func (d *RateLimitDimension) Valid() bool {
+	return !d.Disabled && d.Key != "" && d.Rate > 0 && d.Per > 0
+}
+
+// ConcurrencyLimit caps the number of requests in flight at the same time.
+// Requests over a cap wait up to QueueTimeout for a slot to be released,
+// then they are rejected with RejectStatusCode.
+type ConcurrencyLimit struct {
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// PerKey caps the in-flight requests of each session, 0 means unlimited.
+	PerKey int64 `bson:"per_key" json:"per_key"`
+	// PerAPI caps the in-flight requests of the API, 0 means unlimited.
+	PerAPI int64 `bson:"per_api" json:"per_api"`
+	// PerUpstreamHost caps the in-flight requests to the upstream host picked
+	// for the request, shared with the other APIs proxying to the same host.
+	// 0 means unlimited.
+	PerUpstreamHost int64 `bson:"per_upstream_host" json:"per_upstream_host"`
+	// QueueTimeout is how long requests over a cap wait for a slot, requests
+	// are rejected right away when it's empty.
+	QueueTimeout tyktime.ReadableDuration `bson:"queue_timeout,omitempty" json:"queue_timeout,omitempty"`
+	// RejectStatusCode is the response code of rejected requests, 429 or 503.
+	// Defaults to 429.
+	RejectStatusCode int `bson:"reject_status_code" json:"reject_status_code"`
+	// Distributed counts the in-flight requests across all gateways through
+	// the storage instead of per gateway.
+	Distributed bool `bson:"distributed" json:"distributed"`
+}
+
+const (
+	// LoadSheddingAIMD adjusts the load shedding limit with additive increase, multiplicative decrease.
+	LoadSheddingAIMD = "aimd"
+	// LoadSheddingGradient adjusts the load shedding limit with the gradient of the upstream latency.
+	LoadSheddingGradient = "gradient"
+)
+
+// LoadShedding rejects requests over a concurrency limit which the gateway
+// adjusts from the upstream latency and errors of the API.
+type LoadShedding struct {
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// Algorithm adjusting the limit, `aimd` (default) or `gradient`.
+	Algorithm string `bson:"algorithm" json:"algorithm"`
+	// InitialLimit, MinLimit and MaxLimit bound the limit, defaulting to 20, 1 and 1000.
+	InitialLimit int `bson:"initial_limit" json:"initial_limit"`
+	MinLimit     int `bson:"min_limit" json:"min_limit"`
+	MaxLimit     int `bson:"max_limit" json:"max_limit"`
+	// LatencyThreshold is the upstream latency over which `aimd` decreases the limit.
+	LatencyThreshold tyktime.ReadableDuration `bson:"latency_threshold,omitempty" json:"latency_threshold,omitempty"`
+	// PriorityTags are the session or policy tags of the requests shed last.
+	PriorityTags []string `bson:"priority_tags" json:"priority_tags,omitempty"`
+	// PriorityReserve is the share of the limit only priority requests can use, defaults to 0.1
+	// when unset. Zero reserves none of the limit.
+	PriorityReserve *float64 `bson:"priority_reserve,omitempty" json:"priority_reserve,omitempty"`
+	// RejectStatusCode is the response code of shed requests, defaults to 503.
+	RejectStatusCode int `bson:"reject_status_code" json:"reject_status_code"`
+}
+
+// MCPAggregation exposes several MCP APIs as one MCP server. The discovery
+// requests are fanned out to the member APIs and the calls routed to the
+// member owning the tool, prompt or resource.
+type MCPAggregation struct {
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// Members are the aggregated MCP APIs, listed in the order of their primitives.
+	Members []MCPAggregationMember `bson:"members" json:"members"`
+}
+
+// MCPAggregationMember is an MCP API of an aggregation.
+type MCPAggregationMember struct {
+	APIID string `bson:"api_id" json:"api_id"`
+	// Prefix is prepended to the names of the member's tools and prompts and to the URIs of its resources.
+	Prefix string `bson:"prefix" json:"prefix"`
+}
+
+// MCPToolValidation validates the arguments of the MCP tool calls against the
+// input schemas of the tools, as advertised by the upstream tools/list
+// responses or derived from the x-tyk-mcp-server primitives. The learnt
+// schemas are kept in memory across API reloads but not gateway restarts; the
+// gateway asks the upstream for its tools/list when a tool's schema is
+// unknown, at most once a minute.
+type MCPToolValidation struct {
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// RejectUnknownTools rejects the calls of the tools whose schema is still
+	// unknown once the upstream tools/list was fetched, instead of forwarding
+	// them unchecked.
+	RejectUnknownTools bool `bson:"reject_unknown_tools" json:"reject_unknown_tools"`
+}
+
+// MCPContentGuard guards the results of the MCP tool calls, prompts and
+// resource reads, including the server-sent events, before they reach the
+// clients. Secrets and personal data are redacted, and the results over the
+// maximum size or embedding blocked content types are replaced with an error.
+type MCPContentGuard struct {
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// Redactions are applied in order to the results and to the server notifications and requests.
+	Redactions []MCPRedaction `bson:"redactions" json:"redactions,omitempty"`
+	// MaxResultSize is the maximum size in bytes of a result, unlimited when 0.
+	MaxResultSize int `bson:"max_result_size" json:"max_result_size"`
+	// BlockedContentTypes are the content types the results can't embed, e.g. `image` or `resource`.
+	BlockedContentTypes []string `bson:"blocked_content_types" json:"blocked_content_types,omitempty"`
+}
+
+// MCPRedaction redacts the matches of Pattern, in the values selected by Path
+// when set, or the values selected by Path.
+type MCPRedaction struct {
+	// Name identifies the redaction in the events.
+	Name string `bson:"name" json:"name,omitempty"`
+	// Pattern is a regular expression.
+	Pattern string `bson:"pattern" json:"pattern,omitempty"`
+	// Path is a JSON path into the result, e.g. `$.structuredContent..email`.
+	Path string `bson:"path" json:"path,omitempty"`
+	// Replacement replaces the redacted content, defaults to `[REDACTED]`.
+	Replacement string `bson:"replacement" json:"replacement,omitempty"`
+}
+
+const (
+	// MCPStdioSession spawns an MCP stdio server per MCP session.
+	MCPStdioSession = "session"
+	// MCPStdioPool shares a pool of MCP stdio servers among all clients.
+	MCPStdioPool = "pool"
+)
+
+// MCPStdio serves the MCP API with a local MCP server speaking JSON-RPC over
+// its standard input and output instead of proxying to the target URL. The
+// gateway configuration must allow the command, its arguments, environment
+// variables and working directory, otherwise the API fails to load.
+type MCPStdio struct {
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// Command is the executable of the MCP server, Args its arguments.
+	Command string   `bson:"command" json:"command"`
+	Args    []string `bson:"args" json:"args,omitempty"`
+	// Env are the environment variables of the server as KEY=value, besides PATH and HOME.
+	Env []string `bson:"env" json:"env,omitempty"`
+	// Dir is the working directory of the server.
+	Dir string `bson:"dir" json:"dir,omitempty"`
+	// Mode is `session` (default) to spawn a server per MCP session, used by the
+	// key which started it, or `pool`.
+	Mode string `bson:"mode" json:"mode"`
+	// PoolSize is the number of servers of the pool, defaults to 1.
+	PoolSize int `bson:"pool_size" json:"pool_size"`
+	// MaxSessions limits the concurrent sessions, defaults to 64.
+	MaxSessions int `bson:"max_sessions" json:"max_sessions"`
+	// IdleTimeout stops the servers unused for the duration, defaults to 10m.
+	IdleTimeout tyktime.ReadableDuration `bson:"idle_timeout,omitempty" json:"idle_timeout,omitempty"`
+	// RestartBackoff and MaxRestartBackoff bound the doubling delay before
+	// respawning a server after a crash, defaulting to 1s and 30s.
+	RestartBackoff    tyktime.ReadableDuration `bson:"restart_backoff,omitempty" json:"restart_backoff,omitempty"`
+	MaxRestartBackoff tyktime.ReadableDuration `bson:"max_restart_backoff,omitempty" json:"max_restart_backoff,omitempty"`
+}
+
 type BundleManifest struct {
 	FileList         []string          `bson:"file_list" json:"file_list"`
 	CustomMiddleware MiddlewareSection `bson:"custom_middleware" json:"custom_middleware"`
@@ -1096,6 +1384,7 @@ type ProxyConfig struct {
 	Targets                     []string                      `bson:"target_list" json:"target_list"`
 	StructuredTargetList        *HostList                     `bson:"-" json:"-"`
 	CheckHostAgainstUptimeTests bool                          `bson:"check_host_against_uptime_tests" json:"check_host_against_uptime_tests"`
+	LoadBalancing               LoadBalancingConfig           `bson:"load_balancing" json:"load_balancing"`
 	ServiceDiscovery            ServiceDiscoveryConfiguration `bson:"service_discovery" json:"service_discovery"`
 	Transport                   struct {
 		SSLInsecureSkipVerify   bool     `bson:"ssl_insecure_skip_verify" json:"ssl_insecure_skip_verify"`
@@ -1885,6 +2174,14 @@ type WebHookHandlerConf struct {
 	HeaderList map[string]string `bson:"header_map" json:"header_map"`
 	// The cool-down for the event so it does not trigger again (in seconds).
 	EventTimeout int64 `bson:"event_timeout" json:"event_timeout"`
+	// MaxRetries is the number of times a failed webhook is retried before it's dead-lettered.
+	// Failed webhooks are not retried if it's 0.
+	MaxRetries int `bson:"max_retries" json:"max_retries"`
+	// RetryBackoff is the delay before the first retry (in seconds), doubled on each retry.
+	RetryBackoff int64 `bson:"retry_backoff" json:"retry_backoff"`
+	// SigningSecret is the secret of the HMAC-SHA256 signature of the webhook payload,
+	// signed with the unix timestamp it's sent at as "<timestamp>.<payload>".
+	SigningSecret string `bson:"signing_secret" json:"signing_secret"`
 }
 
 // Scan scans WebHookHandlerConf from `any` in.
@@ -1938,3 +2235,37 @@ func (l *LogEventHandlerConf) Scan(in any) error {
 	*l = *conf
 	return nil
 }
+
+// CloudEventsHandlerConf represents the configuration for an event handler emitting CloudEvents.
+type CloudEventsHandlerConf struct {
+	// Disabled indicates whether the handler is inactive.
+	Disabled bool `bson:"disabled" json:"disabled"`
+	// ID is the optional unique identifier for the event handler.
+	ID string `bson:"id" json:"id"`
+	// Source is the source attribute of the events, it defaults to the gateway node.
+	Source string `bson:"source" json:"source"`
+	// Sink is where the events are sent: `http`, `kafka`, `nats` or `file`. The
+	// handlers of the API definitions can only use the `http` sink, the others
+	// are reserved to the event handlers of the gateway configuration.
+	Sink string `bson:"sink" json:"sink"`
+	// URL is the HTTP endpoint or the NATS server the events are sent to.
+	URL string `bson:"url" json:"url"`
+	// Headers are set on the HTTP requests of the events.
+	Headers map[string]string `bson:"headers" json:"headers"`
+	// Brokers are the Kafka brokers the events are produced to.
+	Brokers []string `bson:"brokers" json:"brokers"`
+	// Topic is the Kafka topic or the NATS subject of the events.
+	Topic string `bson:"topic" json:"topic"`
+	// Path is the file the events are appended to, one per line.
+	Path string `bson:"path" json:"path"`
+}
+
+// Scan extracts data from the input into the CloudEventsHandlerConf struct by performing type conversion.
+func (c *CloudEventsHandlerConf) Scan(in any) error {
+	conf, err := reflect.Cast[CloudEventsHandlerConf](in)
+	if err != nil {
+		return err
+	}
+	*c = *conf
+	return nil
+}
@@ -0,0 +1,99 @@
+package apidef
+
+// LoadBalancingAlgorithm is the strategy used to select an upstream target
+// when load balancing is enabled.
+type LoadBalancingAlgorithm string
+
+const (
+	// LoadBalancingRoundRobin cycles through the targets in order. This is
+	// the default when no algorithm is configured.
+	LoadBalancingRoundRobin LoadBalancingAlgorithm = "round_robin"
+	// LoadBalancingWeightedRoundRobin spreads requests according to target
+	// weights, interleaving picks rather than sending them in bursts.
+	LoadBalancingWeightedRoundRobin LoadBalancingAlgorithm = "weighted_round_robin"
+	// LoadBalancingLeastConnections selects the target with the fewest
+	// outstanding requests relative to its weight.
+	LoadBalancingLeastConnections LoadBalancingAlgorithm = "least_connections"
+	// LoadBalancingEWMA selects the target with the lowest moving average
+	// latency, scaled by its outstanding requests.
+	LoadBalancingEWMA LoadBalancingAlgorithm = "ewma"
+	// LoadBalancingConsistentHash pins requests sharing a hash key to the
+	// same target.
+	LoadBalancingConsistentHash LoadBalancingAlgorithm = "consistent_hash"
+)
+
+// LoadBalancingHashKeySource is the part of the request used to build the
+// consistent hashing key.
+type LoadBalancingHashKeySource string
+
+const (
+	// LoadBalancingHashKeyIP hashes on the client IP address. This is the
+	// default when no source is configured.
+	LoadBalancingHashKeyIP LoadBalancingHashKeySource = "ip"
+	// LoadBalancingHashKeyHeader hashes on the value of a request header.
+	LoadBalancingHashKeyHeader LoadBalancingHashKeySource = "header"
+	// LoadBalancingHashKeySession hashes on the session key of the request.
+	LoadBalancingHashKeySession LoadBalancingHashKeySource = "session"
+)
+
+// LoadBalancingConfig holds the target selection settings used when
+// `enable_load_balancing` is set.
+type LoadBalancingConfig struct {
+	// Algorithm is the target selection strategy, `round_robin` when empty.
+	Algorithm LoadBalancingAlgorithm `bson:"algorithm" json:"algorithm"`
+	// HashKey configures the key for the `consistent_hash` algorithm.
+	HashKey LoadBalancingHashKey `bson:"hash_key" json:"hash_key"`
+	// PassiveHealthCheck configures ejection of targets based on the
+	// outcome of proxied requests.
+	PassiveHealthCheck PassiveHealthCheck `bson:"passive_health_check" json:"passive_health_check"`
+}
+
+// PassiveHealthCheck configures passive outlier detection. Upstream 5xx
+// responses, connection errors and timeouts are counted per target, and a
+// target is taken out of rotation once its error ratio is reached.
+type PassiveHealthCheck struct {
+	// Enabled activates passive health checking.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// ErrorRatio is the fraction of failed requests, between 0 and 1, at
+	// which a target is ejected. Defaults to 0.5.
+	ErrorRatio float64 `bson:"error_ratio" json:"error_ratio"`
+	// MinRequests is the number of requests a target must serve within the
+	// window before the error ratio is evaluated. Defaults to 5.
+	MinRequests int `bson:"min_requests" json:"min_requests"`
+	// Window is the interval in seconds over which failures are counted.
+	// Defaults to 10.
+	Window int64 `bson:"window" json:"window"`
+	// EjectionTime is the number of seconds a target is first ejected for.
+	// It doubles with each consecutive ejection. Defaults to 30.
+	EjectionTime int64 `bson:"ejection_time" json:"ejection_time"`
+	// MaxEjectionTime caps the ejection backoff in seconds. Defaults to ten
+	// times EjectionTime.
+	MaxEjectionTime int64 `bson:"max_ejection_time" json:"max_ejection_time"`
+}
+
+// LoadBalancingHashKey defines where the consistent hashing key is read from.
+type LoadBalancingHashKey struct {
+	// Source is one of `ip`, `header` or `session`, `ip` when empty.
+	Source LoadBalancingHashKeySource `bson:"source" json:"source"`
+	// Name is the header name when Source is `header`.
+	Name string `bson:"name" json:"name"`
+}
+
+// Valid reports whether the algorithm is empty or a known value.
+func (a LoadBalancingAlgorithm) Valid() bool {
+	switch a {
+	case "", LoadBalancingRoundRobin, LoadBalancingWeightedRoundRobin,
+		LoadBalancingLeastConnections, LoadBalancingEWMA, LoadBalancingConsistentHash:
+		return true
+	}
+	return false
+}
+
+// Valid reports whether the hash key source is empty or a known value.
+func (s LoadBalancingHashKeySource) Valid() bool {
+	switch s {
+	case "", LoadBalancingHashKeyIP, LoadBalancingHashKeyHeader, LoadBalancingHashKeySession:
+		return true
+	}
+	return false
+}
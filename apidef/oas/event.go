@@ -42,6 +42,14 @@ type EventHandler struct {
 	//
 	// Tyk classic API definition: `event_handlers.events[].handler_meta.name`.
 	Name string `json:"name,omitempty" bson:"name,omitempty"`
+	// Filter restricts the events passed to the event handler.
+	//
+	// Tyk classic API definition: `event_handlers.events[].filter`.
+	Filter *EventFilter `json:"filter,omitempty" bson:"filter,omitempty"`
+	// Aggregate replaces the events with one summary per group and window.
+	//
+	// Tyk classic API definition: `event_handlers.events[].aggregate`.
+	Aggregate *EventAggregation `json:"aggregate,omitempty" bson:"aggregate,omitempty"`
 
 	// Webhook contains WebhookEvent configs. Encoding and decoding is handled by the custom marshaller.
 	Webhook WebhookEvent `bson:"-" json:"-"`
@@ -116,6 +124,101 @@ func (e *EventHandler) UnmarshalJSON(in []byte) error {
 	return nil
 }
 
+// EventFilter matches events on their metadata. The event handler only receives the
+// events matching all the configured criteria.
+type EventFilter struct {
+	// OrgIDs matches the events of these organisations.
+	//
+	// Tyk classic API definition: `event_handlers.events[].filter.org_ids`.
+	OrgIDs []string `json:"orgIds,omitempty" bson:"orgIds,omitempty"`
+	// APIIDs matches the events of these APIs.
+	//
+	// Tyk classic API definition: `event_handlers.events[].filter.api_ids`.
+	APIIDs []string `json:"apiIds,omitempty" bson:"apiIds,omitempty"`
+	// KeyHashes matches the events of the keys with these hashes.
+	//
+	// Tyk classic API definition: `event_handlers.events[].filter.key_hashes`.
+	KeyHashes []string `json:"keyHashes,omitempty" bson:"keyHashes,omitempty"`
+	// Hosts matches the `HostDown` and `HostUp` events of these hosts.
+	//
+	// Tyk classic API definition: `event_handlers.events[].filter.hosts`.
+	Hosts []string `json:"hosts,omitempty" bson:"hosts,omitempty"`
+	// MinTriggerPercentage matches the `TriggerExceeded` events reaching this quota usage percentage.
+	//
+	// Tyk classic API definition: `event_handlers.events[].filter.min_trigger_percentage`.
+	MinTriggerPercentage int64 `json:"minTriggerPercentage,omitempty" bson:"minTriggerPercentage,omitempty"`
+}
+
+// Fill fills *EventFilter from apidef.EventFilter.
+func (f *EventFilter) Fill(filter apidef.EventFilter) {
+	f.OrgIDs = filter.OrgIDs
+	f.APIIDs = filter.APIIDs
+	f.KeyHashes = filter.KeyHashes
+	f.Hosts = filter.Hosts
+	f.MinTriggerPercentage = filter.MinTriggerPercentage
+}
+
+// ExtractTo extracts *EventFilter into *apidef.EventFilter.
+func (f *EventFilter) ExtractTo(filter *apidef.EventFilter) {
+	filter.OrgIDs = f.OrgIDs
+	filter.APIIDs = f.APIIDs
+	filter.KeyHashes = f.KeyHashes
+	filter.Hosts = f.Hosts
+	filter.MinTriggerPercentage = f.MinTriggerPercentage
+}
+
+// EventAggregation configures the summarisation of the events fired in a window, e.g.
+// one `AuthFailure` summary per key per minute.
+type EventAggregation struct {
+	// Window is the length of the aggregation window. It uses shorthand notation, e.g. "1m".
+	//
+	// Tyk classic API definition: `event_handlers.events[].aggregate.window`.
+	Window ReadableDuration `json:"window" bson:"window"`
+	// GroupBy sends a summary per `key`, `api`, `org` or `host`. All the events are summarised
+	// together when empty.
+	//
+	// Tyk classic API definition: `event_handlers.events[].aggregate.group_by`.
+	GroupBy string `json:"groupBy,omitempty" bson:"groupBy,omitempty"`
+}
+
+// Fill fills *EventAggregation from apidef.EventAggregation.
+func (a *EventAggregation) Fill(aggregation apidef.EventAggregation) {
+	a.Window = ReadableDuration(time.Duration(aggregation.Window) * time.Second)
+	a.GroupBy = aggregation.GroupBy
+}
+
+// ExtractTo extracts *EventAggregation into *apidef.EventAggregation.
+func (a *EventAggregation) ExtractTo(aggregation *apidef.EventAggregation) {
+	aggregation.Window = int64(a.Window.Seconds())
+	aggregation.GroupBy = a.GroupBy
+}
+
+// fillTriggerConfig fills the settings common to all the event handler kinds.
+func (e *EventHandler) fillTriggerConfig(eh apidef.EventHandlerTriggerConfig) {
+	if eh.Filter != nil {
+		e.Filter = &EventFilter{}
+		e.Filter.Fill(*eh.Filter)
+	}
+
+	if eh.Aggregate != nil {
+		e.Aggregate = &EventAggregation{}
+		e.Aggregate.Fill(*eh.Aggregate)
+	}
+}
+
+// extractTriggerConfig extracts the settings common to all the event handler kinds.
+func (e *EventHandler) extractTriggerConfig(eh *apidef.EventHandlerTriggerConfig) {
+	if e.Filter != nil {
+		eh.Filter = &apidef.EventFilter{}
+		e.Filter.ExtractTo(eh.Filter)
+	}
+
+	if e.Aggregate != nil {
+		eh.Aggregate = &apidef.EventAggregation{}
+		e.Aggregate.ExtractTo(eh.Aggregate)
+	}
+}
+
 // WebhookEvent stores the core information about a webhook event.
 type WebhookEvent struct {
 	// URL is the target URL for the webhook.
@@ -153,6 +256,23 @@ type WebhookEvent struct {
 	//
 	// Tyk classic API definition: `event_handlers.events[].handler_meta.header_map`.
 	Headers Headers `json:"headers,omitempty" bson:"headers,omitempty"`
+	// MaxRetries is the number of times a failed webhook is retried, with an exponential
+	// backoff, before it's dead-lettered. Dead-lettered webhooks can be inspected and replayed
+	// with the Gateway API. Failed webhooks are not retried if it's 0.
+	//
+	// Tyk classic API definition: `event_handlers.events[].handler_meta.max_retries`.
+	MaxRetries int `json:"maxRetries,omitempty" bson:"maxRetries,omitempty"`
+	// RetryBackoff is the delay before the first retry, doubled on each retry.
+	// It uses shorthand notation, e.g. "10s". Defaults to 5 seconds.
+	//
+	// Tyk classic API definition: `event_handlers.events[].handler_meta.retry_backoff`.
+	RetryBackoff ReadableDuration `json:"retryBackoff,omitempty" bson:"retryBackoff,omitempty"`
+	// SigningSecret is the secret the webhook payload is signed with. The HMAC-SHA256 signature
+	// of `<timestamp>.<payload>` is sent in the `X-Tyk-Webhook-Signature` header as `sha256=<hex digest>`,
+	// and the unix timestamp the webhook was sent at in the `X-Tyk-Webhook-Timestamp` header.
+	//
+	// Tyk classic API definition: `event_handlers.events[].handler_meta.signing_secret`.
+	SigningSecret string `json:"signingSecret,omitempty" bson:"signingSecret,omitempty"`
 }
 
 // GetWebhookConf converts EventHandler.WebhookEvent apidef.WebHookHandlerConf.
@@ -166,6 +286,10 @@ func (e *EventHandler) GetWebhookConf() apidef.WebHookHandlerConf {
 		HeaderList:   e.Webhook.Headers.Map(),
 		EventTimeout: int64(e.Webhook.CoolDownPeriod.Seconds()),
 		TemplatePath: e.Webhook.BodyTemplate,
+
+		MaxRetries:    e.Webhook.MaxRetries,
+		RetryBackoff:  int64(e.Webhook.RetryBackoff.Seconds()),
+		SigningSecret: e.Webhook.SigningSecret,
 	}
 }
 
@@ -240,9 +364,13 @@ func (e *EventHandlers) Fill(api apidef.APIDefinition) {
 						Headers:        NewHeaders(whConf.HeaderList),
 						BodyTemplate:   whConf.TemplatePath,
 						CoolDownPeriod: ReadableDuration(time.Duration(whConf.EventTimeout) * time.Second),
+						MaxRetries:     whConf.MaxRetries,
+						RetryBackoff:   ReadableDuration(time.Duration(whConf.RetryBackoff) * time.Second),
+						SigningSecret:  whConf.SigningSecret,
 					},
 				}
 
+				ev.fillTriggerConfig(eh)
 				events = append(events, ev)
 			case event.JSVMHandler:
 				jsvmHandlerConf := apidef.JSVMEventHandlerConf{}
@@ -263,6 +391,7 @@ func (e *EventHandlers) Fill(api apidef.APIDefinition) {
 					},
 				}
 
+				ev.fillTriggerConfig(eh)
 				events = append(events, ev)
 			case event.LogHandler:
 				logHandlerConf := apidef.LogEventHandlerConf{}
@@ -281,6 +410,7 @@ func (e *EventHandlers) Fill(api apidef.APIDefinition) {
 					},
 				}
 
+				ev.fillTriggerConfig(eh)
 				events = append(events, ev)
 			default:
 				continue
@@ -340,6 +470,7 @@ func (e *EventHandlers) ExtractTo(api *apidef.APIDefinition) {
 			Handler:     handler,
 			HandlerMeta: *handlerMeta,
 		}
+		ev.extractTriggerConfig(&eventHandlerTriggerConfig)
 
 		if val, ok := api.EventHandlers.Events[ev.Trigger]; ok {
 			api.EventHandlers.Events[ev.Trigger] = append(val, eventHandlerTriggerConfig)
@@ -416,6 +416,49 @@ func TestEventHandlers(t *testing.T) {
 					},
 				},
 			},
+			{
+				title: "filter and aggregate",
+				input: apidef.EventHandlerMetaConfig{
+					Events: map[event.Event][]apidef.EventHandlerTriggerConfig{
+						event.AuthFailure: {
+							{
+								Handler: event.LogHandler,
+								HandlerMeta: map[string]any{
+									"disabled": false,
+									"prefix":   "AuthFailureEvent",
+								},
+								Filter: &apidef.EventFilter{
+									OrgIDs:    []string{"org-id"},
+									KeyHashes: []string{"key-hash"},
+								},
+								Aggregate: &apidef.EventAggregation{
+									Window:  60,
+									GroupBy: apidef.EventGroupByKey,
+								},
+							},
+						},
+					},
+				},
+				expected: EventHandlers{
+					{
+						Enabled: true,
+						Trigger: event.AuthFailure,
+						Kind:    event.LogKind,
+						Name:    "AuthFailureEvent",
+						Filter: &EventFilter{
+							OrgIDs:    []string{"org-id"},
+							KeyHashes: []string{"key-hash"},
+						},
+						Aggregate: &EventAggregation{
+							Window:  ReadableDuration(time.Minute),
+							GroupBy: "key",
+						},
+						LogEvent: LogEvent{
+							LogPrefix: "AuthFailureEvent",
+						},
+					},
+				},
+			},
 			{
 				title:    "skip empty actions",
 				input:    apidef.EventHandlerMetaConfig{},
@@ -58,6 +58,16 @@ func fixSingleOperation(op *Operation) {
 	if op.CircuitBreaker != nil {
 		op.CircuitBreaker.Threshold = 0.5
 	}
+	if op.RetryPolicy != nil {
+		op.RetryPolicy.MaxAttempts = 3
+		op.RetryPolicy.Errors = []string{"connect_failure", "reset"}
+		op.RetryPolicy.BaseBackoff = ReadableDuration(10 * time.Millisecond)
+		op.RetryPolicy.MaxBackoff = ReadableDuration(time.Second)
+	}
+	if op.Hedging != nil {
+		op.Hedging.Delay = ReadableDuration(50 * time.Millisecond)
+		op.Hedging.Percentile = 95
+	}
 }
 
 // fixOperationsForValidation fixes operation fields in an Operations map to pass schema validation.
@@ -145,6 +155,20 @@ func TestXTykGateway_Lint(t *testing.T) {
 		if settings.Upstream.EnforceTimeout != nil {
 			settings.Upstream.EnforceTimeout.Duration = ReadableDuration(5 * time.Second)
 		}
+		if settings.Upstream.LoadBalancing != nil {
+			settings.Upstream.LoadBalancing.Algorithm = "consistent_hash"
+			if settings.Upstream.LoadBalancing.HashKey != nil {
+				settings.Upstream.LoadBalancing.HashKey.Source = "header"
+			}
+			if settings.Upstream.LoadBalancing.PassiveHealthCheck != nil {
+				check := settings.Upstream.LoadBalancing.PassiveHealthCheck
+				check.ErrorRatio = 0.5
+				check.MinRequests = 5
+				check.Window = ReadableDuration(10 * time.Second)
+				check.EjectionTime = ReadableDuration(30 * time.Second)
+				check.MaxEjectionTime = ReadableDuration(5 * time.Minute)
+			}
+		}
 
 		if settings.Info.Versioning != nil {
 			switch settings.Info.Versioning.Location {
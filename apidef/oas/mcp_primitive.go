@@ -92,6 +92,8 @@ func (m *MCPPrimitive) ExtractToExtendedPaths(ep *apidef.ExtendedPathsSet, path
 	m.extractVirtualEndpointTo(ep, path, method)
 	m.extractEndpointPostPluginTo(ep, path, method)
 	m.extractCircuitBreakerTo(ep, path, method)
+	m.extractRetryPolicyTo(ep, path, method)
+	m.extractHedgingTo(ep, path, method)
 	m.extractTrackEndpointTo(ep, path, method)
 	m.extractDoNotTrackEndpointTo(ep, path, method)
 	m.extractRequestSizeLimitTo(ep, path, method)
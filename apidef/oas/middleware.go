@@ -160,6 +160,14 @@ type Global struct {
 	// RequestSizeLimit contains the configuration related to limiting the global request size.
 	RequestSizeLimit *GlobalRequestSizeLimit `bson:"requestSizeLimit,omitempty" json:"requestSizeLimit,omitempty"`
 
+	// MCPToolValidation contains the configuration related to validating the MCP tool call arguments.
+	// Tyk classic API definition: `mcp_tool_validation`.
+	MCPToolValidation *MCPToolValidation `bson:"mcpToolValidation,omitempty" json:"mcpToolValidation,omitempty"`
+
+	// MCPContentGuard contains the configuration related to redacting and blocking the content of MCP results.
+	// Tyk classic API definition: `mcp_content_guard`.
+	MCPContentGuard *MCPContentGuard `bson:"mcpContentGuard,omitempty" json:"mcpContentGuard,omitempty"`
+
 	// IgnoreCase contains the configuration to treat routes as case-insensitive.
 	IgnoreCase *IgnoreCase `bson:"ignoreCase,omitempty" json:"ignoreCase,omitempty"`
 
@@ -305,6 +313,10 @@ func (g *Global) Fill(api apidef.APIDefinition) {
 
 	g.fillRequestSizeLimit(api)
 
+	g.fillMCPToolValidation(api)
+
+	g.fillMCPContentGuard(api)
+
 	g.fillSkips(api)
 }
 
@@ -423,6 +435,10 @@ func (g *Global) ExtractTo(api *apidef.APIDefinition) {
 
 	g.extractRequestSizeLimitTo(api)
 
+	g.extractMCPToolValidationTo(api)
+
+	g.extractMCPContentGuardTo(api)
+
 	g.extractSkipsTo(api)
 }
 
@@ -448,6 +464,50 @@ func (g *Global) extractRequestSizeLimitTo(api *apidef.APIDefinition) {
 	g.RequestSizeLimit.ExtractTo(api)
 }
 
+func (g *Global) fillMCPToolValidation(api apidef.APIDefinition) {
+	if g.MCPToolValidation == nil {
+		g.MCPToolValidation = &MCPToolValidation{}
+	}
+
+	g.MCPToolValidation.Fill(api)
+	if ShouldOmit(g.MCPToolValidation) {
+		g.MCPToolValidation = nil
+	}
+}
+
+func (g *Global) extractMCPToolValidationTo(api *apidef.APIDefinition) {
+	if g.MCPToolValidation == nil {
+		g.MCPToolValidation = &MCPToolValidation{}
+		defer func() {
+			g.MCPToolValidation = nil
+		}()
+	}
+
+	g.MCPToolValidation.ExtractTo(api)
+}
+
+func (g *Global) fillMCPContentGuard(api apidef.APIDefinition) {
+	if g.MCPContentGuard == nil {
+		g.MCPContentGuard = &MCPContentGuard{}
+	}
+
+	g.MCPContentGuard.Fill(api)
+	if ShouldOmit(g.MCPContentGuard) {
+		g.MCPContentGuard = nil
+	}
+}
+
+func (g *Global) extractMCPContentGuardTo(api *apidef.APIDefinition) {
+	if g.MCPContentGuard == nil {
+		g.MCPContentGuard = &MCPContentGuard{}
+		defer func() {
+			g.MCPContentGuard = nil
+		}()
+	}
+
+	g.MCPContentGuard.ExtractTo(api)
+}
+
 func (g *Global) extractContextVariablesTo(api *apidef.APIDefinition) {
 	if g.ContextVariables == nil {
 		g.ContextVariables = &ContextVariables{}
@@ -771,6 +831,67 @@ type Cache struct {
 	//
 	// Tyk classic API definition: `cache_options.cache_control_ttl_header`
 	ControlTTLHeaderName string `bson:"controlTTLHeaderName,omitempty" json:"controlTTLHeaderName,omitempty"`
+
+	// StaleWhileRevalidate is the number of seconds an expired cached object is served
+	// while a background request refreshes it, as in RFC 5861. With `enableUpstreamCacheControl`
+	// the upstream `Cache-Control: stale-while-revalidate` directive takes precedence.
+	//
+	// Tyk classic API definition: `cache_options.stale_while_revalidate`
+	StaleWhileRevalidate int64 `bson:"staleWhileRevalidate,omitempty" json:"staleWhileRevalidate,omitempty"`
+
+	// StaleIfError is the number of seconds an expired cached object is served when the
+	// upstream fails or the circuit breaker is open, as in RFC 5861. With `enableUpstreamCacheControl`
+	// the upstream `Cache-Control: stale-if-error` directive takes precedence.
+	//
+	// Tyk classic API definition: `cache_options.stale_if_error`
+	StaleIfError int64 `bson:"staleIfError,omitempty" json:"staleIfError,omitempty"`
+
+	// EnableRequestCoalescing sends only one request per cached object to the upstream on a
+	// cache miss. The concurrent requests for the same object wait for its response.
+	//
+	// Tyk classic API definition: `cache_options.enable_request_coalescing`
+	EnableRequestCoalescing bool `bson:"enableRequestCoalescing,omitempty" json:"enableRequestCoalescing,omitempty"`
+
+	// DistributedRequestCoalescing coalesces cache misses across the gateways of the cluster
+	// with a Redis lock. It requires `enableRequestCoalescing`.
+	//
+	// Tyk classic API definition: `cache_options.distributed_request_coalescing`
+	DistributedRequestCoalescing bool `bson:"distributedRequestCoalescing,omitempty" json:"distributedRequestCoalescing,omitempty"`
+
+	// RequestCoalescingTimeout is the number of seconds a request waits for a coalesced
+	// response before going to the upstream. Defaults to 10 seconds.
+	//
+	// Tyk classic API definition: `cache_options.request_coalescing_timeout`
+	RequestCoalescingTimeout int64 `bson:"requestCoalescingTimeout,omitempty" json:"requestCoalescingTimeout,omitempty"`
+
+	// TagHeaders are the upstream response headers listing the tags of a cached object,
+	// such as `Surrogate-Key` or `Cache-Tag`. Tags are separated by spaces or commas.
+	// Cached objects can be purged by tag with the Gateway API.
+	//
+	// Tyk classic API definition: `cache_options.cache_tag_headers`
+	TagHeaders []string `bson:"tagHeaders,omitempty" json:"tagHeaders,omitempty"`
+
+	// TagPaths tags the cached objects of the requests matching a path template.
+	//
+	// Tyk classic API definition: `cache_options.cache_tag_paths`
+	TagPaths []CacheTagPath `bson:"tagPaths,omitempty" json:"tagPaths,omitempty"`
+
+	// GenerateWeakETags adds a weak `ETag` computed from the body to the cached objects of
+	// upstreams which don't send one, for clients to make conditional requests answered
+	// with `304 Not Modified`.
+	//
+	// Tyk classic API definition: `cache_options.generate_weak_etags`
+	GenerateWeakETags bool `bson:"generateWeakETags,omitempty" json:"generateWeakETags,omitempty"`
+}
+
+// CacheTagPath tags the cached objects of the requests matching a path template.
+type CacheTagPath struct {
+	// Path is the path template, relative to the listen path, e.g. `/users/{id}`.
+	Path string `bson:"path" json:"path"`
+
+	// Tags are the tags of the cached objects. They can use the named segments of the
+	// path template, e.g. `user-{id}`.
+	Tags []string `bson:"tags" json:"tags"`
 }
 
 // Fill fills *Cache from apidef.CacheOptions.
@@ -782,6 +903,18 @@ func (c *Cache) Fill(cache apidef.CacheOptions) {
 	c.CacheByHeaders = cache.CacheByHeaders
 	c.EnableUpstreamCacheControl = cache.EnableUpstreamCacheControl
 	c.ControlTTLHeaderName = cache.CacheControlTTLHeader
+	c.StaleWhileRevalidate = cache.StaleWhileRevalidate
+	c.StaleIfError = cache.StaleIfError
+	c.EnableRequestCoalescing = cache.EnableRequestCoalescing
+	c.DistributedRequestCoalescing = cache.DistributedRequestCoalescing
+	c.RequestCoalescingTimeout = cache.RequestCoalescingTimeout
+	c.TagHeaders = cache.CacheTagHeaders
+	c.GenerateWeakETags = cache.GenerateWeakETags
+
+	c.TagPaths = nil
+	for _, tagPath := range cache.CacheTagPaths {
+		c.TagPaths = append(c.TagPaths, CacheTagPath{Path: tagPath.Path, Tags: tagPath.Tags})
+	}
 }
 
 // ExtractTo extracts *Cache into *apidef.CacheOptions.
@@ -793,6 +926,18 @@ func (c *Cache) ExtractTo(cache *apidef.CacheOptions) {
 	cache.CacheByHeaders = c.CacheByHeaders
 	cache.EnableUpstreamCacheControl = c.EnableUpstreamCacheControl
 	cache.CacheControlTTLHeader = c.ControlTTLHeaderName
+	cache.StaleWhileRevalidate = c.StaleWhileRevalidate
+	cache.StaleIfError = c.StaleIfError
+	cache.EnableRequestCoalescing = c.EnableRequestCoalescing
+	cache.DistributedRequestCoalescing = c.DistributedRequestCoalescing
+	cache.RequestCoalescingTimeout = c.RequestCoalescingTimeout
+	cache.CacheTagHeaders = c.TagHeaders
+	cache.GenerateWeakETags = c.GenerateWeakETags
+
+	cache.CacheTagPaths = nil
+	for _, tagPath := range c.TagPaths {
+		cache.CacheTagPaths = append(cache.CacheTagPaths, apidef.CacheTagPath{Path: tagPath.Path, Tags: tagPath.Tags})
+	}
 }
 
 // Paths is a mapping of API endpoints to Path plugin configurations. This field is part of the [Middleware](#middleware) structure.
@@ -1285,6 +1430,19 @@ type CachePlugin struct {
 	//
 	// Tyk classic API definition: `version_data.versions..extended_paths.advance_cache_config[].timeout`.
 	Timeout int64 `bson:"timeout,omitempty" json:"timeout,omitempty"`
+
+	// CacheKeyTemplate builds the cache key of the endpoint from context variables and
+	// session metadata instead of the consumer, to share entries between consumers or to
+	// cache per tenant. The key also varies on the method, the path, the query parameters
+	// in normalised order and the request body. Responses aren't cached for the requests
+	// missing a variable of the template.
+	//
+	// Context variables must be enabled to use `$tyk_context` variables.
+	//
+	// Example value: `$tyk_context.jwt_claims_tenant-$tyk_context.cookies_region`.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.advance_cache_config[].cache_key_template`.
+	CacheKeyTemplate string `bson:"cacheKeyTemplate,omitempty" json:"cacheKeyTemplate,omitempty"`
 }
 
 // Fill fills *CachePlugin from apidef.CacheMeta.
@@ -1293,6 +1451,7 @@ func (a *CachePlugin) Fill(cm apidef.CacheMeta) {
 	a.CacheByRegex = cm.CacheKeyRegex
 	a.CacheResponseCodes = cm.CacheOnlyResponseCodes
 	a.Timeout = cm.Timeout
+	a.CacheKeyTemplate = cm.CacheKeyTemplate
 
 	//TT-14102: Default cache timeout in seconds if none is specified but caching is enabled
 	if a.Enabled && a.Timeout == 0 {
@@ -1306,6 +1465,7 @@ func (a *CachePlugin) ExtractTo(cm *apidef.CacheMeta) {
 	cm.CacheKeyRegex = a.CacheByRegex
 	cm.CacheOnlyResponseCodes = a.CacheResponseCodes
 	cm.Timeout = a.Timeout
+	cm.CacheKeyTemplate = a.CacheKeyTemplate
 }
 
 // EnforceTimeout holds the configuration for enforcing request timeouts.
@@ -1749,6 +1909,97 @@ func (cb *CircuitBreaker) ExtractTo(circuitBreaker *apidef.CircuitBreakerMeta) {
 	circuitBreaker.DisableHalfOpenState = !cb.HalfOpenStateEnabled
 }
 
+// RetryPolicy holds configuration for retrying failed upstream requests.
+// Tyk classic API definition: `version_data.versions..extended_paths.retry_policies[*]`.
+type RetryPolicy struct {
+	// Enabled activates the retry policy.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.retry_policies[*].disabled` (negated).
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// MaxAttempts is the total number of upstream attempts, including the first one.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.retry_policies[*].max_attempts`.
+	MaxAttempts int `bson:"maxAttempts" json:"maxAttempts"`
+	// StatusCodes lists the upstream response codes which are retried.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.retry_policies[*].retry_on_status_codes`.
+	StatusCodes []int `bson:"statusCodes,omitempty" json:"statusCodes,omitempty"`
+	// Errors lists the network errors which are retried. Valid values are `connect_failure`, `reset` and `timeout`.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.retry_policies[*].retry_on_errors`.
+	Errors []string `bson:"errors,omitempty" json:"errors,omitempty"`
+	// NonIdempotent allows retrying requests with non-idempotent methods, such as POST and PATCH.
+	// By default only GET, HEAD, OPTIONS, TRACE, PUT and DELETE requests are retried.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.retry_policies[*].retry_non_idempotent`.
+	NonIdempotent bool `bson:"nonIdempotent,omitempty" json:"nonIdempotent,omitempty"`
+	// BaseBackoff is the backoff before the first retry. It doubles with every further retry and is randomised with jitter.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.retry_policies[*].base_backoff`.
+	BaseBackoff ReadableDuration `bson:"baseBackoff,omitempty" json:"baseBackoff,omitempty"`
+	// MaxBackoff caps the backoff between retries.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.retry_policies[*].max_backoff`.
+	MaxBackoff ReadableDuration `bson:"maxBackoff,omitempty" json:"maxBackoff,omitempty"`
+}
+
+// Fill fills *RetryPolicy from apidef.RetryPolicyMeta.
+func (r *RetryPolicy) Fill(meta apidef.RetryPolicyMeta) {
+	r.Enabled = !meta.Disabled
+	r.MaxAttempts = meta.MaxAttempts
+	r.StatusCodes = meta.RetryOnStatusCodes
+	r.Errors = meta.RetryOnErrors
+	r.NonIdempotent = meta.RetryNonIdempotent
+	r.BaseBackoff = meta.BaseBackoff
+	r.MaxBackoff = meta.MaxBackoff
+}
+
+// ExtractTo extracts *RetryPolicy into *apidef.RetryPolicyMeta.
+func (r *RetryPolicy) ExtractTo(meta *apidef.RetryPolicyMeta) {
+	meta.Disabled = !r.Enabled
+	meta.MaxAttempts = r.MaxAttempts
+	meta.RetryOnStatusCodes = r.StatusCodes
+	meta.RetryOnErrors = r.Errors
+	meta.RetryNonIdempotent = r.NonIdempotent
+	meta.BaseBackoff = r.BaseBackoff
+	meta.MaxBackoff = r.MaxBackoff
+}
+
+// Hedging holds configuration for hedged upstream requests on read-only endpoints.
+// When the first upstream hasn't answered in time, a second request is sent to another
+// load balanced target. The first response wins and the slower request is cancelled.
+// Tyk classic API definition: `version_data.versions..extended_paths.hedging[*]`.
+type Hedging struct {
+	// Enabled activates request hedging.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.hedging[*].disabled` (negated).
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// Delay is the time to wait for the first response before sending the hedged request.
+	// When a percentile is configured, it is used until enough latencies are observed.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.hedging[*].delay`.
+	Delay ReadableDuration `bson:"delay,omitempty" json:"delay,omitempty"`
+	// Percentile sends the hedged request once the first request is slower than this percentile
+	// of the endpoint's recently observed latencies, for example `95`.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.hedging[*].percentile`.
+	Percentile float64 `bson:"percentile,omitempty" json:"percentile,omitempty"`
+}
+
+// Fill fills *Hedging from apidef.HedgingMeta.
+func (h *Hedging) Fill(meta apidef.HedgingMeta) {
+	h.Enabled = !meta.Disabled
+	h.Delay = meta.Delay
+	h.Percentile = meta.Percentile
+}
+
+// ExtractTo extracts *Hedging into *apidef.HedgingMeta.
+func (h *Hedging) ExtractTo(meta *apidef.HedgingMeta) {
+	meta.Disabled = !h.Enabled
+	meta.Delay = h.Delay
+	meta.Percentile = h.Percentile
+}
+
 // RequestSizeLimit limits the maximum allowed size of the request body in bytes.
 type RequestSizeLimit struct {
 	// Enabled activates the Request Size Limit functionality.
@@ -1910,6 +2161,111 @@ func (c *ContextVariables) ExtractTo(api *apidef.APIDefinition) {
 	api.EnableContextVars = c.Enabled
 }
 
+// MCPToolValidation validates the arguments of the MCP tool calls against the
+// input schemas of the tools. The schemas are learnt from the upstream tools/list
+// responses and from the tool views derived for the x-tyk-mcp-server primitives.
+// Calls with invalid arguments are rejected with a JSON-RPC invalid params error.
+// The learnt schemas are kept in memory across API reloads but not gateway restarts,
+// the gateway asks the upstream for its tools/list when a tool's schema is unknown,
+// at most once a minute.
+type MCPToolValidation struct {
+	// Enabled activates the validation of the MCP tool call arguments.
+	//
+	// Tyk classic API definition: `mcp_tool_validation.enabled`.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// RejectUnknownTools rejects the calls of the tools whose schema is still unknown once
+	// the upstream tools/list was fetched, instead of forwarding them unchecked.
+	//
+	// Tyk classic API definition: `mcp_tool_validation.reject_unknown_tools`.
+	RejectUnknownTools bool `bson:"rejectUnknownTools,omitempty" json:"rejectUnknownTools,omitempty"`
+}
+
+// Fill fills *MCPToolValidation from apidef.APIDefinition.
+func (m *MCPToolValidation) Fill(api apidef.APIDefinition) {
+	m.Enabled = api.MCPToolValidation.Enabled
+	m.RejectUnknownTools = api.MCPToolValidation.RejectUnknownTools
+}
+
+// ExtractTo extracts *MCPToolValidation into *apidef.APIDefinition.
+func (m *MCPToolValidation) ExtractTo(api *apidef.APIDefinition) {
+	api.MCPToolValidation.Enabled = m.Enabled
+	api.MCPToolValidation.RejectUnknownTools = m.RejectUnknownTools
+}
+
+// MCPContentGuard guards the results of the MCP tool calls, prompts and resource reads before
+// they reach the clients, including the results streamed as server-sent events. The redactions
+// also apply to the notifications and requests the server streams. A result over the maximum
+// size or embedding a blocked content type is replaced with a JSON-RPC error. The
+// `MCPContentRedacted` and `MCPContentBlocked` events are fired for the guarded results.
+//
+// Tyk classic API definition: `mcp_content_guard`.
+type MCPContentGuard struct {
+	// Enabled activates the content guard.
+	//
+	// Tyk classic API definition: `mcp_content_guard.enabled`.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// Redactions are the redactions applied in order to the results.
+	//
+	// Tyk classic API definition: `mcp_content_guard.redactions`.
+	Redactions []MCPRedaction `bson:"redactions,omitempty" json:"redactions,omitempty"`
+	// MaxResultSize is the maximum size in bytes of a result, unlimited when 0.
+	//
+	// Tyk classic API definition: `mcp_content_guard.max_result_size`.
+	MaxResultSize int `bson:"maxResultSize,omitempty" json:"maxResultSize,omitempty"`
+	// BlockedContentTypes are the content types the tool call results and prompt messages can't embed,
+	// among `text`, `image`, `audio`, `resource` and `resource_link`.
+	//
+	// Tyk classic API definition: `mcp_content_guard.blocked_content_types`.
+	BlockedContentTypes []string `bson:"blockedContentTypes,omitempty" json:"blockedContentTypes,omitempty"`
+}
+
+// MCPRedaction redacts content of the MCP results:
+// - with a pattern only, its matches are redacted in all the strings of the result,
+// - with a path only, the selected values are replaced,
+// - with both, the matches are redacted in the strings under the selected values.
+type MCPRedaction struct {
+	// Name identifies the redaction in the events, defaults to the path or pattern.
+	//
+	// Tyk classic API definition: `mcp_content_guard.redactions[].name`.
+	Name string `bson:"name,omitempty" json:"name,omitempty"`
+	// Pattern is a regular expression, e.g. `AKIA[0-9A-Z]{16}`.
+	//
+	// Tyk classic API definition: `mcp_content_guard.redactions[].pattern`.
+	Pattern string `bson:"pattern,omitempty" json:"pattern,omitempty"`
+	// Path is a JSON path into the result, e.g. `$.structuredContent..email`. The `.name`, `['name']`,
+	// `[n]`, `[*]`, `.*` and `..name` segments are supported.
+	//
+	// Tyk classic API definition: `mcp_content_guard.redactions[].path`.
+	Path string `bson:"path,omitempty" json:"path,omitempty"`
+	// Replacement replaces the redacted content, defaults to `[REDACTED]`. It can reference the
+	// groups of the pattern, e.g. `****$1`.
+	//
+	// Tyk classic API definition: `mcp_content_guard.redactions[].replacement`.
+	Replacement string `bson:"replacement,omitempty" json:"replacement,omitempty"`
+}
+
+// Fill fills *MCPContentGuard from apidef.APIDefinition.
+func (m *MCPContentGuard) Fill(api apidef.APIDefinition) {
+	m.Enabled = api.MCPContentGuard.Enabled
+	m.MaxResultSize = api.MCPContentGuard.MaxResultSize
+	m.BlockedContentTypes = api.MCPContentGuard.BlockedContentTypes
+	m.Redactions = nil
+	for _, redaction := range api.MCPContentGuard.Redactions {
+		m.Redactions = append(m.Redactions, MCPRedaction(redaction))
+	}
+}
+
+// ExtractTo extracts *MCPContentGuard into *apidef.APIDefinition.
+func (m *MCPContentGuard) ExtractTo(api *apidef.APIDefinition) {
+	api.MCPContentGuard.Enabled = m.Enabled
+	api.MCPContentGuard.MaxResultSize = m.MaxResultSize
+	api.MCPContentGuard.BlockedContentTypes = m.BlockedContentTypes
+	api.MCPContentGuard.Redactions = nil
+	for _, redaction := range m.Redactions {
+		api.MCPContentGuard.Redactions = append(api.MCPContentGuard.Redactions, apidef.MCPRedaction(redaction))
+	}
+}
+
 // IgnoreCase will make route matching be case insensitive.
 // This accepts request to `/AAA` or `/aaa` if set to true.
 type IgnoreCase struct {
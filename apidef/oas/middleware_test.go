@@ -1020,6 +1020,78 @@ func TestCircuitBreaker(t *testing.T) {
 	})
 }
 
+func TestRetryPolicy(t *testing.T) {
+	t.Parallel()
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+		var emptyRetryPolicy RetryPolicy
+
+		var convertedRetryPolicy apidef.RetryPolicyMeta
+		emptyRetryPolicy.ExtractTo(&convertedRetryPolicy)
+
+		var resultRetryPolicy RetryPolicy
+		resultRetryPolicy.Fill(convertedRetryPolicy)
+
+		assert.Equal(t, emptyRetryPolicy, resultRetryPolicy)
+	})
+
+	t.Run("values", func(t *testing.T) {
+		t.Parallel()
+		expectedRetryPolicy := RetryPolicy{
+			Enabled:       true,
+			MaxAttempts:   3,
+			StatusCodes:   []int{502, 503},
+			Errors:        []string{apidef.RetryOnConnectFailure, apidef.RetryOnReset},
+			NonIdempotent: true,
+			BaseBackoff:   ReadableDuration(50 * time.Millisecond),
+			MaxBackoff:    ReadableDuration(time.Second),
+		}
+
+		meta := apidef.RetryPolicyMeta{}
+		expectedRetryPolicy.ExtractTo(&meta)
+		assert.Equal(t, 3, meta.MaxAttempts)
+		assert.False(t, meta.Disabled)
+
+		actualRetryPolicy := RetryPolicy{}
+		actualRetryPolicy.Fill(meta)
+		assert.Equal(t, expectedRetryPolicy, actualRetryPolicy)
+	})
+}
+
+func TestHedging(t *testing.T) {
+	t.Parallel()
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+		var emptyHedging Hedging
+
+		var convertedHedging apidef.HedgingMeta
+		emptyHedging.ExtractTo(&convertedHedging)
+
+		var resultHedging Hedging
+		resultHedging.Fill(convertedHedging)
+
+		assert.Equal(t, emptyHedging, resultHedging)
+	})
+
+	t.Run("values", func(t *testing.T) {
+		t.Parallel()
+		expectedHedging := Hedging{
+			Enabled:    true,
+			Delay:      ReadableDuration(50 * time.Millisecond),
+			Percentile: 95,
+		}
+
+		meta := apidef.HedgingMeta{}
+		expectedHedging.ExtractTo(&meta)
+		assert.False(t, meta.Disabled)
+		assert.Equal(t, 95.0, meta.Percentile)
+
+		actualHedging := Hedging{}
+		actualHedging.Fill(meta)
+		assert.Equal(t, expectedHedging, actualHedging)
+	})
+}
+
 func TestVirtualEndpoint(t *testing.T) {
 	t.Parallel()
 	t.Run("empty", func(t *testing.T) {
@@ -1327,6 +1399,66 @@ func TestContextVariables(t *testing.T) {
 	})
 }
 
+func TestMCPToolValidation(t *testing.T) {
+	t.Parallel()
+	t.Run("fill", func(t *testing.T) {
+		t.Parallel()
+
+		g := new(Global)
+		g.Fill(apidef.APIDefinition{MCPToolValidation: apidef.MCPToolValidation{Enabled: true, RejectUnknownTools: true}})
+		assert.Equal(t, &MCPToolValidation{Enabled: true, RejectUnknownTools: true}, g.MCPToolValidation)
+
+		g = new(Global)
+		g.Fill(apidef.APIDefinition{})
+		assert.Nil(t, g.MCPToolValidation)
+	})
+
+	t.Run("extractTo", func(t *testing.T) {
+		t.Parallel()
+
+		g := &Global{MCPToolValidation: &MCPToolValidation{Enabled: true}}
+
+		var apiDef apidef.APIDefinition
+		g.ExtractTo(&apiDef)
+		assert.True(t, apiDef.MCPToolValidation.Enabled)
+
+		g = new(Global)
+		apiDef = apidef.APIDefinition{}
+		g.ExtractTo(&apiDef)
+		assert.False(t, apiDef.MCPToolValidation.Enabled)
+		assert.Nil(t, g.MCPToolValidation)
+	})
+}
+
+func TestMCPContentGuard(t *testing.T) {
+	t.Parallel()
+
+	guard := &MCPContentGuard{
+		Enabled: true,
+		Redactions: []MCPRedaction{
+			{Name: "aws-key", Pattern: `AKIA[0-9A-Z]{16}`},
+			{Path: "$..email", Replacement: "***"},
+		},
+		MaxResultSize:       65536,
+		BlockedContentTypes: []string{"image"},
+	}
+
+	g := &Global{MCPContentGuard: guard}
+
+	var apiDef apidef.APIDefinition
+	g.ExtractTo(&apiDef)
+	assert.Equal(t, 65536, apiDef.MCPContentGuard.MaxResultSize)
+	assert.Equal(t, apidef.MCPRedaction{Path: "$..email", Replacement: "***"}, apiDef.MCPContentGuard.Redactions[1])
+
+	g = new(Global)
+	g.Fill(apiDef)
+	assert.Equal(t, guard, g.MCPContentGuard)
+
+	g = new(Global)
+	g.Fill(apidef.APIDefinition{})
+	assert.Nil(t, g.MCPContentGuard)
+}
+
 func TestGlobalRequestSizeLimit(t *testing.T) {
 	t.Parallel()
 	t.Run("fill", func(t *testing.T) {
@@ -75,6 +75,12 @@ type Operation struct {
 	// CircuitBreaker contains the configuration for the circuit breaker functionality.
 	CircuitBreaker *CircuitBreaker `bson:"circuitBreaker,omitempty" json:"circuitBreaker,omitempty"`
 
+	// RetryPolicy contains the configuration for retrying failed upstream requests.
+	RetryPolicy *RetryPolicy `bson:"retryPolicy,omitempty" json:"retryPolicy,omitempty"`
+
+	// Hedging contains the configuration for hedged upstream requests.
+	Hedging *Hedging `bson:"hedging,omitempty" json:"hedging,omitempty"`
+
 	// TrackEndpoint contains the configuration for enabling analytics and logs.
 	TrackEndpoint *TrackEndpoint `bson:"trackEndpoint,omitempty" json:"trackEndpoint,omitempty"`
 
@@ -123,6 +129,8 @@ func (o *Operation) ExtractToExtendedPaths(ep *apidef.ExtendedPathsSet, path str
 	o.extractVirtualEndpointTo(ep, path, method)
 	o.extractEndpointPostPluginTo(ep, path, method)
 	o.extractCircuitBreakerTo(ep, path, method)
+	o.extractRetryPolicyTo(ep, path, method)
+	o.extractHedgingTo(ep, path, method)
 	o.extractTrackEndpointTo(ep, path, method)
 	o.extractDoNotTrackEndpointTo(ep, path, method)
 	o.extractRequestSizeLimitTo(ep, path, method)
@@ -205,6 +213,8 @@ func (s *OAS) fillPathsAndOperations(ep apidef.ExtendedPathsSet) {
 	s.fillVirtualEndpoint(ep.Virtual)
 	s.fillEndpointPostPlugins(ep.GoPlugin)
 	s.fillCircuitBreaker(ep.CircuitBreaker)
+	s.fillRetryPolicy(ep.RetryPolicy)
+	s.fillHedging(ep.Hedging)
 	s.fillTrackEndpoint(ep.TrackEndpoints)
 	s.fillDoNotTrackEndpoint(ep.DoNotTrackEndpoints)
 	s.fillRequestSizeLimit(ep.SizeLimit)
@@ -307,6 +317,8 @@ func (s *OAS) extractPathsAndOperations(ep *apidef.ExtendedPathsSet) {
 					tykOp.extractVirtualEndpointTo(ep, path, method)
 					tykOp.extractEndpointPostPluginTo(ep, path, method)
 					tykOp.extractCircuitBreakerTo(ep, path, method)
+					tykOp.extractRetryPolicyTo(ep, path, method)
+					tykOp.extractHedgingTo(ep, path, method)
 					tykOp.extractTrackEndpointTo(ep, path, method)
 					tykOp.extractDoNotTrackEndpointTo(ep, path, method)
 					tykOp.extractRequestSizeLimitTo(ep, path, method)
@@ -1066,6 +1078,56 @@ func (s *OAS) fillCircuitBreaker(metas []apidef.CircuitBreakerMeta) {
 	}
 }
 
+func (o *Operation) extractRetryPolicyTo(ep *apidef.ExtendedPathsSet, path string, method string) {
+	if o.RetryPolicy == nil {
+		return
+	}
+
+	meta := apidef.RetryPolicyMeta{Path: path, Method: method}
+	o.RetryPolicy.ExtractTo(&meta)
+	ep.RetryPolicy = append(ep.RetryPolicy, meta)
+}
+
+func (s *OAS) fillRetryPolicy(metas []apidef.RetryPolicyMeta) {
+	for _, meta := range metas {
+		operationID := s.getOperationID(meta.Path, meta.Method)
+		operation := s.GetTykExtension().getOperation(operationID)
+		if operation.RetryPolicy == nil {
+			operation.RetryPolicy = &RetryPolicy{}
+		}
+
+		operation.RetryPolicy.Fill(meta)
+		if ShouldOmit(operation.RetryPolicy) {
+			operation.RetryPolicy = nil
+		}
+	}
+}
+
+func (o *Operation) extractHedgingTo(ep *apidef.ExtendedPathsSet, path string, method string) {
+	if o.Hedging == nil {
+		return
+	}
+
+	meta := apidef.HedgingMeta{Path: path, Method: method}
+	o.Hedging.ExtractTo(&meta)
+	ep.Hedging = append(ep.Hedging, meta)
+}
+
+func (s *OAS) fillHedging(metas []apidef.HedgingMeta) {
+	for _, meta := range metas {
+		operationID := s.getOperationID(meta.Path, meta.Method)
+		operation := s.GetTykExtension().getOperation(operationID)
+		if operation.Hedging == nil {
+			operation.Hedging = &Hedging{}
+		}
+
+		operation.Hedging.Fill(meta)
+		if ShouldOmit(operation.Hedging) {
+			operation.Hedging = nil
+		}
+	}
+}
+
 // detectMockResponseContentType determines the Content-Type of the mock response.
 // It first checks the headers for an explicit Content-Type, then attempts to detect
 // the type from the body content. Returns "text/plain" if no specific type can be determined.
@@ -36,6 +36,26 @@ type Upstream struct {
 	// Tyk classic API definition: `global_rate_limit`.
 	RateLimit *RateLimit `bson:"rateLimit,omitempty" json:"rateLimit,omitempty"`
 
+	// RateLimitDimensions contains API level rate limits keyed on request attributes.
+	// Tyk classic API definition: `rate_limit_dimensions`.
+	RateLimitDimensions RateLimitDimensions `bson:"rateLimitDimensions,omitempty" json:"rateLimitDimensions,omitempty"`
+
+	// ConcurrencyLimit contains the configuration related to in-flight request limiting.
+	// Tyk classic API definition: `concurrency_limit`.
+	ConcurrencyLimit *ConcurrencyLimit `bson:"concurrencyLimit,omitempty" json:"concurrencyLimit,omitempty"`
+
+	// LoadShedding contains the configuration related to adaptive load shedding.
+	// Tyk classic API definition: `load_shedding`.
+	LoadShedding *LoadShedding `bson:"loadShedding,omitempty" json:"loadShedding,omitempty"`
+
+	// MCPAggregation contains the configuration exposing several MCP APIs as this MCP API.
+	// Tyk classic API definition: `mcp_aggregation`.
+	MCPAggregation *MCPAggregation `bson:"mcpAggregation,omitempty" json:"mcpAggregation,omitempty"`
+
+	// MCPStdio contains the configuration serving this MCP API with a local MCP stdio server.
+	// Tyk classic API definition: `mcp_stdio`.
+	MCPStdio *MCPStdio `bson:"mcpStdio,omitempty" json:"mcpStdio,omitempty"`
+
 	// Authentication contains the configuration related to upstream authentication.
 	// Tyk classic API definition: `upstream_auth`.
 	Authentication *UpstreamAuth `bson:"authentication,omitempty" json:"authentication,omitempty"`
@@ -115,6 +135,44 @@ func (u *Upstream) Fill(api apidef.APIDefinition) {
 		u.RateLimit = nil
 	}
 
+	u.RateLimitDimensions.Fill(api)
+
+	if u.ConcurrencyLimit == nil {
+		u.ConcurrencyLimit = &ConcurrencyLimit{}
+	}
+
+	u.ConcurrencyLimit.Fill(api)
+	if ShouldOmit(u.ConcurrencyLimit) {
+		u.ConcurrencyLimit = nil
+	}
+
+	if u.LoadShedding == nil {
+		u.LoadShedding = &LoadShedding{}
+	}
+
+	u.LoadShedding.Fill(api)
+	if ShouldOmit(u.LoadShedding) {
+		u.LoadShedding = nil
+	}
+
+	if u.MCPAggregation == nil {
+		u.MCPAggregation = &MCPAggregation{}
+	}
+
+	u.MCPAggregation.Fill(api)
+	if ShouldOmit(u.MCPAggregation) {
+		u.MCPAggregation = nil
+	}
+
+	if u.MCPStdio == nil {
+		u.MCPStdio = &MCPStdio{}
+	}
+
+	u.MCPStdio.Fill(api)
+	if ShouldOmit(u.MCPStdio) {
+		u.MCPStdio = nil
+	}
+
 	if u.Authentication == nil {
 		u.Authentication = &UpstreamAuth{}
 	}
@@ -226,6 +284,44 @@ func (u *Upstream) ExtractTo(api *apidef.APIDefinition) {
 
 	u.RateLimit.ExtractTo(api)
 
+	u.RateLimitDimensions.ExtractTo(api)
+
+	if u.ConcurrencyLimit == nil {
+		u.ConcurrencyLimit = &ConcurrencyLimit{}
+		defer func() {
+			u.ConcurrencyLimit = nil
+		}()
+	}
+
+	u.ConcurrencyLimit.ExtractTo(api)
+
+	if u.LoadShedding == nil {
+		u.LoadShedding = &LoadShedding{}
+		defer func() {
+			u.LoadShedding = nil
+		}()
+	}
+
+	u.LoadShedding.ExtractTo(api)
+
+	if u.MCPAggregation == nil {
+		u.MCPAggregation = &MCPAggregation{}
+		defer func() {
+			u.MCPAggregation = nil
+		}()
+	}
+
+	u.MCPAggregation.ExtractTo(api)
+
+	if u.MCPStdio == nil {
+		u.MCPStdio = &MCPStdio{}
+		defer func() {
+			u.MCPStdio = nil
+		}()
+	}
+
+	u.MCPStdio.ExtractTo(api)
+
 	if u.Authentication == nil {
 		u.Authentication = &UpstreamAuth{}
 		defer func() {
@@ -975,6 +1071,350 @@ func (r *RateLimit) ExtractTo(api *apidef.APIDefinition) {
 	api.GlobalRateLimit.Per = r.Per.Seconds()
 }
 
+// RateLimitDimension configures an API level rate limit keyed on request attributes.
+type RateLimitDimension struct {
+	// Enabled activates the rate limit dimension.
+	//
+	// Tyk classic API definition: `!rate_limit_dimensions[].disabled`.
+	Enabled bool `json:"enabled" bson:"enabled"`
+	// Name identifies the dimension, it must be unique within the API.
+	//
+	// Tyk classic API definition: `rate_limit_dimensions[].name`.
+	Name string `json:"name,omitempty" bson:"name,omitempty"`
+	// Key is the value requests are rate limited by, built from context variables.
+	// Every distinct value gets its own rate limit. For example:
+	// - "$tyk_context.remote_addr": limits each client IP.
+	// - "$tyk_context.headers_X_Tenant_Id": limits each tenant.
+	// - "$tyk_context.jwt_claims_sub-$tyk_context.path_parts": combines a JWT claim and the path.
+	//
+	// Requests missing a variable of the key are limited per client, by key or IP, instead.
+	// Context variables must be enabled on the API for the key to be resolved.
+	//
+	// Tyk classic API definition: `rate_limit_dimensions[].key`.
+	Key string `json:"key" bson:"key"`
+	// Rate specifies the number of requests allowed for each key value in each time interval (`per`).
+	//
+	// Tyk classic API definition: `rate_limit_dimensions[].rate`.
+	Rate int `json:"rate" bson:"rate"`
+	// Per defines the time interval for rate limiting using shorthand notation, e.g. "1m" or "30s".
+	//
+	// Tyk classic API definition: `rate_limit_dimensions[].per`.
+	Per ReadableDuration `json:"per" bson:"per"`
+}
+
+// RateLimitDimensions holds the rate limit dimensions of an API.
+type RateLimitDimensions []RateLimitDimension
+
+// Fill fills *RateLimitDimensions from apidef.APIDefinition.
+func (d *RateLimitDimensions) Fill(api apidef.APIDefinition) {
+	if len(api.RateLimitDimensions) == 0 {
+		*d = nil
+		return
+	}
+
+	dimensions := make(RateLimitDimensions, len(api.RateLimitDimensions))
+	for i, dimension := range api.RateLimitDimensions {
+		dimensions[i] = RateLimitDimension{
+			Enabled: !dimension.Disabled,
+			Name:    dimension.Name,
+			Key:     dimension.Key,
+			Rate:    int(dimension.Rate),
+			Per:     ReadableDuration(time.Duration(dimension.Per) * time.Second),
+		}
+	}
+
+	*d = dimensions
+}
+
+// ExtractTo extracts RateLimitDimensions into *apidef.APIDefinition.
+func (d RateLimitDimensions) ExtractTo(api *apidef.APIDefinition) {
+	if len(d) == 0 {
+		api.RateLimitDimensions = nil
+		return
+	}
+
+	api.RateLimitDimensions = make([]apidef.RateLimitDimension, len(d))
+	for i, dimension := range d {
+		api.RateLimitDimensions[i] = apidef.RateLimitDimension{
+			Disabled: !dimension.Enabled,
+			Name:     dimension.Name,
+			Key:      dimension.Key,
+			Rate:     float64(dimension.Rate),
+			Per:      dimension.Per.Seconds(),
+		}
+	}
+}
+
+// ConcurrencyLimit caps the number of requests in flight at the same time.
+// Requests over a cap wait up to `queueTimeout` for a slot to be released,
+// then they are rejected with `rejectStatusCode`.
+//
+// Tyk classic API definition: `concurrency_limit`.
+type ConcurrencyLimit struct {
+	// Enabled activates the concurrency limits.
+	//
+	// Tyk classic API definition: `concurrency_limit.enabled`.
+	Enabled bool `json:"enabled" bson:"enabled"`
+	// PerKey caps the in-flight requests of each session, 0 means unlimited.
+	//
+	// Tyk classic API definition: `concurrency_limit.per_key`.
+	PerKey int64 `json:"perKey,omitempty" bson:"perKey,omitempty"`
+	// PerAPI caps the in-flight requests of the API, 0 means unlimited.
+	//
+	// Tyk classic API definition: `concurrency_limit.per_api`.
+	PerAPI int64 `json:"perAPI,omitempty" bson:"perAPI,omitempty"`
+	// PerUpstreamHost caps the in-flight requests to the upstream host picked for the
+	// request, shared with the other APIs proxying to the same host. 0 means unlimited.
+	//
+	// Tyk classic API definition: `concurrency_limit.per_upstream_host`.
+	PerUpstreamHost int64 `json:"perUpstreamHost,omitempty" bson:"perUpstreamHost,omitempty"`
+	// QueueTimeout is how long requests over a cap wait for a slot, e.g. "500ms" or "2s".
+	// Requests are rejected right away when it's empty.
+	//
+	// Tyk classic API definition: `concurrency_limit.queue_timeout`.
+	QueueTimeout ReadableDuration `json:"queueTimeout,omitempty" bson:"queueTimeout,omitempty"`
+	// RejectStatusCode is the response code of rejected requests, 429 or 503. Defaults to 429.
+	//
+	// Tyk classic API definition: `concurrency_limit.reject_status_code`.
+	RejectStatusCode int `json:"rejectStatusCode,omitempty" bson:"rejectStatusCode,omitempty"`
+	// Distributed counts the in-flight requests across all gateways through the storage
+	// instead of per gateway.
+	//
+	// Tyk classic API definition: `concurrency_limit.distributed`.
+	Distributed bool `json:"distributed,omitempty" bson:"distributed,omitempty"`
+}
+
+// Fill fills *ConcurrencyLimit from apidef.APIDefinition.
+func (c *ConcurrencyLimit) Fill(api apidef.APIDefinition) {
+	c.Enabled = api.ConcurrencyLimit.Enabled
+	c.PerKey = api.ConcurrencyLimit.PerKey
+	c.PerAPI = api.ConcurrencyLimit.PerAPI
+	c.PerUpstreamHost = api.ConcurrencyLimit.PerUpstreamHost
+	c.QueueTimeout = api.ConcurrencyLimit.QueueTimeout
+	c.RejectStatusCode = api.ConcurrencyLimit.RejectStatusCode
+	c.Distributed = api.ConcurrencyLimit.Distributed
+}
+
+// ExtractTo extracts *ConcurrencyLimit into *apidef.APIDefinition.
+func (c *ConcurrencyLimit) ExtractTo(api *apidef.APIDefinition) {
+	api.ConcurrencyLimit.Enabled = c.Enabled
+	api.ConcurrencyLimit.PerKey = c.PerKey
+	api.ConcurrencyLimit.PerAPI = c.PerAPI
+	api.ConcurrencyLimit.PerUpstreamHost = c.PerUpstreamHost
+	api.ConcurrencyLimit.QueueTimeout = c.QueueTimeout
+	api.ConcurrencyLimit.RejectStatusCode = c.RejectStatusCode
+	api.ConcurrencyLimit.Distributed = c.Distributed
+}
+
+// LoadShedding rejects requests over a concurrency limit which the gateway adjusts
+// from the upstream latency and errors of the API.
+//
+// Tyk classic API definition: `load_shedding`.
+type LoadShedding struct {
+	// Enabled activates load shedding.
+	//
+	// Tyk classic API definition: `load_shedding.enabled`.
+	Enabled bool `json:"enabled" bson:"enabled"`
+	// Algorithm adjusting the limit:
+	// - `aimd`: increases the limit by one for successful requests and decreases it by 10% on errors or slow requests (default).
+	// - `gradient`: scales the limit with the ratio between the long term and the current upstream latency.
+	//
+	// Tyk classic API definition: `load_shedding.algorithm`.
+	Algorithm string `json:"algorithm,omitempty" bson:"algorithm,omitempty"`
+	// InitialLimit is the concurrency limit before any request was observed, defaults to 20.
+	//
+	// Tyk classic API definition: `load_shedding.initial_limit`.
+	InitialLimit int `json:"initialLimit,omitempty" bson:"initialLimit,omitempty"`
+	// MinLimit is the lowest concurrency limit, defaults to 1.
+	//
+	// Tyk classic API definition: `load_shedding.min_limit`.
+	MinLimit int `json:"minLimit,omitempty" bson:"minLimit,omitempty"`
+	// MaxLimit is the highest concurrency limit, defaults to 1000.
+	//
+	// Tyk classic API definition: `load_shedding.max_limit`.
+	MaxLimit int `json:"maxLimit,omitempty" bson:"maxLimit,omitempty"`
+	// LatencyThreshold is the upstream latency over which `aimd` decreases the limit, e.g. "500ms".
+	//
+	// Tyk classic API definition: `load_shedding.latency_threshold`.
+	LatencyThreshold ReadableDuration `json:"latencyThreshold,omitempty" bson:"latencyThreshold,omitempty"`
+	// PriorityTags are the session or policy tags of the requests shed last.
+	//
+	// Tyk classic API definition: `load_shedding.priority_tags`.
+	PriorityTags []string `json:"priorityTags,omitempty" bson:"priorityTags,omitempty"`
+	// PriorityReserve is the share of the limit only priority requests can use, defaults to 0.1
+	// when unset. Zero reserves none of the limit.
+	//
+	// Tyk classic API definition: `load_shedding.priority_reserve`.
+	PriorityReserve *float64 `json:"priorityReserve,omitempty" bson:"priorityReserve,omitempty"`
+	// RejectStatusCode is the response code of shed requests, defaults to 503.
+	//
+	// Tyk classic API definition: `load_shedding.reject_status_code`.
+	RejectStatusCode int `json:"rejectStatusCode,omitempty" bson:"rejectStatusCode,omitempty"`
+}
+
+// Fill fills *LoadShedding from apidef.APIDefinition.
+func (l *LoadShedding) Fill(api apidef.APIDefinition) {
+	l.Enabled = api.LoadShedding.Enabled
+	l.Algorithm = api.LoadShedding.Algorithm
+	l.InitialLimit = api.LoadShedding.InitialLimit
+	l.MinLimit = api.LoadShedding.MinLimit
+	l.MaxLimit = api.LoadShedding.MaxLimit
+	l.LatencyThreshold = api.LoadShedding.LatencyThreshold
+	l.PriorityTags = api.LoadShedding.PriorityTags
+	l.PriorityReserve = api.LoadShedding.PriorityReserve
+	l.RejectStatusCode = api.LoadShedding.RejectStatusCode
+}
+
+// ExtractTo extracts *LoadShedding into *apidef.APIDefinition.
+func (l *LoadShedding) ExtractTo(api *apidef.APIDefinition) {
+	api.LoadShedding.Enabled = l.Enabled
+	api.LoadShedding.Algorithm = l.Algorithm
+	api.LoadShedding.InitialLimit = l.InitialLimit
+	api.LoadShedding.MinLimit = l.MinLimit
+	api.LoadShedding.MaxLimit = l.MaxLimit
+	api.LoadShedding.LatencyThreshold = l.LatencyThreshold
+	api.LoadShedding.PriorityTags = l.PriorityTags
+	api.LoadShedding.PriorityReserve = l.PriorityReserve
+	api.LoadShedding.RejectStatusCode = l.RejectStatusCode
+}
+
+// MCPAggregation exposes several MCP APIs as one MCP server. The `initialize` and list
+// requests are fanned out to the member APIs and their results merged, the calls are routed
+// to the member owning the tool, prompt or resource. The member APIs authenticate the
+// forwarded requests and apply their own access rights.
+//
+// Tyk classic API definition: `mcp_aggregation`.
+type MCPAggregation struct {
+	// Enabled activates the aggregation.
+	//
+	// Tyk classic API definition: `mcp_aggregation.enabled`.
+	Enabled bool `json:"enabled" bson:"enabled"`
+	// Members are the aggregated MCP APIs, listed in the order of their primitives.
+	//
+	// Tyk classic API definition: `mcp_aggregation.members`.
+	Members []MCPAggregationMember `json:"members,omitempty" bson:"members,omitempty"`
+}
+
+// MCPAggregationMember is an MCP API of an aggregation.
+type MCPAggregationMember struct {
+	// APIID is the ID of the member MCP API.
+	//
+	// Tyk classic API definition: `mcp_aggregation.members[].api_id`.
+	APIID string `json:"apiId" bson:"apiId"`
+	// Prefix is prepended to the names of the member's tools and prompts and to the URIs of its resources, e.g. `github_`.
+	//
+	// Tyk classic API definition: `mcp_aggregation.members[].prefix`.
+	Prefix string `json:"prefix,omitempty" bson:"prefix,omitempty"`
+}
+
+// Fill fills *MCPAggregation from apidef.APIDefinition.
+func (m *MCPAggregation) Fill(api apidef.APIDefinition) {
+	m.Enabled = api.MCPAggregation.Enabled
+	m.Members = nil
+	for _, member := range api.MCPAggregation.Members {
+		m.Members = append(m.Members, MCPAggregationMember{APIID: member.APIID, Prefix: member.Prefix})
+	}
+}
+
+// ExtractTo extracts *MCPAggregation into *apidef.APIDefinition.
+func (m *MCPAggregation) ExtractTo(api *apidef.APIDefinition) {
+	api.MCPAggregation.Enabled = m.Enabled
+	api.MCPAggregation.Members = nil
+	for _, member := range m.Members {
+		api.MCPAggregation.Members = append(api.MCPAggregation.Members, apidef.MCPAggregationMember{APIID: member.APIID, Prefix: member.Prefix})
+	}
+}
+
+// MCPStdio serves the MCP API with a local MCP server speaking JSON-RPC over its standard
+// input and output, instead of proxying the requests to the upstream URL. The clients keep
+// using Streamable HTTP, so the MCP access control, rate limits and analytics apply. The
+// command and its arguments must be allowed by the `mcp_stdio` section of the gateway
+// configuration, otherwise the API fails to load.
+//
+// Tyk classic API definition: `mcp_stdio`.
+type MCPStdio struct {
+	// Enabled activates the stdio server.
+	//
+	// Tyk classic API definition: `mcp_stdio.enabled`.
+	Enabled bool `json:"enabled" bson:"enabled"`
+	// Command is the executable of the MCP server, e.g. `/usr/local/bin/mcp-server-git`.
+	//
+	// Tyk classic API definition: `mcp_stdio.command`.
+	Command string `json:"command" bson:"command"`
+	// Args are the arguments of the command.
+	//
+	// Tyk classic API definition: `mcp_stdio.args`.
+	Args []string `json:"args,omitempty" bson:"args,omitempty"`
+	// Env are the environment variables of the server as `KEY=value`. The server only
+	// inherits `PATH` and `HOME` from the gateway. The gateway configuration must allow
+	// the names of the variables.
+	//
+	// Tyk classic API definition: `mcp_stdio.env`.
+	Env []string `json:"env,omitempty" bson:"env,omitempty"`
+	// Dir is the working directory of the server, it must be allowed by the gateway configuration.
+	//
+	// Tyk classic API definition: `mcp_stdio.dir`.
+	Dir string `json:"dir,omitempty" bson:"dir,omitempty"`
+	// Mode selects how the servers are spawned:
+	// - `session`: a server per MCP session, started by the `initialize` request and stopped when the session is deleted (default).
+	//   A session can only be used with the key which started it.
+	// - `pool`: a pool of servers initialized by the gateway and shared by all clients.
+	//
+	// Tyk classic API definition: `mcp_stdio.mode`.
+	Mode string `json:"mode,omitempty" bson:"mode,omitempty"`
+	// PoolSize is the number of servers of the pool, defaults to 1.
+	//
+	// Tyk classic API definition: `mcp_stdio.pool_size`.
+	PoolSize int `json:"poolSize,omitempty" bson:"poolSize,omitempty"`
+	// MaxSessions limits the concurrent sessions, defaults to 64.
+	//
+	// Tyk classic API definition: `mcp_stdio.max_sessions`.
+	MaxSessions int `json:"maxSessions,omitempty" bson:"maxSessions,omitempty"`
+	// IdleTimeout stops the servers unused for the duration, e.g. "5m", defaults to 10m.
+	//
+	// Tyk classic API definition: `mcp_stdio.idle_timeout`.
+	IdleTimeout ReadableDuration `json:"idleTimeout,omitempty" bson:"idleTimeout,omitempty"`
+	// RestartBackoff is the delay before respawning a crashed server, doubled after each
+	// consecutive crash, defaults to 1s.
+	//
+	// Tyk classic API definition: `mcp_stdio.restart_backoff`.
+	RestartBackoff ReadableDuration `json:"restartBackoff,omitempty" bson:"restartBackoff,omitempty"`
+	// MaxRestartBackoff caps the restart delay, defaults to 30s.
+	//
+	// Tyk classic API definition: `mcp_stdio.max_restart_backoff`.
+	MaxRestartBackoff ReadableDuration `json:"maxRestartBackoff,omitempty" bson:"maxRestartBackoff,omitempty"`
+}
+
+// Fill fills *MCPStdio from apidef.APIDefinition.
+func (m *MCPStdio) Fill(api apidef.APIDefinition) {
+	m.Enabled = api.MCPStdio.Enabled
+	m.Command = api.MCPStdio.Command
+	m.Args = api.MCPStdio.Args
+	m.Env = api.MCPStdio.Env
+	m.Dir = api.MCPStdio.Dir
+	m.Mode = api.MCPStdio.Mode
+	m.PoolSize = api.MCPStdio.PoolSize
+	m.MaxSessions = api.MCPStdio.MaxSessions
+	m.IdleTimeout = api.MCPStdio.IdleTimeout
+	m.RestartBackoff = api.MCPStdio.RestartBackoff
+	m.MaxRestartBackoff = api.MCPStdio.MaxRestartBackoff
+}
+
+// ExtractTo extracts *MCPStdio into *apidef.APIDefinition.
+func (m *MCPStdio) ExtractTo(api *apidef.APIDefinition) {
+	api.MCPStdio.Enabled = m.Enabled
+	api.MCPStdio.Command = m.Command
+	api.MCPStdio.Args = m.Args
+	api.MCPStdio.Env = m.Env
+	api.MCPStdio.Dir = m.Dir
+	api.MCPStdio.Mode = m.Mode
+	api.MCPStdio.PoolSize = m.PoolSize
+	api.MCPStdio.MaxSessions = m.MaxSessions
+	api.MCPStdio.IdleTimeout = m.IdleTimeout
+	api.MCPStdio.RestartBackoff = m.RestartBackoff
+	api.MCPStdio.MaxRestartBackoff = m.MaxRestartBackoff
+}
+
 // RateLimitEndpoint carries same settings as RateLimit but for endpoints.
 type RateLimitEndpoint RateLimit
 
@@ -1340,6 +1780,82 @@ type LoadBalancing struct {
 	SkipUnavailableHosts bool `json:"skipUnavailableHosts,omitempty" bson:"skipUnavailableHosts,omitempty"`
 	// Targets defines the list of targets with their respective weights for load balancing.
 	Targets []LoadBalancingTarget `json:"targets,omitempty" bson:"targets,omitempty"`
+	// Algorithm is the strategy used to select a target. Valid values are
+	// `round_robin` (default), `weighted_round_robin`, `least_connections`,
+	// `ewma` and `consistent_hash`.
+	// Tyk classic API definition: `proxy.load_balancing.algorithm`
+	Algorithm string `json:"algorithm,omitempty" bson:"algorithm,omitempty"`
+	// HashKey configures the request attribute hashed by the `consistent_hash` algorithm.
+	// Tyk classic API definition: `proxy.load_balancing.hash_key`
+	HashKey *LoadBalancingHashKey `json:"hashKey,omitempty" bson:"hashKey,omitempty"`
+	// PassiveHealthCheck ejects targets from rotation based on the outcome of proxied requests.
+	// Tyk classic API definition: `proxy.load_balancing.passive_health_check`
+	PassiveHealthCheck *PassiveHealthCheck `json:"passiveHealthCheck,omitempty" bson:"passiveHealthCheck,omitempty"`
+}
+
+// PassiveHealthCheck configures passive outlier detection. Upstream 5xx responses,
+// connection errors and timeouts are counted per target, and a target is ejected
+// from load balancing once its error ratio is reached. Ejection and recovery
+// fire the `HostDown` and `HostUp` events.
+type PassiveHealthCheck struct {
+	// Enabled activates passive health checking.
+	// Tyk classic API definition: `proxy.load_balancing.passive_health_check.enabled`
+	Enabled bool `json:"enabled" bson:"enabled"`
+	// ErrorRatio is the fraction of failed requests, between 0 and 1, at which a target is ejected.
+	// Tyk classic API definition: `proxy.load_balancing.passive_health_check.error_ratio`
+	ErrorRatio float64 `json:"errorRatio,omitempty" bson:"errorRatio,omitempty"`
+	// MinRequests is the number of requests a target must serve within the window before the error ratio is evaluated.
+	// Tyk classic API definition: `proxy.load_balancing.passive_health_check.min_requests`
+	MinRequests int `json:"minRequests,omitempty" bson:"minRequests,omitempty"`
+	// Window is the interval over which failures are counted.
+	// Tyk classic API definition: `proxy.load_balancing.passive_health_check.window`
+	Window ReadableDuration `json:"window,omitempty" bson:"window,omitempty"`
+	// EjectionTime is how long a target is first ejected for. It doubles with each consecutive ejection.
+	// Tyk classic API definition: `proxy.load_balancing.passive_health_check.ejection_time`
+	EjectionTime ReadableDuration `json:"ejectionTime,omitempty" bson:"ejectionTime,omitempty"`
+	// MaxEjectionTime caps the ejection backoff.
+	// Tyk classic API definition: `proxy.load_balancing.passive_health_check.max_ejection_time`
+	MaxEjectionTime ReadableDuration `json:"maxEjectionTime,omitempty" bson:"maxEjectionTime,omitempty"`
+}
+
+// Fill fills *PassiveHealthCheck from apidef.PassiveHealthCheck.
+func (p *PassiveHealthCheck) Fill(check apidef.PassiveHealthCheck) {
+	p.Enabled = check.Enabled
+	p.ErrorRatio = check.ErrorRatio
+	p.MinRequests = check.MinRequests
+	p.Window = ReadableDuration(time.Duration(check.Window) * time.Second)
+	p.EjectionTime = ReadableDuration(time.Duration(check.EjectionTime) * time.Second)
+	p.MaxEjectionTime = ReadableDuration(time.Duration(check.MaxEjectionTime) * time.Second)
+}
+
+// ExtractTo extracts *PassiveHealthCheck into *apidef.PassiveHealthCheck.
+func (p *PassiveHealthCheck) ExtractTo(check *apidef.PassiveHealthCheck) {
+	check.Enabled = p.Enabled
+	check.ErrorRatio = p.ErrorRatio
+	check.MinRequests = p.MinRequests
+	check.Window = int64(p.Window.Seconds())
+	check.EjectionTime = int64(p.EjectionTime.Seconds())
+	check.MaxEjectionTime = int64(p.MaxEjectionTime.Seconds())
+}
+
+// LoadBalancingHashKey defines where the consistent hashing key is read from.
+type LoadBalancingHashKey struct {
+	// Source is the request attribute to hash: `ip` (default), `header` or `session`.
+	Source string `json:"source,omitempty" bson:"source,omitempty"`
+	// Name is the header name when Source is `header`.
+	Name string `json:"name,omitempty" bson:"name,omitempty"`
+}
+
+// Fill fills *LoadBalancingHashKey from apidef.LoadBalancingHashKey.
+func (h *LoadBalancingHashKey) Fill(hashKey apidef.LoadBalancingHashKey) {
+	h.Source = string(hashKey.Source)
+	h.Name = hashKey.Name
+}
+
+// ExtractTo extracts *LoadBalancingHashKey into *apidef.LoadBalancingHashKey.
+func (h *LoadBalancingHashKey) ExtractTo(hashKey *apidef.LoadBalancingHashKey) {
+	hashKey.Source = apidef.LoadBalancingHashKeySource(h.Source)
+	hashKey.Name = h.Name
 }
 
 // LoadBalancingTarget represents a single upstream target for load balancing with a URL and an associated weight.
@@ -1361,6 +1877,23 @@ func (l *LoadBalancing) Fill(api apidef.APIDefinition) {
 
 	l.Enabled = api.Proxy.EnableLoadBalancing
 	l.SkipUnavailableHosts = api.Proxy.CheckHostAgainstUptimeTests
+	l.Algorithm = string(api.Proxy.LoadBalancing.Algorithm)
+
+	if l.HashKey == nil {
+		l.HashKey = &LoadBalancingHashKey{}
+	}
+	l.HashKey.Fill(api.Proxy.LoadBalancing.HashKey)
+	if ShouldOmit(l.HashKey) {
+		l.HashKey = nil
+	}
+
+	if l.PassiveHealthCheck == nil {
+		l.PassiveHealthCheck = &PassiveHealthCheck{}
+	}
+	l.PassiveHealthCheck.Fill(api.Proxy.LoadBalancing.PassiveHealthCheck)
+	if ShouldOmit(l.PassiveHealthCheck) {
+		l.PassiveHealthCheck = nil
+	}
 
 	targetCounter := make(map[string]*LoadBalancingTarget)
 	for _, target := range api.Proxy.Targets {
@@ -1409,12 +1942,31 @@ func (l *LoadBalancing) ExtractTo(api *apidef.APIDefinition) {
 		api.Proxy.EnableLoadBalancing = false
 		api.Proxy.CheckHostAgainstUptimeTests = false
 		api.Proxy.Targets = nil
+		api.Proxy.LoadBalancing = apidef.LoadBalancingConfig{}
 		return
 	}
 
 	proxyConfTargets := make([]string, 0, len(l.Targets))
 	api.Proxy.EnableLoadBalancing = l.Enabled
 	api.Proxy.CheckHostAgainstUptimeTests = l.SkipUnavailableHosts
+	api.Proxy.LoadBalancing.Algorithm = apidef.LoadBalancingAlgorithm(l.Algorithm)
+
+	if l.HashKey == nil {
+		l.HashKey = &LoadBalancingHashKey{}
+		defer func() {
+			l.HashKey = nil
+		}()
+	}
+	l.HashKey.ExtractTo(&api.Proxy.LoadBalancing.HashKey)
+
+	if l.PassiveHealthCheck == nil {
+		l.PassiveHealthCheck = &PassiveHealthCheck{}
+		defer func() {
+			l.PassiveHealthCheck = nil
+		}()
+	}
+	l.PassiveHealthCheck.ExtractTo(&api.Proxy.LoadBalancing.PassiveHealthCheck)
+
 	for _, target := range l.Targets {
 		for i := 0; i < target.Weight; i++ {
 			proxyConfTargets = append(proxyConfTargets, target.URL)
@@ -8,6 +8,7 @@ import (
 	"sort"
 	"testing"
 
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -269,6 +270,142 @@ func TestUpstream(t *testing.T) {
 		})
 
 	})
+
+	t.Run("concurrency limit", func(t *testing.T) {
+		concurrencyUpstream := Upstream{
+			ConcurrencyLimit: &ConcurrencyLimit{
+				Enabled:          true,
+				PerKey:           5,
+				PerAPI:           100,
+				PerUpstreamHost:  200,
+				QueueTimeout:     ReadableDuration(500 * time.Millisecond),
+				RejectStatusCode: http.StatusServiceUnavailable,
+				Distributed:      true,
+			},
+		}
+
+		var convertedAPI apidef.APIDefinition
+		convertedAPI.SetDisabledFlags()
+		concurrencyUpstream.ExtractTo(&convertedAPI)
+
+		assert.Equal(t, int64(100), convertedAPI.ConcurrencyLimit.PerAPI)
+
+		var resultUpstream Upstream
+		resultUpstream.Fill(convertedAPI)
+
+		assert.Equal(t, concurrencyUpstream, resultUpstream)
+	})
+
+	t.Run("load shedding", func(t *testing.T) {
+		sheddingUpstream := Upstream{
+			LoadShedding: &LoadShedding{
+				Enabled:          true,
+				Algorithm:        apidef.LoadSheddingGradient,
+				InitialLimit:     50,
+				MinLimit:         10,
+				MaxLimit:         500,
+				LatencyThreshold: ReadableDuration(time.Second),
+				PriorityTags:     []string{"premium"},
+				PriorityReserve:  lo.ToPtr(0.2),
+				RejectStatusCode: http.StatusTooManyRequests,
+			},
+		}
+
+		var convertedAPI apidef.APIDefinition
+		convertedAPI.SetDisabledFlags()
+		sheddingUpstream.ExtractTo(&convertedAPI)
+
+		assert.Equal(t, []string{"premium"}, convertedAPI.LoadShedding.PriorityTags)
+
+		var resultUpstream Upstream
+		resultUpstream.Fill(convertedAPI)
+
+		assert.Equal(t, sheddingUpstream, resultUpstream)
+	})
+
+	t.Run("mcp aggregation", func(t *testing.T) {
+		aggregationUpstream := Upstream{
+			MCPAggregation: &MCPAggregation{
+				Enabled: true,
+				Members: []MCPAggregationMember{
+					{APIID: "github", Prefix: "github_"},
+					{APIID: "jira"},
+				},
+			},
+		}
+
+		var convertedAPI apidef.APIDefinition
+		convertedAPI.SetDisabledFlags()
+		aggregationUpstream.ExtractTo(&convertedAPI)
+
+		assert.Equal(t, []apidef.MCPAggregationMember{{APIID: "github", Prefix: "github_"}, {APIID: "jira"}}, convertedAPI.MCPAggregation.Members)
+
+		var resultUpstream Upstream
+		resultUpstream.Fill(convertedAPI)
+
+		assert.Equal(t, aggregationUpstream, resultUpstream)
+	})
+
+	t.Run("mcp stdio", func(t *testing.T) {
+		stdioUpstream := Upstream{
+			MCPStdio: &MCPStdio{
+				Enabled:        true,
+				Command:        "mcp-server-git",
+				Args:           []string{"--repository", "/srv/repo"},
+				Env:            []string{"LOG_LEVEL=debug"},
+				Mode:           apidef.MCPStdioPool,
+				PoolSize:       2,
+				IdleTimeout:    ReadableDuration(10 * time.Minute),
+				RestartBackoff: ReadableDuration(2 * time.Second),
+			},
+		}
+
+		var convertedAPI apidef.APIDefinition
+		convertedAPI.SetDisabledFlags()
+		stdioUpstream.ExtractTo(&convertedAPI)
+
+		assert.Equal(t, "mcp-server-git", convertedAPI.MCPStdio.Command)
+		assert.Equal(t, ReadableDuration(10*time.Minute), convertedAPI.MCPStdio.IdleTimeout)
+
+		var resultUpstream Upstream
+		resultUpstream.Fill(convertedAPI)
+
+		assert.Equal(t, stdioUpstream, resultUpstream)
+	})
+
+	t.Run("rate limit dimensions", func(t *testing.T) {
+		rateLimitUpstream := Upstream{
+			RateLimitDimensions: RateLimitDimensions{
+				{
+					Enabled: true,
+					Name:    "per-ip",
+					Key:     "$tyk_context.remote_addr",
+					Rate:    10,
+					Per:     ReadableDuration(time.Minute),
+				},
+				{
+					Enabled: false,
+					Name:    "per-tenant",
+					Key:     "$tyk_context.headers_X_Tenant_Id",
+					Rate:    100,
+					Per:     ReadableDuration(time.Second),
+				},
+			},
+		}
+
+		var convertedAPI apidef.APIDefinition
+		convertedAPI.SetDisabledFlags()
+		rateLimitUpstream.ExtractTo(&convertedAPI)
+
+		require.Len(t, convertedAPI.RateLimitDimensions, 2)
+		assert.Equal(t, float64(60), convertedAPI.RateLimitDimensions[0].Per)
+		assert.True(t, convertedAPI.RateLimitDimensions[1].Disabled)
+
+		var resultUpstream Upstream
+		resultUpstream.Fill(convertedAPI)
+
+		assert.Equal(t, rateLimitUpstream, resultUpstream)
+	})
 }
 
 func TestServiceDiscovery(t *testing.T) {
@@ -1111,6 +1248,85 @@ func TestLoadBalancing(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("algorithm", func(t *testing.T) {
+		t.Parallel()
+
+		input := &LoadBalancing{
+			Enabled:   true,
+			Algorithm: string(apidef.LoadBalancingConsistentHash),
+			HashKey: &LoadBalancingHashKey{
+				Source: string(apidef.LoadBalancingHashKeyHeader),
+				Name:   "X-Tenant-ID",
+			},
+			Targets: []LoadBalancingTarget{
+				{URL: "http://upstream-one", Weight: 1},
+				{URL: "http://upstream-two", Weight: 1},
+			},
+		}
+
+		g := new(Upstream)
+		g.LoadBalancing = input
+
+		var apiDef apidef.APIDefinition
+		g.ExtractTo(&apiDef)
+
+		assert.Equal(t, apidef.LoadBalancingConfig{
+			Algorithm: apidef.LoadBalancingConsistentHash,
+			HashKey: apidef.LoadBalancingHashKey{
+				Source: apidef.LoadBalancingHashKeyHeader,
+				Name:   "X-Tenant-ID",
+			},
+		}, apiDef.Proxy.LoadBalancing)
+
+		filled := new(Upstream)
+		filled.Fill(apiDef)
+		assert.Equal(t, input, filled.LoadBalancing)
+
+		// Without targets the algorithm settings are reset.
+		g.LoadBalancing = &LoadBalancing{Algorithm: string(apidef.LoadBalancingEWMA)}
+		g.ExtractTo(&apiDef)
+		assert.Empty(t, apiDef.Proxy.LoadBalancing)
+	})
+
+	t.Run("passive health check", func(t *testing.T) {
+		t.Parallel()
+
+		input := &LoadBalancing{
+			Enabled: true,
+			PassiveHealthCheck: &PassiveHealthCheck{
+				Enabled:         true,
+				ErrorRatio:      0.25,
+				MinRequests:     10,
+				Window:          ReadableDuration(30 * time.Second),
+				EjectionTime:    ReadableDuration(time.Minute),
+				MaxEjectionTime: ReadableDuration(10 * time.Minute),
+			},
+			Targets: []LoadBalancingTarget{
+				{URL: "http://upstream-one", Weight: 1},
+				{URL: "http://upstream-two", Weight: 1},
+			},
+		}
+
+		g := new(Upstream)
+		g.LoadBalancing = input
+
+		var apiDef apidef.APIDefinition
+		g.ExtractTo(&apiDef)
+
+		assert.Equal(t, apidef.PassiveHealthCheck{
+			Enabled:         true,
+			ErrorRatio:      0.25,
+			MinRequests:     10,
+			Window:          30,
+			EjectionTime:    60,
+			MaxEjectionTime: 600,
+		}, apiDef.Proxy.LoadBalancing.PassiveHealthCheck)
+
+		filled := new(Upstream)
+		filled.Fill(apiDef)
+		assert.Equal(t, input, filled.LoadBalancing)
+	})
 }
 
 func TestLoadBalancingWeightZeroTargets(t *testing.T) {
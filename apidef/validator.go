@@ -4,8 +4,12 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
+	"regexp"
 	"sort"
 	"strings"
+
+	"github.com/TykTechnologies/tyk/internal/jsonpath"
 )
 
 type ValidationResult struct {
@@ -60,6 +64,15 @@ var DefaultValidationRuleSet = ValidationRuleSet{
 	&RuleValidateEnforceTimeout{},
 	&RuleUpstreamAuth{},
 	&RuleLoadBalancingTargets{},
+	&RuleLoadBalancingAlgorithm{},
+	&RuleValidateRetryPolicy{},
+	&RuleValidateHedging{},
+	&RuleValidateRateLimitDimensions{},
+	&RuleValidateConcurrencyLimit{},
+	&RuleValidateLoadShedding{},
+	&RuleValidateMCPAggregation{},
+	&RuleValidateMCPStdio{},
+	&RuleValidateMCPContentGuard{},
 }
 
 func Validate(definition *APIDefinition, ruleSet ValidationRuleSet) ValidationResult {
@@ -230,6 +243,39 @@ var (
 	ErrInvalidUpstreamOAuthClientAuthMethod = errors.New("invalid upstream OAuth client authentication method, valid values are: client_secret_basic, client_secret_post")
 	// ErrAllLoadBalancingTargetsZeroWeight is the error to return when all load balancing targets have weight 0.
 	ErrAllLoadBalancingTargetsZeroWeight = errors.New("all load balancing targets have weight 0, at least one target must have weight > 0")
+	// ErrInvalidLoadBalancingAlgorithm is the error to return when the configured load balancing algorithm is unknown.
+	ErrInvalidLoadBalancingAlgorithm = errors.New("invalid load balancing algorithm, valid values are: round_robin, weighted_round_robin, least_connections, ewma, consistent_hash")
+	// ErrInvalidLoadBalancingHashKey is the error to return when the consistent hashing key configuration is invalid.
+	ErrInvalidLoadBalancingHashKey = errors.New("invalid load balancing hash key, source must be one of ip, header, session and a header source requires a name")
+	// ErrInvalidRetryPolicy is the error to return when an endpoint retry policy has negative values.
+	ErrInvalidRetryPolicy = errors.New("invalid retry policy, max attempts and backoffs must not be negative")
+	// ErrInvalidRetryOnError is the error to return when an endpoint retry policy lists an unknown network error.
+	ErrInvalidRetryOnError = errors.New("invalid retry policy error, valid values are: connect_failure, reset, timeout")
+	// ErrInvalidHedging is the error to return when an endpoint hedging configuration is invalid.
+	ErrInvalidHedging = errors.New("invalid hedging, a positive delay or a percentile between 0 and 100 is required")
+	// ErrInvalidHedgingMethod is the error to return when hedging is configured for an endpoint which isn't read-only.
+	ErrInvalidHedgingMethod = errors.New("invalid hedging method, only GET and HEAD endpoints can be hedged")
+	// ErrInvalidRateLimitDimension is the error to return when a rate limit dimension is misconfigured.
+	ErrInvalidRateLimitDimension = errors.New("invalid rate limit dimension, a key, a positive rate and per are required")
+	// ErrDuplicateRateLimitDimension is the error to return when rate limit dimension names aren't unique.
+	ErrDuplicateRateLimitDimension = errors.New("duplicate rate limit dimension names are not allowed")
+	// ErrRateLimitDimensionContextVars is the error to return when a rate limit dimension key uses context variables
+	// that aren't enabled on the API.
+	ErrRateLimitDimensionContextVars = errors.New("rate limit dimension keys using context variables require context variables to be enabled")
+	// ErrInvalidConcurrencyLimit is the error to return when the concurrency limits are negative.
+	ErrInvalidConcurrencyLimit = errors.New("invalid concurrency limit, limits and queue timeout must not be negative")
+	// ErrInvalidConcurrencyLimitStatusCode is the error to return when the concurrency limit reject status code is unsupported.
+	ErrInvalidConcurrencyLimitStatusCode = errors.New("invalid concurrency limit reject status code, valid values are: 429, 503")
+	// ErrInvalidLoadSheddingAlgorithm is the error to return when the load shedding algorithm is unknown.
+	ErrInvalidLoadSheddingAlgorithm = errors.New("invalid load shedding algorithm, valid values are: aimd, gradient")
+	// ErrInvalidLoadShedding is the error to return when the load shedding limits are misconfigured.
+	ErrInvalidLoadShedding = errors.New("invalid load shedding, limits must not be negative, max limit must not be lower than min limit and priority reserve must be lower than 1")
+	// ErrInvalidMCPAggregation is the error to return when the MCP aggregation members are misconfigured.
+	ErrInvalidMCPAggregation = errors.New("invalid MCP aggregation, members must be other APIs, listed once, with distinct prefixes")
+	// ErrInvalidMCPStdio is the error to return when the MCP stdio server is misconfigured.
+	ErrInvalidMCPStdio = errors.New("invalid MCP stdio server, command is required, mode must be session or pool and limits must not be negative")
+	// ErrInvalidMCPContentGuard is the error to return when the MCP content guard is misconfigured.
+	ErrInvalidMCPContentGuard = errors.New("invalid MCP content guard, redactions require a valid pattern or path and the max result size must not be negative")
 )
 
 // RuleUpstreamAuth implements validations for upstream authentication configurations.
@@ -295,3 +341,245 @@ func (r *RuleLoadBalancingTargets) Validate(apiDef *APIDefinition, validationRes
 		validationResult.AppendError(ErrAllLoadBalancingTargetsZeroWeight)
 	}
 }
+
+// RuleLoadBalancingAlgorithm implements validations for the load balancing algorithm configuration.
+type RuleLoadBalancingAlgorithm struct{}
+
+// Validate validates the load balancing algorithm and the consistent hashing key.
+func (r *RuleLoadBalancingAlgorithm) Validate(apiDef *APIDefinition, validationResult *ValidationResult) {
+	lb := apiDef.Proxy.LoadBalancing
+
+	if !lb.Algorithm.Valid() {
+		validationResult.IsValid = false
+		validationResult.AppendError(ErrInvalidLoadBalancingAlgorithm)
+		return
+	}
+
+	if lb.Algorithm != LoadBalancingConsistentHash {
+		return
+	}
+
+	if !lb.HashKey.Source.Valid() || (lb.HashKey.Source == LoadBalancingHashKeyHeader && lb.HashKey.Name == "") {
+		validationResult.IsValid = false
+		validationResult.AppendError(ErrInvalidLoadBalancingHashKey)
+	}
+}
+
+// RuleValidateRetryPolicy implements validations for endpoint retry policies.
+type RuleValidateRetryPolicy struct{}
+
+// Validate validates the attempt count, backoffs and retried errors of retry policies.
+func (r *RuleValidateRetryPolicy) Validate(apiDef *APIDefinition, validationResult *ValidationResult) {
+	for _, vInfo := range apiDef.VersionData.Versions {
+		for _, policy := range vInfo.ExtendedPaths.RetryPolicy {
+			if policy.MaxAttempts < 0 || policy.BaseBackoff < 0 || policy.MaxBackoff < 0 {
+				validationResult.IsValid = false
+				validationResult.AppendError(ErrInvalidRetryPolicy)
+				return
+			}
+
+			for _, kind := range policy.RetryOnErrors {
+				switch kind {
+				case RetryOnConnectFailure, RetryOnReset, RetryOnTimeout:
+				default:
+					validationResult.IsValid = false
+					validationResult.AppendError(ErrInvalidRetryOnError)
+					return
+				}
+			}
+		}
+	}
+}
+
+// RuleValidateHedging implements validations for endpoint hedging.
+type RuleValidateHedging struct{}
+
+// Validate validates the delay, percentile and method of hedged endpoints.
+func (r *RuleValidateHedging) Validate(apiDef *APIDefinition, validationResult *ValidationResult) {
+	for _, vInfo := range apiDef.VersionData.Versions {
+		for _, hedging := range vInfo.ExtendedPaths.Hedging {
+			if hedging.Disabled {
+				continue
+			}
+
+			if hedging.Delay < 0 || hedging.Percentile < 0 || hedging.Percentile > 100 ||
+				(hedging.Delay == 0 && hedging.Percentile == 0) {
+				validationResult.IsValid = false
+				validationResult.AppendError(ErrInvalidHedging)
+				return
+			}
+
+			if hedging.Method != http.MethodGet && hedging.Method != http.MethodHead {
+				validationResult.IsValid = false
+				validationResult.AppendError(ErrInvalidHedgingMethod)
+				return
+			}
+		}
+	}
+}
+
+// RuleValidateRateLimitDimensions implements validations for rate limit dimensions.
+type RuleValidateRateLimitDimensions struct{}
+
+// contextVarPrefix marks a context variable in a rate limit dimension key.
+const contextVarPrefix = "$tyk_context."
+
+// Validate validates that enabled rate limit dimensions have a key, a rate and a unique name,
+// and that the context variables of their keys are enabled.
+func (r *RuleValidateRateLimitDimensions) Validate(apiDef *APIDefinition, validationResult *ValidationResult) {
+	names := map[string]bool{}
+	for _, dimension := range apiDef.RateLimitDimensions {
+		if dimension.Disabled {
+			continue
+		}
+
+		if !dimension.Valid() {
+			validationResult.IsValid = false
+			validationResult.AppendError(ErrInvalidRateLimitDimension)
+			return
+		}
+
+		if !apiDef.EnableContextVars && strings.Contains(dimension.Key, contextVarPrefix) {
+			validationResult.IsValid = false
+			validationResult.AppendError(ErrRateLimitDimensionContextVars)
+			return
+		}
+
+		if names[dimension.Name] {
+			validationResult.IsValid = false
+			validationResult.AppendError(ErrDuplicateRateLimitDimension)
+			return
+		}
+		names[dimension.Name] = true
+	}
+}
+
+// RuleValidateConcurrencyLimit implements validations for concurrency limits.
+type RuleValidateConcurrencyLimit struct{}
+
+// Validate validates the concurrency limits and reject status code.
+func (r *RuleValidateConcurrencyLimit) Validate(apiDef *APIDefinition, validationResult *ValidationResult) {
+	limit := apiDef.ConcurrencyLimit
+	if !limit.Enabled {
+		return
+	}
+
+	if limit.PerKey < 0 || limit.PerAPI < 0 || limit.PerUpstreamHost < 0 || limit.QueueTimeout < 0 {
+		validationResult.IsValid = false
+		validationResult.AppendError(ErrInvalidConcurrencyLimit)
+		return
+	}
+
+	switch limit.RejectStatusCode {
+	case 0, http.StatusTooManyRequests, http.StatusServiceUnavailable:
+	default:
+		validationResult.IsValid = false
+		validationResult.AppendError(ErrInvalidConcurrencyLimitStatusCode)
+	}
+}
+
+// RuleValidateLoadShedding implements validations for load shedding.
+type RuleValidateLoadShedding struct{}
+
+// Validate validates the load shedding algorithm and limits.
+func (r *RuleValidateLoadShedding) Validate(apiDef *APIDefinition, validationResult *ValidationResult) {
+	shedding := apiDef.LoadShedding
+	if !shedding.Enabled {
+		return
+	}
+
+	switch shedding.Algorithm {
+	case "", LoadSheddingAIMD, LoadSheddingGradient:
+	default:
+		validationResult.IsValid = false
+		validationResult.AppendError(ErrInvalidLoadSheddingAlgorithm)
+		return
+	}
+
+	if shedding.InitialLimit < 0 || shedding.MinLimit < 0 || shedding.MaxLimit < 0 ||
+		(shedding.MaxLimit > 0 && shedding.MaxLimit < shedding.MinLimit) ||
+		(shedding.PriorityReserve != nil && (*shedding.PriorityReserve < 0 || *shedding.PriorityReserve >= 1)) ||
+		shedding.LatencyThreshold < 0 {
+		validationResult.IsValid = false
+		validationResult.AppendError(ErrInvalidLoadShedding)
+	}
+}
+
+// RuleValidateMCPAggregation implements validations for MCP aggregation.
+type RuleValidateMCPAggregation struct{}
+
+// Validate validates the members of the MCP aggregation.
+func (r *RuleValidateMCPAggregation) Validate(apiDef *APIDefinition, validationResult *ValidationResult) {
+	aggregation := apiDef.MCPAggregation
+	if !aggregation.Enabled {
+		return
+	}
+
+	apiIDs := make(map[string]bool, len(aggregation.Members))
+	prefixes := make(map[string]bool, len(aggregation.Members))
+	for _, member := range aggregation.Members {
+		if member.APIID == "" || member.APIID == apiDef.APIID || apiIDs[member.APIID] || prefixes[member.Prefix] {
+			validationResult.IsValid = false
+			validationResult.AppendError(ErrInvalidMCPAggregation)
+			return
+		}
+		apiIDs[member.APIID] = true
+		prefixes[member.Prefix] = true
+	}
+}
+
+// RuleValidateMCPContentGuard implements validations for the MCP content guard.
+type RuleValidateMCPContentGuard struct{}
+
+// Validate validates the redactions and the max result size of the MCP content guard.
+func (r *RuleValidateMCPContentGuard) Validate(apiDef *APIDefinition, validationResult *ValidationResult) {
+	guard := apiDef.MCPContentGuard
+	if !guard.Enabled {
+		return
+	}
+
+	valid := guard.MaxResultSize >= 0
+	for _, redaction := range guard.Redactions {
+		if redaction.Pattern == "" && redaction.Path == "" {
+			valid = false
+		}
+		if redaction.Path != "" {
+			if _, err := jsonpath.Parse(redaction.Path); err != nil {
+				valid = false
+			}
+		}
+		if _, err := regexp.Compile(redaction.Pattern); err != nil {
+			valid = false
+		}
+	}
+
+	if !valid {
+		validationResult.IsValid = false
+		validationResult.AppendError(ErrInvalidMCPContentGuard)
+	}
+}
+
+// RuleValidateMCPStdio implements validations for MCP stdio servers.
+type RuleValidateMCPStdio struct{}
+
+// Validate validates the command, mode and limits of the MCP stdio server.
+func (r *RuleValidateMCPStdio) Validate(apiDef *APIDefinition, validationResult *ValidationResult) {
+	stdio := apiDef.MCPStdio
+	if !stdio.Enabled {
+		return
+	}
+
+	switch stdio.Mode {
+	case "", MCPStdioSession, MCPStdioPool:
+	default:
+		validationResult.IsValid = false
+		validationResult.AppendError(ErrInvalidMCPStdio)
+		return
+	}
+
+	if stdio.Command == "" || stdio.PoolSize < 0 || stdio.MaxSessions < 0 ||
+		stdio.IdleTimeout < 0 || stdio.RestartBackoff < 0 || stdio.MaxRestartBackoff < 0 {
+		validationResult.IsValid = false
+		validationResult.AppendError(ErrInvalidMCPStdio)
+	}
+}
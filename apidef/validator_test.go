@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
 
 	tyktime "github.com/TykTechnologies/tyk/internal/time"
@@ -704,3 +705,592 @@ func TestRuleLoadBalancingTargets_Validate(t *testing.T) {
 		t.Run(tc.name, runValidationTest(tc.apiDef, ruleSet, tc.result))
 	}
 }
+
+func TestRuleLoadBalancingAlgorithm_Validate(t *testing.T) {
+	ruleSet := ValidationRuleSet{
+		&RuleLoadBalancingAlgorithm{},
+	}
+
+	testCases := []struct {
+		name   string
+		config LoadBalancingConfig
+		result ValidationResult
+	}{
+		{
+			name:   "default algorithm",
+			config: LoadBalancingConfig{},
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "least connections",
+			config: LoadBalancingConfig{Algorithm: LoadBalancingLeastConnections},
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "unknown algorithm",
+			config: LoadBalancingConfig{Algorithm: "random"},
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrInvalidLoadBalancingAlgorithm},
+			},
+		},
+		{
+			name: "consistent hash on header",
+			config: LoadBalancingConfig{
+				Algorithm: LoadBalancingConsistentHash,
+				HashKey:   LoadBalancingHashKey{Source: LoadBalancingHashKeyHeader, Name: "X-Tenant"},
+			},
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name: "consistent hash on header without name",
+			config: LoadBalancingConfig{
+				Algorithm: LoadBalancingConsistentHash,
+				HashKey:   LoadBalancingHashKey{Source: LoadBalancingHashKeyHeader},
+			},
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrInvalidLoadBalancingHashKey},
+			},
+		},
+		{
+			name: "consistent hash with unknown source",
+			config: LoadBalancingConfig{
+				Algorithm: LoadBalancingConsistentHash,
+				HashKey:   LoadBalancingHashKey{Source: "cookie"},
+			},
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrInvalidLoadBalancingHashKey},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		apiDef := &APIDefinition{Proxy: ProxyConfig{LoadBalancing: tc.config}}
+		t.Run(tc.name, runValidationTest(apiDef, ruleSet, tc.result))
+	}
+}
+
+func TestRuleValidateRetryPolicy_Validate(t *testing.T) {
+	ruleSet := ValidationRuleSet{
+		&RuleValidateRetryPolicy{},
+	}
+
+	getAPIDef := func(policies ...RetryPolicyMeta) *APIDefinition {
+		return &APIDefinition{
+			VersionData: VersionData{
+				Versions: map[string]VersionInfo{
+					"Default": {
+						Name: "Default",
+						ExtendedPaths: ExtendedPathsSet{
+							RetryPolicy: policies,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name   string
+		apiDef *APIDefinition
+		result ValidationResult
+	}{
+		{
+			name: "valid policy",
+			apiDef: getAPIDef(RetryPolicyMeta{
+				Path:               "/get",
+				Method:             http.MethodGet,
+				MaxAttempts:        3,
+				RetryOnStatusCodes: []int{http.StatusBadGateway},
+				RetryOnErrors:      []string{RetryOnConnectFailure, RetryOnReset, RetryOnTimeout},
+				BaseBackoff:        tyktime.ReadableDuration(10 * time.Millisecond),
+				MaxBackoff:         tyktime.ReadableDuration(time.Second),
+			}),
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "negative max attempts",
+			apiDef: getAPIDef(RetryPolicyMeta{Path: "/get", Method: http.MethodGet, MaxAttempts: -1}),
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrInvalidRetryPolicy},
+			},
+		},
+		{
+			name: "negative backoff",
+			apiDef: getAPIDef(RetryPolicyMeta{
+				Path:        "/get",
+				Method:      http.MethodGet,
+				MaxAttempts: 2,
+				BaseBackoff: tyktime.ReadableDuration(-time.Second),
+			}),
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrInvalidRetryPolicy},
+			},
+		},
+		{
+			name: "unknown error",
+			apiDef: getAPIDef(RetryPolicyMeta{
+				Path:          "/get",
+				Method:        http.MethodGet,
+				MaxAttempts:   2,
+				RetryOnErrors: []string{"dns"},
+			}),
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrInvalidRetryOnError},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, runValidationTest(tc.apiDef, ruleSet, tc.result))
+	}
+}
+
+func TestRuleValidateHedging_Validate(t *testing.T) {
+	ruleSet := ValidationRuleSet{
+		&RuleValidateHedging{},
+	}
+
+	getAPIDef := func(hedging ...HedgingMeta) *APIDefinition {
+		return &APIDefinition{
+			VersionData: VersionData{
+				Versions: map[string]VersionInfo{
+					"Default": {
+						Name: "Default",
+						ExtendedPaths: ExtendedPathsSet{
+							Hedging: hedging,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name   string
+		apiDef *APIDefinition
+		result ValidationResult
+	}{
+		{
+			name: "valid delay",
+			apiDef: getAPIDef(HedgingMeta{
+				Path:   "/get",
+				Method: http.MethodGet,
+				Delay:  tyktime.ReadableDuration(50 * time.Millisecond),
+			}),
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "valid percentile",
+			apiDef: getAPIDef(HedgingMeta{Path: "/get", Method: http.MethodHead, Percentile: 95}),
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "disabled",
+			apiDef: getAPIDef(HedgingMeta{Disabled: true, Path: "/post", Method: http.MethodPost}),
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "no delay",
+			apiDef: getAPIDef(HedgingMeta{Path: "/get", Method: http.MethodGet}),
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrInvalidHedging},
+			},
+		},
+		{
+			name:   "percentile out of range",
+			apiDef: getAPIDef(HedgingMeta{Path: "/get", Method: http.MethodGet, Percentile: 101}),
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrInvalidHedging},
+			},
+		},
+		{
+			name: "non read-only method",
+			apiDef: getAPIDef(HedgingMeta{
+				Path:   "/post",
+				Method: http.MethodPost,
+				Delay:  tyktime.ReadableDuration(50 * time.Millisecond),
+			}),
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrInvalidHedgingMethod},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, runValidationTest(tc.apiDef, ruleSet, tc.result))
+	}
+}
+
+func TestRuleValidateRateLimitDimensions_Validate(t *testing.T) {
+	ruleSet := ValidationRuleSet{
+		&RuleValidateRateLimitDimensions{},
+	}
+
+	getAPIDef := func(dimensions ...RateLimitDimension) *APIDefinition {
+		return &APIDefinition{EnableContextVars: true, RateLimitDimensions: dimensions}
+	}
+
+	testCases := []struct {
+		name   string
+		apiDef *APIDefinition
+		result ValidationResult
+	}{
+		{
+			name: "valid",
+			apiDef: getAPIDef(
+				RateLimitDimension{Name: "per-ip", Key: "$tyk_context.remote_addr", Rate: 10, Per: 60},
+				RateLimitDimension{Name: "per-tenant", Key: "$tyk_context.headers_X_Tenant_Id", Rate: 100, Per: 60},
+			),
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "disabled",
+			apiDef: getAPIDef(RateLimitDimension{Disabled: true}),
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "no key",
+			apiDef: getAPIDef(RateLimitDimension{Name: "per-ip", Rate: 10, Per: 60}),
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrInvalidRateLimitDimension},
+			},
+		},
+		{
+			name:   "no rate",
+			apiDef: getAPIDef(RateLimitDimension{Name: "per-ip", Key: "$tyk_context.remote_addr", Per: 60}),
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrInvalidRateLimitDimension},
+			},
+		},
+		{
+			name: "duplicate name",
+			apiDef: getAPIDef(
+				RateLimitDimension{Name: "per-ip", Key: "$tyk_context.remote_addr", Rate: 10, Per: 60},
+				RateLimitDimension{Name: "per-ip", Key: "$tyk_context.headers_X_Real_Ip", Rate: 10, Per: 60},
+			),
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrDuplicateRateLimitDimension},
+			},
+		},
+		{
+			name: "context variables disabled",
+			apiDef: &APIDefinition{RateLimitDimensions: []RateLimitDimension{
+				{Name: "per-ip", Key: "$tyk_context.remote_addr", Rate: 10, Per: 60},
+			}},
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrRateLimitDimensionContextVars},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, runValidationTest(tc.apiDef, ruleSet, tc.result))
+	}
+}
+
+func TestRuleValidateConcurrencyLimit_Validate(t *testing.T) {
+	ruleSet := ValidationRuleSet{
+		&RuleValidateConcurrencyLimit{},
+	}
+
+	getAPIDef := func(limit ConcurrencyLimit) *APIDefinition {
+		return &APIDefinition{ConcurrencyLimit: limit}
+	}
+
+	testCases := []struct {
+		name   string
+		apiDef *APIDefinition
+		result ValidationResult
+	}{
+		{
+			name: "valid",
+			apiDef: getAPIDef(ConcurrencyLimit{
+				Enabled:          true,
+				PerKey:           5,
+				PerAPI:           100,
+				QueueTimeout:     tyktime.ReadableDuration(time.Second),
+				RejectStatusCode: http.StatusServiceUnavailable,
+			}),
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "disabled",
+			apiDef: getAPIDef(ConcurrencyLimit{PerKey: -1}),
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "negative limit",
+			apiDef: getAPIDef(ConcurrencyLimit{Enabled: true, PerUpstreamHost: -1}),
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrInvalidConcurrencyLimit},
+			},
+		},
+		{
+			name:   "unsupported status code",
+			apiDef: getAPIDef(ConcurrencyLimit{Enabled: true, PerAPI: 10, RejectStatusCode: http.StatusBadGateway}),
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrInvalidConcurrencyLimitStatusCode},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, runValidationTest(tc.apiDef, ruleSet, tc.result))
+	}
+}
+
+func TestRuleValidateLoadShedding_Validate(t *testing.T) {
+	ruleSet := ValidationRuleSet{
+		&RuleValidateLoadShedding{},
+	}
+
+	getAPIDef := func(shedding LoadShedding) *APIDefinition {
+		return &APIDefinition{LoadShedding: shedding}
+	}
+
+	testCases := []struct {
+		name   string
+		apiDef *APIDefinition
+		result ValidationResult
+	}{
+		{
+			name:   "defaults",
+			apiDef: getAPIDef(LoadShedding{Enabled: true}),
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name: "valid",
+			apiDef: getAPIDef(LoadShedding{
+				Enabled:         true,
+				Algorithm:       LoadSheddingGradient,
+				MinLimit:        10,
+				MaxLimit:        500,
+				PriorityTags:    []string{"premium"},
+				PriorityReserve: lo.ToPtr(0.3),
+			}),
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "disabled",
+			apiDef: getAPIDef(LoadShedding{Algorithm: "unknown"}),
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "unknown algorithm",
+			apiDef: getAPIDef(LoadShedding{Enabled: true, Algorithm: "vegas"}),
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrInvalidLoadSheddingAlgorithm},
+			},
+		},
+		{
+			name:   "max limit lower than min limit",
+			apiDef: getAPIDef(LoadShedding{Enabled: true, MinLimit: 10, MaxLimit: 5}),
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrInvalidLoadShedding},
+			},
+		},
+		{
+			name:   "priority reserve out of range",
+			apiDef: getAPIDef(LoadShedding{Enabled: true, PriorityReserve: lo.ToPtr(1.0)}),
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrInvalidLoadShedding},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, runValidationTest(tc.apiDef, ruleSet, tc.result))
+	}
+}
+
+func TestRuleValidateMCPAggregation_Validate(t *testing.T) {
+	ruleSet := ValidationRuleSet{
+		&RuleValidateMCPAggregation{},
+	}
+
+	getAPIDef := func(enabled bool, members ...MCPAggregationMember) *APIDefinition {
+		return &APIDefinition{
+			APIID:          "aggregate",
+			MCPAggregation: MCPAggregation{Enabled: enabled, Members: members},
+		}
+	}
+
+	invalid := ValidationResult{
+		IsValid: false,
+		Errors:  []error{ErrInvalidMCPAggregation},
+	}
+
+	testCases := []struct {
+		name   string
+		apiDef *APIDefinition
+		result ValidationResult
+	}{
+		{
+			name:   "valid",
+			apiDef: getAPIDef(true, MCPAggregationMember{APIID: "github", Prefix: "github_"}, MCPAggregationMember{APIID: "jira"}),
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "disabled",
+			apiDef: getAPIDef(false, MCPAggregationMember{APIID: "aggregate"}),
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "missing api id",
+			apiDef: getAPIDef(true, MCPAggregationMember{Prefix: "github_"}),
+			result: invalid,
+		},
+		{
+			name:   "aggregating itself",
+			apiDef: getAPIDef(true, MCPAggregationMember{APIID: "aggregate"}),
+			result: invalid,
+		},
+		{
+			name:   "duplicate member",
+			apiDef: getAPIDef(true, MCPAggregationMember{APIID: "github", Prefix: "a_"}, MCPAggregationMember{APIID: "github", Prefix: "b_"}),
+			result: invalid,
+		},
+		{
+			name:   "duplicate prefix",
+			apiDef: getAPIDef(true, MCPAggregationMember{APIID: "github", Prefix: "dev_"}, MCPAggregationMember{APIID: "jira", Prefix: "dev_"}),
+			result: invalid,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, runValidationTest(tc.apiDef, ruleSet, tc.result))
+	}
+}
+
+func TestRuleValidateMCPStdio_Validate(t *testing.T) {
+	ruleSet := ValidationRuleSet{
+		&RuleValidateMCPStdio{},
+	}
+
+	getAPIDef := func(stdio MCPStdio) *APIDefinition {
+		return &APIDefinition{MCPStdio: stdio}
+	}
+
+	invalid := ValidationResult{
+		IsValid: false,
+		Errors:  []error{ErrInvalidMCPStdio},
+	}
+
+	testCases := []struct {
+		name   string
+		apiDef *APIDefinition
+		result ValidationResult
+	}{
+		{
+			name:   "valid",
+			apiDef: getAPIDef(MCPStdio{Enabled: true, Command: "mcp-server-git", Mode: MCPStdioPool, PoolSize: 2}),
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "disabled",
+			apiDef: getAPIDef(MCPStdio{Mode: "unknown"}),
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "missing command",
+			apiDef: getAPIDef(MCPStdio{Enabled: true}),
+			result: invalid,
+		},
+		{
+			name:   "unknown mode",
+			apiDef: getAPIDef(MCPStdio{Enabled: true, Command: "mcp-server-git", Mode: "unknown"}),
+			result: invalid,
+		},
+		{
+			name:   "negative limit",
+			apiDef: getAPIDef(MCPStdio{Enabled: true, Command: "mcp-server-git", MaxSessions: -1}),
+			result: invalid,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, runValidationTest(tc.apiDef, ruleSet, tc.result))
+	}
+}
+
+func TestRuleValidateMCPContentGuard_Validate(t *testing.T) {
+	ruleSet := ValidationRuleSet{
+		&RuleValidateMCPContentGuard{},
+	}
+
+	getAPIDef := func(guard MCPContentGuard) *APIDefinition {
+		return &APIDefinition{MCPContentGuard: guard}
+	}
+
+	invalid := ValidationResult{
+		IsValid: false,
+		Errors:  []error{ErrInvalidMCPContentGuard},
+	}
+
+	testCases := []struct {
+		name   string
+		apiDef *APIDefinition
+		result ValidationResult
+	}{
+		{
+			name: "valid",
+			apiDef: getAPIDef(MCPContentGuard{Enabled: true, MaxResultSize: 1024, Redactions: []MCPRedaction{
+				{Pattern: `AKIA[0-9A-Z]{16}`},
+				{Path: "$..email"},
+			}}),
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "disabled",
+			apiDef: getAPIDef(MCPContentGuard{Redactions: []MCPRedaction{{Pattern: "("}}}),
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "empty redaction",
+			apiDef: getAPIDef(MCPContentGuard{Enabled: true, Redactions: []MCPRedaction{{Name: "empty"}}}),
+			result: invalid,
+		},
+		{
+			name:   "invalid pattern",
+			apiDef: getAPIDef(MCPContentGuard{Enabled: true, Redactions: []MCPRedaction{{Pattern: "("}}}),
+			result: invalid,
+		},
+		{
+			name:   "invalid path",
+			apiDef: getAPIDef(MCPContentGuard{Enabled: true, Redactions: []MCPRedaction{{Path: "email"}}}),
+			result: invalid,
+		},
+		{
+			name:   "unclosed path",
+			apiDef: getAPIDef(MCPContentGuard{Enabled: true, Redactions: []MCPRedaction{{Path: "$.a["}}}),
+			result: invalid,
+		},
+		{
+			name:   "negative max result size",
+			apiDef: getAPIDef(MCPContentGuard{Enabled: true, MaxResultSize: -1}),
+			result: invalid,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, runValidationTest(tc.apiDef, ruleSet, tc.result))
+	}
+}
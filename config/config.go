@@ -98,6 +98,18 @@ const (
 	DefaultOTelResourceName = "tyk-gateway"
 )
 
+// Storage backend types
+const (
+	StorageTypeRedis    = "redis"
+	StorageTypeEmbedded = "embedded"
+)
+
+// Message bus types
+const (
+	MessageBusRedis = "redis"
+	MessageBusNATS  = "nats"
+)
+
 type PolicySource string
 
 const (
@@ -151,7 +163,10 @@ type DBAppConfOptionsConfig struct {
 }
 
 type StorageOptionsConf struct {
-	// This should be set to `redis` (lowercase)
+	// The storage backend to use, either `redis` (lowercase) or `embedded`.
+	// The embedded backend keeps the data in process and persists it to a local file,
+	// so the Gateway can run without Redis. It's meant for single Gateway deployments.
+	// Analytics and the sentinel and Redis rolling rate limiters aren't supported with it.
 	Type string `json:"type"`
 	// The Redis host, by default this is set to `localhost`, but for production this should be set to a cluster.
 	Host string `json:"host"`
@@ -229,6 +244,48 @@ type StorageOptionsConf struct {
 	// authentication solution for temporal storage (for example, GCP MemoryStore IAM)
 	// instead of the traditional fixed username and password.
 	IAMAuth IAMAuthConfig `json:"iam_auth"`
+
+	// Embedded configures the embedded storage backend, used when `type` is set to `embedded`.
+	Embedded EmbeddedStorageConfig `json:"embedded"`
+}
+
+// MessageBusConfig configures the message bus used for cluster notifications.
+type MessageBusConfig struct {
+	// Type is the message bus to use, either `redis` or `nats`. Defaults to `redis`.
+	// Notifications published by the Dashboard go through Redis, so `nats` should only be used
+	// when every publisher sends its notifications over NATS.
+	Type string `json:"type"`
+	// NATS configures the connection to NATS, used when `type` is set to `nats`. The gateway falls
+	// back to Redis pub/sub when NATS can't be reached on startup.
+	NATS NATSConfig `json:"nats"`
+}
+
+// NATSConfig configures the connection to a NATS cluster.
+type NATSConfig struct {
+	// URL of the NATS server. Multiple servers can be set as a comma separated list.
+	URL string `json:"url"`
+	// Username used to authenticate with NATS.
+	Username string `json:"username"`
+	// Password used to authenticate with NATS.
+	Password string `json:"password" structviewer:"obfuscate"`
+	// Token used to authenticate with NATS.
+	Token string `json:"token" structviewer:"obfuscate"`
+	// Path to the CA file.
+	CAFile string `json:"ca_file"`
+	// Path to the cert file.
+	CertFile string `json:"cert_file"`
+	// Path to the key file.
+	KeyFile string `json:"key_file"`
+}
+
+// EmbeddedStorageConfig configures the embedded storage backend.
+type EmbeddedStorageConfig struct {
+	// Path of the file the data is persisted to. Every write is appended to the file, which is compacted as it
+	// grows. If empty, the data is only kept in memory.
+	Path string `json:"path"`
+	// SyncInterval is the interval, in seconds, at which the writes are flushed to disk. The writes since the
+	// last flush survive a gateway crash, but not a host crash. Defaults to 5 seconds.
+	SyncInterval int `json:"sync_interval"`
 }
 
 // Configure the cloud provider's Identity and Access Management (IAM) authentication
@@ -382,6 +439,56 @@ type AccessLogsConfig struct {
 	Template []string `json:"template"`
 }
 
+type RetryBudgetConfig struct {
+	// Ratio is the number of retries allowed per upstream request, measured over `ttl`.
+	// For example, `0.2` allows one retry for every five requests.
+	// Default: 0.2
+	Ratio float64 `json:"ratio"`
+
+	// MinRetriesPerSecond is the number of retries per second which are always allowed, so that APIs with little traffic can still retry.
+	// Set a negative value to disable it.
+	// Default: 10
+	MinRetriesPerSecond int `json:"min_retries_per_second"`
+
+	// TTL is the window in seconds over which requests and retries are counted.
+	// Default: 10
+	TTL int64 `json:"ttl"`
+}
+
+type MCPStdioConfig struct {
+	// Enabled allows MCP APIs to be served by local MCP stdio servers, spawned by the Gateway with the command of their API definition.
+	// Only enable it when the API definitions are trusted, as they run commands on the Gateway host.
+	// Default: false
+	Enabled bool `json:"enabled"`
+
+	// AllowedCommands are the commands MCP stdio servers can be spawned with. An MCP API served by a stdio server
+	// fails to load unless its command, arguments, environment variables and working directory match an entry.
+	// When empty, no command is allowed.
+	AllowedCommands []MCPStdioCommand `json:"allowed_commands"`
+}
+
+// MCPStdioCommand allows MCP stdio servers to be spawned with a command.
+type MCPStdioCommand struct {
+	// Command is matched against the `command` of the API definitions.
+	Command string `json:"command"`
+
+	// Args must be equal to the `args` of the API definitions, unless AllowAnyArgs is set.
+	Args []string `json:"args"`
+
+	// AllowAnyArgs allows the command with any arguments. Interpreters such as `node`, `python` or `sh` run
+	// arbitrary code through their arguments, only set it for commands which don't.
+	AllowAnyArgs bool `json:"allow_any_args"`
+
+	// AllowedEnv are the names of the environment variables the API definitions can set for the command. Variables
+	// such as `LD_PRELOAD`, `NODE_OPTIONS` or `PYTHONPATH` load arbitrary code, don't allow them.
+	// When empty, the API definitions can't set environment variables.
+	AllowedEnv []string `json:"allowed_env"`
+
+	// Dir is the working directory the API definitions can run the command in. When empty, the API definitions
+	// can't set the working directory.
+	Dir string `json:"dir"`
+}
+
 type HealthCheckConfig struct {
 	// Setting this value to `true` will enable the health-check endpoint on /Tyk/health.
 	EnableHealthChecks bool `json:"enable_health_checks"`
@@ -429,6 +536,10 @@ type MonitorConfig struct {
 	MonitorUserKeys bool `json:"monitor_user_keys"`
 	// Apply the monitoring subsystem to organization keys.
 	MonitorOrgKeys bool `json:"monitor_org_keys"`
+	// Filter restricts the trigger events sent to the webhook, e.g. with `min_trigger_percentage`.
+	Filter *apidef.EventFilter `json:"filter,omitempty"`
+	// Aggregate sends one summary of the trigger events per group and window, `window` is in seconds.
+	Aggregate *apidef.EventAggregation `json:"aggregate,omitempty"`
 }
 
 type WebHookHandlerConf struct {
@@ -442,6 +553,12 @@ type WebHookHandlerConf struct {
 	HeaderList map[string]string `bson:"header_map" json:"header_map" structviewer:"obfuscate"`
 	// The cool-down for the event so it does not trigger again (in seconds).
 	EventTimeout int64 `bson:"event_timeout" json:"event_timeout"`
+	// The number of times a failed webhook is retried before it's dead-lettered.
+	MaxRetries int `bson:"max_retries" json:"max_retries"`
+	// The delay before the first retry (in seconds), doubled on each retry.
+	RetryBackoff int64 `bson:"retry_backoff" json:"retry_backoff"`
+	// The secret of the HMAC-SHA256 signature of the webhook payload.
+	SigningSecret string `bson:"signing_secret" json:"signing_secret" structviewer:"obfuscate"`
 }
 
 // DNSMonitorConfig configures the background DNS monitoring for worker gateways
@@ -536,6 +653,17 @@ type SlaveOptionsConfig struct {
 	SyncUsedCertsOnly bool `json:"sync_used_certs_only"`
 }
 
+// LocalResponseCacheConf configures the in-memory tier of the response cache.
+type LocalResponseCacheConf struct {
+	// Enable to keep fresh cached responses in memory in front of Redis, saving a Redis round trip
+	// and the decoding of the entry on cache hits. Entries purged with the Gateway API are evicted
+	// from the memory of every node.
+	Enabled bool `json:"enabled"`
+
+	// MaxSize is the maximum size of the in-memory responses in bytes. Defaults to 64MB.
+	MaxSize int64 `json:"max_size"`
+}
+
 type LocalSessionCacheConf struct {
 	// By default sessions are set to cache. Set this to `true` to stop Tyk from caching keys locally on the node.
 	DisableCacheSessionState bool `json:"disable_cached_session_state"`
@@ -951,6 +1079,10 @@ type Config struct {
 	// Disable dynamic API and Policy reloads, e.g. it will load new changes only on procecss start.
 	SuppressRedisSignalReload bool `json:"suppress_redis_signal_reload"`
 
+	// MessageBus configures the channel cluster notifications, e.g. hot reloads, key invalidations and
+	// distributed rate limiter peer discovery, are sent over. Defaults to Redis pub/sub.
+	MessageBus MessageBusConfig `json:"message_bus"`
+
 	// ReloadInterval defines a duration in seconds within which the gateway responds to a reload event.
 	// The value defaults to 1, values lower than 1 are ignored.
 	ReloadInterval int64 `json:"reload_interval"`
@@ -1127,6 +1259,17 @@ type Config struct {
 	// This can cause a file-handler limit to be exceeded. Setting to false can have performance benefits as the connection can be reused.
 	ProxyCloseConnections bool `json:"proxy_close_connections"`
 
+	// RetryBudget limits the retries made by endpoint retry policies, so that retries can't amplify an upstream outage.
+	// The budget is shared by all APIs of the Gateway.
+	RetryBudget RetryBudgetConfig `json:"retry_budget"`
+
+	// MCPStdio controls the MCP APIs served by local MCP stdio servers instead of an upstream URL.
+	MCPStdio MCPStdioConfig `json:"mcp_stdio"`
+
+	// JSONRPCMaxBatchSize is the maximum number of requests in a JSON-RPC batch sent to an MCP API, larger
+	// batches are rejected with an invalid request error. Defaults to 100.
+	JSONRPCMaxBatchSize int `json:"jsonrpc_max_batch_size"`
+
 	// Tyk nodes can provide uptime awareness, uptime testing and analytics for your underlying APIs uptime and availability.
 	// Tyk can also notify you when a service goes down.
 	UptimeTests UptimeTestsConfig `json:"uptime_tests"`
@@ -1226,6 +1369,9 @@ type Config struct {
 	// This does not affect rate limiting.
 	LocalSessionCache LocalSessionCacheConf `json:"local_session_cache"`
 
+	// LocalResponseCache keeps hot cached API responses in memory, in front of the Redis cache.
+	LocalResponseCache LocalResponseCacheConf `json:"local_response_cache"`
+
 	// Enable to use a separate Redis for cache storage
 	EnableSeperateCacheStore bool               `json:"enable_separate_cache_store"`
 	CacheStorage             StorageOptionsConf `json:"cache_storage"`
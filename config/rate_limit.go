@@ -10,6 +10,11 @@ type RateLimit struct {
 	// EnableFixedWindow enables fixed window rate limiting.
 	EnableFixedWindowRateLimiter bool `json:"enable_fixed_window_rate_limiter"`
 
+	// EnableGCRARateLimiter enables the Generic Cell Rate Algorithm rate limiter. It stores a single timestamp
+	// per key in Redis, spacing requests evenly while allowing bursts of up to the rate limit. It reports accurate
+	// `X-RateLimit-Remaining`, `X-RateLimit-Reset` and `Retry-After` headers.
+	EnableGCRARateLimiter bool `json:"enable_gcra_rate_limiter"`
+
 	// Redis based rate limiter with sliding log. Provides 100% rate limiting accuracy, but require two additional Redis roundtrips for each request.
 	EnableRedisRollingLimiter bool `json:"enable_redis_rolling_limiter"`
 
@@ -62,6 +67,10 @@ func (r *RateLimit) String() string {
 		return "Fixed Window Rate Limiter enabled"
 	}
 
+	if r.EnableGCRARateLimiter {
+		return "GCRA Rate Limiter enabled"
+	}
+
 	// Smoothing check is here, because the rate limiters above this line
 	// do not support smoothing. Smoothing is applied for RRL/Sentinel.
 	if r.EnableRateLimitSmoothing {
@@ -81,6 +81,17 @@ const (
 	// in the JWT middleware. The value (a *gateway.Binding) is type-asserted on
 	// the gateway side; only the key lives here to avoid an import cycle.
 	MatchedIdPBinding
+	// LoadBalancedTarget holds the upstream host picked by the load balancer
+	// for the outbound request, as it appears in the target list.
+	LoadBalancedTarget
+	// RetryCount holds the number of upstream retries made for the request.
+	RetryCount
+	// HedgedResponse is set when the response came from a hedged upstream request.
+	HedgedResponse
+	// ConcurrencyRelease holds the func releasing the concurrency limit slots taken by the request.
+	ConcurrencyRelease
+	// LoadSheddingRelease holds the func releasing the load shedding slot taken by the request.
+	LoadSheddingRelease
 )
 
 func ctxSetSession(r *http.Request, s *user.SessionState, scheduleUpdate bool, hashKey bool) {
@@ -38,6 +38,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"strconv"
@@ -2482,7 +2483,7 @@ func (gw *Gateway) createOauthClient(w http.ResponseWriter, r *http.Request) {
 					storageManager := gw.getGlobalMDCBStorageHandler(prefix, false)
 					storageManager.Connect()
 
-					storageDriver := &storage.RedisCluster{KeyPrefix: prefix, HashKeys: false, ConnectionHandler: gw.StorageConnectionHandler}
+					storageDriver := gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{KeyPrefix: prefix, HashKeys: false})
 					storageDriver.Connect()
 
 					apiSpec.OAuthManager = &OAuthManager{
@@ -2871,7 +2872,7 @@ func (gw *Gateway) getOauthClientDetails(keyName, apiID string) (interface{}, in
 		storageManager := gw.getGlobalMDCBStorageHandler(prefix, false)
 		storageManager.Connect()
 
-		storageDriver := &storage.RedisCluster{KeyPrefix: prefix, HashKeys: false, ConnectionHandler: gw.StorageConnectionHandler}
+		storageDriver := gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{KeyPrefix: prefix, HashKeys: false})
 		storageDriver.Connect()
 
 		apiSpec.OAuthManager = &OAuthManager{
@@ -3099,6 +3100,17 @@ func userRatesCheck(w http.ResponseWriter, r *http.Request) {
 func (gw *Gateway) invalidateCacheHandler(w http.ResponseWriter, r *http.Request) {
 	apiID := mux.Vars(r)["apiID"]
 
+	query := r.URL.Query()
+	if query.Has("tag") || query.Has("path") || query.Has("key") {
+		gw.purgeCacheHandler(w, r, cachePurge{
+			APIID: apiID,
+			Tags:  query["tag"],
+			Paths: query["path"],
+			Keys:  query["key"],
+		})
+		return
+	}
+
 	if ok := gw.invalidateAPICache(apiID); !ok {
 		err := errors.New("scan/delete failed")
 		var orgid string
@@ -3121,9 +3133,53 @@ func (gw *Gateway) invalidateCacheHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	// The shared store is invalidated, the other gateways evict the responses
+	// they keep in memory
+	if gw.localResponseCache != nil {
+		gw.MainNotifier.Notify(Notification{Command: NoticeEvictLocalAPICache, Payload: apiID, Gw: gw})
+	}
+
 	doJSONWrite(w, http.StatusOK, apiOk("cache invalidated"))
 }
 
+// purgeCacheHandler purges the cached responses of an API by tag, path glob or
+// cache key, and propagates the purge to the other gateways.
+func (gw *Gateway) purgeCacheHandler(w http.ResponseWriter, r *http.Request, purge cachePurge) {
+	for _, glob := range purge.Paths {
+		if _, err := path.Match(glob, ""); err != nil {
+			doJSONWrite(w, http.StatusBadRequest, apiError(fmt.Sprintf("Invalid path glob %q", glob)))
+			return
+		}
+	}
+
+	keys, err := gw.purgeAPICache(purge)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"prefix":  "api",
+			"api_id":  purge.APIID,
+			"status":  "fail",
+			"err":     err,
+			"user_ip": requestIPHops(r),
+		}).Error("Failed to purge cache: ", err)
+
+		doJSONWrite(w, http.StatusInternalServerError, apiError("Cache purge failed"))
+		return
+	}
+
+	// The indexes are purged, the other gateways evict the resolved keys
+	// from the responses they keep in memory
+	if gw.localResponseCache != nil && len(keys) > 0 {
+		payload, _ := json.Marshal(cachePurge{APIID: purge.APIID, Keys: keys})
+		gw.MainNotifier.Notify(Notification{
+			Command: NoticePurgeAPICache,
+			Payload: string(payload),
+			Gw:      gw,
+		})
+	}
+
+	doJSONWrite(w, http.StatusOK, apiOk(fmt.Sprintf("%d cache entries purged", len(keys))))
+}
+
 func (gw *Gateway) RevokeTokenHandler(w http.ResponseWriter, r *http.Request) {
 	err := r.ParseForm()
 
@@ -3342,6 +3398,61 @@ func ctxGetMatchedBinding(r *http.Request) *Binding {
 	return b
 }
 
+// ctxSetLoadBalancedTarget records the load balanced target picked for the outbound request.
+func ctxSetLoadBalancedTarget(r *http.Request, target string) {
+	setCtxValue(r, ctx.LoadBalancedTarget, target)
+}
+
+// ctxGetLoadBalancedTarget returns the load balanced target picked for the outbound request.
+func ctxGetLoadBalancedTarget(r *http.Request) string {
+	target, _ := r.Context().Value(ctx.LoadBalancedTarget).(string)
+	return target
+}
+
+// ctxSetRetryCount records the number of upstream retries made for the request.
+func ctxSetRetryCount(r *http.Request, count int) {
+	setCtxValue(r, ctx.RetryCount, count)
+}
+
+// ctxGetRetryCount returns the number of upstream retries made for the request.
+func ctxGetRetryCount(r *http.Request) int {
+	count, _ := r.Context().Value(ctx.RetryCount).(int)
+	return count
+}
+
+// ctxSetHedgedResponse records that the response came from a hedged upstream request.
+func ctxSetHedgedResponse(r *http.Request) {
+	setCtxValue(r, ctx.HedgedResponse, true)
+}
+
+// ctxSetConcurrencyRelease stores the func releasing the concurrency limit slots taken by the request.
+func ctxSetConcurrencyRelease(r *http.Request, release func()) {
+	setCtxValue(r, ctx.ConcurrencyRelease, release)
+}
+
+// ctxGetConcurrencyRelease returns the func releasing the concurrency limit slots taken by the request.
+func ctxGetConcurrencyRelease(r *http.Request) func() {
+	release, _ := r.Context().Value(ctx.ConcurrencyRelease).(func())
+	return release
+}
+
+// ctxSetLoadSheddingRelease stores the func releasing the load shedding slot taken by the request.
+func ctxSetLoadSheddingRelease(r *http.Request, release func()) {
+	setCtxValue(r, ctx.LoadSheddingRelease, release)
+}
+
+// ctxGetLoadSheddingRelease returns the func releasing the load shedding slot taken by the request.
+func ctxGetLoadSheddingRelease(r *http.Request) func() {
+	release, _ := r.Context().Value(ctx.LoadSheddingRelease).(func())
+	return release
+}
+
+// ctxGetHedgedResponse reports whether the response came from a hedged upstream request.
+func ctxGetHedgedResponse(r *http.Request) bool {
+	hedged, _ := r.Context().Value(ctx.HedgedResponse).(bool)
+	return hedged
+}
+
 func ctxGetSession(r *http.Request) *user.SessionState {
 	return ctx.GetSession(r)
 }
@@ -31,11 +31,13 @@ import (
 	"github.com/TykTechnologies/tyk/config"
 	"github.com/TykTechnologies/tyk/ee/middleware/streams"
 	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/internal/hedge"
 	"github.com/TykTechnologies/tyk/internal/httpctx"
 	"github.com/TykTechnologies/tyk/internal/httputil"
 	"github.com/TykTechnologies/tyk/internal/mcp"
 	"github.com/TykTechnologies/tyk/internal/model"
 	"github.com/TykTechnologies/tyk/internal/oasutil"
+	"github.com/TykTechnologies/tyk/internal/retry"
 	"github.com/TykTechnologies/tyk/internal/service/gojsonschema"
 	"github.com/TykTechnologies/tyk/pkg/schema"
 	"github.com/TykTechnologies/tyk/regexp"
@@ -85,6 +87,8 @@ const (
 	PersistGraphQL
 	RateLimit
 	OASMockResponse
+	RetryPolicy
+	Hedging
 )
 
 // RequestStatus is a custom type to avoid collisions
@@ -126,6 +130,8 @@ const (
 	StatusGoPlugin                        RequestStatus = "Go plugin"
 	StatusPersistGraphQL                  RequestStatus = "Persist GraphQL"
 	StatusRateLimit                       RequestStatus = "Rate Limited"
+	StatusRetryPolicy                     RequestStatus = "Retry policy enforced"
+	StatusHedging                         RequestStatus = "Hedging enforced"
 	// MCPPrimitiveNotFound is returned when a primitive VEM is accessed directly (not via JSON-RPC routing).
 	// It intentionally maps to HTTP 404 to avoid exposing internal-only endpoints.
 	MCPPrimitiveNotFound RequestStatus = "MCP Primitive Not Found"
@@ -136,6 +142,7 @@ type EndPointCacheMeta struct {
 	CacheKeyRegex          string
 	CacheOnlyResponseCodes []int
 	Timeout                int64
+	CacheKeyTemplate       string
 }
 
 type TransformSpec struct {
@@ -143,6 +150,18 @@ type TransformSpec struct {
 	Template *texttemplate.Template
 }
 
+// ExtendedRetryPolicyMeta holds a retry policy along with its compiled form.
+type ExtendedRetryPolicyMeta struct {
+	apidef.RetryPolicyMeta
+	Policy retry.Policy
+}
+
+// ExtendedHedgingMeta holds a hedging configuration along with its delay policy.
+type ExtendedHedgingMeta struct {
+	apidef.HedgingMeta
+	Policy *hedge.Policy `json:"-"`
+}
+
 type ExtendedCircuitBreakerMeta struct {
 	apidef.CircuitBreakerMeta
 	CB *circuit.Breaker `json:"-"`
@@ -1010,6 +1029,7 @@ func (a APIDefinitionLoader) compileCachedPathSpec(oldpaths []string, newpaths [
 		newSpec.CacheConfig.CacheKeyRegex = spec.CacheKeyRegex
 		newSpec.CacheConfig.CacheOnlyResponseCodes = spec.CacheOnlyResponseCodes
 		newSpec.CacheConfig.Timeout = spec.Timeout
+		newSpec.CacheConfig.CacheKeyTemplate = spec.CacheKeyTemplate
 		// Extend with method actions
 		urlSpec = append(urlSpec, newSpec)
 	}
@@ -1157,6 +1177,72 @@ func (a APIDefinitionLoader) compileTimeoutPathSpec(paths []apidef.HardTimeoutMe
 	return urlSpec
 }
 
+func (a APIDefinitionLoader) compileRetryPolicyPathSpec(paths []apidef.RetryPolicyMeta, stat URLStatus, conf config.Config) []URLSpec {
+	// transform an extended configuration URL into an array of URLSpecs
+	// This way we can iterate the whole array once, on match we break with status
+	urlSpec := []URLSpec{}
+
+	for _, stringSpec := range paths {
+		if stringSpec.Disabled || stringSpec.MaxAttempts < 2 {
+			continue
+		}
+
+		errorKinds := make([]retry.ErrorKind, 0, len(stringSpec.RetryOnErrors))
+		for _, kind := range stringSpec.RetryOnErrors {
+			errorKinds = append(errorKinds, retry.ErrorKind(kind))
+		}
+
+		newSpec := URLSpec{}
+		a.generateRegex(stringSpec.Path, &newSpec, stat, conf)
+		// Extend with method actions
+		newSpec.RetryPolicy = ExtendedRetryPolicyMeta{
+			RetryPolicyMeta: stringSpec,
+			Policy: retry.Policy{
+				MaxAttempts:   stringSpec.MaxAttempts,
+				StatusCodes:   stringSpec.RetryOnStatusCodes,
+				Errors:        errorKinds,
+				NonIdempotent: stringSpec.RetryNonIdempotent,
+				BaseBackoff:   time.Duration(stringSpec.BaseBackoff),
+				MaxBackoff:    time.Duration(stringSpec.MaxBackoff),
+			},
+		}
+
+		urlSpec = append(urlSpec, newSpec)
+	}
+
+	return urlSpec
+}
+
+func (a APIDefinitionLoader) compileHedgingPathSpec(paths []apidef.HedgingMeta, stat URLStatus, conf config.Config) []URLSpec {
+	// transform an extended configuration URL into an array of URLSpecs
+	// This way we can iterate the whole array once, on match we break with status
+	urlSpec := []URLSpec{}
+
+	for _, stringSpec := range paths {
+		if stringSpec.Disabled {
+			continue
+		}
+
+		// Only read-only requests are safe to send twice.
+		if stringSpec.Method != http.MethodGet && stringSpec.Method != http.MethodHead {
+			log.Warning("[PROXY] [HEDGING] Hedging is only supported for GET and HEAD endpoints, skipping path: ", stringSpec.Path)
+			continue
+		}
+
+		newSpec := URLSpec{}
+		a.generateRegex(stringSpec.Path, &newSpec, stat, conf)
+		// Extend with method actions
+		newSpec.Hedging = ExtendedHedgingMeta{
+			HedgingMeta: stringSpec,
+			Policy:      hedge.NewPolicy(time.Duration(stringSpec.Delay), stringSpec.Percentile),
+		}
+
+		urlSpec = append(urlSpec, newSpec)
+	}
+
+	return urlSpec
+}
+
 func (a APIDefinitionLoader) compileRequestSizePathSpec(paths []apidef.RequestSizeMeta, stat URLStatus, conf config.Config) []URLSpec {
 	// transform an extended configuration URL into an array of URLSpecs
 	// This way we can iterate the whole array once, on match we break with status
@@ -1934,6 +2020,10 @@ func (a APIDefinitionLoader) initMCPConfiguration(spec *APISpec) {
 	if spec.JsonRpcVersion == apidef.JsonRPC20 {
 		spec.JSONRPCRouter = mcp.NewRouter()
 	}
+
+	if spec.MCPToolValidation.Enabled {
+		spec.MCPToolSchemas = mcp.NewToolSchemas()
+	}
 }
 
 func (a APIDefinitionLoader) getExtendedPathSpecs(apiVersionDef apidef.VersionInfo, apiSpec *APISpec, conf config.Config) ([]URLSpec, bool) {
@@ -1952,6 +2042,8 @@ func (a APIDefinitionLoader) getExtendedPathSpecs(apiVersionDef apidef.VersionIn
 	headerTransformPathsOnResponse := a.compileInjectedHeaderSpec(apiVersionDef.ExtendedPaths.TransformResponseHeader, HeaderInjectedResponse, conf)
 	hardTimeouts := a.compileTimeoutPathSpec(apiVersionDef.ExtendedPaths.HardTimeouts, HardTimeout, conf)
 	circuitBreakers := a.compileCircuitBreakerPathSpec(apiVersionDef.ExtendedPaths.CircuitBreaker, CircuitBreaker, apiSpec, conf)
+	retryPolicies := a.compileRetryPolicyPathSpec(apiVersionDef.ExtendedPaths.RetryPolicy, RetryPolicy, conf)
+	hedgedPaths := a.compileHedgingPathSpec(apiVersionDef.ExtendedPaths.Hedging, Hedging, conf)
 	urlRewrites := a.compileURLRewritesPathSpec(apiVersionDef.ExtendedPaths.URLRewrite, URLRewrite, conf)
 	virtualPaths := a.compileVirtualPathsSpec(apiVersionDef.ExtendedPaths.Virtual, VirtualPath, apiSpec, conf)
 	requestSizes := a.compileRequestSizePathSpec(apiVersionDef.ExtendedPaths.SizeLimit, RequestSizeLimit, conf)
@@ -1983,6 +2075,8 @@ func (a APIDefinitionLoader) getExtendedPathSpecs(apiVersionDef apidef.VersionIn
 	combinedPath = append(combinedPath, headerTransformPathsOnResponse...)
 	combinedPath = append(combinedPath, hardTimeouts...)
 	combinedPath = append(combinedPath, circuitBreakers...)
+	combinedPath = append(combinedPath, retryPolicies...)
+	combinedPath = append(combinedPath, hedgedPaths...)
 	combinedPath = append(combinedPath, urlRewrites...)
 	combinedPath = append(combinedPath, requestSizes...)
 	combinedPath = append(combinedPath, goPlugins...)
@@ -2093,6 +2187,10 @@ func (a *APISpec) getURLStatus(stat URLStatus) RequestStatus {
 		return StatusPersistGraphQL
 	case RateLimit:
 		return StatusRateLimit
+	case RetryPolicy:
+		return StatusRetryPolicy
+	case Hedging:
+		return StatusHedging
 	default:
 		log.Error("URL Status was not one of Ignored, Blacklist or WhiteList! Blocking.")
 		return EndPointNotAllowed
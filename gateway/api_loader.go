@@ -34,6 +34,7 @@ import (
 
 	"github.com/TykTechnologies/tyk/internal/httpctx"
 	"github.com/TykTechnologies/tyk/internal/httputil"
+	"github.com/TykTechnologies/tyk/internal/loadbalancer"
 	"github.com/TykTechnologies/tyk/internal/mcp/pairing"
 	"github.com/TykTechnologies/tyk/internal/otel"
 	"github.com/TykTechnologies/tyk/internal/service/newrelic"
@@ -64,13 +65,13 @@ type ProcessSpecOptions struct {
 func (gw *Gateway) prepareStorage() generalStores {
 	var gs generalStores
 
-	gs.redisStore = &storage.RedisCluster{KeyPrefix: "apikey-", HashKeys: gw.GetConfig().HashKeys, ConnectionHandler: gw.StorageConnectionHandler}
+	gs.redisStore = gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{KeyPrefix: "apikey-", HashKeys: gw.GetConfig().HashKeys})
 	gs.redisStore.Connect()
 
-	gs.redisOrgStore = &storage.RedisCluster{KeyPrefix: "orgkey.", ConnectionHandler: gw.StorageConnectionHandler}
+	gs.redisOrgStore = gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{KeyPrefix: "orgkey."})
 	gs.redisOrgStore.Connect()
 
-	gs.healthStore = &storage.RedisCluster{KeyPrefix: "apihealth.", ConnectionHandler: gw.StorageConnectionHandler}
+	gs.healthStore = gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{KeyPrefix: "apihealth."})
 	gs.healthStore.Connect()
 
 	gs.rpcAuthStore = &RPCStorageHandler{KeyPrefix: "apikey-", HashKeys: gw.GetConfig().HashKeys, Gw: gw}
@@ -236,6 +237,18 @@ func (gw *Gateway) processSpec(
 	if spec.Proxy.EnableLoadBalancing {
 		sl := apidef.NewHostListFromList(spec.Proxy.Targets)
 		spec.Proxy.StructuredTargetList = sl
+		spec.LoadBalancer = loadbalancer.New(loadbalancer.Algorithm(spec.Proxy.LoadBalancing.Algorithm))
+		if spec.Proxy.LoadBalancing.PassiveHealthCheck.Enabled {
+			spec.OutlierDetector = newOutlierDetector(spec)
+		}
+	}
+
+	if spec.LoadShedding.Enabled {
+		spec.LoadShedder = newLoadShedder(spec.LoadShedding)
+	}
+
+	if spec.ConcurrencyLimit.Enabled {
+		spec.ConcurrencyLimiter = gw.newConcurrencyLimiter(spec.ConcurrencyLimit)
 	}
 
 	// Initialise the auth and session managers (use Redis for now)
@@ -389,7 +402,7 @@ func (gw *Gateway) processSpec(
 	baseMid := NewBaseMiddleware(gw, spec, proxy, logger)
 
 	keyPrefix := "cache-" + spec.APIID
-	cacheStore := storage.RedisCluster{KeyPrefix: keyPrefix, IsCache: true, ConnectionHandler: gw.StorageConnectionHandler}
+	cacheStore := gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{KeyPrefix: keyPrefix, IsCache: true})
 	cacheStore.Connect()
 
 	var chain http.Handler
@@ -620,6 +633,7 @@ func (gw *Gateway) processSpec(
 	if spec.IsMCP() {
 		gw.mwAppendEnabled(&chainArray, &JSONRPCAccessControlMiddleware{baseMid.Copy()})
 		gw.mwAppendEnabled(&chainArray, &MCPAccessControlMiddleware{baseMid.Copy()})
+		gw.mwAppendEnabled(&chainArray, &MCPToolValidationMiddleware{baseMid.Copy()})
 	}
 
 	gw.mwAppendEnabled(&chainArray, &OAuth2Middleware{BaseMiddleware: baseMid.Copy()})
@@ -631,6 +645,8 @@ func (gw *Gateway) processSpec(
 	)
 
 	gw.mwAppendEnabled(&chainArray, &RateLimitForAPI{BaseMiddleware: baseMid.Copy(), quotaKey: options.quotaKey})
+	gw.mwAppendEnabled(&chainArray, &ConcurrencyLimit{BaseMiddleware: baseMid.Copy()})
+	gw.mwAppendEnabled(&chainArray, &LoadShedding{BaseMiddleware: baseMid.Copy()})
 	gw.mwAppendEnabled(&chainArray, &GraphQLMiddleware{BaseMiddleware: baseMid.Copy()})
 
 	if streamMw := getStreamingMiddleware(baseMid); streamMw != nil {
@@ -661,7 +677,7 @@ func (gw *Gateway) processSpec(
 
 	// Earliest we can respond with cache get 200 ok
 	gw.mwAppendEnabled(&chainArray, newMockResponseMiddleware(baseMid.Copy()))
-	gw.mwAppendEnabled(&chainArray, &RedisCacheMiddleware{BaseMiddleware: baseMid.Copy(), store: &cacheStore})
+	gw.mwAppendEnabled(&chainArray, &RedisCacheMiddleware{BaseMiddleware: baseMid.Copy(), store: cacheStore})
 	gw.mwAppendEnabled(&chainArray, &VirtualEndpoint{BaseMiddleware: baseMid.Copy()})
 	gw.mwAppendEnabled(&chainArray, &RequestSigning{BaseMiddleware: baseMid.Copy()})
 	gw.mwAppendEnabled(&chainArray, &GoPluginMiddleware{BaseMiddleware: baseMid.Copy()})
@@ -685,6 +701,10 @@ func (gw *Gateway) processSpec(
 		}
 	}
 
+	// MCPAggregationMiddleware answers the requests of aggregating MCP APIs once
+	// their VEM chain completed, instead of proxying them upstream.
+	gw.mwAppendEnabled(&chainArray, &MCPAggregationMiddleware{BaseMiddleware: baseMid.Copy()})
+
 	// MCPVEMContinuationMiddleware must be the last middleware in the chain.
 	// After all VEM-specific middleware has been applied, it checks the routing state
 	// and either continues to the next VEM or allows the request to proceed to upstream.
@@ -906,14 +926,15 @@ func (gw *Gateway) findInternalHttpHandlerByNameOrID(apiNameOrID string) (handle
 	return h.(*ChainObject).ThisHandler, targetAPI, true
 }
 
-func (gw *Gateway) loadGlobalApps() {
+// loadGlobalApps loads the synced API specs, returning the ones which failed to load.
+func (gw *Gateway) loadGlobalApps() []apiLoadFailure {
 	// we need to make a full copy of the slice, as loadApps will
 	// use in-place to sort the apis.
 	gw.apisMu.RLock()
 	specs := make([]*APISpec, len(gw.apiSpecs))
 	copy(specs, gw.apiSpecs)
 	gw.apisMu.RUnlock()
-	gw.loadApps(specs)
+	return gw.loadApps(specs)
 }
 
 func trimCategories(name string) string {
@@ -1026,6 +1047,29 @@ func (gw *Gateway) loadHTTPService(spec *APISpec, apisByListen map[string]int, g
 			chainObj = chain.(*ChainObject)
 		}
 	} else {
+		// The tool schemas are learnt from the tools/list responses, the ones
+		// of the previous version of the API are kept until the next one.
+		if curSpec != nil && curSpec.MCPToolSchemas != nil && spec.MCPToolSchemas != nil {
+			spec.MCPToolSchemas.Seed(curSpec.MCPToolSchemas)
+		}
+
+		if spec.MCPStdio.Enabled {
+			bridge, err := gw.newMCPStdioBridge(spec, log.WithField("api_id", spec.APIID))
+			if err != nil {
+				return nil, err
+			}
+			spec.MCPStdioBridge = bridge
+			spec.AddUnloadHook(bridge.Close)
+		}
+
+		if spec.MCPContentGuard.Enabled && spec.IsMCP() {
+			guard, err := newMCPResultGuard(spec)
+			if err != nil {
+				return nil, fmt.Errorf("couldn't compile the MCP content guard: %w", err)
+			}
+			spec.MCPResultGuard = guard
+		}
+
 		chainObj = gw.processSpec(spec, apisByListen, gs, logrus.NewEntry(log))
 	}
 
@@ -1307,7 +1351,8 @@ func listenPathLength(listenPath string) int {
 }
 
 // Create the individual API (app) specs based on live configurations and assign middleware
-func (gw *Gateway) loadApps(specs []*APISpec) {
+// loadApps loads the API specs, returning the ones which failed to load.
+func (gw *Gateway) loadApps(specs []*APISpec) (failed []apiLoadFailure) {
 	mainLog.Info("Loading API configurations.")
 
 	synthesizedSpecs, mcpPairingSnapshot, err := synthesizeMCPAdapterSpecs(specs, gw.currentSyntheticMCPAdapterSpecs())
@@ -1398,6 +1443,7 @@ func (gw *Gateway) loadApps(specs []*APISpec) {
 				if err := recover(); err != nil {
 					if err := recoverFromLoadApiPanic(spec, err); err != nil {
 						log.Error(err)
+						failed = append(failed, apiLoadFailure{spec: spec, err: err})
 					}
 				}
 			}()
@@ -1430,6 +1476,7 @@ func (gw *Gateway) loadApps(specs []*APISpec) {
 				tmpSpecHandle, err := gw.loadHTTPService(spec, apisByListen, &gs, muxer)
 				if err != nil {
 					log.WithError(err).Errorf("error loading API")
+					failed = append(failed, apiLoadFailure{spec: spec, err: err})
 					return
 				}
 				tmpSpecHandles.Store(spec.APIID, tmpSpecHandle)
@@ -1498,6 +1545,7 @@ func (gw *Gateway) loadApps(specs []*APISpec) {
 		mainLog.Warning("All APIs are protected with mTLS, except for the control API. " +
 			"We recommend configuring the control API port or control hostname to ensure consistent security measures")
 	}
+	return failed
 }
 
 func recoverFromLoadApiPanic(spec *APISpec, err any) error {
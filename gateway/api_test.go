@@ -1811,6 +1811,97 @@ func TestInvalidateCache(t *testing.T) {
 	}...)
 }
 
+func TestPurgeCache(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Surrogate-Key", "all "+strings.Split(r.URL.Path, "/")[1])
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	defer upstream.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "purge-test"
+		spec.Proxy.ListenPath = "/"
+		spec.Proxy.TargetURL = upstream.URL
+		spec.CacheOptions = apidef.CacheOptions{
+			EnableCache:          true,
+			CacheAllSafeRequests: true,
+			CacheTimeout:         60,
+			CacheTagHeaders:      []string{"Surrogate-Key"},
+			CacheTagPaths:        []apidef.CacheTagPath{{Path: "/users/{id}", Tags: []string{"user-{id}"}}},
+		}
+	})
+
+	cached := map[string]string{cachedResponseHeader: "1"}
+	cache := func(t *testing.T, paths ...string) {
+		t.Helper()
+		for _, path := range paths {
+			assert.Eventually(t, func() bool {
+				resp, err := ts.Run(t, test.TestCase{Path: path})
+				return err == nil && resp.Header.Get(cachedResponseHeader) == "1"
+			}, 5*time.Second, 10*time.Millisecond, "response was not stored in cache")
+		}
+	}
+
+	t.Run("by tag from path", func(t *testing.T) {
+		cache(t, "/users/1", "/users/2")
+
+		_, _ = ts.Run(t, []test.TestCase{
+			{Method: http.MethodDelete, Path: "/tyk/cache/purge-test?tag=user-1", AdminAuth: true, Code: http.StatusOK},
+			{Path: "/users/1", HeadersNotMatch: cached, Code: http.StatusOK},
+			{Path: "/users/2", HeadersMatch: cached, Code: http.StatusOK},
+		}...)
+	})
+
+	t.Run("by path glob", func(t *testing.T) {
+		cache(t, "/users/1", "/posts/1")
+
+		_, _ = ts.Run(t, []test.TestCase{
+			{Method: http.MethodDelete, Path: "/tyk/cache/purge-test?path=/posts/*", AdminAuth: true, Code: http.StatusOK},
+			{Path: "/posts/1", HeadersNotMatch: cached, Code: http.StatusOK},
+			{Path: "/users/1", HeadersMatch: cached, Code: http.StatusOK},
+		}...)
+	})
+
+	t.Run("by tag from header", func(t *testing.T) {
+		cache(t, "/users/1", "/posts/1")
+
+		_, _ = ts.Run(t, []test.TestCase{
+			{Method: http.MethodDelete, Path: "/tyk/cache/purge-test?tag=all", AdminAuth: true, Code: http.StatusOK},
+			{Path: "/users/1", HeadersNotMatch: cached, Code: http.StatusOK},
+			{Path: "/posts/1", HeadersNotMatch: cached, Code: http.StatusOK},
+		}...)
+	})
+
+	t.Run("invalid glob", func(t *testing.T) {
+		_, _ = ts.Run(t, test.TestCase{Method: http.MethodDelete, Path: "/tyk/cache/purge-test?path=[", AdminAuth: true, Code: http.StatusBadRequest})
+	})
+
+	t.Run("indexes", func(t *testing.T) {
+		store := ts.Gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{KeyPrefix: "cache-purge-test", IsCache: true})
+		store.Connect()
+		indexed := func(index string) int {
+			members, _, err := store.GetSortedSetRange(index, "-inf", "+inf")
+			require.NoError(t, err)
+			return len(members)
+		}
+
+		cache(t, "/users/1", "/posts/1")
+		require.Eventually(t, func() bool {
+			return indexed(cacheTagIndex("all")) == 2 && indexed(cachePathIndex) == 2
+		}, 5*time.Second, 10*time.Millisecond)
+
+		_, _ = ts.Run(t, test.TestCase{Method: http.MethodDelete, Path: "/tyk/cache/purge-test?path=/posts/*", AdminAuth: true, Code: http.StatusOK})
+		assert.Equal(t, 1, indexed(cacheTagIndex("all")), "purged by path, removed from the tag index")
+		assert.Equal(t, 0, indexed(cacheTagIndex("posts")))
+
+		_, _ = ts.Run(t, test.TestCase{Method: http.MethodDelete, Path: "/tyk/cache/purge-test?tag=user-1", AdminAuth: true, Code: http.StatusOK})
+		assert.Equal(t, 0, indexed(cachePathIndex), "purged by tag, removed from the path index")
+	})
+}
+
 func TestGetOAuthClients(t *testing.T) {
 	ts := StartTest(nil)
 	defer ts.Close()
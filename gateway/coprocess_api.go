@@ -16,13 +16,21 @@ import (
 // CoProcessDefaultKeyPrefix is used as a key prefix for this CP.
 const CoProcessDefaultKeyPrefix = "coprocess-data:"
 
-func getStorageForPython(ctx context.Context) *storage.RedisCluster {
-	rc := storage.NewConnectionHandler(ctx)
-
-	go rc.Connect(ctx, nil, &config.Config{})
-	rc.WaitConnect(ctx)
+// GatewayStorageConnectionHandler declared as global variable, set during gw start
+var GatewayStorageConnectionHandler *storage.ConnectionHandler
+
+// getStorageForPython returns the storage of the gateway, or a default Redis
+// connection if the gateway isn't started.
+func getStorageForPython(ctx context.Context) storage.Handler {
+	rc := GatewayStorageConnectionHandler
+	if rc == nil {
+		rc = storage.NewConnectionHandler(ctx)
+
+		go rc.Connect(ctx, nil, &config.Config{})
+		rc.WaitConnect(ctx)
+	}
 
-	handler := &storage.RedisCluster{KeyPrefix: CoProcessDefaultKeyPrefix, ConnectionHandler: rc}
+	handler := rc.NewHandler(&storage.RedisCluster{KeyPrefix: CoProcessDefaultKeyPrefix})
 	handler.Connect()
 	return handler
 }
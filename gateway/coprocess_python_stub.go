@@ -11,10 +11,13 @@ import (
 	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/config"
 	"github.com/TykTechnologies/tyk/coprocess"
+	"github.com/TykTechnologies/tyk/storage"
 )
 
 var GatewayFireSystemEvent func(name apidef.TykEvent, meta interface{})
 
+var GatewayStorageConnectionHandler *storage.ConnectionHandler
+
 func NewPythonDispatcher(conf config.Config) (dispatcher coprocess.Dispatcher, err error) {
 	return nil, errors.New("python support not compiled")
 }
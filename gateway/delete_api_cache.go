@@ -1,14 +1,153 @@
 package gateway
 
 import (
+	"encoding/json"
 	"fmt"
+	"path"
+	"slices"
+	"strings"
 
 	"github.com/TykTechnologies/tyk/storage"
 )
 
 func (gw *Gateway) invalidateAPICache(apiID string) bool {
-	store := storage.RedisCluster{IsCache: true, ConnectionHandler: gw.StorageConnectionHandler}
+	store := gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{IsCache: true})
 	store.Connect()
 
+	gw.evictLocalAPICache(apiID)
+
 	return store.DeleteScanMatch(fmt.Sprintf("cache-%s*", apiID))
 }
+
+// evictLocalAPICache removes the cached responses of the API from the local
+// response cache, if enabled.
+func (gw *Gateway) evictLocalAPICache(apiID string) {
+	if gw.localResponseCache != nil {
+		gw.localResponseCache.DeletePrefix(localCacheKey(apiID, ""))
+	}
+}
+
+// cachePurge selects the cached responses of an API to purge.
+type cachePurge struct {
+	APIID string `json:"api_id"`
+	// Tags purges the responses tagged from the upstream headers or the cache tag paths.
+	Tags []string `json:"tags,omitempty"`
+	// Paths purges the responses of the request paths, relative to the listen
+	// path, matching one of the globs.
+	Paths []string `json:"paths,omitempty"`
+	// Keys purges the responses cached under the keys.
+	Keys []string `json:"keys,omitempty"`
+}
+
+// purgeAPICache deletes the cached responses selected by the purge, looking
+// them up in the tag and path indexes. The purged entries are removed from
+// all the indexes. It returns the keys purged.
+func (gw *Gateway) purgeAPICache(purge cachePurge) ([]string, error) {
+	store := gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{KeyPrefix: "cache-" + purge.APIID, IsCache: true})
+	store.Connect()
+
+	keys := append([]string{}, purge.Keys...)
+
+	for _, tag := range purge.Tags {
+		members, _, err := store.GetSortedSetRange(cacheTagIndex(tag), "-inf", "+inf")
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, members...)
+		store.DeleteKey(cacheTagIndex(tag))
+	}
+
+	pathMembers, _, err := store.GetSortedSetRange(cachePathIndex, "-inf", "+inf")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(purge.Paths) > 0 {
+		for _, member := range pathMembers {
+			reqPath, key, _ := strings.Cut(member, " ")
+			for _, glob := range purge.Paths {
+				if ok, _ := path.Match(glob, reqPath); ok {
+					keys = append(keys, key)
+					break
+				}
+			}
+		}
+	}
+
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+	if len(keys) == 0 {
+		return keys, nil
+	}
+
+	unindexCacheEntries(store, keys, pathMembers)
+
+	gw.evictLocalCacheEntries(purge.APIID, keys)
+
+	toDelete := make([]string, 0, 2*len(keys))
+	for _, key := range keys {
+		toDelete = append(toDelete, key, cacheEntryTags(key))
+	}
+	// DeleteKeys prefixes the keys in place
+	store.DeleteKeys(toDelete)
+
+	return keys, nil
+}
+
+// unindexCacheEntries removes the purged keys from the path index and from
+// the indexes of their tags. The stores which can't remove a member of a
+// sorted set keep them until they expire.
+func unindexCacheEntries(store storage.Handler, keys []string, pathMembers []string) {
+	remover, ok := store.(storage.RemoveFromSortedSetHandler)
+	if !ok {
+		return
+	}
+
+	for _, member := range pathMembers {
+		_, key, _ := strings.Cut(member, " ")
+		if _, found := slices.BinarySearch(keys, key); found {
+			_ = remover.RemoveFromSortedSet(cachePathIndex, member) //nolint:errcheck // the member expires with the entry
+		}
+	}
+
+	for _, key := range keys {
+		tags, err := store.GetKey(cacheEntryTags(key))
+		if err != nil {
+			continue
+		}
+
+		for _, tag := range strings.Split(tags, "\n") {
+			_ = remover.RemoveFromSortedSet(cacheTagIndex(tag), key) //nolint:errcheck // the member expires with the entry
+		}
+	}
+}
+
+// evictLocalCacheEntries removes the cached responses of the API under the
+// keys from the local response cache, if enabled.
+func (gw *Gateway) evictLocalCacheEntries(apiID string, keys []string) {
+	if gw.localResponseCache == nil {
+		return
+	}
+
+	for _, key := range keys {
+		gw.localResponseCache.Delete(localCacheKey(apiID, key))
+	}
+}
+
+// handlePurgeAPICache evicts the cached responses of a cluster notification
+// from the local response cache. The gateway which sent it already purged
+// them from the shared store along with the indexes.
+func (gw *Gateway) handlePurgeAPICache(payload string) {
+	if gw.localResponseCache == nil {
+		return
+	}
+
+	var purge cachePurge
+	if err := json.Unmarshal([]byte(payload), &purge); err != nil {
+		log.WithError(err).Error("Could not decode cache purge")
+		return
+	}
+
+	gw.evictLocalCacheEntries(purge.APIID, purge.Keys)
+}
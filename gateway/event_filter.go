@@ -0,0 +1,280 @@
+package gateway
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/internal/certcheck"
+	"github.com/TykTechnologies/tyk/internal/crypto"
+)
+
+// eventAttributes are the event metadata the event filters and the
+// aggregation groups apply to. The attributes missing from an event are empty.
+type eventAttributes struct {
+	OrgID string
+	APIID string
+	// Key is the key of the event, KeyHash its hash.
+	Key     string
+	KeyHash string
+	Host    string
+	// UsagePercentage is the quota usage of a TriggerExceeded event, -1 for other events.
+	UsagePercentage int64
+}
+
+// newEventAttributes extracts the attributes of the event. The organisation
+// and API of the handler's API are used when the event doesn't carry them.
+func newEventAttributes(em config.EventMessage, spec *APISpec) eventAttributes {
+	attrs := eventAttributes{UsagePercentage: -1}
+
+	switch meta := derefEventMeta(em.Meta).(type) {
+	case EventKeyFailureMeta:
+		attrs.Key = meta.Key
+	case EventVersionFailureMeta:
+		attrs.Key = meta.Key
+	case EventTriggerExceededMeta:
+		attrs.OrgID = meta.OrgID
+		attrs.Key = meta.Key
+		attrs.UsagePercentage = meta.UsagePercentage
+	case EventTokenMeta:
+		attrs.OrgID = meta.Org
+		attrs.Key = meta.Key
+	case EventCurcuitBreakerMeta:
+		attrs.APIID = meta.APIID
+	case EventHostStatusMeta:
+		if u, err := url.Parse(meta.HostInfo.CheckURL); err == nil {
+			attrs.Host = u.Hostname()
+		}
+		attrs.APIID = meta.HostInfo.MetaData["api_id"]
+	case EventAPIMeta:
+		attrs.OrgID = meta.OrgID
+		attrs.APIID = meta.APIID
+	case EventPolicyMeta:
+		attrs.OrgID = meta.OrgID
+	case EventMCPContentMeta:
+		attrs.OrgID = meta.OrgID
+		attrs.APIID = meta.APIID
+		attrs.Key = meta.Key
+	case certcheck.EventCertificateExpiringSoonMeta:
+		attrs.APIID = meta.APIID
+	case certcheck.EventCertificateExpiredMeta:
+		attrs.APIID = meta.APIID
+	}
+
+	if spec != nil {
+		if attrs.OrgID == "" {
+			attrs.OrgID = spec.OrgID
+		}
+		if attrs.APIID == "" {
+			attrs.APIID = spec.APIID
+		}
+	}
+
+	if attrs.Key != "" {
+		attrs.KeyHash = crypto.HashStr(attrs.Key)
+	}
+
+	return attrs
+}
+
+// derefEventMeta returns the value of the event metadata passed by pointer.
+func derefEventMeta(meta any) any {
+	v := reflect.ValueOf(meta)
+	if v.Kind() == reflect.Pointer && !v.IsNil() {
+		return v.Elem().Interface()
+	}
+	return meta
+}
+
+// eventFilterMatches checks the event attributes match all the criteria of
+// the filter. Keys match by hash, or by value when the event carries a hashed key.
+func eventFilterMatches(filter *apidef.EventFilter, attrs eventAttributes) bool {
+	if filter == nil {
+		return true
+	}
+
+	if len(filter.OrgIDs) > 0 && !slices.Contains(filter.OrgIDs, attrs.OrgID) {
+		return false
+	}
+
+	if len(filter.APIIDs) > 0 && !slices.Contains(filter.APIIDs, attrs.APIID) {
+		return false
+	}
+
+	if len(filter.KeyHashes) > 0 {
+		if attrs.Key == "" {
+			return false
+		}
+		if !slices.Contains(filter.KeyHashes, attrs.KeyHash) && !slices.Contains(filter.KeyHashes, attrs.Key) {
+			return false
+		}
+	}
+
+	if len(filter.Hosts) > 0 && !slices.Contains(filter.Hosts, attrs.Host) {
+		return false
+	}
+
+	if filter.MinTriggerPercentage > 0 && attrs.UsagePercentage < filter.MinTriggerPercentage {
+		return false
+	}
+
+	return true
+}
+
+// eventGroup returns the aggregation group of the event.
+func eventGroup(groupBy string, attrs eventAttributes) string {
+	switch groupBy {
+	case apidef.EventGroupByKey:
+		return attrs.KeyHash
+	case apidef.EventGroupByAPI:
+		return attrs.APIID
+	case apidef.EventGroupByOrg:
+		return attrs.OrgID
+	case apidef.EventGroupByHost:
+		return attrs.Host
+	default:
+		return ""
+	}
+}
+
+// EventAggregateMeta is the metadata of an aggregated event. It holds the
+// fields of the first event of the window, so the handler templates keep
+// working, along with the aggregation fields: Count, Group, Window,
+// FirstSeen and LastSeen.
+type EventAggregateMeta map[string]any
+
+// LogMessage summarises the aggregated events for the log handler.
+func (m EventAggregateMeta) LogMessage(prefix string) string {
+	return fmt.Sprintf("%s:%v: %v events in %v", prefix, m["Group"], m["Count"], m["Window"])
+}
+
+// eventMetaFields returns the exported fields of the event metadata by name,
+// the fields of the embedded structs are promoted.
+func eventMetaFields(meta any, fields map[string]any) {
+	v := reflect.ValueOf(derefEventMeta(meta))
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		switch {
+		case field.Anonymous && field.Type.Kind() == reflect.Struct:
+			eventMetaFields(v.Field(i).Interface(), fields)
+		case field.IsExported():
+			fields[field.Name] = v.Field(i).Interface()
+		}
+	}
+}
+
+// eventWindow accumulates the events of a group during a window.
+type eventWindow struct {
+	first     config.EventMessage
+	count     int
+	firstSeen time.Time
+	lastSeen  time.Time
+}
+
+// filteredEventHandler passes the events matching the filter to the wrapped
+// handler. With an aggregation, the events are replaced by one summary per
+// group, sent when the window of the group's first event elapses.
+type filteredEventHandler struct {
+	handler config.TykEventHandler
+	filter  *apidef.EventFilter
+	groupBy string
+	window  time.Duration
+	spec    *APISpec
+
+	mu      sync.Mutex
+	windows map[string]*eventWindow
+}
+
+// newFilteredEventHandler wraps the handler with the filter and aggregation
+// of the trigger, the handler is returned as is without them.
+func newFilteredEventHandler(handler config.TykEventHandler, filter *apidef.EventFilter, aggregate *apidef.EventAggregation, spec *APISpec) config.TykEventHandler {
+	h := &filteredEventHandler{
+		handler: handler,
+		filter:  filter,
+		spec:    spec,
+		windows: make(map[string]*eventWindow),
+	}
+
+	if aggregate != nil && aggregate.Window > 0 {
+		h.groupBy = aggregate.GroupBy
+		h.window = time.Duration(aggregate.Window) * time.Second
+	}
+
+	if h.filter == nil && h.window == 0 {
+		return handler
+	}
+
+	return h
+}
+
+// Init is a no-op, the wrapped handler is initialised.
+func (h *filteredEventHandler) Init(any) error {
+	return nil
+}
+
+// HandleEvent passes the event to the wrapped handler, or adds it to the window of its group.
+func (h *filteredEventHandler) HandleEvent(em config.EventMessage) {
+	attrs := newEventAttributes(em, h.spec)
+	if !eventFilterMatches(h.filter, attrs) {
+		log.Debugf("Event %s filtered out", em.Type)
+		return
+	}
+
+	if h.window == 0 {
+		h.handler.HandleEvent(em)
+		return
+	}
+
+	group := eventGroup(h.groupBy, attrs)
+	now := time.Now()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if w, ok := h.windows[group]; ok {
+		w.count++
+		w.lastSeen = now
+		return
+	}
+
+	h.windows[group] = &eventWindow{first: em, count: 1, firstSeen: now, lastSeen: now}
+	time.AfterFunc(h.window, func() {
+		h.flush(group)
+	})
+}
+
+// flush sends the summary of the group's window to the wrapped handler.
+func (h *filteredEventHandler) flush(group string) {
+	h.mu.Lock()
+	w, ok := h.windows[group]
+	delete(h.windows, group)
+	h.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	meta := EventAggregateMeta{}
+	eventMetaFields(w.first.Meta, meta)
+	meta["Message"] = fmt.Sprintf("%d %s events in %s", w.count, w.first.Type, h.window)
+	meta["Count"] = w.count
+	meta["Group"] = group
+	meta["Window"] = h.window.String()
+	meta["FirstSeen"] = w.firstSeen
+	meta["LastSeen"] = w.lastSeen
+
+	h.handler.HandleEvent(config.EventMessage{
+		Type:      w.first.Type,
+		Meta:      meta,
+		TimeStamp: time.Now().Local().String(),
+	})
+}
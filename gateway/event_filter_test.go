@@ -0,0 +1,141 @@
+package gateway
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/internal/crypto"
+)
+
+type recordingEventHandler struct {
+	mu     sync.Mutex
+	events []config.EventMessage
+}
+
+func (h *recordingEventHandler) Init(any) error {
+	return nil
+}
+
+func (h *recordingEventHandler) HandleEvent(em config.EventMessage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events = append(h.events, em)
+}
+
+func (h *recordingEventHandler) received() []config.EventMessage {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]config.EventMessage{}, h.events...)
+}
+
+func TestEventFilterMatches(t *testing.T) {
+	spec := &APISpec{APIDefinition: &apidef.APIDefinition{APIID: "api1", OrgID: "org1"}}
+
+	authFailure := config.EventMessage{
+		Type: EventAuthFailure,
+		Meta: EventKeyFailureMeta{Key: "key1", Path: "/get"},
+	}
+	triggerExceeded := config.EventMessage{
+		Type: EventTriggerExceeded,
+		Meta: EventTriggerExceededMeta{OrgID: "org2", Key: "key2", UsagePercentage: 80},
+	}
+	hostDown := config.EventMessage{
+		Type: EventHOSTDOWN,
+		Meta: EventHostStatusMeta{HostInfo: HostHealthReport{HostData: HostData{CheckURL: "http://upstream.local:8080/health"}}},
+	}
+
+	tests := []struct {
+		name   string
+		filter *apidef.EventFilter
+		em     config.EventMessage
+		spec   *APISpec
+		match  bool
+	}{
+		{"no filter", nil, authFailure, nil, true},
+		{"api of the spec", &apidef.EventFilter{APIIDs: []string{"api1"}}, authFailure, spec, true},
+		{"other api", &apidef.EventFilter{APIIDs: []string{"api2"}}, authFailure, spec, false},
+		{"org of the spec", &apidef.EventFilter{OrgIDs: []string{"org1"}}, authFailure, spec, true},
+		{"org of the event", &apidef.EventFilter{OrgIDs: []string{"org2"}}, triggerExceeded, spec, true},
+		{"key hash", &apidef.EventFilter{KeyHashes: []string{crypto.HashStr("key1")}}, authFailure, nil, true},
+		{"hashed key", &apidef.EventFilter{KeyHashes: []string{"key1"}}, authFailure, nil, true},
+		{"other key", &apidef.EventFilter{KeyHashes: []string{crypto.HashStr("key2")}}, authFailure, nil, false},
+		{"event without key", &apidef.EventFilter{KeyHashes: []string{"key1"}}, hostDown, nil, false},
+		{"host", &apidef.EventFilter{Hosts: []string{"upstream.local"}}, hostDown, nil, true},
+		{"other host", &apidef.EventFilter{Hosts: []string{"other.local"}}, hostDown, nil, false},
+		{"trigger percentage reached", &apidef.EventFilter{MinTriggerPercentage: 80}, triggerExceeded, nil, true},
+		{"trigger percentage not reached", &apidef.EventFilter{MinTriggerPercentage: 90}, triggerExceeded, nil, false},
+		{"event without trigger percentage", &apidef.EventFilter{MinTriggerPercentage: 50}, authFailure, nil, false},
+		{"all criteria", &apidef.EventFilter{OrgIDs: []string{"org2"}, KeyHashes: []string{crypto.HashStr("key2")}, MinTriggerPercentage: 50}, triggerExceeded, nil, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.match, eventFilterMatches(tc.filter, newEventAttributes(tc.em, tc.spec)))
+		})
+	}
+}
+
+func TestNewFilteredEventHandler(t *testing.T) {
+	handler := &recordingEventHandler{}
+
+	assert.Same(t, handler, newFilteredEventHandler(handler, nil, nil, nil))
+	assert.Same(t, handler, newFilteredEventHandler(handler, nil, &apidef.EventAggregation{}, nil))
+	assert.IsType(t, &filteredEventHandler{}, newFilteredEventHandler(handler, &apidef.EventFilter{}, nil, nil))
+
+	filtered := newFilteredEventHandler(handler, &apidef.EventFilter{KeyHashes: []string{"key1"}}, nil, nil)
+	filtered.HandleEvent(config.EventMessage{Type: EventAuthFailure, Meta: &EventKeyFailureMeta{Key: "key1"}})
+	filtered.HandleEvent(config.EventMessage{Type: EventAuthFailure, Meta: &EventKeyFailureMeta{Key: "key2"}})
+
+	require.Len(t, handler.received(), 1)
+}
+
+func TestFilteredEventHandler_aggregate(t *testing.T) {
+	handler := &recordingEventHandler{}
+	h := &filteredEventHandler{
+		handler: handler,
+		groupBy: apidef.EventGroupByKey,
+		window:  50 * time.Millisecond,
+		windows: make(map[string]*eventWindow),
+	}
+
+	fire := func(key string) {
+		h.HandleEvent(config.EventMessage{
+			Type: EventAuthFailure,
+			Meta: EventKeyFailureMeta{EventMetaDefault: EventMetaDefault{Message: "Auth Failure"}, Key: key, Path: "/get"},
+		})
+	}
+
+	for i := 0; i < 3; i++ {
+		fire("key1")
+	}
+	fire("key2")
+
+	assert.Empty(t, handler.received())
+	assert.Eventually(t, func() bool {
+		return len(handler.received()) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	counts := map[any]any{}
+	for _, em := range handler.received() {
+		assert.Equal(t, EventAuthFailure, em.Type)
+
+		meta, ok := em.Meta.(EventAggregateMeta)
+		require.True(t, ok)
+		assert.Equal(t, "/get", meta["Path"])
+		assert.Equal(t, "50ms", meta["Window"])
+		counts[meta["Key"]] = meta["Count"]
+	}
+	assert.Equal(t, map[any]any{"key1": 3, "key2": 1}, counts)
+
+	// a new window starts after the summary
+	fire("key1")
+	assert.Eventually(t, func() bool {
+		return len(handler.received()) == 3
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,261 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/internal/cloudevents"
+	"github.com/TykTechnologies/tyk/internal/event"
+	"github.com/TykTechnologies/tyk/internal/httpclient"
+)
+
+const (
+	// cloudEventsTypePrefix prefixes the event names in the CloudEvents type attribute.
+	cloudEventsTypePrefix = "io.tyk.gateway."
+	cloudEventsTimeout    = 10 * time.Second
+)
+
+// ErrCloudEventsSinkNotAllowed is returned by the CloudEvents handlers of the
+// API definitions using another sink than http.
+var ErrCloudEventsSinkNotAllowed = errors.New("only the http cloudevents sink is allowed in API definitions")
+
+// CloudEventsHandler is an event handler emitting the events as CloudEvents
+// to an HTTP endpoint, a Kafka topic, a NATS subject or a file.
+type CloudEventsHandler struct {
+	conf apidef.CloudEventsHandlerConf
+	sink cloudevents.Config
+	// spec is the API of the handler, it's nil for global handlers.
+	spec *APISpec
+	Gw   *Gateway `json:"-"`
+}
+
+func (h *CloudEventsHandler) logger() *logrus.Entry {
+	return log.WithField("prefix", "cloudevents_handler")
+}
+
+// Init initializes the CloudEventsHandler instance with the given configuration.
+func (h *CloudEventsHandler) Init(handlerConf any) error {
+	if err := h.conf.Scan(handlerConf); err != nil {
+		h.logger().Error("Problem getting configuration, skipping. ", err)
+		return err
+	}
+
+	if h.conf.Disabled {
+		h.logger().Infof("skipping disabled cloudevents handler %s", h.conf.ID)
+		return ErrEventHandlerDisabled
+	}
+
+	h.sink = cloudEventsSinkConfig(h.conf)
+	if err := h.sink.Validate(); err != nil {
+		h.logger().Error("Invalid cloudevents sink, skipping. ", err)
+		return err
+	}
+
+	// The API definitions can't write files or connect to brokers on the
+	// gateway host network, only the gateway configuration can.
+	if h.spec != nil && h.sink.Type != cloudevents.SinkHTTP {
+		h.logger().Errorf("cloudevents %s sink isn't allowed in API definitions, skipping", h.sink.Type)
+		return ErrCloudEventsSinkNotAllowed
+	}
+
+	return nil
+}
+
+// HandleEvent sends the event to the sink.
+func (h *CloudEventsHandler) HandleEvent(em config.EventMessage) {
+	source := h.conf.Source
+	if source == "" {
+		source = "urn:tyk:gateway:" + h.Gw.GetNodeID()
+	}
+
+	event := cloudevents.New(source, cloudEventsTypePrefix+string(em.Type), em.Meta)
+	if h.spec != nil {
+		event.Subject = h.spec.APIID
+	}
+
+	logger := h.logger().WithFields(logrus.Fields{
+		"event": em.Type,
+		"sink":  h.conf.Sink,
+	})
+
+	sink, err := h.Gw.cloudEventsSink(h.sink)
+	if err != nil {
+		logger.WithError(err).Error("Could not connect to cloudevents sink")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.Gw.ctx, cloudEventsTimeout)
+	defer cancel()
+
+	if err := sink.Send(ctx, event); err != nil {
+		logger.WithError(err).Error("Could not send cloudevent")
+	}
+}
+
+func cloudEventsSinkConfig(conf apidef.CloudEventsHandlerConf) cloudevents.Config {
+	return cloudevents.Config{
+		Type:    conf.Sink,
+		URL:     conf.URL,
+		Headers: conf.Headers,
+		Brokers: conf.Brokers,
+		Topic:   conf.Topic,
+		Path:    conf.Path,
+	}
+}
+
+// cloudEventsSinkConn is the connection of the handlers to a sink.
+type cloudEventsSinkConn struct {
+	// ready is closed when the connection attempt is over.
+	ready chan struct{}
+	sink  cloudevents.Sink
+	err   error
+	// failures counts the consecutive failed connection attempts, the next
+	// one isn't made before retryAt.
+	failures int
+	retryAt  time.Time
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func (c *cloudEventsSinkConn) close() {
+	<-c.ready
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		if c.sink == nil {
+			return
+		}
+		if err := c.sink.Close(); err != nil {
+			log.WithError(err).Warning("Could not close cloudevents sink")
+		}
+	})
+}
+
+// cloudEventsRetryDelay is the delay before reconnecting to a sink after the
+// failed connection attempts, doubling from a second up to a minute.
+func cloudEventsRetryDelay(failures int) time.Duration {
+	if failures > 7 {
+		return time.Minute
+	}
+	return min(time.Second<<(failures-1), time.Minute)
+}
+
+// cloudEventsSink returns the connected sink of the configuration, shared by
+// the handlers with the same sink. The sink is connected outside of the lock,
+// the handlers of the sink wait for the connection attempt while the others
+// go on. A sink failing to connect returns the connection error until the
+// retry delay elapsed. Sinks are closed when they aren't used after a reload
+// and when the gateway stops.
+func (gw *Gateway) cloudEventsSink(conf cloudevents.Config) (cloudevents.Sink, error) {
+	key, err := json.Marshal(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	gw.cloudEventsSinksMu.Lock()
+	conn, ok := gw.cloudEventsSinks[string(key)]
+	if ok {
+		select {
+		case <-conn.ready:
+			if conn.err == nil || time.Now().Before(conn.retryAt) {
+				gw.cloudEventsSinksMu.Unlock()
+				return conn.sink, conn.err
+			}
+		default:
+			gw.cloudEventsSinksMu.Unlock()
+			<-conn.ready
+			return conn.sink, conn.err
+		}
+	}
+
+	next := &cloudEventsSinkConn{ready: make(chan struct{}), closed: make(chan struct{})}
+	if ok {
+		next.failures = conn.failures
+	}
+	if gw.cloudEventsSinks == nil {
+		gw.cloudEventsSinks = make(map[string]*cloudEventsSinkConn)
+	}
+	gw.cloudEventsSinks[string(key)] = next
+	gw.cloudEventsSinksMu.Unlock()
+
+	next.sink, next.err = gw.connectCloudEventsSink(conf)
+	if next.err != nil {
+		next.failures++
+		next.retryAt = time.Now().Add(cloudEventsRetryDelay(next.failures))
+	}
+	close(next.ready)
+
+	if next.err == nil {
+		go func() {
+			select {
+			case <-gw.ctx.Done():
+				next.close()
+			case <-next.closed:
+			}
+		}()
+	}
+
+	return next.sink, next.err
+}
+
+func (gw *Gateway) connectCloudEventsSink(conf cloudevents.Config) (cloudevents.Sink, error) {
+	// The HTTP sink uses the external services settings of webhooks
+	client, err := NewExternalHTTPClientFactory(gw).CreateWebhookClient()
+	if err != nil {
+		if gw.GetConfig().ExternalServices.Webhooks.MTLS.Enabled && httpclient.IsMTLSError(err) {
+			return nil, err
+		}
+		client = &http.Client{Timeout: cloudEventsTimeout}
+	}
+
+	return cloudevents.NewSink(conf, client)
+}
+
+// closeUnusedCloudEventsSinks closes the sinks without handler in the global
+// and the API event handlers, it's called once the APIs are reloaded.
+func (gw *Gateway) closeUnusedCloudEventsSinks() {
+	inUse := make(map[string]struct{})
+	mark := func(events map[apidef.TykEvent][]apidef.EventHandlerTriggerConfig) {
+		for _, handlers := range events {
+			for _, handler := range handlers {
+				if handler.Handler != event.CloudEventsHandler {
+					continue
+				}
+
+				var conf apidef.CloudEventsHandlerConf
+				if err := conf.Scan(handler.HandlerMeta); err != nil || conf.Disabled {
+					continue
+				}
+
+				if key, err := json.Marshal(cloudEventsSinkConfig(conf)); err == nil {
+					inUse[string(key)] = struct{}{}
+				}
+			}
+		}
+	}
+
+	mark(gw.GetConfig().EventHandlers.Events)
+	gw.apisMu.RLock()
+	for _, spec := range gw.apisByID {
+		mark(spec.EventHandlers.Events)
+	}
+	gw.apisMu.RUnlock()
+
+	gw.cloudEventsSinksMu.Lock()
+	defer gw.cloudEventsSinksMu.Unlock()
+
+	for key, conn := range gw.cloudEventsSinks {
+		if _, ok := inUse[key]; !ok {
+			delete(gw.cloudEventsSinks, key)
+			go conn.close()
+		}
+	}
+}
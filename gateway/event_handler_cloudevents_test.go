@@ -0,0 +1,164 @@
+package gateway
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/internal/cloudevents"
+	"github.com/TykTechnologies/tyk/internal/event"
+)
+
+func TestCloudEventsHandler_Init(t *testing.T) {
+	ts := StartTest(nil)
+	t.Cleanup(ts.Close)
+
+	t.Run("on disabled", func(t *testing.T) {
+		h := &CloudEventsHandler{Gw: ts.Gw}
+		err := h.Init(map[string]any{"disabled": true, "sink": "file", "path": "events.log"})
+		assert.ErrorIs(t, err, ErrEventHandlerDisabled)
+	})
+
+	t.Run("invalid sink", func(t *testing.T) {
+		h := &CloudEventsHandler{Gw: ts.Gw}
+		err := h.Init(map[string]any{"sink": "smtp"})
+		assert.ErrorIs(t, err, cloudevents.ErrUnknownSink)
+	})
+
+	t.Run("sink not allowed in API definitions", func(t *testing.T) {
+		spec := &APISpec{APIDefinition: &apidef.APIDefinition{APIID: "cloudevents-api"}}
+
+		h := &CloudEventsHandler{spec: spec, Gw: ts.Gw}
+		err := h.Init(map[string]any{"sink": "file", "path": filepath.Join(t.TempDir(), "events.log")})
+		assert.ErrorIs(t, err, ErrCloudEventsSinkNotAllowed)
+
+		h = &CloudEventsHandler{Gw: ts.Gw}
+		assert.NoError(t, h.Init(map[string]any{"sink": "file", "path": filepath.Join(t.TempDir(), "events.log")}))
+	})
+}
+
+func TestCloudEventsHandler_HandleEvent(t *testing.T) {
+	ts := StartTest(nil)
+	t.Cleanup(ts.Close)
+
+	received := make(chan []byte, 1)
+	sink := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- body
+	}))
+	t.Cleanup(sink.Close)
+
+	spec := &APISpec{APIDefinition: &apidef.APIDefinition{APIID: "cloudevents-api"}}
+
+	h, err := ts.Gw.EventHandlerByName(apidef.EventHandlerTriggerConfig{
+		Handler: event.CloudEventsHandler,
+		HandlerMeta: map[string]any{
+			"sink":   "http",
+			"url":    sink.URL,
+			"source": "urn:tyk:test",
+		},
+	}, spec)
+	require.NoError(t, err)
+
+	h.HandleEvent(config.EventMessage{
+		Type: EventQuotaExceeded,
+		Meta: EventKeyFailureMeta{
+			EventMetaDefault: EventMetaDefault{Message: "Key Quota Limit Exceeded"},
+			Path:             "/quota",
+			Key:              "abc",
+		},
+		TimeStamp: time.Now().Local().String(),
+	})
+
+	var content []byte
+	select {
+	case content = <-received:
+	case <-time.After(time.Second):
+		t.Fatal("cloudevent wasn't sent")
+	}
+
+	var ce map[string]any
+	require.NoError(t, json.Unmarshal(content, &ce))
+
+	assert.Equal(t, cloudevents.SpecVersion, ce["specversion"])
+	assert.Equal(t, "io.tyk.gateway.QuotaExceeded", ce["type"])
+	assert.Equal(t, "urn:tyk:test", ce["source"])
+	assert.Equal(t, "cloudevents-api", ce["subject"])
+	assert.NotEmpty(t, ce["id"])
+
+	data, ok := ce["data"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "/quota", data["Path"])
+	assert.Equal(t, "Key Quota Limit Exceeded", data["Message"])
+}
+
+func TestGateway_CloudEventsSink(t *testing.T) {
+	ts := StartTest(nil)
+	t.Cleanup(ts.Close)
+
+	t.Run("backs off failed connections", func(t *testing.T) {
+		conf := cloudevents.Config{Type: cloudevents.SinkNATS, URL: "nats://127.0.0.1:1", Topic: "events"}
+
+		_, err := ts.Gw.cloudEventsSink(conf)
+		require.Error(t, err)
+
+		key, err := json.Marshal(conf)
+		require.NoError(t, err)
+
+		ts.Gw.cloudEventsSinksMu.Lock()
+		conn := ts.Gw.cloudEventsSinks[string(key)]
+		ts.Gw.cloudEventsSinksMu.Unlock()
+		require.NotNil(t, conn)
+		assert.Equal(t, 1, conn.failures)
+		assert.True(t, conn.retryAt.After(time.Now()))
+
+		_, err = ts.Gw.cloudEventsSink(conf)
+		assert.ErrorIs(t, err, conn.err)
+
+		ts.Gw.cloudEventsSinksMu.Lock()
+		assert.Same(t, conn, ts.Gw.cloudEventsSinks[string(key)])
+		ts.Gw.cloudEventsSinksMu.Unlock()
+	})
+
+	t.Run("closes unused sinks on reload", func(t *testing.T) {
+		used := filepath.Join(t.TempDir(), "used.log")
+		unused := filepath.Join(t.TempDir(), "unused.log")
+
+		globalConf := ts.Gw.GetConfig()
+		globalConf.EventHandlers.Events = map[apidef.TykEvent][]apidef.EventHandlerTriggerConfig{
+			EventQuotaExceeded: {{
+				Handler:     event.CloudEventsHandler,
+				HandlerMeta: map[string]any{"sink": "file", "path": used},
+			}},
+		}
+		ts.Gw.SetConfig(globalConf)
+
+		for _, path := range []string{used, unused} {
+			_, err := ts.Gw.cloudEventsSink(cloudevents.Config{Type: cloudevents.SinkFile, Path: path})
+			require.NoError(t, err)
+		}
+
+		ts.Gw.closeUnusedCloudEventsSinks()
+
+		ts.Gw.cloudEventsSinksMu.Lock()
+		defer ts.Gw.cloudEventsSinksMu.Unlock()
+
+		var paths []string
+		for key := range ts.Gw.cloudEventsSinks {
+			var conf cloudevents.Config
+			require.NoError(t, json.Unmarshal([]byte(key), &conf))
+			paths = append(paths, conf.Path)
+		}
+		assert.Contains(t, paths, used)
+		assert.NotContains(t, paths, unused)
+	})
+}
@@ -7,7 +7,6 @@ import (
 	"errors"
 	"fmt"
 	htmltemplate "html/template"
-	"io/ioutil"
 	"net/http"
 	"net/url"
 	"path/filepath"
@@ -20,7 +19,6 @@ import (
 	"github.com/TykTechnologies/tyk/config"
 	"github.com/TykTechnologies/tyk/header"
 	"github.com/TykTechnologies/tyk/internal/certcheck"
-	"github.com/TykTechnologies/tyk/internal/httpclient"
 	"github.com/TykTechnologies/tyk/storage"
 )
 
@@ -47,6 +45,7 @@ type WebHookHandler struct {
 	conf     apidef.WebHookHandlerConf
 	template *htmltemplate.Template // non-nil if Init is run without error
 	store    storage.Handler
+	queue    *webhookQueue
 
 	contentType      string
 	dashboardService DashboardServiceSender
@@ -70,9 +69,14 @@ func (w *WebHookHandler) Init(handlerConf interface{}) error {
 		return ErrEventHandlerDisabled
 	}
 
-	w.store = &storage.RedisCluster{KeyPrefix: "webhook.cache.", ConnectionHandler: w.Gw.StorageConnectionHandler}
+	w.store = w.Gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{KeyPrefix: "webhook.cache."})
 	w.store.Connect()
 
+	if w.conf.MaxRetries > 0 {
+		w.queue = newWebhookQueue(w.Gw)
+		w.Gw.startWebhookRetries()
+	}
+
 	// Pre-load template on init
 	if w.conf.TemplatePath != "" {
 		w.template, err = htmltemplate.ParseFiles(w.conf.TemplatePath)
@@ -240,6 +244,11 @@ func (w *WebHookHandler) BuildRequest(reqBody string) (*http.Request, error) {
 
 	req.Header.Set(header.UserAgent, header.TykHookshot)
 
+	// Sign the payload for the receiver to verify it
+	if w.conf.SigningSecret != "" {
+		signWebhook(req.Header, w.conf.SigningSecret, reqBody)
+	}
+
 	ignoreCanonical := w.Gw.GetConfig().IgnoreCanonicalMIMEHeaderKey
 	for key, val := range w.conf.HeaderList {
 		setCustomHeader(req.Header, key, val, ignoreCanonical)
@@ -297,51 +306,19 @@ func (w *WebHookHandler) HandleEvent(em config.EventMessage) {
 		return
 	}
 
-	// Create HTTP client using factory for webhook service
-	clientFactory := NewExternalHTTPClientFactory(w.Gw)
-	cli, err := clientFactory.CreateWebhookClient()
-	if err != nil {
-		// Check if mTLS is explicitly enabled and error is certificate-related - if so, don't fallback as it would bypass security
-		gwConfig := w.Gw.GetConfig()
-		if gwConfig.ExternalServices.Webhooks.MTLS.Enabled && httpclient.IsMTLSError(err) {
-			log.WithError(err).Error("mTLS configuration failed for webhooks. Webhook delivery will be skipped to maintain security.")
-			// Skip webhook delivery entirely when mTLS is misconfigured
+	if err := w.Gw.sendWebhook(req); err != nil {
+		// Skip webhook delivery entirely when mTLS is misconfigured
+		if errors.Is(err, errWebhookMTLS) {
 			return
-		} else {
-			// For other errors (not configured, proxy config), fallback to default client
-			log.WithError(err).Debug("Failed to create webhook HTTP client, falling back to default")
-			log.Debug("[ExternalServices] Falling back to legacy webhook client due to factory error")
-			cli = &http.Client{Timeout: 30 * time.Second}
 		}
-	} else {
-		log.Debugf("[ExternalServices] Using external services webhook client for URL: %s", req.URL.String())
-	}
 
-	resp, err := cli.Do(req)
-	if err != nil {
 		log.WithFields(logrus.Fields{
 			"prefix": "webhooks",
 		}).Error("Webhook request failed: ", err)
-	} else {
-		defer resp.Body.Close()
-		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			content, err := ioutil.ReadAll(resp.Body)
-			if err == nil {
-				log.WithFields(logrus.Fields{
-					"prefix":       "webhooks",
-					"responseCode": resp.StatusCode,
-				}).Debug(string(content))
-			} else {
-				log.WithFields(logrus.Fields{
-					"prefix": "webhooks",
-				}).Error(err)
-			}
-
-		} else {
-			log.WithFields(logrus.Fields{
-				"prefix":       "webhooks",
-				"responseCode": resp.StatusCode,
-			}).Error("Request to webhook failed")
+
+		// Persist the failed delivery to be retried
+		if w.conf.MaxRetries > 0 {
+			w.queue.fail(w.newWebhookDelivery(string(em.Type), req, reqBody), err)
 		}
 	}
 
@@ -0,0 +1,485 @@
+package gateway
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/internal/httpclient"
+	"github.com/TykTechnologies/tyk/internal/uuid"
+	"github.com/TykTechnologies/tyk/storage"
+)
+
+const (
+	webhookDeliveryPrefix = "webhook.delivery."
+	webhookQueueKey       = "queue"
+	webhookDeadLettersKey = "dead-letters"
+
+	// webhookRetryInterval is how often the queue is checked for retries due.
+	webhookRetryInterval = time.Second
+	// webhookRetryConcurrency is the number of retries a gateway sends at once.
+	webhookRetryConcurrency    = 10
+	defaultWebhookRetryBackoff = 5 * time.Second
+	maxWebhookRetryBackoff     = time.Hour
+	// webhookClaimTimeout is how long a gateway holds a retry before the
+	// other gateways may send it.
+	webhookClaimTimeout = time.Minute
+	// webhookDeadLetterTTL is how long dead-lettered deliveries are kept to be replayed.
+	webhookDeadLetterTTL = 7 * 24 * time.Hour
+	// webhookQueuedTTL is how long a queued delivery is kept once its retry is due.
+	webhookQueuedTTL = 7 * 24 * time.Hour
+	// webhookRedactedHeader replaces the header values of the listed deliveries.
+	webhookRedactedHeader = "[REDACTED]"
+)
+
+var (
+	// ErrWebhookDeliveryNotFound is returned when a webhook delivery doesn't exist.
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+
+	errWebhookMTLS = errors.New("webhook mTLS configuration failed")
+)
+
+// webhookDelivery is a failed webhook request, persisted to be retried until
+// it's delivered or dead-lettered.
+type webhookDelivery struct {
+	ID          string      `json:"id"`
+	WebhookID   string      `json:"webhook_id,omitempty"`
+	WebhookName string      `json:"webhook_name,omitempty"`
+	Event       string      `json:"event"`
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	Header      http.Header `json:"headers"`
+	Body        string      `json:"body"`
+	// SigningSecret signs every attempt again, with the time it's sent.
+	SigningSecret string `json:"signing_secret,omitempty"`
+	// Attempts is the number of failed attempts.
+	Attempts     int   `json:"attempts"`
+	MaxRetries   int   `json:"max_retries"`
+	RetryBackoff int64 `json:"retry_backoff"`
+	// NextAttempt is the unix time of the next retry, unset once dead-lettered.
+	NextAttempt int64     `json:"next_attempt,omitempty"`
+	LastError   string    `json:"last_error"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// backoff returns the delay before the next retry, doubled after each failed attempt.
+func (d *webhookDelivery) backoff() time.Duration {
+	backoff := defaultWebhookRetryBackoff
+	if d.RetryBackoff > 0 {
+		backoff = time.Duration(d.RetryBackoff) * time.Second
+	}
+
+	for i := 1; i < d.Attempts && backoff < maxWebhookRetryBackoff; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, maxWebhookRetryBackoff)
+}
+
+// signWebhookPayload returns the HMAC-SHA256 signature of a webhook payload
+// sent at the unix timestamp. The timestamp is signed with the payload, as
+// "<timestamp>.<payload>", so that the receivers can reject replayed requests.
+func signWebhookPayload(secret, timestamp, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + payload))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// signWebhook sets the signature of a webhook payload sent now, and its
+// timestamp, on the request headers.
+func signWebhook(h http.Header, secret, payload string) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	h.Set(header.XTykWebhookTimestamp, timestamp)
+	h.Set(header.XTykWebhookSignature, signWebhookPayload(secret, timestamp, payload))
+}
+
+// sendWebhook sends a webhook request. It fails if the receiver doesn't
+// acknowledge it with a 2xx status code.
+func (gw *Gateway) sendWebhook(req *http.Request) error {
+	// Create HTTP client using factory for webhook service
+	clientFactory := NewExternalHTTPClientFactory(gw)
+	cli, err := clientFactory.CreateWebhookClient()
+	if err != nil {
+		// Check if mTLS is explicitly enabled and error is certificate-related - if so, don't fallback as it would bypass security
+		if gw.GetConfig().ExternalServices.Webhooks.MTLS.Enabled && httpclient.IsMTLSError(err) {
+			log.WithError(err).Error("mTLS configuration failed for webhooks. Webhook delivery will be skipped to maintain security.")
+			return fmt.Errorf("%w: %v", errWebhookMTLS, err)
+		}
+
+		// For other errors (not configured, proxy config), fallback to default client
+		log.WithError(err).Debug("Failed to create webhook HTTP client, falling back to default")
+		log.Debug("[ExternalServices] Falling back to legacy webhook client due to factory error")
+		cli = &http.Client{Timeout: 30 * time.Second}
+	} else {
+		log.Debugf("[ExternalServices] Using external services webhook client for URL: %s", req.URL.String())
+	}
+
+	resp, err := cli.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	content, err := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response code %d", resp.StatusCode)
+	}
+
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"prefix": "webhooks",
+		}).Error(err)
+		return nil
+	}
+
+	log.WithFields(logrus.Fields{
+		"prefix":       "webhooks",
+		"responseCode": resp.StatusCode,
+	}).Debug(string(content))
+
+	return nil
+}
+
+// webhookQueue persists the failed webhook deliveries, retrying them with an
+// exponential backoff until they are delivered or dead-lettered. The queue is
+// a sorted set of the delivery IDs scored by the time of their next attempt,
+// shared by the gateways using the same storage. A retry is claimed by one
+// gateway at a time, and is only removed from the queue once it's delivered or
+// dead-lettered, so it's retried by another gateway if the one sending it
+// stops.
+type webhookQueue struct {
+	gw    *Gateway
+	store storage.Handler
+}
+
+func newWebhookQueue(gw *Gateway) *webhookQueue {
+	store := gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{KeyPrefix: webhookDeliveryPrefix})
+	store.Connect()
+
+	return &webhookQueue{gw: gw, store: store}
+}
+
+func (q *webhookQueue) logger() *logrus.Entry {
+	return log.WithField("prefix", "webhooks")
+}
+
+func (q *webhookQueue) save(d *webhookDelivery, ttl int64) error {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	return q.store.SetKey(d.ID, string(data), ttl)
+}
+
+func (q *webhookQueue) get(id string) (*webhookDelivery, error) {
+	data, err := q.store.GetKey(id)
+	if err != nil {
+		return nil, ErrWebhookDeliveryNotFound
+	}
+
+	var d webhookDelivery
+	if err := json.Unmarshal([]byte(data), &d); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// fail records a failed attempt of the delivery, scheduling its retry or
+// dead-lettering it once the retries are exhausted.
+func (q *webhookQueue) fail(d *webhookDelivery, reason error) {
+	d.Attempts++
+	d.LastError = reason.Error()
+
+	logger := q.logger().WithFields(logrus.Fields{
+		"delivery": d.ID,
+		"target":   d.URL,
+		"attempts": d.Attempts,
+	})
+
+	if d.Attempts > d.MaxRetries {
+		d.NextAttempt = 0
+		if err := q.save(d, int64(webhookDeadLetterTTL.Seconds())); err != nil {
+			logger.WithError(err).Error("Could not dead-letter webhook delivery")
+			return
+		}
+		q.store.AppendToSet(webhookDeadLettersKey, d.ID)
+
+		q.dequeue(d.ID)
+
+		logger.WithError(reason).Error("Webhook delivery failed, dead-lettered")
+		return
+	}
+
+	backoff := d.backoff()
+	d.NextAttempt = time.Now().Add(backoff).Unix()
+	if err := q.enqueue(d); err != nil {
+		logger.WithError(err).Error("Could not queue webhook delivery")
+		return
+	}
+
+	logger.WithError(reason).Warningf("Webhook delivery failed, retrying in %s", backoff)
+}
+
+// enqueue saves the delivery and schedules its next attempt. The delivery
+// expires if it isn't retried within webhookQueuedTTL of its retry being due.
+func (q *webhookQueue) enqueue(d *webhookDelivery) error {
+	ttl := time.Until(time.Unix(d.NextAttempt, 0)) + webhookQueuedTTL
+	if err := q.save(d, int64(ttl.Seconds())); err != nil {
+		return err
+	}
+
+	q.store.AddToSortedSet(webhookQueueKey, d.ID, float64(d.NextAttempt))
+	return nil
+}
+
+// dequeue removes the delivery from the queue.
+func (q *webhookQueue) dequeue(id string) {
+	if remover, ok := q.store.(storage.RemoveFromSortedSetHandler); ok {
+		_ = remover.RemoveFromSortedSet(webhookQueueKey, id) //nolint:errcheck // a stale entry is removed on its next retry
+	}
+}
+
+// send attempts the delivery, it's removed from the queue once delivered.
+func (q *webhookQueue) send(d *webhookDelivery) {
+	req, err := http.NewRequest(d.Method, d.URL, strings.NewReader(d.Body))
+	if err != nil {
+		q.fail(d, err)
+		return
+	}
+	req.Header = d.Header.Clone()
+	if d.SigningSecret != "" {
+		signWebhook(req.Header, d.SigningSecret, d.Body)
+	}
+
+	if err := q.gw.sendWebhook(req); err != nil {
+		q.fail(d, err)
+		return
+	}
+
+	q.store.DeleteKey(d.ID)
+	q.dequeue(d.ID)
+	q.logger().WithField("delivery", d.ID).Debug("Webhook delivered")
+}
+
+// retryDue sends the queued deliveries whose retry is due, up to
+// webhookRetryConcurrency at a time. It returns once they're sent.
+func (q *webhookQueue) retryDue() {
+	locker, ok := q.store.(storage.LockHandler)
+	if !ok {
+		return
+	}
+
+	now := time.Now().Unix()
+	ids, _, err := q.store.GetSortedSetRange(webhookQueueKey, "-inf", strconv.FormatInt(now, 10))
+	if err != nil {
+		return
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	sending := make(chan struct{}, webhookRetryConcurrency)
+	for _, id := range ids {
+		d, err := q.get(id)
+		if err != nil {
+			// The delivery expired or was discarded
+			q.dequeue(id)
+			continue
+		}
+
+		// Another gateway failed the attempt since the queue was read
+		if d.NextAttempt > now {
+			continue
+		}
+
+		claimKey := fmt.Sprintf("%sclaim.%s.%d", webhookDeliveryPrefix, d.ID, d.Attempts)
+		if claimed, err := locker.Lock(claimKey, webhookClaimTimeout); err != nil || !claimed {
+			continue
+		}
+
+		// The delivery stays queued while it's sent, it's due again once the
+		// claim expires if this gateway stops before it's sent.
+		q.store.AddToSortedSet(webhookQueueKey, d.ID, float64(time.Now().Add(webhookClaimTimeout).Unix()))
+
+		sending <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sending }()
+
+			q.send(d)
+		}()
+	}
+}
+
+// startWebhookRetries starts retrying the queued webhook deliveries, once a
+// webhook handler retries its failed deliveries or a dead letter is replayed.
+func (gw *Gateway) startWebhookRetries() {
+	gw.webhookRetries.Do(func() {
+		go newWebhookQueue(gw).retryLoop(gw.ctx)
+	})
+}
+
+// retryLoop retries the queued deliveries until the context is done.
+func (q *webhookQueue) retryLoop(ctx context.Context) {
+	ticker := time.NewTicker(webhookRetryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			q.retryDue()
+		}
+	}
+}
+
+// deadLetters returns the dead-lettered deliveries, oldest first.
+func (q *webhookQueue) deadLetters() ([]*webhookDelivery, error) {
+	ids, err := q.store.GetListRange(webhookDeadLettersKey, 0, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]*webhookDelivery, 0, len(ids))
+	for _, id := range ids {
+		d, err := q.get(id)
+		if err != nil {
+			// Expired
+			q.store.RemoveFromList(webhookDeadLettersKey, id)
+			continue
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, nil
+}
+
+// getDeadLetter returns a dead-lettered delivery.
+func (q *webhookQueue) getDeadLetter(id string) (*webhookDelivery, error) {
+	d, err := q.get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.NextAttempt != 0 {
+		return nil, ErrWebhookDeliveryNotFound
+	}
+	return d, nil
+}
+
+// replay queues a dead-lettered delivery to be sent again, with its retries reset.
+func (q *webhookQueue) replay(id string) error {
+	d, err := q.getDeadLetter(id)
+	if err != nil {
+		return err
+	}
+
+	if err := q.store.RemoveFromList(webhookDeadLettersKey, id); err != nil {
+		return err
+	}
+
+	d.Attempts = 0
+	d.NextAttempt = time.Now().Unix()
+	return q.enqueue(d)
+}
+
+// discard deletes a dead-lettered delivery.
+func (q *webhookQueue) discard(id string) error {
+	if _, err := q.getDeadLetter(id); err != nil {
+		return err
+	}
+
+	if err := q.store.RemoveFromList(webhookDeadLettersKey, id); err != nil {
+		return err
+	}
+	q.store.DeleteKey(id)
+
+	return nil
+}
+
+// newWebhookDelivery creates the delivery of a webhook request, to be retried.
+func (w *WebHookHandler) newWebhookDelivery(eventType string, req *http.Request, reqBody string) *webhookDelivery {
+	return &webhookDelivery{
+		ID:            uuid.New(),
+		WebhookID:     w.conf.ID,
+		WebhookName:   w.conf.Name,
+		Event:         eventType,
+		Method:        req.Method,
+		URL:           req.URL.String(),
+		Header:        req.Header.Clone(),
+		Body:          reqBody,
+		SigningSecret: w.conf.SigningSecret,
+		MaxRetries:    w.conf.MaxRetries,
+		RetryBackoff:  w.conf.RetryBackoff,
+		CreatedAt:     time.Now(),
+	}
+}
+
+// redacted returns a copy of the delivery without its signing secret and
+// header values, to be listed.
+func (d *webhookDelivery) redacted() *webhookDelivery {
+	redacted := *d
+	redacted.SigningSecret = ""
+	redacted.Header = make(http.Header, len(d.Header))
+	for name := range d.Header {
+		redacted.Header.Set(name, webhookRedactedHeader)
+	}
+	return &redacted
+}
+
+// webhookDeadLettersHandler lists the dead-lettered webhook deliveries. The
+// header values are redacted, as they may hold credentials of the receivers.
+func (gw *Gateway) webhookDeadLettersHandler(w http.ResponseWriter, _ *http.Request) {
+	deliveries, err := newWebhookQueue(gw).deadLetters()
+	if err != nil {
+		doJSONWrite(w, http.StatusInternalServerError, apiError("Could not list dead-lettered webhooks"))
+		return
+	}
+
+	for i, d := range deliveries {
+		deliveries[i] = d.redacted()
+	}
+
+	doJSONWrite(w, http.StatusOK, deliveries)
+}
+
+// webhookDeadLetterHandler replays a dead-lettered webhook delivery, or discards it on DELETE.
+func (gw *Gateway) webhookDeadLetterHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["deliveryID"]
+	queue := newWebhookQueue(gw)
+
+	var err error
+	message := "webhook delivery queued"
+	if r.Method == http.MethodDelete {
+		err = queue.discard(id)
+		message = "webhook delivery discarded"
+	} else if err = queue.replay(id); err == nil {
+		gw.startWebhookRetries()
+	}
+
+	switch {
+	case errors.Is(err, ErrWebhookDeliveryNotFound):
+		doJSONWrite(w, http.StatusNotFound, apiError("Webhook delivery not found"))
+	case err != nil:
+		log.WithError(err).WithField("delivery", id).Error("Could not update dead-lettered webhook")
+		doJSONWrite(w, http.StatusInternalServerError, apiError("Could not update dead-lettered webhook"))
+	default:
+		doJSONWrite(w, http.StatusOK, apiOk(message))
+	}
+}
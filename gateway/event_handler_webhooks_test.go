@@ -2,9 +2,14 @@ package gateway
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -12,10 +17,13 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/config"
 	"github.com/TykTechnologies/tyk/header"
 	"github.com/TykTechnologies/tyk/internal/certcheck"
 	"github.com/TykTechnologies/tyk/internal/model"
+	"github.com/TykTechnologies/tyk/internal/uuid"
+	"github.com/TykTechnologies/tyk/test"
 )
 
 func (ts *Test) createWebHookHandler(t *testing.T) *WebHookHandler {
@@ -569,3 +577,127 @@ func TestWebhookTemplateFuncs(t *testing.T) {
 		})
 	})
 }
+
+func TestWebhookDelivery_backoff(t *testing.T) {
+	d := &webhookDelivery{RetryBackoff: 10}
+	for attempts, backoff := range []time.Duration{10 * time.Second, 10 * time.Second, 20 * time.Second, 40 * time.Second} {
+		d.Attempts = attempts
+		assert.Equal(t, backoff, d.backoff())
+	}
+
+	d.Attempts = 100
+	assert.Equal(t, maxWebhookRetryBackoff, d.backoff())
+
+	assert.Equal(t, defaultWebhookRetryBackoff, (&webhookDelivery{Attempts: 1}).backoff())
+}
+
+func TestWebhookQueue(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	delivered := make(chan struct{}, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		delivered <- struct{}{}
+	}))
+	defer receiver.Close()
+
+	queue := newWebhookQueue(ts.Gw)
+	queued := func(id string) bool {
+		ids, _, err := queue.store.GetSortedSetRange(webhookQueueKey, "-inf", "+inf")
+		require.NoError(t, err)
+		return slices.Contains(ids, id)
+	}
+
+	d := &webhookDelivery{ID: uuid.New(), Method: http.MethodPost, URL: receiver.URL, MaxRetries: 1, RetryBackoff: 1}
+	queue.fail(d, errors.New("unavailable"))
+	assert.True(t, queued(d.ID))
+
+	ttl, err := queue.store.GetExp(d.ID)
+	require.NoError(t, err)
+	assert.Greater(t, ttl, int64(0), "the queued deliveries expire")
+
+	require.Eventually(t, func() bool {
+		queue.retryDue()
+		return len(delivered) > 0
+	}, 5*time.Second, 100*time.Millisecond)
+
+	assert.False(t, queued(d.ID))
+	_, err = queue.get(d.ID)
+	assert.ErrorIs(t, err, ErrWebhookDeliveryNotFound)
+}
+
+func TestWebhookRetries(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	var failing atomic.Bool
+	failing.Store(true)
+	received := make(chan string, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(header.XTykWebhookSignature) != signWebhookPayload("secret", r.Header.Get(header.XTykWebhookTimestamp), string(body)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		received <- string(body)
+	}))
+	defer receiver.Close()
+
+	name := "retried-" + uuid.New()
+	handler := &WebHookHandler{Gw: ts.Gw}
+	require.NoError(t, handler.Init(apidef.WebHookHandlerConf{
+		Name:          name,
+		TargetPath:    receiver.URL,
+		Method:        http.MethodPost,
+		MaxRetries:    1,
+		RetryBackoff:  1,
+		SigningSecret: "secret",
+	}))
+
+	handler.HandleEvent(config.EventMessage{
+		Type: EventKeyExpired,
+		Meta: EventKeyFailureMeta{EventMetaDefault: EventMetaDefault{Message: "THIS IS A TEST"}},
+	})
+
+	queue := newWebhookQueue(ts.Gw)
+	var delivery *webhookDelivery
+	require.Eventually(t, func() bool {
+		queue.retryDue()
+
+		deadLetters, err := queue.deadLetters()
+		require.NoError(t, err)
+		for _, d := range deadLetters {
+			if d.WebhookName == name {
+				delivery = d
+				return true
+			}
+		}
+		return false
+	}, 10*time.Second, 100*time.Millisecond, "the webhook was not dead-lettered")
+
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.Equal(t, "unexpected response code 503", delivery.LastError)
+
+	failing.Store(false)
+	_, _ = ts.Run(t, []test.TestCase{
+		{
+			Method: http.MethodGet, Path: "/tyk/webhooks/dead-letters", AdminAuth: true, Code: http.StatusOK,
+			BodyMatch: webhookRedactedHeader, BodyNotMatch: "sha256=",
+		},
+		{Method: http.MethodPost, Path: "/tyk/webhooks/dead-letters/" + delivery.ID + "/replay", AdminAuth: true, Code: http.StatusOK},
+		{Method: http.MethodDelete, Path: "/tyk/webhooks/dead-letters/" + delivery.ID, AdminAuth: true, Code: http.StatusNotFound},
+	}...)
+
+	queue.retryDue()
+	select {
+	case body := <-received:
+		assert.Equal(t, delivery.Body, body)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the replayed webhook was not delivered")
+	}
+}
@@ -0,0 +1,275 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/TykTechnologies/tyk/internal/event"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+// Changes reported by the lifecycle events.
+const (
+	lifecycleAdded   = "added"
+	lifecycleUpdated = "updated"
+	lifecycleRemoved = "removed"
+)
+
+// EventAPIMeta is the metadata of the APILoaded, APILoadFailed and APIRemoved events.
+type EventAPIMeta struct {
+	EventMetaDefault
+	APIID      string
+	OrgID      string
+	Name       string
+	ListenPath string
+	// Change is added or updated for APILoaded events.
+	Change string
+	// ChangedFields are the top-level fields of the API definition which changed on update.
+	ChangedFields []string
+	// Checksum is the checksum of the API definition, PreviousChecksum the one it replaces.
+	Checksum         string
+	PreviousChecksum string
+	// Error is the reason of an APILoadFailed event.
+	Error string
+}
+
+func (e EventAPIMeta) LogMessage(prefix string) string {
+	return fmt.Sprintf("%s:%s:%s: %s", prefix, e.APIID, e.Name, e.Message)
+}
+
+// EventPolicyMeta is the metadata of the PolicyUpdated event.
+type EventPolicyMeta struct {
+	EventMetaDefault
+	PolicyID string
+	OrgID    string
+	Name     string
+	// Change is added, updated or removed.
+	Change string
+	// ChangedFields are the top-level fields of the policy which changed on update.
+	ChangedFields []string
+}
+
+func (e EventPolicyMeta) LogMessage(prefix string) string {
+	return fmt.Sprintf("%s:%s:%s: %s", prefix, e.PolicyID, e.Name, e.Message)
+}
+
+// EventReloadMeta is the metadata of the ReloadCompleted event, listing the
+// IDs of the APIs and policies the reload changed.
+type EventReloadMeta struct {
+	EventMetaDefault
+	APIs            int
+	Policies        int
+	APIsAdded       []string
+	APIsUpdated     []string
+	APIsRemoved     []string
+	APIsFailed      []string
+	PoliciesAdded   []string
+	PoliciesUpdated []string
+	PoliciesRemoved []string
+	Duration        time.Duration
+}
+
+func (e EventReloadMeta) LogMessage(prefix string) string {
+	return fmt.Sprintf("%s: %d APIs (%d added, %d updated, %d removed, %d failed), %d policies (%d added, %d updated, %d removed) in %s",
+		prefix, e.APIs, len(e.APIsAdded), len(e.APIsUpdated), len(e.APIsRemoved), len(e.APIsFailed),
+		e.Policies, len(e.PoliciesAdded), len(e.PoliciesUpdated), len(e.PoliciesRemoved), e.Duration)
+}
+
+// apiLoadFailure is an API which failed validation or failed to load.
+type apiLoadFailure struct {
+	spec *APISpec
+	err  error
+}
+
+// reloadChanges snapshots the loaded APIs and policies when a reload starts,
+// to fire the lifecycle events of the changes once it completes.
+type reloadChanges struct {
+	gw       *Gateway
+	start    time.Time
+	apis     map[string]*APISpec
+	policies map[string]user.Policy
+}
+
+func (gw *Gateway) newReloadChanges() *reloadChanges {
+	gw.apisMu.RLock()
+	apis := maps.Clone(gw.apisByID)
+	gw.apisMu.RUnlock()
+
+	return &reloadChanges{
+		gw:       gw,
+		start:    time.Now(),
+		apis:     apis,
+		policies: policiesByID(gw.policies.AsSlice()),
+	}
+}
+
+// fire fires the lifecycle events of the changes since the snapshot, failed
+// are the APIs which couldn't be loaded.
+func (c *reloadChanges) fire(failed []apiLoadFailure) {
+	summary := EventReloadMeta{
+		EventMetaDefault: EventMetaDefault{Message: "Reload completed"},
+	}
+
+	c.firePolicyChanges(&summary)
+	c.fireAPIChanges(&summary, failed)
+
+	summary.Duration = time.Since(c.start)
+	c.gw.FireSystemEvent(event.ReloadCompleted, summary)
+}
+
+func (c *reloadChanges) firePolicyChanges(summary *EventReloadMeta) {
+	policies := policiesByID(c.gw.policies.AsSlice())
+	summary.Policies = len(policies)
+
+	for _, id := range slices.Sorted(maps.Keys(policies)) {
+		pol := policies[id]
+		meta := EventPolicyMeta{PolicyID: id, OrgID: pol.OrgID, Name: pol.Name}
+
+		prev, ok := c.policies[id]
+		switch {
+		case !ok:
+			meta.Change = lifecycleAdded
+			summary.PoliciesAdded = append(summary.PoliciesAdded, id)
+		default:
+			meta.ChangedFields = changedFields(prev, pol)
+			if len(meta.ChangedFields) == 0 {
+				continue
+			}
+			meta.Change = lifecycleUpdated
+			summary.PoliciesUpdated = append(summary.PoliciesUpdated, id)
+		}
+
+		meta.Message = "Policy " + meta.Change
+		c.gw.FireSystemEvent(event.PolicyUpdated, meta)
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(c.policies)) {
+		if _, ok := policies[id]; ok {
+			continue
+		}
+
+		pol := c.policies[id]
+		summary.PoliciesRemoved = append(summary.PoliciesRemoved, id)
+		c.gw.FireSystemEvent(event.PolicyUpdated, EventPolicyMeta{
+			EventMetaDefault: EventMetaDefault{Message: "Policy " + lifecycleRemoved},
+			PolicyID:         id,
+			OrgID:            pol.OrgID,
+			Name:             pol.Name,
+			Change:           lifecycleRemoved,
+		})
+	}
+}
+
+func (c *reloadChanges) fireAPIChanges(summary *EventReloadMeta, failed []apiLoadFailure) {
+	failedIDs := make(map[string]bool, len(failed))
+	for _, f := range failed {
+		failedIDs[f.spec.APIID] = true
+		summary.APIsFailed = append(summary.APIsFailed, f.spec.APIID)
+
+		meta := newEventAPIMeta(f.spec, "API failed to load")
+		meta.Error = f.err.Error()
+		c.gw.FireSystemEvent(event.APILoadFailed, meta)
+	}
+
+	c.gw.apisMu.RLock()
+	apis := maps.Clone(c.gw.apisByID)
+	c.gw.apisMu.RUnlock()
+	summary.APIs = len(apis)
+
+	for _, id := range slices.Sorted(maps.Keys(apis)) {
+		spec := apis[id]
+		if failedIDs[id] || spec.IsSyntheticMCPAdapter() {
+			continue
+		}
+
+		meta := newEventAPIMeta(spec, "")
+		prev, ok := c.apis[id]
+		switch {
+		case !ok:
+			meta.Change = lifecycleAdded
+			summary.APIsAdded = append(summary.APIsAdded, id)
+		case prev.Checksum != spec.Checksum:
+			meta.Change = lifecycleUpdated
+			meta.PreviousChecksum = prev.Checksum
+			meta.ChangedFields = changedFields(prev.APIDefinition, spec.APIDefinition)
+			summary.APIsUpdated = append(summary.APIsUpdated, id)
+		default:
+			continue
+		}
+
+		meta.Message = "API " + meta.Change
+		c.gw.FireSystemEvent(event.APILoaded, meta)
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(c.apis)) {
+		spec := c.apis[id]
+		if _, ok := apis[id]; ok || spec.IsSyntheticMCPAdapter() {
+			continue
+		}
+
+		summary.APIsRemoved = append(summary.APIsRemoved, id)
+		c.gw.FireSystemEvent(event.APIRemoved, newEventAPIMeta(spec, "API removed"))
+	}
+}
+
+func newEventAPIMeta(spec *APISpec, message string) EventAPIMeta {
+	return EventAPIMeta{
+		EventMetaDefault: EventMetaDefault{Message: message},
+		APIID:            spec.APIID,
+		OrgID:            spec.OrgID,
+		Name:             spec.Name,
+		ListenPath:       spec.Proxy.ListenPath,
+		Checksum:         spec.Checksum,
+	}
+}
+
+// policiesByID indexes the policies by ID, or by database ID for the policies without one.
+func policiesByID(policies []user.Policy) map[string]user.Policy {
+	byID := make(map[string]user.Policy, len(policies))
+	for _, pol := range policies {
+		id := pol.ID
+		if id == "" {
+			id = pol.MID.Hex()
+		}
+		byID[id] = pol
+	}
+	return byID
+}
+
+// changedFields returns the sorted top-level JSON fields which differ between prev and next.
+func changedFields(prev, next any) []string {
+	var prevFields, nextFields map[string]json.RawMessage
+	if err := remarshal(prev, &prevFields); err != nil {
+		return nil
+	}
+	if err := remarshal(next, &nextFields); err != nil {
+		return nil
+	}
+
+	var changed []string
+	for name, value := range nextFields {
+		if !bytes.Equal(prevFields[name], value) {
+			changed = append(changed, name)
+		}
+	}
+	for name := range prevFields {
+		if _, ok := nextFields[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+
+	slices.Sort(changed)
+	return changed
+}
+
+func remarshal(in any, out any) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,102 @@
+package gateway
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/internal/event"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+func TestChangedFields(t *testing.T) {
+	prev := user.Policy{ID: "pol1", Name: "Gold", Rate: 10}
+	next := user.Policy{ID: "pol1", Name: "Gold", Rate: 20, QuotaMax: 100}
+
+	assert.Equal(t, []string{"quota_max", "rate"}, changedFields(prev, next))
+	assert.Empty(t, changedFields(prev, prev))
+}
+
+func TestReloadLifecycleEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.log")
+
+	ts := StartTest(func(globalConf *config.Config) {
+		handler := apidef.EventHandlerTriggerConfig{
+			Handler:     event.CloudEventsHandler,
+			HandlerMeta: map[string]any{"sink": "file", "path": path},
+		}
+
+		globalConf.EventHandlers.Events = map[apidef.TykEvent][]apidef.EventHandlerTriggerConfig{
+			event.APILoaded:       {handler},
+			event.APIRemoved:      {handler},
+			event.ReloadCompleted: {handler},
+		}
+	})
+	t.Cleanup(ts.Close)
+
+	// events returns the data of the events of the type received so far
+	events := func(eventType event.Event) []map[string]any {
+		content, _ := os.ReadFile(path)
+
+		var found []map[string]any
+		for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+			var ce struct {
+				Type string         `json:"type"`
+				Data map[string]any `json:"data"`
+			}
+			if json.Unmarshal([]byte(line), &ce) == nil && ce.Type == cloudEventsTypePrefix+string(eventType) {
+				found = append(found, ce.Data)
+			}
+		}
+		return found
+	}
+
+	waitFor := func(eventType event.Event, count int) []map[string]any {
+		t.Helper()
+
+		assert.Eventually(t, func() bool {
+			return len(events(eventType)) >= count
+		}, 5*time.Second, 10*time.Millisecond)
+
+		return events(eventType)
+	}
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "lifecycle-api"
+		spec.Name = "lifecycle"
+		spec.Proxy.ListenPath = "/lifecycle/"
+	})
+
+	loaded := waitFor(event.APILoaded, 1)
+	assert.Equal(t, "lifecycle-api", loaded[0]["APIID"])
+	assert.Equal(t, lifecycleAdded, loaded[0]["Change"])
+
+	reloads := waitFor(event.ReloadCompleted, 1)
+	assert.Contains(t, reloads[len(reloads)-1]["APIsAdded"], "lifecycle-api")
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "lifecycle-api"
+		spec.Name = "lifecycle updated"
+		spec.Proxy.ListenPath = "/lifecycle/"
+	})
+
+	loaded = waitFor(event.APILoaded, 2)
+	assert.Equal(t, lifecycleUpdated, loaded[1]["Change"])
+	assert.Contains(t, loaded[1]["ChangedFields"], "name")
+	assert.NotEqual(t, loaded[1]["Checksum"], loaded[1]["PreviousChecksum"])
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "other-api"
+		spec.Proxy.ListenPath = "/other/"
+	})
+
+	removed := waitFor(event.APIRemoved, 1)
+	assert.Equal(t, "lifecycle-api", removed[0]["APIID"])
+}
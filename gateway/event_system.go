@@ -118,9 +118,18 @@ type EventTokenMeta struct {
 	Key string
 }
 
-// EventHandlerByName is a convenience function to get event handler instances from an API Definition
+// EventHandlerByName is a convenience function to get event handler instances from an API Definition.
+// The handler is wrapped with the filter and the aggregation of the trigger when configured.
 func (gw *Gateway) EventHandlerByName(handlerConf apidef.EventHandlerTriggerConfig, spec *APISpec) (config.TykEventHandler, error) {
+	h, err := gw.eventHandlerByName(handlerConf, spec)
+	if err != nil {
+		return h, err
+	}
+
+	return newFilteredEventHandler(h, handlerConf.Filter, handlerConf.Aggregate, spec), nil
+}
 
+func (gw *Gateway) eventHandlerByName(handlerConf apidef.EventHandlerTriggerConfig, spec *APISpec) (config.TykEventHandler, error) {
 	conf := handlerConf.HandlerMeta
 	switch handlerConf.Handler {
 	case EH_LogHandler:
@@ -141,6 +150,10 @@ func (gw *Gateway) EventHandlerByName(handlerConf apidef.EventHandlerTriggerConf
 			}
 			return h, err
 		}
+	case event.CloudEventsHandler:
+		h := &CloudEventsHandler{spec: spec, Gw: gw}
+		err := h.Init(conf)
+		return h, err
 	case EH_CoProcessHandler:
 		if spec != nil {
 			dispatcher := loadedDrivers[spec.CustomMiddleware.Driver]
@@ -89,6 +89,29 @@ type ErrorHandler struct {
 	*BaseMiddleware
 }
 
+// serveStaleIfError writes the stale cache entry of the request, if it's within
+// its stale-if-error window, in place of the error.
+func (e *ErrorHandler) serveStaleIfError(w http.ResponseWriter, r *http.Request) bool {
+	options := ctxGetCacheOptions(r)
+	if options == nil || options.stale == "" {
+		return false
+	}
+
+	start := ctxGetRequestStartTime(r)
+	if start.IsZero() {
+		start = time.Now()
+	}
+
+	cache := &RedisCacheMiddleware{BaseMiddleware: e.BaseMiddleware}
+	cache.Init()
+	if err := cache.writeCachedResponse(w, r, options.stale, start, revalidationFailedWarning); err != nil {
+		e.Logger().WithError(err).Error("Could not serve stale cache entry")
+		return false
+	}
+
+	return true
+}
+
 // TemplateExecutor is an interface used to switch between text/templates and html/template.
 // It only switch to text/template (templatesRaw) when contentType is XML related
 type TemplateExecutor interface {
@@ -98,6 +121,11 @@ type TemplateExecutor interface {
 // HandleError is the actual error handler and will store the error details in analytics if analytics processing is enabled.
 func (e *ErrorHandler) HandleError(w http.ResponseWriter, r *http.Request, errMsg string, errCode int, writeResponse bool) {
 	defer e.Base().UpdateRequestSession(r)
+
+	if writeResponse && errCode >= http.StatusInternalServerError && e.serveStaleIfError(w, r) {
+		return
+	}
+
 	response := &http.Response{}
 
 	if writeResponse {
@@ -164,6 +192,9 @@ func (e *ErrorHandler) HandleError(w http.ResponseWriter, r *http.Request, errMs
 		if len(e.Spec.Tags) > 0 {
 			tags = append(tags, e.Spec.Tags...)
 		}
+
+		tags = addRetryTag(r, tags)
+
 		trackEP := false
 		trackedPath := r.URL.Path
 
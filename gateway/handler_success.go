@@ -176,7 +176,19 @@ func recordMCPDetails(rec *analytics.AnalyticsRecord, r *http.Request) {
 	}
 }
 
-const traceTagPrefix = "trace-id-"
+const (
+	traceTagPrefix = "trace-id-"
+	retryTagPrefix = "retries-"
+)
+
+// addRetryTag tags the analytics record with the number of upstream retries
+// the retry policy made for the request.
+func addRetryTag(r *http.Request, tags []string) []string {
+	if count := ctxGetRetryCount(r); count > 0 {
+		tags = append(tags, retryTagPrefix+strconv.Itoa(count))
+	}
+	return tags
+}
 
 func (s *SuccessHandler) addTraceIDTag(reqCtx context.Context, tags []string) []string {
 	if !s.Gw.GetConfig().OpenTelemetry.TracesEnabled() {
@@ -231,6 +243,12 @@ func (s *SuccessHandler) RecordHit(r *http.Request, timing analytics.Latency, co
 			tags = append(tags, "cached-response")
 		}
 
+		tags = addRetryTag(r, tags)
+
+		if ctxGetHedgedResponse(r) {
+			tags = append(tags, "hedged-response")
+		}
+
 		tags = s.addTraceIDTag(r.Context(), tags)
 
 		rawRequest := ""
@@ -85,7 +85,7 @@ func (gw *Gateway) gatherHealthChecks() {
 
 	expected := map[string]string{"redis": Datastore}
 
-	redisStore := storage.RedisCluster{KeyPrefix: "livenesscheck-", ConnectionHandler: gw.StorageConnectionHandler}
+	redisStore := gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{KeyPrefix: "livenesscheck-"})
 	redisStore.Connect()
 
 	key := "tyk-liveness-probe"
@@ -151,7 +151,7 @@ func TestHostChecker(t *testing.T) {
 	for i := 0; i < 10; i++ {
 		targetWG.Add(1)
 		go func() {
-			host, err := ts.Gw.nextTarget(spec.Proxy.StructuredTargetList, spec)
+			host, err := ts.Gw.nextTarget(spec.Proxy.StructuredTargetList, spec, nil)
 			if err != nil {
 				t.Error("Should return nil error, got", err)
 			}
@@ -29,7 +29,7 @@ type JSVMAPIHelper struct {
 	Gw     *Gateway
 	Log    *logrus.Entry
 	RawLog *logrus.Logger
-	Store  *storage.RedisCluster
+	Store  storage.Handler
 }
 
 // JSVM storage binding limits. All keys live under jsvmStoreKeyPrefix so
@@ -207,34 +207,50 @@ func (h *JSVMAPIHelper) SetKeyData(apiKey, encodedSession, suppressReset string)
 	return nil
 }
 
-// storeClient returns the raw redis client for the JSVM store, and validates
-// and prefixes the key. The raw client is used (rather than RedisCluster's
-// methods) so every op gets a hard timeout via context and the prefix cannot
-// be bypassed by key hashing (fixKey hashes before prefixing when HashKeys
-// is on, which would break namespacing guarantees).
-func (h *JSVMAPIHelper) storeClient(key string) (redis.UniversalClient, string, error) {
+// storeClient returns the raw redis client for the JSVM store, or the
+// embedded database with the embedded storage, and validates and prefixes
+// the key. The raw client is used (rather than RedisCluster's methods) so
+// every op gets a hard timeout via context and the prefix cannot be bypassed
+// by key hashing (fixKey hashes before prefixing when HashKeys is on, which
+// would break namespacing guarantees).
+func (h *JSVMAPIHelper) storeClient(key string) (redis.UniversalClient, *storage.EmbeddedDB, string, error) {
 	if h.Store == nil {
-		return nil, "", errJSVMStoreUnavailable
+		return nil, nil, "", errJSVMStoreUnavailable
 	}
 	if key == "" || len(key) > jsvmStoreMaxKeyLen {
-		return nil, "", fmt.Errorf("storage key must be 1-%d bytes", jsvmStoreMaxKeyLen)
+		return nil, nil, "", fmt.Errorf("storage key must be 1-%d bytes", jsvmStoreMaxKeyLen)
 	}
-	client, err := h.Store.Client()
-	if err != nil {
-		h.Log.WithError(err).Error("JSVM storage: failed to get redis client")
-		return nil, "", err
+
+	switch store := h.Store.(type) {
+	case *storage.EmbeddedStorage:
+		return nil, store.DB, jsvmStoreKeyPrefix + key, nil
+	case *storage.RedisCluster:
+		client, err := store.Client()
+		if err != nil {
+			h.Log.WithError(err).Error("JSVM storage: failed to get redis client")
+			return nil, nil, "", err
+		}
+		return client, nil, jsvmStoreKeyPrefix + key, nil
 	}
-	return client, jsvmStoreKeyPrefix + key, nil
+
+	return nil, nil, "", errJSVMStoreUnavailable
 }
 
 // StorageGet retrieves a key from the JSVM store. A missing key returns
 // found=false with no error; err is only non-nil on storage failure so
 // callers can distinguish "absent" from "Redis down".
 func (h *JSVMAPIHelper) StorageGet(key string) (value string, found bool, err error) {
-	client, fixedKey, err := h.storeClient(key)
+	client, db, fixedKey, err := h.storeClient(key)
 	if err != nil {
 		return "", false, err
 	}
+	if db != nil {
+		value, err = db.Get(fixedKey)
+		if errors.Is(err, storage.ErrKeyNotFound) {
+			return "", false, nil
+		}
+		return value, err == nil, err
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), jsvmStoreOpTimeout)
 	defer cancel()
 
@@ -251,13 +267,17 @@ func (h *JSVMAPIHelper) StorageGet(key string) (value string, found bool, err er
 
 // StorageSet stores a value in the JSVM store. ttlSeconds 0 means no expiry.
 func (h *JSVMAPIHelper) StorageSet(key, value string, ttlSeconds int64) error {
-	client, fixedKey, err := h.storeClient(key)
+	client, db, fixedKey, err := h.storeClient(key)
 	if err != nil {
 		return err
 	}
 	if len(value) > jsvmStoreMaxValueLen {
 		return fmt.Errorf("storage value exceeds %d bytes", jsvmStoreMaxValueLen)
 	}
+	if db != nil {
+		db.Set(fixedKey, value, time.Duration(ttlSeconds)*time.Second)
+		return nil
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), jsvmStoreOpTimeout)
 	defer cancel()
 
@@ -271,13 +291,16 @@ func (h *JSVMAPIHelper) StorageSet(key, value string, ttlSeconds int64) error {
 // StorageSetNX stores a value only if the key does not exist (SET NX EX).
 // Returns true if this call claimed the key.
 func (h *JSVMAPIHelper) StorageSetNX(key, value string, ttlSeconds int64) (bool, error) {
-	client, fixedKey, err := h.storeClient(key)
+	client, db, fixedKey, err := h.storeClient(key)
 	if err != nil {
 		return false, err
 	}
 	if len(value) > jsvmStoreMaxValueLen {
 		return false, fmt.Errorf("storage value exceeds %d bytes", jsvmStoreMaxValueLen)
 	}
+	if db != nil {
+		return db.SetIfNotExists(fixedKey, value, time.Duration(ttlSeconds)*time.Second), nil
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), jsvmStoreOpTimeout)
 	defer cancel()
 
@@ -291,10 +314,14 @@ func (h *JSVMAPIHelper) StorageSetNX(key, value string, ttlSeconds int64) (bool,
 
 // StorageDel removes a key from the JSVM store.
 func (h *JSVMAPIHelper) StorageDel(key string) error {
-	client, fixedKey, err := h.storeClient(key)
+	client, db, fixedKey, err := h.storeClient(key)
 	if err != nil {
 		return err
 	}
+	if db != nil {
+		db.Delete(fixedKey)
+		return nil
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), jsvmStoreOpTimeout)
 	defer cancel()
 
@@ -308,10 +335,13 @@ func (h *JSVMAPIHelper) StorageDel(key string) error {
 // StorageTTL returns the remaining TTL of a key in seconds, following redis
 // semantics: -1 means no expiry, -2 means the key does not exist.
 func (h *JSVMAPIHelper) StorageTTL(key string) (int64, error) {
-	client, fixedKey, err := h.storeClient(key)
+	client, db, fixedKey, err := h.storeClient(key)
 	if err != nil {
 		return 0, err
 	}
+	if db != nil {
+		return db.TTL(fixedKey), nil
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), jsvmStoreOpTimeout)
 	defer cancel()
 
@@ -331,10 +361,21 @@ func (h *JSVMAPIHelper) StorageTTL(key string) (int64, error) {
 // string (JS numbers lose precision past 2^53). ttlSeconds is applied only
 // when the increment created the key, matching IncrememntWithExpire semantics.
 func (h *JSVMAPIHelper) StorageIncr(key string, ttlSeconds int64) (string, error) {
-	client, fixedKey, err := h.storeClient(key)
+	client, db, fixedKey, err := h.storeClient(key)
 	if err != nil {
 		return "", err
 	}
+	if db != nil {
+		val, err := db.IncrBy(fixedKey, 1)
+		if err != nil {
+			h.Log.WithError(err).Error("JSVM storage: failed to increment key")
+			return "", err
+		}
+		if val == 1 && ttlSeconds > 0 {
+			db.Expire(fixedKey, time.Duration(ttlSeconds)*time.Second)
+		}
+		return strconv.FormatInt(val, 10), nil
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), jsvmStoreOpTimeout)
 	defer cancel()
 
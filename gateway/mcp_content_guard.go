@@ -0,0 +1,150 @@
+package gateway
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/internal/event"
+	"github.com/TykTechnologies/tyk/internal/httpctx"
+	jsonrpcerrors "github.com/TykTechnologies/tyk/internal/jsonrpc/errors"
+	"github.com/TykTechnologies/tyk/internal/mcp"
+	"github.com/TykTechnologies/tyk/request"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+// EventMCPContentMeta is the metadata of the MCPContentRedacted and
+// MCPContentBlocked events. It describes the violations, never the content.
+type EventMCPContentMeta struct {
+	EventMetaDefault
+	APIID  string
+	OrgID  string
+	Key    string
+	Path   string
+	Origin string
+	// Method is the JSON-RPC method of the request, Primitive the name of its tool, prompt or resource.
+	Method    string
+	Primitive string
+	// Violations are the redactions applied, or the reasons the result was blocked.
+	Violations []mcp.ContentViolation
+}
+
+func (e EventMCPContentMeta) LogMessage(prefix string) string {
+	return fmt.Sprintf("%s:%s:%s:%s: %s", prefix, e.APIID, e.Method, e.Primitive, e.Message)
+}
+
+// newMCPResultGuard compiles the content guard of the MCP API. The API must
+// not be loaded when it fails, as its results would be let through unguarded.
+func newMCPResultGuard(spec *APISpec) (*mcp.ContentGuard, error) {
+	def := spec.MCPContentGuard
+
+	redactions := make([]mcp.RedactionRule, 0, len(def.Redactions))
+	for _, redaction := range def.Redactions {
+		redactions = append(redactions, mcp.RedactionRule(redaction))
+	}
+
+	return mcp.NewContentGuard(mcp.ContentGuardConfig{
+		Redactions:          redactions,
+		MaxResultSize:       def.MaxResultSize,
+		BlockedContentTypes: def.BlockedContentTypes,
+	})
+}
+
+// guardMCPMessage applies the content guard of the API to a JSON-RPC message
+// and fires the events of its violations. It returns the message to send to
+// the client, nil when the message is unchanged.
+func guardMCPMessage(spec *APISpec, r *http.Request, ses *user.SessionState, msg []byte) []byte {
+	outcome := spec.MCPResultGuard.Guard(msg)
+	if len(outcome.Violations) == 0 {
+		return nil
+	}
+
+	meta := EventMCPContentMeta{
+		EventMetaDefault: EventMetaDefault{Message: "MCP response content redacted"},
+		APIID:            spec.APIID,
+		OrgID:            spec.OrgID,
+		Path:             r.URL.Path,
+		Origin:           request.RealIP(r),
+		Violations:       outcome.Violations,
+	}
+	if ses != nil {
+		meta.Key = ses.KeyID
+	}
+	id := any(outcome.ID)
+	if state := httpctx.GetJSONRPCRoutingState(r); state != nil {
+		meta.Method = state.Method
+		meta.Primitive = state.PrimitiveName
+		if outcome.ID == nil {
+			id = state.ID
+		}
+	}
+
+	if !outcome.Blocked {
+		spec.FireEvent(apidef.TykEvent(event.MCPContentRedacted), meta)
+		return outcome.Message
+	}
+
+	meta.Message = "MCP response content blocked"
+	spec.FireEvent(apidef.TykEvent(event.MCPContentBlocked), meta)
+
+	blocked, err := json.Marshal(JSONRPCErrorResponse{
+		JSONRPC: apidef.JsonRPC20,
+		Error: JSONRPCError{
+			Code:    jsonrpcerrors.CodeContentBlocked,
+			Message: mcp.ErrMsgContentBlocked,
+			Data:    map[string]any{"violations": outcome.Violations},
+		},
+		ID: id,
+	})
+	if err != nil {
+		return nil
+	}
+	return blocked
+}
+
+// decodeMCPBody returns a reader of the decompressed response body, so the
+// content guard can parse it. It returns false for the content encodings
+// which can't be decompressed.
+func decodeMCPBody(res *http.Response) (io.Reader, bool) {
+	switch res.Header.Get(header.ContentEncoding) {
+	case "", "identity":
+		return res.Body, true
+	case "gzip":
+		reader, err := gzip.NewReader(res.Body)
+		if err != nil {
+			return nil, false
+		}
+		return reader, true
+	case "deflate":
+		return flate.NewReader(res.Body), true
+	default:
+		return nil, false
+	}
+}
+
+// decodeMCPStream decompresses the server-sent events of the response for
+// the content guard hook. A stream which can't be decompressed is dropped, as
+// its content can't be guarded.
+func decodeMCPStream(res *http.Response) {
+	body := res.Body
+	reader, ok := decodeMCPBody(res)
+	res.Header.Del(header.ContentEncoding)
+
+	if !ok {
+		log.Warning("Dropped an MCP event stream with an unsupported content encoding")
+		body.Close()
+		res.Body = io.NopCloser(bytes.NewReader(nil))
+		return
+	}
+
+	res.Body = struct {
+		io.Reader
+		io.Closer
+	}{reader, body}
+}
@@ -0,0 +1,86 @@
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/internal/mcp/stdio"
+)
+
+// newMCPStdioBridge creates the bridge serving the MCP API with a local MCP
+// stdio server. It fails when the gateway configuration doesn't allow the
+// command, its arguments, environment variables and working directory, the
+// API must then not be loaded.
+func (gw *Gateway) newMCPStdioBridge(spec *APISpec, logger *logrus.Entry) (*stdio.Bridge, error) {
+	conf := gw.GetConfig().MCPStdio
+	def := spec.MCPStdio
+
+	if !spec.IsMCP() {
+		return nil, errors.New("MCP stdio server is only supported by MCP APIs")
+	}
+	if !conf.Enabled {
+		return nil, errors.New("MCP stdio servers are disabled by the gateway configuration")
+	}
+
+	allowed := slices.ContainsFunc(conf.AllowedCommands, func(cmd config.MCPStdioCommand) bool {
+		return mcpStdioCommandAllows(cmd, def)
+	})
+	if !allowed {
+		return nil, fmt.Errorf("MCP stdio server command %q, its arguments, environment and working directory aren't allowed by the gateway configuration", def.Command)
+	}
+
+	return stdio.New(stdio.Config{
+		Command:           def.Command,
+		Args:              def.Args,
+		Env:               def.Env,
+		Dir:               def.Dir,
+		Mode:              def.Mode,
+		PoolSize:          def.PoolSize,
+		MaxSessions:       def.MaxSessions,
+		IdleTimeout:       time.Duration(def.IdleTimeout),
+		RestartBackoff:    time.Duration(def.RestartBackoff),
+		MaxRestartBackoff: time.Duration(def.MaxRestartBackoff),
+		Owner:             mcpStdioOwner,
+		Logger:            logger.WithField("prefix", "mcp-stdio"),
+	}), nil
+}
+
+// mcpStdioCommandAllows returns true if the allowed command matches the
+// command, the arguments, the environment variables and the working directory
+// of the MCP stdio server.
+func mcpStdioCommandAllows(cmd config.MCPStdioCommand, def apidef.MCPStdio) bool {
+	if cmd.Command != def.Command || !cmd.AllowAnyArgs && !slices.Equal(cmd.Args, def.Args) {
+		return false
+	}
+
+	if def.Dir != "" && (cmd.Dir == "" || filepath.Clean(def.Dir) != filepath.Clean(cmd.Dir)) {
+		return false
+	}
+
+	for _, env := range def.Env {
+		name, _, ok := strings.Cut(env, "=")
+		if !ok || !slices.Contains(cmd.AllowedEnv, name) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// mcpStdioOwner returns the key of the request, so the MCP sessions can only
+// be used with the key which started them.
+func mcpStdioOwner(r *http.Request) string {
+	if session := ctxGetSession(r); session != nil {
+		return session.KeyID
+	}
+	return ""
+}
@@ -0,0 +1,140 @@
+package gateway
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/internal/mcp"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+// mcpStdioScript is an MCP stdio server answering every request with the same text content.
+const mcpStdioScript = `while read -r line; do
+	id=$(printf '%s' "$line" | sed -n 's/.*"id":\([0-9]*\).*/\1/p')
+	[ -n "$id" ] && printf '{"jsonrpc":"2.0","id":%s,"result":{"content":[{"type":"text","text":"from stdio"}]}}\n' "$id"
+done`
+
+func buildMCPStdioSpec(apiID, listenPath string) *APISpec {
+	spec := buildMCPAggregationSpec(apiID, listenPath, TestHttpAny, true)
+	spec.MCPStdio = apidef.MCPStdio{
+		Enabled: true,
+		Command: "sh",
+		Args:    []string{"-c", mcpStdioScript},
+	}
+	return spec
+}
+
+func TestMCPStdioBridge(t *testing.T) {
+	ts := StartTest(func(c *config.Config) {
+		c.MCPStdio.Enabled = true
+		c.MCPStdio.AllowedCommands = []config.MCPStdioCommand{
+			{Command: "sh", Args: []string{"-c", mcpStdioScript}},
+			{Command: "cat", AllowAnyArgs: true, AllowedEnv: []string{"MCP_LOG_LEVEL"}, Dir: "/tmp"},
+		}
+	})
+	t.Cleanup(ts.Close)
+
+	specs := ts.Gw.LoadAPI(buildMCPStdioSpec("stdio", "/stdio/"))
+	require.NotNil(t, specs[0].MCPStdioBridge)
+
+	headers := map[string]string{headerContentType: contentTypeJSON, "Accept": "application/json, text/event-stream"}
+
+	resp, err := ts.Run(t, test.TestCase{
+		Method: http.MethodPost, Path: "/stdio/", Headers: headers,
+		Data: `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		Code: http.StatusOK,
+	})
+	require.NoError(t, err)
+	sessionID := resp.Header.Get(mcp.HeaderSessionID)
+	require.NotEmpty(t, sessionID)
+
+	headers[mcp.HeaderSessionID] = sessionID
+	_, _ = ts.Run(t, []test.TestCase{
+		{
+			Method: http.MethodPost, Path: "/stdio/", Headers: headers,
+			Data: `{"jsonrpc":"2.0","id":"call","method":"tools/call","params":{"name":"search"}}`,
+			Code: http.StatusOK, BodyMatch: `"id":"call".*from stdio`,
+		},
+		{
+			Method: http.MethodPost, Path: "/stdio/", Headers: map[string]string{headerContentType: contentTypeJSON, mcp.HeaderSessionID: "unknown"},
+			Data: `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+			Code: http.StatusNotFound,
+		},
+	}...)
+
+	t.Run("command not allowed", func(t *testing.T) {
+		spec := buildMCPStdioSpec("stdio-denied", "/stdio-denied/")
+		spec.MCPStdio.Command = "bash"
+
+		specs := ts.Gw.LoadAPI(spec)
+		assert.Nil(t, specs[0].MCPStdioBridge)
+		_, _ = ts.Run(t, test.TestCase{Method: http.MethodPost, Path: "/stdio-denied/", Headers: headers, Code: http.StatusNotFound})
+	})
+
+	t.Run("arguments not allowed", func(t *testing.T) {
+		spec := buildMCPStdioSpec("stdio-args", "/stdio-args/")
+		spec.MCPStdio.Args = []string{"-c", "id"}
+
+		specs := ts.Gw.LoadAPI(spec)
+		assert.Nil(t, specs[0].MCPStdioBridge)
+	})
+
+	t.Run("any arguments allowed", func(t *testing.T) {
+		spec := buildMCPStdioSpec("stdio-any", "/stdio-any/")
+		spec.MCPStdio.Command = "cat"
+		spec.MCPStdio.Args = []string{"-u"}
+
+		specs := ts.Gw.LoadAPI(spec)
+		assert.NotNil(t, specs[0].MCPStdioBridge)
+	})
+
+	t.Run("environment and working directory", func(t *testing.T) {
+		spec := buildMCPStdioSpec("stdio-env", "/stdio-env/")
+		spec.MCPStdio.Command = "cat"
+		spec.MCPStdio.Env = []string{"MCP_LOG_LEVEL=debug"}
+		spec.MCPStdio.Dir = "/tmp/"
+
+		specs := ts.Gw.LoadAPI(spec)
+		assert.NotNil(t, specs[0].MCPStdioBridge)
+	})
+
+	t.Run("environment not allowed", func(t *testing.T) {
+		spec := buildMCPStdioSpec("stdio-env-denied", "/stdio-env-denied/")
+		spec.MCPStdio.Command = "cat"
+		spec.MCPStdio.Env = []string{"LD_PRELOAD=/tmp/evil.so"}
+
+		specs := ts.Gw.LoadAPI(spec)
+		assert.Nil(t, specs[0].MCPStdioBridge)
+	})
+
+	t.Run("working directory not allowed", func(t *testing.T) {
+		spec := buildMCPStdioSpec("stdio-dir-denied", "/stdio-dir-denied/")
+		spec.MCPStdio.Dir = "/tmp"
+
+		specs := ts.Gw.LoadAPI(spec)
+		assert.Nil(t, specs[0].MCPStdioBridge)
+	})
+}
+
+func TestMCPStdioBridge_NoAllowedCommands(t *testing.T) {
+	ts := StartTest(func(c *config.Config) {
+		c.MCPStdio.Enabled = true
+	})
+	t.Cleanup(ts.Close)
+
+	specs := ts.Gw.LoadAPI(buildMCPStdioSpec("stdio", "/stdio/"))
+	assert.Nil(t, specs[0].MCPStdioBridge)
+}
+
+func TestMCPStdioBridge_Disabled(t *testing.T) {
+	ts := StartTest(nil)
+	t.Cleanup(ts.Close)
+
+	specs := ts.Gw.LoadAPI(buildMCPStdioSpec("stdio", "/stdio/"))
+	assert.Nil(t, specs[0].MCPStdioBridge)
+}
@@ -109,6 +109,12 @@ func (gw *Gateway) createDynamicMiddleware(name string, isPre, useSession bool,
 	return gw.createMiddleware(dMiddleware)
 }
 
+// requestDoneHandler is implemented by middlewares which need to run once the
+// rest of the chain has handled the request.
+type requestDoneHandler interface {
+	RequestDone(r *http.Request)
+}
+
 // Generic middleware caller to make extension easier
 func (gw *Gateway) createMiddleware(actualMW TykMiddleware) func(http.Handler) http.Handler {
 	mw := &TraceMiddleware{
@@ -224,6 +230,11 @@ func (gw *Gateway) createMiddleware(actualMW TykMiddleware) func(http.Handler) h
 			logger.WithField("code", errCode).WithField("ns", finishTime.Nanoseconds()).Debug("Finished")
 
 			mw.Base().UpdateRequestSession(r)
+
+			if done, ok := actualMW.(requestDoneHandler); ok {
+				defer done.RequestDone(r)
+			}
+
 			// Special code, bypasses all other execution
 			if errCode != middleware.StatusRespond {
 				// No error, carry on...
@@ -332,6 +343,12 @@ func NewBaseMiddleware(gw *Gateway, spec *APISpec, proxy ReturningHttpHandler, l
 		if len(v.ExtendedPaths.CircuitBreaker) > 0 {
 			baseMid.Spec.CircuitBreakerEnabled = true
 		}
+		if len(v.ExtendedPaths.RetryPolicy) > 0 {
+			baseMid.Spec.RetryPolicyEnabled = true
+		}
+		if len(v.ExtendedPaths.Hedging) > 0 {
+			baseMid.Spec.HedgingEnabled = true
+		}
 		if len(v.ExtendedPaths.HardTimeouts) > 0 {
 			baseMid.Spec.EnforcedTimeoutEnabled = true
 		}
@@ -19,14 +19,17 @@ import (
 	"github.com/TykTechnologies/tyk/ctx"
 	"github.com/TykTechnologies/tyk/internal/agentprotocol"
 	"github.com/TykTechnologies/tyk/internal/certcheck"
+	"github.com/TykTechnologies/tyk/internal/concurrency"
 	"github.com/TykTechnologies/tyk/internal/errors"
 	"github.com/TykTechnologies/tyk/internal/graphengine"
 	"github.com/TykTechnologies/tyk/internal/httpctx"
 	"github.com/TykTechnologies/tyk/internal/httputil"
 	"github.com/TykTechnologies/tyk/internal/jsonrpc"
+	"github.com/TykTechnologies/tyk/internal/loadbalancer"
+	"github.com/TykTechnologies/tyk/internal/loadshed"
+	"github.com/TykTechnologies/tyk/internal/mcp"
 	restmcpadapter "github.com/TykTechnologies/tyk/internal/mcp/adapter"
-
-	_ "github.com/TykTechnologies/tyk/internal/mcp" // registers MCP VEM prefixes
+	"github.com/TykTechnologies/tyk/internal/mcp/stdio"
 )
 
 // APISpec represents a path specification for an API, to avoid enumerating multiple nested lists, a single
@@ -51,8 +54,14 @@ type APISpec struct {
 	GojaJSVM                 GojaJSVM
 	ResponseChain            []TykResponseHandler
 	RoundRobin               RoundRobin
+	LoadBalancer             *loadbalancer.Balancer
+	OutlierDetector          *loadbalancer.OutlierDetector
+	LoadShedder              *loadshed.Limiter
+	ConcurrencyLimiter       concurrency.Limiter
 	URLRewriteEnabled        bool
 	CircuitBreakerEnabled    bool
+	RetryPolicyEnabled       bool
+	HedgingEnabled           bool
 	EnforcedTimeoutEnabled   bool
 	LastGoodHostList         *apidef.HostList
 	HasRun                   bool
@@ -90,6 +99,19 @@ type APISpec struct {
 
 	JSONRPCRouter jsonrpc.Router
 
+	// MCPToolSchemas holds the input schemas of the MCP tools used to validate
+	// the tools/call arguments. Nil unless the tool call validation is enabled.
+	MCPToolSchemas *mcp.ToolSchemas
+
+	// MCPResultGuard redacts and blocks the content of the MCP results. Nil
+	// unless the content guard is enabled, the API fails to load when it
+	// doesn't compile.
+	MCPResultGuard *mcp.ContentGuard
+
+	// MCPStdioBridge serves the MCP API with a local MCP stdio server instead
+	// of the upstream URL. Nil unless the stdio server is enabled and allowed.
+	MCPStdioBridge *stdio.Bridge
+
 	// OperationsAllowListEnabled is true if any JSON-RPC operation (method-level) has
 	// an allow rule enabled. Pre-calculated during API loading.
 	OperationsAllowListEnabled bool
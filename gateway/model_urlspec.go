@@ -27,6 +27,8 @@ type URLSpec struct {
 	InjectHeadersResponse     apidef.HeaderInjectionMeta
 	HardTimeout               apidef.HardTimeoutMeta
 	CircuitBreaker            ExtendedCircuitBreakerMeta
+	RetryPolicy               ExtendedRetryPolicyMeta
+	Hedging                   ExtendedHedgingMeta
 	URLRewrite                *apidef.URLRewriteMeta
 	VirtualPathSpec           apidef.VirtualMeta
 	RequestSize               apidef.RequestSizeMeta
@@ -101,6 +103,10 @@ func (u *URLSpec) modeSpecificSpec(mode URLStatus) (interface{}, bool) {
 		return &u.HardTimeout.TimeOut, true
 	case CircuitBreaker:
 		return &u.CircuitBreaker, true
+	case RetryPolicy:
+		return &u.RetryPolicy, true
+	case Hedging:
+		return &u.Hedging, true
 	case URLRewrite:
 		return u.URLRewrite, true
 	case VirtualPath:
@@ -153,6 +159,10 @@ func (u *URLSpec) matchesMethod(method string) bool {
 		return method == u.HardTimeout.Method
 	case CircuitBreaker:
 		return method == u.CircuitBreaker.Method
+	case RetryPolicy:
+		return method == u.RetryPolicy.Method
+	case Hedging:
+		return method == u.Hedging.Method
 	case URLRewrite:
 		return method == u.URLRewrite.Method
 	case VirtualPath:
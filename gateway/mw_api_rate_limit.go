@@ -7,10 +7,12 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/ctx"
 	"github.com/TykTechnologies/tyk/header"
 	tykerrors "github.com/TykTechnologies/tyk/internal/errors"
 	"github.com/TykTechnologies/tyk/internal/event"
+	"github.com/TykTechnologies/tyk/request"
 	"github.com/TykTechnologies/tyk/storage"
 	"github.com/TykTechnologies/tyk/user"
 )
@@ -43,6 +45,13 @@ func (k *RateLimitForAPI) shouldEnable() bool {
 		}
 	}
 
+	// rate limit dimensions
+	for _, d := range k.Spec.RateLimitDimensions {
+		if d.Valid() {
+			return true
+		}
+	}
+
 	// global api rate limit
 	if k.Spec.GlobalRateLimit.Rate == 0 || k.Spec.GlobalRateLimit.Disabled {
 		return false
@@ -131,6 +140,67 @@ func (k *RateLimitForAPI) ProcessRequest(rw http.ResponseWriter, r *http.Request
 		return k.handleRateLimitFailure(r, event.RateLimitExceeded, "API Rate Limit Exceeded", k.keyName)
 	}
 
+	for _, d := range k.Spec.RateLimitDimensions {
+		if !d.Valid() {
+			continue
+		}
+
+		keyName, session, ok := k.getDimensionSession(r, d)
+		if !ok {
+			continue
+		}
+
+		reason := k.Gw.SessionLimiter.ForwardMessage(
+			r,
+			session,
+			keyName,
+			k.quotaKey,
+			true,
+			false,
+			k.Spec,
+			false,
+			nil,
+		)
+
+		k.emitRateLimitEvents(r, keyName)
+
+		if reason == sessionFailRateLimit {
+			ctx.SetErrorClassification(r, tykerrors.ClassifyRateLimitError(tykerrors.ErrTypeAPIRateLimit, k.Name()))
+			return k.handleRateLimitFailure(r, event.RateLimitExceeded, "API Rate Limit Exceeded", keyName)
+		}
+	}
+
 	// Request is valid, carry on
 	return nil, http.StatusOK
 }
+
+// getDimensionSession returns the rate limit key and session for the value of
+// the dimension key in the request. The requests missing a variable of the
+// key are limited per client instead, by auth token or IP, so that they don't
+// share the limit of a partial value.
+func (k *RateLimitForAPI) getDimensionSession(r *http.Request, d apidef.RateLimitDimension) (string, *user.SessionState, bool) {
+	// track per-dimension with a hash of the dimension name and the key value
+	var keyName string
+	if _, missing := k.Gw.unresolvedRequestVariable(r, d.Key); missing {
+		client := ctxGetAuthToken(r)
+		if client == "" {
+			client = request.RealIP(r)
+		}
+		keyName = k.keyName + "-dim-client-" + storage.HashStr(fmt.Sprintf("%s:%s", d.Name, client))
+	} else {
+		value := k.Gw.ReplaceTykVariables(r, d.Key, false)
+		if value == "" {
+			return "", nil, false
+		}
+		keyName = k.keyName + "-dim-" + storage.HashStr(fmt.Sprintf("%s:%s", d.Name, value))
+	}
+
+	session := &user.SessionState{
+		Rate:        d.Rate,
+		Per:         d.Per,
+		LastUpdated: k.apiSess.LastUpdated,
+	}
+	session.SetKeyHash(storage.HashKey(keyName, k.Gw.GetConfig().HashKeys))
+
+	return keyName, session, true
+}
@@ -154,6 +154,43 @@ func TestAPIRateLimitResponseHeaders(t *testing.T) {
 	}
 }
 
+func TestAPIRateLimitDimensions(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	_ = ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "api-rate-limit-dimensions-test"
+		spec.Proxy.ListenPath = "/api-rate-limit-dimensions-test"
+		spec.UseKeylessAccess = true
+		spec.EnableContextVars = true
+		spec.RateLimitDimensions = []apidef.RateLimitDimension{
+			{Name: "per-tenant", Key: "$tyk_context.headers_X_Tenant_Id", Rate: 2, Per: 60},
+			{Name: "per-ip", Key: "$tyk_context.remote_addr", Rate: 100, Per: 60},
+		}
+	})
+
+	tenant := func(id string) map[string]string {
+		return map[string]string{"X-Tenant-Id": id}
+	}
+
+	_, _ = ts.Run(t, []test.TestCase{
+		{Path: "/api-rate-limit-dimensions-test", Headers: tenant("a"), Code: http.StatusOK},
+		{Path: "/api-rate-limit-dimensions-test", Headers: tenant("a"), Code: http.StatusOK},
+		// the per-tenant limit is reached
+		{Path: "/api-rate-limit-dimensions-test", Headers: tenant("a"), Code: http.StatusTooManyRequests},
+		// another tenant has its own limit
+		{Path: "/api-rate-limit-dimensions-test", Headers: tenant("b"), Code: http.StatusOK},
+		{Path: "/api-rate-limit-dimensions-test", Headers: tenant("b"), Code: http.StatusOK},
+		{Path: "/api-rate-limit-dimensions-test", Headers: tenant("b"), Code: http.StatusTooManyRequests},
+		// requests without the header are limited per client
+		{Path: "/api-rate-limit-dimensions-test", Code: http.StatusOK},
+		{Path: "/api-rate-limit-dimensions-test", Code: http.StatusOK},
+		{Path: "/api-rate-limit-dimensions-test", Code: http.StatusTooManyRequests},
+		// the other tenants keep their own limit
+		{Path: "/api-rate-limit-dimensions-test", Headers: tenant("c"), Code: http.StatusOK},
+	}...)
+}
+
 func TestRLOpen(t *testing.T) {
 	ts := StartTest(nil)
 	defer ts.Close()
@@ -38,10 +38,7 @@ func (m *CertificateCheckMW) Init() {
 			WithField("mw", m.Name()).
 			Debug("Initializing Redis store for cooldowns.")
 
-		m.store = &storage.RedisCluster{
-			KeyPrefix:         "cert-cooldown:",
-			ConnectionHandler: m.Gw.StorageConnectionHandler,
-		}
+		m.store = m.Gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{KeyPrefix: "cert-cooldown:"})
 
 		m.store.Connect()
 	}
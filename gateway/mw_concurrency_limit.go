@@ -0,0 +1,147 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/ctx"
+	"github.com/TykTechnologies/tyk/internal/concurrency"
+	tykerrors "github.com/TykTechnologies/tyk/internal/errors"
+	"github.com/TykTechnologies/tyk/storage"
+)
+
+const concurrencyKeyPrefix = "concurrency-"
+
+// newConcurrencyLimiter creates the limiter of the concurrency limits of an
+// API, the local limiter is shared by the APIs of the gateway.
+func (gw *Gateway) newConcurrencyLimiter(conf apidef.ConcurrencyLimit) concurrency.Limiter {
+	if conf.Distributed {
+		// Raw keys, as the counter increments them as is but prefixes and hashes decremented keys.
+		return concurrency.NewDistributed(gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{KeyPrefix: "", HashKeys: false}))
+	}
+	return gw.concurrencyLimiter
+}
+
+// ConcurrencyLimit caps the number of requests in flight at the same time per
+// key and per API. The slots taken by a request are released once the rest
+// of the chain has handled it. The upstream host limit is taken by the proxy
+// once the target is picked, see acquireUpstreamHostSlot.
+type ConcurrencyLimit struct {
+	*BaseMiddleware
+}
+
+func (k *ConcurrencyLimit) Name() string {
+	return "ConcurrencyLimit"
+}
+
+func (k *ConcurrencyLimit) EnabledForSpec() bool {
+	limit := k.Spec.ConcurrencyLimit
+	return limit.Enabled && k.Spec.ConcurrencyLimiter != nil && (limit.PerKey > 0 || limit.PerAPI > 0)
+}
+
+// ProcessRequest will run any checks on the request on the way through the system, return an error to have the chain fail
+func (k *ConcurrencyLimit) ProcessRequest(_ http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	// Skip concurrency limits for looping, the original request holds the slots
+	if !ctxCheckLimits(r) {
+		return nil, http.StatusOK
+	}
+
+	limit := k.Spec.ConcurrencyLimit
+
+	// Requests over a cap are rejected right away without a queue timeout.
+	queueCtx, cancel := context.WithTimeout(r.Context(), time.Duration(limit.QueueTimeout))
+	defer cancel()
+
+	var releases []func()
+	releaseAll := func() {
+		for _, release := range releases {
+			release()
+		}
+	}
+
+	acquire := func(key string, max int64) error {
+		if max <= 0 {
+			return nil
+		}
+
+		release, err := k.Spec.ConcurrencyLimiter.Acquire(queueCtx, concurrencyKeyPrefix+key, max)
+		if err != nil {
+			return err
+		}
+
+		releases = append(releases, release)
+		return nil
+	}
+
+	var err error
+	if session := ctxGetSession(r); session != nil {
+		err = acquire("key-"+k.Spec.APIID+"-"+session.KeyHash(), limit.PerKey)
+	}
+	if err == nil {
+		err = acquire("api-"+k.Spec.APIID, limit.PerAPI)
+	}
+
+	if err != nil {
+		releaseAll()
+
+		k.Logger().Debug("Concurrency limit reached")
+		return concurrencyLimitError(r, limit, err, k.Name())
+	}
+
+	ctxSetConcurrencyRelease(r, releaseAll)
+
+	return nil, http.StatusOK
+}
+
+// RequestDone releases the slots taken by the request.
+func (k *ConcurrencyLimit) RequestDone(r *http.Request) {
+	if !ctxCheckLimits(r) {
+		return
+	}
+
+	if release := ctxGetConcurrencyRelease(r); release != nil {
+		release()
+	}
+}
+
+// concurrencyLimitError returns the error and the status code of a request
+// which couldn't take a slot.
+func concurrencyLimitError(r *http.Request, limit apidef.ConcurrencyLimit, err error, name string) (error, int) {
+	if !errors.Is(err, concurrency.ErrLimitReached) {
+		return err, http.StatusInternalServerError
+	}
+
+	code := limit.RejectStatusCode
+	if code == 0 {
+		code = http.StatusTooManyRequests
+	}
+
+	ctx.SetErrorClassification(r, tykerrors.ClassifyRateLimitError(tykerrors.ErrTypeOtherRateLimit, name))
+	return errors.New("Concurrency Limit Exceeded"), code
+}
+
+// acquireUpstreamHostSlot takes a slot of the upstream host the request is
+// proxied to, once the target is picked. The slots of a host are shared with
+// the other APIs proxying to it. It returns the func releasing the slot, or
+// the error and the status code to reject the request with.
+func acquireUpstreamHostSlot(r *http.Request, spec *APISpec, host string) (func(), error, int) {
+	limit := spec.ConcurrencyLimit
+	if !limit.Enabled || spec.ConcurrencyLimiter == nil || limit.PerUpstreamHost <= 0 || host == "" || !ctxCheckLimits(r) {
+		return func() {}, nil, http.StatusOK
+	}
+
+	// Requests over the cap are rejected right away without a queue timeout.
+	queueCtx, cancel := context.WithTimeout(r.Context(), time.Duration(limit.QueueTimeout))
+	defer cancel()
+
+	release, err := spec.ConcurrencyLimiter.Acquire(queueCtx, concurrencyKeyPrefix+"host-"+host, limit.PerUpstreamHost)
+	if err != nil {
+		err, code := concurrencyLimitError(r, limit, err, "ConcurrencyLimit")
+		return nil, err, code
+	}
+
+	return release, nil, http.StatusOK
+}
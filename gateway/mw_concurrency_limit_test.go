@@ -0,0 +1,113 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	tyktime "github.com/TykTechnologies/tyk/internal/time"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestConcurrencyLimit(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	received := make(chan struct{}, 1)
+	unblock := make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			received <- struct{}{}
+			<-unblock
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	loadAPI := func(limit apidef.ConcurrencyLimit) {
+		ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.APIID = "concurrency-limit-test"
+			spec.Proxy.ListenPath = "/"
+			spec.Proxy.TargetURL = upstream.URL
+			spec.UseKeylessAccess = true
+			spec.ConcurrencyLimit = limit
+		})
+	}
+
+	// slowRequest holds the only slot until unblocked.
+	slowRequest := func() chan struct{} {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, _ = ts.Run(t, test.TestCase{Path: "/slow", Code: http.StatusOK})
+		}()
+		<-received
+		return done
+	}
+
+	t.Run("reject", func(t *testing.T) {
+		loadAPI(apidef.ConcurrencyLimit{Enabled: true, PerAPI: 1, RejectStatusCode: http.StatusServiceUnavailable})
+
+		done := slowRequest()
+		_, _ = ts.Run(t, test.TestCase{Path: "/fast", Code: http.StatusServiceUnavailable})
+
+		unblock <- struct{}{}
+		<-done
+
+		_, _ = ts.Run(t, test.TestCase{Path: "/fast", Code: http.StatusOK})
+	})
+
+	t.Run("queue", func(t *testing.T) {
+		loadAPI(apidef.ConcurrencyLimit{Enabled: true, PerAPI: 1, QueueTimeout: tyktime.ReadableDuration(5 * time.Second)})
+
+		done := slowRequest()
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			unblock <- struct{}{}
+		}()
+
+		start := time.Now()
+		_, _ = ts.Run(t, test.TestCase{Path: "/fast", Code: http.StatusOK})
+		assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond, "the request waits for the slot")
+		<-done
+	})
+
+	t.Run("queue timeout", func(t *testing.T) {
+		loadAPI(apidef.ConcurrencyLimit{Enabled: true, PerUpstreamHost: 1, QueueTimeout: tyktime.ReadableDuration(20 * time.Millisecond)})
+
+		done := slowRequest()
+		_, _ = ts.Run(t, test.TestCase{Path: "/fast", Code: http.StatusTooManyRequests})
+
+		unblock <- struct{}{}
+		<-done
+	})
+
+	t.Run("upstream host shared across APIs", func(t *testing.T) {
+		limit := apidef.ConcurrencyLimit{Enabled: true, PerUpstreamHost: 1}
+		ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.APIID = "concurrency-limit-test"
+			spec.Proxy.ListenPath = "/"
+			spec.Proxy.TargetURL = upstream.URL
+			spec.UseKeylessAccess = true
+			spec.ConcurrencyLimit = limit
+		}, func(spec *APISpec) {
+			spec.APIID = "concurrency-limit-other"
+			spec.Proxy.ListenPath = "/other/"
+			spec.Proxy.TargetURL = upstream.URL
+			spec.UseKeylessAccess = true
+			spec.ConcurrencyLimit = limit
+		})
+
+		done := slowRequest()
+		_, _ = ts.Run(t, test.TestCase{Path: "/other/fast", Code: http.StatusTooManyRequests})
+
+		unblock <- struct{}{}
+		<-done
+
+		_, _ = ts.Run(t, test.TestCase{Path: "/other/fast", Code: http.StatusOK})
+	})
+}
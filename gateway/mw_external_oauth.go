@@ -326,14 +326,14 @@ func isExpired(claims jwt.MapClaims) bool {
 }
 
 func newIntrospectionCache(gw *Gateway) *introspectionCache {
-	conn := &storage.RedisCluster{KeyPrefix: "introspection-", ConnectionHandler: gw.StorageConnectionHandler}
+	conn := gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{KeyPrefix: "introspection-"})
 	conn.Connect()
 
-	return &introspectionCache{RedisCluster: conn}
+	return &introspectionCache{Handler: conn}
 }
 
 type introspectionCache struct {
-	*storage.RedisCluster
+	storage.Handler
 }
 
 func (c *introspectionCache) GetRes(token string) (jwt.MapClaims, bool) {
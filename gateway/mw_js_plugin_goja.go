@@ -35,7 +35,7 @@ type GojaJSVM struct {
 	Gw      *Gateway       `json:"-"`
 
 	programs    []gojaProgram // compiled JS programs replayed on each new runtime
-	store       *storage.RedisCluster
+	store       storage.Handler
 	initialized bool
 }
 
@@ -246,7 +246,7 @@ func (j *GojaJSVM) Init(spec *APISpec, logger *logrus.Entry, gw *Gateway) {
 	// HashKeys stays false so keys land verbatim under the enforced prefix;
 	// the helper additionally prefixes on the raw client so plugin keys can
 	// never escape the jsvm-store namespace.
-	j.store = &storage.RedisCluster{KeyPrefix: jsvmStoreKeyPrefix, HashKeys: false, ConnectionHandler: gw.StorageConnectionHandler}
+	j.store = gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{KeyPrefix: jsvmStoreKeyPrefix, HashKeys: false})
 
 	j.Spec = spec
 	j.initialized = true
@@ -816,6 +816,36 @@ func TestGoja_StorageBindings(t *testing.T) {
 		assert.ErrorIs(t, err, storage.ErrKeyNotFound)
 	})
 
+	t.Run("embedded storage", func(t *testing.T) {
+		db, err := storage.OpenEmbeddedDB("", time.Hour)
+		require.NoError(t, err)
+		defer db.Close()
+
+		h := &JSVMAPIHelper{Log: logrus.NewEntry(log), Store: &storage.EmbeddedStorage{DB: db}}
+		require.NoError(t, h.StorageSet("key", "value", 0))
+		value, found, err := h.StorageGet("key")
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, "value", value)
+		assert.True(t, db.Exists("jsvm-store:key"))
+
+		claimed, err := h.StorageSetNX("key", "other", 0)
+		assert.NoError(t, err)
+		assert.False(t, claimed)
+
+		count, err := h.StorageIncr("counter", 30)
+		assert.NoError(t, err)
+		assert.Equal(t, "1", count)
+		ttl, err := h.StorageTTL("counter")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(30), ttl)
+
+		require.NoError(t, h.StorageDel("key"))
+		_, found, err = h.StorageGet("key")
+		assert.NoError(t, err)
+		assert.False(t, found)
+	})
+
 	t.Run("nil store throws instead of failing silently", func(t *testing.T) {
 		vm.DeInit()
 		defer vm.Init(nil, logrus.NewEntry(log), ts.Gw)
@@ -183,6 +183,12 @@ func (m *JSONRPCMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Reques
 		return nil, http.StatusOK
 	}
 
+	// Serve batch requests element by element
+	if elements, ok := m.readJSONRPCBatch(r); ok {
+		m.processBatch(w, r, elements)
+		return nil, middleware.StatusRespond
+	}
+
 	// Parse JSON-RPC request
 	rpcReq, body, err := m.readAndParseJSONRPC(w, r)
 	if err != nil {
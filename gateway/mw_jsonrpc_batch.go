@@ -0,0 +1,147 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	jsonrpcerrors "github.com/TykTechnologies/tyk/internal/jsonrpc/errors"
+	"github.com/TykTechnologies/tyk/internal/mcp"
+)
+
+// defaultJSONRPCMaxBatchSize is the maximum number of requests in a JSON-RPC
+// batch when it isn't configured.
+const defaultJSONRPCMaxBatchSize = 100
+
+// readJSONRPCBatch reads the elements of a JSON-RPC batch request. It reports
+// false, with the body restored, when the body isn't a JSON array.
+func (m *JSONRPCMiddleware) readJSONRPCBatch(r *http.Request) ([]json.RawMessage, bool) {
+	body, err := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return nil, false
+	}
+
+	trimmed := bytes.TrimLeft(body, " \t\r\n")
+	if len(trimmed) == 0 || trimmed[0] != '[' {
+		return nil, false
+	}
+
+	var elements []json.RawMessage
+	if err := json.Unmarshal(body, &elements); err != nil {
+		return nil, false
+	}
+	return elements, true
+}
+
+// batchSizeLimit returns the request size limit the elements of a batch are
+// counted against together, 0 when there's none.
+func (m *JSONRPCMiddleware) batchSizeLimit(r *http.Request) int64 {
+	limit := m.Gw.GetConfig().HttpServerOptions.MaxRequestBodySize
+
+	vInfo, _ := m.Spec.Version(r)
+	if vInfo.GlobalSizeLimit > 0 && !vInfo.GlobalSizeLimitDisabled && (limit <= 0 || vInfo.GlobalSizeLimit < limit) {
+		limit = vInfo.GlobalSizeLimit
+	}
+
+	return limit
+}
+
+// processBatch serves the elements of a JSON-RPC batch request one after the
+// other, each through the middleware chain of the API as a single request, so
+// every element is routed, authenticated, access-checked and rate limited on
+// its own. The responses are returned as a batch, without the notifications'.
+// A batch of notifications only is accepted without response body. Batches
+// with more elements than the configured maximum, or larger than the request
+// size limit, are rejected before any element is served.
+func (m *JSONRPCMiddleware) processBatch(w http.ResponseWriter, r *http.Request, elements []json.RawMessage) {
+	if len(elements) == 0 {
+		m.writeJSONRPCError(w, r, nil, mcp.JSONRPCInvalidRequest, mcp.ErrMsgInvalidRequest, nil)
+		return
+	}
+
+	maxBatchSize := m.Gw.GetConfig().JSONRPCMaxBatchSize
+	if maxBatchSize <= 0 {
+		maxBatchSize = defaultJSONRPCMaxBatchSize
+	}
+	if len(elements) > maxBatchSize {
+		m.writeJSONRPCError(w, r, nil, mcp.JSONRPCInvalidRequest, "batch too large", map[string]int{"max_batch_size": maxBatchSize})
+		return
+	}
+
+	if limit := m.batchSizeLimit(r); limit > 0 {
+		var size int64
+		for _, element := range elements {
+			size += int64(len(element))
+		}
+		if size > limit {
+			m.writeJSONRPCError(w, r, nil, mcp.JSONRPCInvalidRequest, "batch too large", map[string]int64{"max_size": limit})
+			return
+		}
+	}
+
+	var sessionID string
+	responses := make([]json.RawMessage, 0, len(elements))
+	for _, element := range elements {
+		var rpcReq JSONRPCRequest
+		if err := json.Unmarshal(element, &rpcReq); err != nil || rpcReq.JSONRPC != apidef.JsonRPC20 || rpcReq.Method == "" {
+			responses = append(responses, batchErrorResponse(rpcReq.ID, mcp.JSONRPCInvalidRequest, mcp.ErrMsgInvalidRequest))
+			continue
+		}
+
+		header := r.Header.Clone()
+		header.Set("Accept", "application/json, text/event-stream")
+
+		rec, err := m.Gw.serveJSONRPCRequest(r, m.Spec.APIID, r.URL.RequestURI(), header, element)
+		if rpcReq.ID == nil {
+			continue
+		}
+		if err != nil {
+			m.Logger().WithError(err).Error("Failed to serve JSON-RPC batch element")
+			responses = append(responses, batchErrorResponse(rpcReq.ID, mcp.JSONRPCInternalError, "Internal error"))
+			continue
+		}
+
+		if sessionID == "" {
+			sessionID = rec.Header().Get(mcp.HeaderSessionID)
+		}
+
+		result, rpcError := parseJSONRPCResponseBody(rec.Header().Get(headerContentType), rec.Body.Bytes())
+		if result == nil && rpcError == nil {
+			code := jsonrpcerrors.MapHTTPStatusToJSONRPCCode(rec.Code)
+			if code == 0 {
+				code = jsonrpcerrors.CodeUpstreamError
+			}
+			responses = append(responses, batchErrorResponse(rpcReq.ID, code, http.StatusText(rec.Code)))
+			continue
+		}
+
+		response, _ := json.Marshal(mcp.JSONRPCResponse{JSONRPC: apidef.JsonRPC20, ID: rpcReq.ID, Result: result, Error: rpcError}) //nolint:errcheck
+		responses = append(responses, response)
+	}
+
+	if sessionID != "" {
+		w.Header().Set(mcp.HeaderSessionID, sessionID)
+	}
+
+	if len(responses) == 0 {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	w.Header().Set(headerContentType, contentTypeJSON)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responses) //nolint:errcheck
+}
+
+// batchErrorResponse returns the JSON-RPC error response of a batch element.
+func batchErrorResponse(id any, code int, message string) json.RawMessage {
+	response, _ := json.Marshal(JSONRPCErrorResponse{ //nolint:errcheck
+		JSONRPC: apidef.JsonRPC20,
+		Error:   JSONRPCError{Code: code, Message: message},
+		ID:      id,
+	})
+	return response
+}
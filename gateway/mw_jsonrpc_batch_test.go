@@ -0,0 +1,102 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/apidef/oas"
+	jsonrpcerrors "github.com/TykTechnologies/tyk/internal/jsonrpc/errors"
+	"github.com/TykTechnologies/tyk/internal/mcp"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestJSONRPCMiddleware_Batch(t *testing.T) {
+	ts := StartTest(nil)
+	t.Cleanup(ts.Close)
+
+	oasAPI := getSampleOASAPI()
+	tykExt := oasAPI.GetTykExtension()
+	tykExt.Info.ID = "batch"
+	tykExt.Server.ListenPath = oas.ListenPath{Value: "/batch/", Strip: true}
+	tykExt.Upstream.URL = newMCPToolSchemaServer(t, false).URL
+	tykExt.Middleware = &oas.Middleware{
+		McpTools: oas.MCPPrimitives{
+			"search": &oas.MCPPrimitive{
+				Operation: oas.Operation{
+					RateLimit: &oas.RateLimitEndpoint{Enabled: true, Rate: 1, Per: oas.ReadableDuration(time.Minute)},
+				},
+			},
+		},
+	}
+	oasAPI.SetTykExtension(tykExt)
+
+	var def apidef.APIDefinition
+	oasAPI.ExtractTo(&def)
+	def.IsOAS = true
+	def.UseKeylessAccess = true
+	def.GlobalRateLimit = apidef.GlobalRateLimit{Rate: 100, Per: 1}
+	def.MarkAsMCP()
+
+	spec := &APISpec{APIDefinition: &def, OAS: oasAPI}
+	ts.Gw.LoadAPI(spec)
+
+	headers := map[string]string{headerContentType: contentTypeJSON}
+
+	t.Run("elements are served individually", func(t *testing.T) {
+		resp, err := ts.Run(t, test.TestCase{
+			Method: http.MethodPost, Path: "/batch/", Headers: headers,
+			Data: `[
+				{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"search"}},
+				{"jsonrpc":"2.0","id":"two","method":"tools/call","params":{"name":"search"}},
+				{"jsonrpc":"2.0","method":"notifications/initialized"},
+				{"id":4}
+			]`,
+			Code: http.StatusOK,
+		})
+		require.NoError(t, err)
+
+		var responses []struct {
+			ID     any             `json:"id"`
+			Result json.RawMessage `json:"result"`
+			Error  *JSONRPCError   `json:"error"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&responses))
+		require.Len(t, responses, 3)
+
+		assert.Equal(t, float64(1), responses[0].ID)
+		assert.Contains(t, string(responses[0].Result), "called")
+
+		assert.Equal(t, "two", responses[1].ID)
+		require.NotNil(t, responses[1].Error)
+		assert.Equal(t, jsonrpcerrors.CodeRateLimitExceeded, responses[1].Error.Code)
+
+		assert.Equal(t, float64(4), responses[2].ID)
+		require.NotNil(t, responses[2].Error)
+		assert.Equal(t, mcp.JSONRPCInvalidRequest, responses[2].Error.Code)
+	})
+
+	_, _ = ts.Run(t, []test.TestCase{
+		{
+			Method: http.MethodPost, Path: "/batch/", Headers: headers,
+			Data: `[{"jsonrpc":"2.0","method":"notifications/initialized"}]`,
+			Code: http.StatusAccepted, BodyMatch: `^$`,
+		},
+		{
+			Method: http.MethodPost, Path: "/batch/", Headers: headers,
+			Data: `[]`,
+			Code: http.StatusBadRequest, BodyMatch: `"code":-32600`,
+		},
+		{
+			Method: http.MethodPost, Path: "/batch/", Headers: headers,
+			Data: "[" + strings.Repeat(`{"jsonrpc":"2.0","method":"notifications/initialized"},`, defaultJSONRPCMaxBatchSize) + `{"jsonrpc":"2.0","method":"notifications/initialized"}]`,
+			Code: http.StatusBadRequest, BodyMatch: `"code":-32600`,
+		},
+	}...)
+}
@@ -1,10 +1,17 @@
 package gateway
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 
 	"github.com/TykTechnologies/tyk/internal/httpctx"
 	jsonrpcerrors "github.com/TykTechnologies/tyk/internal/jsonrpc/errors"
+	"github.com/TykTechnologies/tyk/internal/mcp"
 )
 
 // writeJSONRPCAccessDenied writes a JSON-RPC 2.0 error response for access-denied cases.
@@ -17,3 +24,78 @@ func writeJSONRPCAccessDenied(w http.ResponseWriter, r *http.Request, detail str
 	}
 	jsonrpcerrors.WriteJSONRPCError(w, requestID, http.StatusForbidden, detail)
 }
+
+// serveJSONRPCRequest sends a JSON-RPC request body to the API through its
+// middleware chain, at the path or the API's listen path when empty. The
+// request starts a new context cancelled with the calling request's, so the
+// routing state of the calling request doesn't leak into the chain.
+func (gw *Gateway) serveJSONRPCRequest(r *http.Request, apiID, path string, header http.Header, body []byte) (*httptest.ResponseRecorder, error) {
+	spec := gw.getApiSpec(apiID)
+	handler, found := gw.apisHandlesByID.Load(apiID)
+	if spec == nil || !found {
+		return nil, errors.New("API is not loaded")
+	}
+	chain, ok := handler.(*ChainObject)
+	if !ok {
+		return nil, errors.New("API has no handler")
+	}
+
+	if path == "" {
+		path = spec.Proxy.ListenPath
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	defer context.AfterFunc(r.Context(), cancel)()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header = header
+	req.Header.Del(httpHeaderContentLength)
+	req.Header.Set(headerContentType, contentTypeJSON)
+	req.Host = r.Host
+	req.RemoteAddr = r.RemoteAddr
+
+	rec := httptest.NewRecorder()
+	chain.ThisHandler.ServeHTTP(rec, req)
+
+	return rec, nil
+}
+
+// parseJSONRPCResponseBody returns the result and error of the JSON-RPC
+// response in the body, a JSON object or a server-sent event stream.
+func parseJSONRPCResponseBody(contentType string, body []byte) (result, rpcError json.RawMessage) {
+	if !strings.HasPrefix(contentType, "text/event-stream") {
+		var envelope mcp.JSONRPCResponse
+		if err := json.Unmarshal(body, &envelope); err != nil {
+			return nil, nil
+		}
+		return envelope.Result, envelope.Error
+	}
+
+	rest := body
+	for len(rest) > 0 {
+		event, _, next, err := parseSSEEvent(rest)
+		if err != nil {
+			break
+		}
+		rest = next
+
+		if event == nil || (event.Event != "" && event.Event != "message") {
+			continue
+		}
+
+		var envelope mcp.JSONRPCResponse
+		if err := json.Unmarshal([]byte(strings.Join(event.Data, "\n")), &envelope); err != nil {
+			continue
+		}
+		if envelope.Result != nil || envelope.Error != nil {
+			return envelope.Result, envelope.Error
+		}
+	}
+
+	return nil, nil
+}
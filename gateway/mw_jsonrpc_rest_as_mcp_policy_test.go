@@ -145,7 +145,7 @@ func TestRESTAsMCPPolicy_EndpointRateLimitBlocksToolCall(t *testing.T) {
 	cfg := config.Default
 	drlManager := &drl.DRL{RequestTokenValue: 1}
 	drlManager.SetCurrentTokenValue(1)
-	gw.SessionLimiter = NewSessionLimiter(t.Context(), &cfg, drlManager, &cfg.ExternalServices)
+	gw.SessionLimiter = NewSessionLimiter(t.Context(), &cfg, drlManager, &cfg.ExternalServices, nil)
 	gw.limitHeaderFactory = rate.NewSenderFactory(cfg.RateLimitResponseHeaders)
 
 	mw := &JSONRPCMiddleware{BaseMiddleware: &BaseMiddleware{Spec: adapterSpec, Gw: gw}}
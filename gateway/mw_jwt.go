@@ -1139,7 +1139,7 @@ func (k *JWTMiddleware) processCentralisedJWT(r *http.Request, token *jwt.Token)
 			storageManager := k.Gw.getGlobalMDCBStorageHandler(prefix, false)
 			storageManager.Connect()
 
-			storageDriver := &storage.RedisCluster{KeyPrefix: prefix, HashKeys: false, ConnectionHandler: k.Gw.StorageConnectionHandler}
+			storageDriver := k.Gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{KeyPrefix: prefix, HashKeys: false})
 			storageDriver.Connect()
 
 			k.Spec.OAuthManager = &OAuthManager{
@@ -0,0 +1,94 @@
+package gateway
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/ctx"
+	tykerrors "github.com/TykTechnologies/tyk/internal/errors"
+	"github.com/TykTechnologies/tyk/internal/loadshed"
+)
+
+// newLoadShedder creates the adaptive concurrency limit of an API.
+func newLoadShedder(conf apidef.LoadShedding) *loadshed.Limiter {
+	return loadshed.New(loadshed.Config{
+		Algorithm:        loadshed.Algorithm(conf.Algorithm),
+		InitialLimit:     conf.InitialLimit,
+		MinLimit:         conf.MinLimit,
+		MaxLimit:         conf.MaxLimit,
+		LatencyThreshold: time.Duration(conf.LatencyThreshold),
+		PriorityReserve:  conf.PriorityReserve,
+	})
+}
+
+// LoadShedding rejects requests over the concurrency limit adjusted from the
+// upstream latency and errors observed by the reverse proxy. Requests of
+// sessions with a priority tag are shed last.
+type LoadShedding struct {
+	*BaseMiddleware
+}
+
+func (k *LoadShedding) Name() string {
+	return "LoadShedding"
+}
+
+func (k *LoadShedding) EnabledForSpec() bool {
+	return k.Spec.LoadShedding.Enabled && k.Spec.LoadShedder != nil
+}
+
+// ProcessRequest will run any checks on the request on the way through the system, return an error to have the chain fail
+func (k *LoadShedding) ProcessRequest(_ http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	// Skip load shedding for looping, the original request holds the slot
+	if !ctxCheckLimits(r) {
+		return nil, http.StatusOK
+	}
+
+	release, ok := k.Spec.LoadShedder.Acquire(k.hasPriority(r))
+	if !ok {
+		k.Logger().Debug("Load shedding limit reached")
+
+		code := k.Spec.LoadShedding.RejectStatusCode
+		if code == 0 {
+			code = http.StatusServiceUnavailable
+		}
+
+		ctx.SetErrorClassification(r, tykerrors.ClassifyRateLimitError(tykerrors.ErrTypeOtherRateLimit, k.Name()))
+		return errors.New("Service temporarily unavailable."), code
+	}
+
+	ctxSetLoadSheddingRelease(r, release)
+
+	return nil, http.StatusOK
+}
+
+// hasPriority reports whether the session of the request has one of the
+// priority tags. Policy tags are applied to the session.
+func (k *LoadShedding) hasPriority(r *http.Request) bool {
+	session := ctxGetSession(r)
+	if session == nil {
+		return false
+	}
+
+	for _, tag := range session.Tags {
+		for _, priorityTag := range k.Spec.LoadShedding.PriorityTags {
+			if tag == priorityTag {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// RequestDone releases the slot taken by the request.
+func (k *LoadShedding) RequestDone(r *http.Request) {
+	if !ctxCheckLimits(r) {
+		return
+	}
+
+	if release := ctxGetLoadSheddingRelease(r); release != nil {
+		release()
+	}
+}
@@ -0,0 +1,66 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/samber/lo"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/test"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+func TestLoadShedding(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	received := make(chan struct{}, 1)
+	unblock := make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			received <- struct{}{}
+			<-unblock
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+		spec.Proxy.TargetURL = upstream.URL
+		spec.UseKeylessAccess = false
+		spec.LoadShedding = apidef.LoadShedding{
+			Enabled: true,
+			// A fixed limit of 2, one of them reserved to priority requests.
+			InitialLimit:    2,
+			MinLimit:        2,
+			MaxLimit:        2,
+			PriorityTags:    []string{"premium"},
+			PriorityReserve: lo.ToPtr(0.5),
+		}
+	})
+
+	regular := map[string]string{"authorization": CreateSession(ts.Gw)}
+	premium := map[string]string{"authorization": CreateSession(ts.Gw, func(s *user.SessionState) {
+		s.Tags = []string{"premium"}
+	})}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = ts.Run(t, test.TestCase{Path: "/slow", Headers: regular, Code: http.StatusOK})
+	}()
+	<-received
+
+	_, _ = ts.Run(t, []test.TestCase{
+		{Path: "/fast", Headers: regular, Code: http.StatusServiceUnavailable},
+		{Path: "/fast", Headers: premium, Code: http.StatusOK},
+	}...)
+
+	unblock <- struct{}{}
+	<-done
+
+	_, _ = ts.Run(t, test.TestCase{Path: "/fast", Headers: regular, Code: http.StatusOK})
+}
@@ -0,0 +1,408 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/internal/httpctx"
+	"github.com/TykTechnologies/tyk/internal/mcp"
+	"github.com/TykTechnologies/tyk/internal/middleware"
+)
+
+// maxMCPAggregationPages bounds the pages of a member list the aggregation follows.
+const maxMCPAggregationPages = 100
+
+// mcpAggregatedCapabilities are the server capabilities an aggregation
+// exposes, the notifications of the members are not relayed.
+var mcpAggregatedCapabilities = []string{"tools", "resources", "prompts"}
+
+// MCPAggregationMiddleware serves the JSON-RPC requests of an MCP API aggregating
+// member MCP APIs. The `initialize` and list requests are fanned out to the
+// members and their results merged, with the primitives prefixed with the member
+// prefixes. The calls are routed to the member owning the primitive.
+//
+// It runs once the VEM chain of the request completed, so the access rights of
+// the aggregation apply to the prefixed names. The member chains authenticate
+// the forwarded requests and apply their own access rights to the member names.
+type MCPAggregationMiddleware struct {
+	*BaseMiddleware
+}
+
+// Name returns the middleware name.
+func (m *MCPAggregationMiddleware) Name() string {
+	return "MCPAggregationMiddleware"
+}
+
+// EnabledForSpec returns true for MCP APIs aggregating members.
+func (m *MCPAggregationMiddleware) EnabledForSpec() bool {
+	return m.Spec.IsMCP() && m.Spec.MCPAggregation.Enabled && len(m.Spec.MCPAggregation.Members) > 0
+}
+
+// ProcessRequest answers the JSON-RPC request once its VEM chain completed.
+//
+//nolint:staticcheck // ST1008: middleware interface requires (error, int) return order
+func (m *MCPAggregationMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ any) (error, int) {
+	state := httpctx.GetJSONRPCRoutingState(r)
+	if state == nil {
+		if r.Method == http.MethodPost {
+			m.writeError(w, r, nil, http.StatusBadRequest, mcp.JSONRPCInvalidRequest, mcp.ErrMsgInvalidRequest)
+			return nil, middleware.StatusRespond
+		}
+
+		// The server-sent event streams of the members can't be merged.
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return nil, middleware.StatusRespond
+	}
+
+	// Let the chain route the request through its VEMs first.
+	if r.URL.Path == state.OriginalPath || !httpctx.IsRoutingComplete(r) {
+		return nil, http.StatusOK
+	}
+
+	sessions := mcp.DecodeAggregateSessionID(r.Header.Get(mcp.HeaderSessionID))
+
+	switch {
+	case state.ID == nil:
+		m.notify(r, state, sessions)
+		w.WriteHeader(http.StatusAccepted)
+	case state.Method == mcp.MethodInitialize:
+		m.initialize(w, r, state)
+	case state.Method == mcp.MethodPing:
+		m.writeResult(w, state.ID, map[string]json.RawMessage{})
+	case mcp.ListFilterConfigForMethod(state.Method) != nil:
+		m.list(w, r, state, sessions)
+	case state.Method == mcp.MethodToolsCall, state.Method == mcp.MethodPromptsGet:
+		m.call(w, r, state, sessions, mcp.ParamKeyName)
+	case state.Method == mcp.MethodResourcesRead:
+		m.call(w, r, state, sessions, mcp.ParamKeyURI)
+	default:
+		m.writeError(w, r, state.ID, http.StatusNotFound, mcp.JSONRPCMethodNotFound, "method not found")
+	}
+
+	return nil, middleware.StatusRespond
+}
+
+func (m *MCPAggregationMiddleware) members() []mcp.AggregationMember {
+	members := make([]mcp.AggregationMember, 0, len(m.Spec.MCPAggregation.Members))
+	for _, member := range m.Spec.MCPAggregation.Members {
+		members = append(members, mcp.AggregationMember{ID: member.APIID, Prefix: member.Prefix})
+	}
+	return members
+}
+
+// fanOutMCPMembers calls fn for each member concurrently, returning the results in the member order.
+func fanOutMCPMembers[T any](members []mcp.AggregationMember, fn func(mcp.AggregationMember) T) []T {
+	results := make([]T, len(members))
+
+	var wg sync.WaitGroup
+	for i, member := range members {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = fn(member)
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// notify forwards a notification to all the members.
+func (m *MCPAggregationMiddleware) notify(r *http.Request, state *httpctx.JSONRPCRoutingState, sessions map[string]string) {
+	fanOutMCPMembers(m.members(), func(member mcp.AggregationMember) error {
+		_, err := m.callMember(r, member, sessions[member.ID], state.Method, state.Params, nil)
+		if err != nil {
+			m.memberLogger(member).WithError(err).Warn("Failed to forward MCP notification")
+		}
+		return err
+	})
+}
+
+// initialize initialises the members and merges their capabilities. The
+// session IDs of the members are combined into the session ID of the aggregation.
+func (m *MCPAggregationMiddleware) initialize(w http.ResponseWriter, r *http.Request, state *httpctx.JSONRPCRoutingState) {
+	members := m.members()
+	responses := fanOutMCPMembers(members, func(member mcp.AggregationMember) *mcpMemberResponse {
+		res, err := m.callMember(r, member, "", state.Method, state.Params, state.ID)
+		if err == nil && res.result == nil {
+			err = res.err()
+		}
+		if err != nil {
+			m.memberLogger(member).WithError(err).Warn("Failed to initialise MCP aggregation member")
+			return nil
+		}
+		return res
+	})
+
+	var protocolVersion json.RawMessage
+	capabilities := map[string]json.RawMessage{}
+	sessions := map[string]string{}
+
+	for i, res := range responses {
+		if res == nil {
+			continue
+		}
+
+		if sessionID := res.header.Get(mcp.HeaderSessionID); sessionID != "" {
+			sessions[members[i].ID] = sessionID
+		}
+
+		var result struct {
+			ProtocolVersion json.RawMessage            `json:"protocolVersion"`
+			Capabilities    map[string]json.RawMessage `json:"capabilities"`
+		}
+		if err := json.Unmarshal(res.result, &result); err != nil {
+			continue
+		}
+
+		if protocolVersion == nil {
+			protocolVersion = result.ProtocolVersion
+		}
+		for _, capability := range mcpAggregatedCapabilities {
+			if _, ok := result.Capabilities[capability]; ok {
+				capabilities[capability] = json.RawMessage(`{}`)
+			}
+		}
+	}
+
+	if protocolVersion == nil {
+		m.writeError(w, r, state.ID, http.StatusBadGateway, mcp.JSONRPCInternalError, "no member MCP server could be initialised")
+		return
+	}
+
+	capabilitiesRaw, _ := json.Marshal(capabilities)
+	serverInfo, _ := json.Marshal(map[string]string{"name": m.Spec.Name, "version": VERSION})
+	result := map[string]json.RawMessage{
+		"protocolVersion": protocolVersion,
+		"capabilities":    capabilitiesRaw,
+		"serverInfo":      serverInfo,
+	}
+
+	if sessionID := mcp.EncodeAggregateSessionID(sessions); sessionID != "" {
+		w.Header().Set(mcp.HeaderSessionID, sessionID)
+	}
+
+	resultRaw, _ := json.Marshal(result)
+	envelope := mcp.JSONRPCResponse{JSONRPC: apidef.JsonRPC20, ID: state.ID, Result: resultRaw}
+
+	if ruleSets := effectiveJSONRPCMethodRuleSets(m.Spec, ctxGetSession(r)); len(ruleSets) > 0 {
+		if body, ok := mcp.FilterInitializeCapabilitiesParsed(&envelope, result, ruleSets); ok {
+			m.writeBody(w, http.StatusOK, contentTypeJSON, body)
+			return
+		}
+	}
+
+	m.writeResult(w, state.ID, result)
+}
+
+// list merges the lists of the members, prefixing their primitives, and
+// filters the merged list with the access rights of the aggregation.
+func (m *MCPAggregationMiddleware) list(w http.ResponseWriter, r *http.Request, state *httpctx.JSONRPCRoutingState, sessions map[string]string) {
+	cfg := mcp.ListFilterConfigForMethod(state.Method)
+
+	lists := fanOutMCPMembers(m.members(), func(member mcp.AggregationMember) []json.RawMessage {
+		items, err := m.listMember(r, member, sessions[member.ID], state, cfg)
+		if err != nil {
+			m.memberLogger(member).WithError(err).Warnf("Failed to list MCP aggregation member %s", cfg.ArrayKey)
+			return nil
+		}
+		return mcp.PrefixItems(items, cfg.NameField, member.Prefix)
+	})
+
+	items := make([]json.RawMessage, 0)
+	for _, list := range lists {
+		items = append(items, list...)
+	}
+
+	if m.Spec.MCPToolSchemas != nil && state.Method == mcp.MethodToolsList {
+		m.Spec.MCPToolSchemas.Learn(items)
+	}
+
+	if ruleSets := effectiveMCPListRuleSets(m.Spec, ctxGetSession(r), cfg); len(ruleSets) > 0 {
+		items = mcp.FilterItemsWithRuleSets(items, cfg.NameField, ruleSets)
+	}
+
+	itemsRaw, _ := json.Marshal(items)
+	m.writeResult(w, state.ID, map[string]json.RawMessage{cfg.ArrayKey: itemsRaw})
+}
+
+// listMember lists the items of a member, following its pages.
+func (m *MCPAggregationMiddleware) listMember(r *http.Request, member mcp.AggregationMember, sessionID string, state *httpctx.JSONRPCRoutingState, cfg *mcp.ListFilterConfig) ([]json.RawMessage, error) {
+	var (
+		items  []json.RawMessage
+		params json.RawMessage
+	)
+
+	for page := 0; page < maxMCPAggregationPages; page++ {
+		res, err := m.callMember(r, member, sessionID, state.Method, params, state.ID)
+		if err != nil {
+			return nil, err
+		}
+		if res.result == nil {
+			return nil, res.err()
+		}
+
+		var result map[string]json.RawMessage
+		if err := json.Unmarshal(res.result, &result); err != nil {
+			return nil, err
+		}
+
+		var pageItems []json.RawMessage
+		if raw, ok := result[cfg.ArrayKey]; ok {
+			if err := json.Unmarshal(raw, &pageItems); err != nil {
+				return nil, err
+			}
+		}
+		items = append(items, pageItems...)
+
+		var cursor string
+		if raw, ok := result["nextCursor"]; !ok || json.Unmarshal(raw, &cursor) != nil || cursor == "" {
+			return items, nil
+		}
+		if params, err = mcp.SetStringParam(nil, "cursor", cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("more than %d pages", maxMCPAggregationPages)
+}
+
+// call routes the request to the member owning the primitive named by the key
+// param, and relays the member's response.
+func (m *MCPAggregationMiddleware) call(w http.ResponseWriter, r *http.Request, state *httpctx.JSONRPCRoutingState, sessions map[string]string, key string) {
+	member, memberName, ok := mcp.ResolveAggregationMember(m.members(), state.PrimitiveName)
+	if !ok {
+		m.writeError(w, r, state.ID, http.StatusBadRequest, mcp.JSONRPCInvalidParams, fmt.Sprintf("unknown %s: %s", key, state.PrimitiveName))
+		return
+	}
+
+	params, err := mcp.SetStringParam(state.Params, key, memberName)
+	if err != nil {
+		m.writeError(w, r, state.ID, http.StatusBadRequest, mcp.JSONRPCInvalidParams, mcp.ErrMsgInvalidParams)
+		return
+	}
+
+	res, err := m.callMember(r, member, sessions[member.ID], state.Method, params, state.ID)
+	if err != nil {
+		m.memberLogger(member).WithError(err).Error("Failed to call MCP aggregation member")
+		m.writeError(w, r, state.ID, http.StatusBadGateway, mcp.JSONRPCInternalError, "member MCP server unavailable")
+		return
+	}
+
+	body := res.body
+	if m.Spec.MCPResultGuard != nil && mcp.IsGuardedMethod(state.Method) {
+		body = m.guard(r, res)
+	}
+
+	m.writeBody(w, res.status, res.header.Get(headerContentType), body)
+}
+
+// guard applies the content guard of the aggregation to the member response,
+// as the aggregation writes it itself instead of the response handlers.
+func (m *MCPAggregationMiddleware) guard(r *http.Request, res *mcpMemberResponse) []byte {
+	ses := ctxGetSession(r)
+
+	if !strings.HasPrefix(res.header.Get(headerContentType), "text/event-stream") {
+		if guarded := guardMCPMessage(m.Spec, r, ses, res.body); guarded != nil {
+			return guarded
+		}
+		return res.body
+	}
+
+	tap := NewSSETap(io.NopCloser(bytes.NewReader(res.body)), NewMCPContentGuardSSEHook(m.Spec, r, ses))
+	body, err := io.ReadAll(tap)
+	if err != nil {
+		m.Logger().WithError(err).Error("Failed to guard the MCP aggregation member events")
+		return nil
+	}
+	return body
+}
+
+// mcpMemberResponse is the response of a member to a forwarded request.
+type mcpMemberResponse struct {
+	status int
+	header http.Header
+	body   []byte
+
+	// result and rpcError are the parts of the JSON-RPC response, read from
+	// the first response event of a server-sent event stream.
+	result   json.RawMessage
+	rpcError json.RawMessage
+}
+
+// err describes the failed response of a member.
+func (res *mcpMemberResponse) err() error {
+	if res.rpcError != nil {
+		return fmt.Errorf("JSON-RPC error: %s", res.rpcError)
+	}
+	return fmt.Errorf("unexpected response status %d", res.status)
+}
+
+// callMember sends the JSON-RPC request to the member API through its middleware chain.
+func (m *MCPAggregationMiddleware) callMember(r *http.Request, member mcp.AggregationMember, sessionID, method string, params json.RawMessage, id any) (*mcpMemberResponse, error) {
+	body, err := json.Marshal(JSONRPCRequest{JSONRPC: apidef.JsonRPC20, Method: method, Params: params, ID: id})
+	if err != nil {
+		return nil, err
+	}
+
+	header := r.Header.Clone()
+	header.Set("Accept", "application/json, text/event-stream")
+	// The member responses are parsed and guarded, they must not be compressed.
+	header.Del("Accept-Encoding")
+	header.Del(mcp.HeaderSessionID)
+	if sessionID != "" {
+		header.Set(mcp.HeaderSessionID, sessionID)
+	}
+
+	rec, err := m.Gw.serveJSONRPCRequest(r, member.ID, "", header, body)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &mcpMemberResponse{
+		status: rec.Code,
+		header: rec.Header(),
+		body:   rec.Body.Bytes(),
+	}
+	res.result, res.rpcError = parseJSONRPCResponseBody(res.header.Get(headerContentType), res.body)
+
+	return res, nil
+}
+
+func (m *MCPAggregationMiddleware) memberLogger(member mcp.AggregationMember) *logrus.Entry {
+	return m.Logger().WithField("member_api_id", member.ID)
+}
+
+func (m *MCPAggregationMiddleware) writeResult(w http.ResponseWriter, id any, result map[string]json.RawMessage) {
+	resultRaw, _ := json.Marshal(result)
+	body, _ := json.Marshal(mcp.JSONRPCResponse{JSONRPC: apidef.JsonRPC20, ID: id, Result: resultRaw})
+	m.writeBody(w, http.StatusOK, contentTypeJSON, body)
+}
+
+func (m *MCPAggregationMiddleware) writeError(w http.ResponseWriter, r *http.Request, id any, httpCode, code int, message string) {
+	ctxSetJSONRPCErrorCode(r, code)
+
+	body, _ := json.Marshal(JSONRPCErrorResponse{
+		JSONRPC: apidef.JsonRPC20,
+		Error:   JSONRPCError{Code: code, Message: message},
+		ID:      id,
+	})
+	m.writeBody(w, httpCode, contentTypeJSON, body)
+}
+
+func (m *MCPAggregationMiddleware) writeBody(w http.ResponseWriter, status int, contentType string, body []byte) {
+	if contentType != "" {
+		w.Header().Set(headerContentType, contentType)
+	}
+	w.WriteHeader(status)
+	w.Write(body) //nolint:errcheck
+}
@@ -0,0 +1,206 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/apidef/oas"
+	"github.com/TykTechnologies/tyk/internal/mcp"
+	"github.com/TykTechnologies/tyk/test"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+// newMCPAggregationMember starts an MCP server with the tools, listed one per
+// page. With sse, it responds with server-sent events.
+func newMCPAggregationMember(t *testing.T, name string, sse bool, tools ...string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req JSONRPCRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ID == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		var params struct {
+			Name   string `json:"name"`
+			Cursor string `json:"cursor"`
+		}
+		_ = json.Unmarshal(req.Params, &params)
+
+		var result any
+		switch req.Method {
+		case mcp.MethodInitialize:
+			w.Header().Set(mcp.HeaderSessionID, name+"-session")
+			result = map[string]any{
+				"protocolVersion": "2025-06-18",
+				"capabilities":    map[string]any{"tools": map[string]any{"listChanged": true}, "logging": map[string]any{}},
+				"serverInfo":      map[string]any{"name": name, "version": "1.0.0"},
+			}
+		case mcp.MethodToolsList:
+			page := 0
+			fmt.Sscan(params.Cursor, &page) //nolint:errcheck
+			list := map[string]any{"tools": []map[string]any{{"name": tools[page], "description": name}}}
+			if page+1 < len(tools) {
+				list["nextCursor"] = fmt.Sprint(page + 1)
+			}
+			result = list
+		case mcp.MethodToolsCall:
+			text := fmt.Sprintf("%s:%s:%s", name, params.Name, r.Header.Get(mcp.HeaderSessionID))
+			result = map[string]any{"content": []map[string]any{{"type": "text", "text": text}}}
+		}
+
+		body := makeJSONRPCResponse(req.ID, result)
+		if sse {
+			w.Header().Set(headerContentType, "text/event-stream")
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", body)
+			return
+		}
+
+		w.Header().Set(headerContentType, contentTypeJSON)
+		w.Write(body) //nolint:errcheck
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func buildMCPAggregationSpec(apiID, listenPath, target string, keyless bool) *APISpec {
+	oasAPI := getSampleOASAPI()
+	tykExt := oasAPI.GetTykExtension()
+	tykExt.Info.ID = apiID
+	tykExt.Server.ListenPath = oas.ListenPath{Value: listenPath, Strip: true}
+	tykExt.Upstream.URL = target
+	oasAPI.SetTykExtension(tykExt)
+
+	var def apidef.APIDefinition
+	oasAPI.ExtractTo(&def)
+	def.IsOAS = true
+	def.UseKeylessAccess = keyless
+	def.MarkAsMCP()
+
+	return &APISpec{APIDefinition: &def, OAS: oasAPI}
+}
+
+func TestMCPAggregationMiddleware(t *testing.T) {
+	ts := StartTest(nil)
+	t.Cleanup(ts.Close)
+
+	github := newMCPAggregationMember(t, "github", false, "search", "create_issue")
+	jira := newMCPAggregationMember(t, "jira", true, "search")
+
+	aggregate := buildMCPAggregationSpec("aggregate", "/aggregate/", TestHttpAny, false)
+	aggregate.MCPAggregation = apidef.MCPAggregation{
+		Enabled: true,
+		Members: []apidef.MCPAggregationMember{
+			{APIID: "github", Prefix: "gh_"},
+			{APIID: "jira", Prefix: "jira_"},
+		},
+	}
+
+	ts.Gw.LoadAPI(
+		buildMCPAggregationSpec("github", "/github/", github.URL, true),
+		buildMCPAggregationSpec("jira", "/jira/", jira.URL, true),
+		aggregate,
+	)
+
+	_, key := ts.CreateSession(func(s *user.SessionState) {
+		s.AccessRights = map[string]user.AccessDefinition{
+			"aggregate": {
+				APIID: "aggregate",
+				MCPAccessRights: user.MCPAccessRights{
+					Tools: user.AccessControlRules{Blocked: []string{"gh_create_issue"}},
+				},
+			},
+		}
+	})
+
+	rpc := func(id any, method string, params map[string]any) map[string]any {
+		return map[string]any{"jsonrpc": "2.0", "id": id, "method": method, "params": params}
+	}
+
+	headers := map[string]string{
+		headerContentType: contentTypeJSON,
+		"Authorization":   key,
+	}
+
+	t.Run("initialize", func(t *testing.T) {
+		resp, err := ts.Run(t, test.TestCase{
+			Method: http.MethodPost, Path: "/aggregate/", Headers: headers,
+			Data: rpc(1, mcp.MethodInitialize, map[string]any{"protocolVersion": "2025-06-18"}),
+			Code: http.StatusOK,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]string{"github": "github-session", "jira": "jira-session"},
+			mcp.DecodeAggregateSessionID(resp.Header.Get(mcp.HeaderSessionID)))
+
+		var envelope struct {
+			Result struct {
+				Capabilities map[string]any    `json:"capabilities"`
+				ServerInfo   map[string]string `json:"serverInfo"`
+			} `json:"result"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&envelope))
+		assert.Equal(t, map[string]any{"tools": map[string]any{}}, envelope.Result.Capabilities)
+		assert.Equal(t, aggregate.Name, envelope.Result.ServerInfo["name"])
+	})
+
+	t.Run("tools/list merges the members and applies the access rights", func(t *testing.T) {
+		_, _ = ts.Run(t, test.TestCase{
+			Method: http.MethodPost, Path: "/aggregate/", Headers: headers,
+			Data: rpc(2, mcp.MethodToolsList, nil),
+			Code: http.StatusOK,
+			BodyMatchFunc: func(body []byte) bool {
+				return assert.Equal(t, []string{"gh_search", "jira_search"}, extractToolNames(t, body))
+			},
+		})
+	})
+
+	t.Run("tools/call is routed to the member", func(t *testing.T) {
+		sessionID := mcp.EncodeAggregateSessionID(map[string]string{"github": "github-session", "jira": "jira-session"})
+
+		callHeaders := map[string]string{mcp.HeaderSessionID: sessionID}
+		for name, value := range headers {
+			callHeaders[name] = value
+		}
+
+		_, _ = ts.Run(t, []test.TestCase{
+			{
+				Method: http.MethodPost, Path: "/aggregate/", Headers: callHeaders,
+				Data: rpc(3, mcp.MethodToolsCall, map[string]any{"name": "gh_search"}),
+				Code: http.StatusOK, BodyMatch: `github:search:github-session`,
+			},
+			{
+				Method: http.MethodPost, Path: "/aggregate/", Headers: callHeaders,
+				Data: rpc(4, mcp.MethodToolsCall, map[string]any{"name": "jira_search"}),
+				Code: http.StatusOK, BodyMatch: `jira:search:jira-session`,
+			},
+			{
+				Method: http.MethodPost, Path: "/aggregate/", Headers: callHeaders,
+				Data: rpc(5, mcp.MethodToolsCall, map[string]any{"name": "gh_create_issue"}),
+				Code: http.StatusForbidden,
+			},
+			{
+				Method: http.MethodPost, Path: "/aggregate/", Headers: callHeaders,
+				Data: rpc(6, mcp.MethodToolsCall, map[string]any{"name": "slack_post"}),
+				Code: http.StatusBadRequest,
+			},
+		}...)
+	})
+
+	t.Run("notifications are forwarded", func(t *testing.T) {
+		_, _ = ts.Run(t, test.TestCase{
+			Method: http.MethodPost, Path: "/aggregate/", Headers: headers,
+			Data: map[string]any{"jsonrpc": "2.0", "method": "notifications/initialized"},
+			Code: http.StatusAccepted,
+		})
+	})
+}
@@ -0,0 +1,164 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/internal/httpctx"
+	"github.com/TykTechnologies/tyk/internal/mcp"
+	"github.com/TykTechnologies/tyk/internal/mcp/pairing"
+	"github.com/TykTechnologies/tyk/internal/middleware"
+)
+
+// MCPToolValidationMiddleware validates the arguments of the MCP tools/call
+// requests against the input schema of the called tool. The schemas are learnt
+// from the tools/list responses and, for the REST-as-MCP proxies, from the tool
+// view derived for their x-tyk-mcp-server primitives. The learnt schemas are
+// kept across API reloads. When the schema of the called tool is unknown, e.g.
+// after a gateway restart, the tools/list of the upstream is fetched first. The
+// calls of the tools still unknown are forwarded unchecked, or rejected with
+// RejectUnknownTools.
+type MCPToolValidationMiddleware struct {
+	*BaseMiddleware
+}
+
+// Name returns the middleware name.
+func (m *MCPToolValidationMiddleware) Name() string {
+	return "MCPToolValidationMiddleware"
+}
+
+// EnabledForSpec returns true for the MCP APIs validating the tool calls.
+func (m *MCPToolValidationMiddleware) EnabledForSpec() bool {
+	return m.Spec.IsMCP() && m.Spec.JsonRpcVersion == apidef.JsonRPC20 &&
+		m.Spec.MCPToolValidation.Enabled && m.Spec.MCPToolSchemas != nil
+}
+
+// ProcessRequest rejects the tools/call requests with invalid arguments with a
+// JSON-RPC invalid params error listing the failing argument pointers.
+//
+//nolint:staticcheck // ST1008: middleware interface requires (error, int) return order
+func (m *MCPToolValidationMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ any) (error, int) {
+	state := httpctx.GetJSONRPCRoutingState(r)
+	if state == nil || state.Method != mcp.MethodToolsCall || state.PrimitiveName == "" {
+		return nil, http.StatusOK
+	}
+
+	// Validate once, on the final VEM pass.
+	if r.URL.Path == state.OriginalPath || !httpctx.IsRoutingComplete(r) {
+		return nil, http.StatusOK
+	}
+
+	m.seedDerivedToolSchema(state.PrimitiveName)
+
+	var params struct {
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(state.Params, &params); err != nil {
+		m.writeInvalidParams(w, r, state.ID, mcp.ErrMsgInvalidParamsType, nil)
+		return nil, middleware.StatusRespond
+	}
+
+	errs, known := m.Spec.MCPToolSchemas.Validate(state.PrimitiveName, params.Arguments)
+	if !known {
+		m.Spec.MCPToolSchemas.Fetch(func() {
+			m.fetchToolSchemas(r)
+		})
+		errs, known = m.Spec.MCPToolSchemas.Validate(state.PrimitiveName, params.Arguments)
+	}
+
+	if !known {
+		if !m.Spec.MCPToolValidation.RejectUnknownTools {
+			return nil, http.StatusOK
+		}
+
+		m.Logger().WithField("tool", state.PrimitiveName).Debug("MCP tool call rejected, the tool is unknown")
+		m.writeInvalidParams(w, r, state.ID, mcp.ErrMsgUnknownTool, map[string]any{"tool": state.PrimitiveName})
+		return nil, middleware.StatusRespond
+	}
+
+	if len(errs) == 0 {
+		return nil, http.StatusOK
+	}
+
+	m.Logger().WithField("tool", state.PrimitiveName).Debugf("MCP tool call arguments failed validation: %d errors", len(errs))
+	m.writeInvalidParams(w, r, state.ID, mcp.ErrMsgInvalidParams, map[string]any{"errors": errs})
+	return nil, middleware.StatusRespond
+}
+
+// fetchToolSchemas learns the input schemas of the tools from a tools/list
+// request sent through the API, with the headers of the calling request so that
+// it's authenticated and sent within the same MCP session.
+func (m *MCPToolValidationMiddleware) fetchToolSchemas(r *http.Request) {
+	header := r.Header.Clone()
+	header.Set("Accept", "application/json, text/event-stream")
+
+	body := []byte(`{"jsonrpc":"2.0","id":"tyk-tool-schemas","method":"tools/list"}`)
+	rec, err := m.Gw.serveJSONRPCRequest(r, m.Spec.APIID, "", header, body)
+	if err != nil {
+		m.Logger().WithError(err).Warn("Failed to fetch the MCP tools")
+		return
+	}
+
+	result, _ := parseJSONRPCResponseBody(rec.Header().Get(headerContentType), rec.Body.Bytes())
+	if result == nil {
+		m.Logger().WithField("status", rec.Code).Warn("Failed to fetch the MCP tools")
+		return
+	}
+
+	var list struct {
+		Tools []json.RawMessage `json:"tools"`
+	}
+	if err := json.Unmarshal(result, &list); err == nil {
+		m.Spec.MCPToolSchemas.Learn(list.Tools)
+	}
+}
+
+// seedDerivedToolSchema stores the input schema of the tool from the tool view
+// of a REST-as-MCP proxy. The view lives on the synthetic adapter of the paired
+// REST API, which is rebuilt on reload, so it is looked up per call.
+func (m *MCPToolValidationMiddleware) seedDerivedToolSchema(name string) {
+	if !m.Spec.IsPairedMCPAdapterProxy() {
+		return
+	}
+
+	_, restAPIID, ok := pairedMCPAdapterTarget(m.Spec.Proxy.TargetURL)
+	if !ok {
+		return
+	}
+
+	adapter := m.Gw.getApiSpec(pairing.CanonicalAdapterAPIID(restAPIID))
+	if adapter == nil || !adapter.IsSyntheticMCPAdapter() {
+		return
+	}
+
+	tool, ok := adapter.MCPAdapter.ToolViews[m.Spec.APIID].ToolByName(name)
+	if !ok || tool.InputSchema == nil {
+		return
+	}
+
+	schema, err := json.Marshal(tool.InputSchema)
+	if err != nil {
+		return
+	}
+
+	if err := m.Spec.MCPToolSchemas.Set(name, schema); err != nil {
+		m.Logger().WithError(err).WithField("tool", name).Warn("Invalid MCP tool input schema")
+	}
+}
+
+func (m *MCPToolValidationMiddleware) writeInvalidParams(w http.ResponseWriter, r *http.Request, id any, message string, data any) {
+	ctxSetJSONRPCErrorCode(r, mcp.JSONRPCInvalidParams)
+
+	w.Header().Set(headerContentType, contentTypeJSON)
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(JSONRPCErrorResponse{ //nolint:errcheck
+		JSONRPC: apidef.JsonRPC20,
+		Error: JSONRPCError{
+			Code:    mcp.JSONRPCInvalidParams,
+			Message: message,
+			Data:    data,
+		},
+		ID: id,
+	})
+}
@@ -0,0 +1,115 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/internal/mcp"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+// newMCPToolSchemaServer starts an MCP server listing a search tool requiring
+// a string query. With sse, it responds with server-sent events.
+func newMCPToolSchemaServer(t *testing.T, sse bool) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req JSONRPCRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var result any
+		switch req.Method {
+		case mcp.MethodToolsList:
+			result = map[string]any{"tools": []map[string]any{{
+				"name": "search",
+				"inputSchema": map[string]any{
+					"type":       "object",
+					"properties": map[string]any{"query": map[string]any{"type": "string"}, "limit": map[string]any{"type": "integer"}},
+					"required":   []string{"query"},
+				},
+			}}}
+		case mcp.MethodToolsCall:
+			result = map[string]any{"content": []map[string]any{{"type": "text", "text": "called"}}}
+		}
+
+		body := makeJSONRPCResponse(req.ID, result)
+		if sse {
+			w.Header().Set(headerContentType, "text/event-stream")
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", body)
+			return
+		}
+
+		w.Header().Set(headerContentType, contentTypeJSON)
+		w.Write(body) //nolint:errcheck
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestMCPToolValidationMiddleware(t *testing.T) {
+	ts := StartTest(nil)
+	t.Cleanup(ts.Close)
+
+	for _, sse := range []bool{false, true} {
+		t.Run(fmt.Sprintf("sse=%v", sse), func(t *testing.T) {
+			spec := buildMCPAggregationSpec("tool-validation", "/tool-validation/", newMCPToolSchemaServer(t, sse).URL, true)
+			spec.MCPToolValidation.Enabled = true
+			spec.MCPToolValidation.RejectUnknownTools = true
+			ts.Gw.LoadAPI(spec)
+
+			rpc := func(id int, method string, params map[string]any) map[string]any {
+				return map[string]any{"jsonrpc": "2.0", "id": id, "method": method, "params": params}
+			}
+			call := func(id int, arguments map[string]any) map[string]any {
+				return rpc(id, mcp.MethodToolsCall, map[string]any{"name": "search", "arguments": arguments})
+			}
+			headers := map[string]string{headerContentType: contentTypeJSON}
+
+			// The unknown schema is fetched from the upstream tools/list.
+			_, _ = ts.Run(t, []test.TestCase{
+				{Method: http.MethodPost, Path: "/tool-validation/", Headers: headers, Data: call(1, nil), Code: http.StatusBadRequest, BodyMatch: `"/query"`},
+				{Method: http.MethodPost, Path: "/tool-validation/", Headers: headers, Data: rpc(2, mcp.MethodToolsList, nil), Code: http.StatusOK},
+				{Method: http.MethodPost, Path: "/tool-validation/", Headers: headers, Data: call(3, map[string]any{"query": "bug", "limit": 5}), Code: http.StatusOK},
+				{
+					Method: http.MethodPost, Path: "/tool-validation/", Headers: headers,
+					Data: rpc(5, mcp.MethodToolsCall, map[string]any{"name": "missing"}),
+					Code: http.StatusBadRequest, BodyMatch: mcp.ErrMsgUnknownTool,
+				},
+			}...)
+
+			resp, err := ts.Run(t, test.TestCase{
+				Method: http.MethodPost, Path: "/tool-validation/", Headers: headers,
+				Data: call(4, map[string]any{"limit": "five"}),
+				Code: http.StatusBadRequest,
+			})
+			require.NoError(t, err)
+
+			var envelope struct {
+				Error struct {
+					Code int `json:"code"`
+					Data struct {
+						Errors []mcp.ArgumentError `json:"errors"`
+					} `json:"data"`
+				} `json:"error"`
+			}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&envelope))
+			assert.Equal(t, mcp.JSONRPCInvalidParams, envelope.Error.Code)
+
+			pointers := make([]string, 0, len(envelope.Error.Data.Errors))
+			for _, argErr := range envelope.Error.Data.Errors {
+				pointers = append(pointers, argErr.Pointer)
+			}
+			assert.ElementsMatch(t, []string{"/query", "/limit"}, pointers)
+		})
+	}
+}
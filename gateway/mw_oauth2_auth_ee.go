@@ -21,14 +21,18 @@ func getUpstreamOAuthMw(base *BaseMiddleware) TykMiddleware {
 	return WrapMiddleware(base, upstreamOAuthMw)
 }
 
-func getClientCredentialsStorageHandler(base *BaseMiddleware) *storage.RedisCluster {
-	handler := &storage.RedisCluster{KeyPrefix: "upstreamOAuthCC-", ConnectionHandler: base.Gw.StorageConnectionHandler}
-	handler.Connect()
-	return handler
+func getClientCredentialsStorageHandler(base *BaseMiddleware) upstreamoauth.Storage {
+	return newUpstreamOAuthStorage(base, "upstreamOAuthCC-")
+}
+
+func getPasswordStorageHandler(base *BaseMiddleware) upstreamoauth.Storage {
+	return newUpstreamOAuthStorage(base, "upstreamOAuthPW-")
 }
 
-func getPasswordStorageHandler(base *BaseMiddleware) *storage.RedisCluster {
-	handler := &storage.RedisCluster{KeyPrefix: "upstreamOAuthPW-", ConnectionHandler: base.Gw.StorageConnectionHandler}
+// newUpstreamOAuthStorage returns the storage of the upstream OAuth tokens,
+// both the Redis and the embedded storage support the locks it needs.
+func newUpstreamOAuthStorage(base *BaseMiddleware, keyPrefix string) upstreamoauth.Storage {
+	handler := base.Gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{KeyPrefix: keyPrefix})
 	handler.Connect()
-	return handler
+	return handler.(upstreamoauth.Storage)
 }
@@ -58,9 +58,7 @@ func getOAuth2ExchangeMw(base *BaseMiddleware) TykMiddleware {
 			if p.Cache != nil && p.Cache.Enabled {
 				// The cache key already carries the oauth2:exchange: namespace; the
 				// store uses raw-key ops, so no KeyPrefix/HashKeys is applied here.
-				store := &storage.RedisCluster{
-					ConnectionHandler: base.Gw.StorageConnectionHandler,
-				}
+				store := base.Gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{})
 				store.Connect()
 				cache = newRedisExchangeCache(store, base.Gw.GetConfig().Secret)
 				break
@@ -2,21 +2,28 @@ package gateway
 
 import (
 	"bufio"
+	"context"
 	"crypto/md5"
 	"encoding/base64"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"hash"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/TykTechnologies/murmur3"
+	"github.com/gorilla/mux"
 
 	"github.com/TykTechnologies/tyk-pump/analytics"
+	"github.com/TykTechnologies/tyk/header"
 	"github.com/TykTechnologies/tyk/internal/middleware"
 	"github.com/TykTechnologies/tyk/regexp"
 	"github.com/TykTechnologies/tyk/request"
@@ -25,14 +32,97 @@ import (
 
 const (
 	cachedResponseHeader = "x-tyk-cached-response"
+
+	// staleWarning and revalidationFailedWarning are the RFC 7234 warnings of
+	// responses served stale.
+	staleWarning              = `110 - "Response is Stale"`
+	revalidationFailedWarning = `111 - "Revalidation Failed"`
+
+	defaultRequestCoalescingTimeout = 10 * time.Second
+	// cacheLockPollInterval is how often a request waiting for another gateway
+	// checks whether the entry was cached.
+	cacheLockPollInterval = 50 * time.Millisecond
+
+	defaultLocalResponseCacheSize = 64 << 20
+
+	// varyMarker prefixes the cache entries of responses varying on request
+	// headers, listing the headers. The variants are cached under their own keys.
+	varyMarker = "vary:"
 )
 
+// varyKey returns the key of the variant of a cache entry for the values of
+// the request headers the response varies on.
+func varyKey(key string, names []string, h http.Header) string {
+	hash := md5.New()
+	for _, name := range names {
+		io.WriteString(hash, name+":"+strings.Join(h.Values(name), ",")+"\n")
+	}
+	return key + "-vary-" + hex.EncodeToString(hash.Sum(nil))
+}
+
+// parseVary returns the sorted canonical names of the headers listed in the
+// Vary header values.
+func parseVary(values []string) []string {
+	var names []string
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// localCacheKey is the key of a cache entry of the API in the local response
+// cache. API IDs can't contain the separator, so the keys of an API don't
+// share a prefix with the keys of another.
+func localCacheKey(apiID, key string) string {
+	return apiID + "/" + key
+}
+
+// getLocal returns a fresh entry from the local response cache, if enabled.
+func (m *RedisCacheMiddleware) getLocal(key string) (string, bool) {
+	if m.Gw.localResponseCache == nil {
+		return "", false
+	}
+	return m.Gw.localResponseCache.Get(localCacheKey(m.Spec.APIID, key))
+}
+
+// setLocal keeps a fresh entry in the local response cache until it expires.
+func (m *RedisCacheMiddleware) setLocal(key, cachedData, expiry string) {
+	if m.Gw.localResponseCache == nil {
+		return
+	}
+
+	i, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil {
+		return
+	}
+	m.Gw.localResponseCache.Set(localCacheKey(m.Spec.APIID, key), cachedData, time.Until(time.Unix(i, 0)))
+}
+
 // RedisCacheMiddleware is a caching middleware that will pull data from Redis instead of the upstream proxy
 type RedisCacheMiddleware struct {
 	*BaseMiddleware
 
 	store storage.Handler
 	sh    SuccessHandler
+
+	// revalidating holds the keys of the entries being refreshed in the background.
+	revalidating sync.Map
+	// flights holds the cache misses being fetched from the upstream by key.
+	flights sync.Map
+
+	tagPaths []cacheTagPath
+}
+
+// cacheTagPath is a compiled path template tagging cached responses.
+type cacheTagPath struct {
+	route *mux.Route
+	tags  []string
 }
 
 func (m *RedisCacheMiddleware) Name() string {
@@ -41,6 +131,38 @@ func (m *RedisCacheMiddleware) Name() string {
 
 func (m *RedisCacheMiddleware) Init() {
 	m.sh = SuccessHandler{m.BaseMiddleware}
+
+	m.tagPaths = nil
+	for _, tagPath := range m.Spec.CacheOptions.CacheTagPaths {
+		route := new(mux.Route).Path(tagPath.Path)
+		if err := route.GetError(); err != nil {
+			m.Logger().WithError(err).Errorf("Invalid cache tag path %q", tagPath.Path)
+			continue
+		}
+		m.tagPaths = append(m.tagPaths, cacheTagPath{route: route, tags: tagPath.Tags})
+	}
+}
+
+// pathTags returns the tags of the cache tag paths matching the path, with
+// the named segments of the templates replaced.
+func (m *RedisCacheMiddleware) pathTags(reqPath string) []string {
+	var tags []string
+	req := &http.Request{URL: &url.URL{Path: reqPath}}
+	for _, tagPath := range m.tagPaths {
+		var match mux.RouteMatch
+		if !tagPath.route.Match(req, &match) {
+			continue
+		}
+
+		for _, tag := range tagPath.tags {
+			for name, value := range match.Vars {
+				tag = strings.ReplaceAll(tag, "{"+name+"}", value)
+			}
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
 }
 
 func (m *RedisCacheMiddleware) EnabledForSpec() bool {
@@ -48,10 +170,36 @@ func (m *RedisCacheMiddleware) EnabledForSpec() bool {
 }
 
 func (m *RedisCacheMiddleware) CreateCheckSum(req *http.Request, keyName string, regex string, additionalKeyFromHeaders string) (string, error) {
+	return m.createCheckSum(req, req.URL.String(), keyName, regex, additionalKeyFromHeaders)
+}
+
+// templateCheckSum creates the cache key of an endpoint with a cache key
+// template. The key is made of the method, the path, the query params in
+// normalised order, the expanded template and the body. The consumer is only
+// part of the key if the template references it. It fails if a context or
+// session metadata variable of the template is missing, so that the requests
+// without it don't share a cached response.
+func (m *RedisCacheMiddleware) templateCheckSum(req *http.Request, template string, regex string) (string, error) {
+	if v, ok := m.Gw.unresolvedRequestVariable(req, template); ok {
+		return "", fmt.Errorf("cache key template variable %s is missing", v)
+	}
+
+	u := *req.URL
+	u.RawQuery = u.Query().Encode()
+
+	additionalKey := m.Gw.ReplaceTykVariables(req, template, false)
+	if fromHeaders := m.getCacheKeyFromHeaders(req); fromHeaders != "" {
+		additionalKey += "-" + fromHeaders
+	}
+
+	return m.createCheckSum(req, u.String(), "", regex, additionalKey)
+}
+
+func (m *RedisCacheMiddleware) createCheckSum(req *http.Request, reqURL string, keyName string, regex string, additionalKeyFromHeaders string) (string, error) {
 	h := md5.New()
 
 	// Compose key into string
-	key := req.Method + "-" + req.URL.String()
+	key := req.Method + "-" + reqURL
 	if additionalKeyFromHeaders != "" {
 		key = key + "-" + additionalKeyFromHeaders
 	}
@@ -147,6 +295,104 @@ type cacheOptions struct {
 	key                    string
 	cacheOnlyResponseCodes []int
 	timeout                int64
+	staleWhileRevalidate   int64
+	staleIfError           int64
+	// stale is the expired entry served in place of an upstream error.
+	stale string
+	// flight is set on the request coalescing the cache misses of the entry.
+	flight *cacheFlight
+	// locked is set once the distributed coalescing lock of the entry is held.
+	locked bool
+	// path is the request path relative to the listen path, indexed to purge
+	// entries by path.
+	path string
+	// tags are the tags of the matching cache tag paths.
+	tags []string
+	// baseKey is the key of the entry before the variant of a response
+	// varying on request headers is selected, header holds the request
+	// headers the variant is selected with.
+	baseKey string
+	header  http.Header
+	// revalidate is the expired entry the upstream is asked to validate, the
+	// client's conditional headers are replaced with its validators.
+	revalidate      string
+	ifNoneMatch     string
+	ifModifiedSince string
+}
+
+// addValidators turns the request into a conditional request with the
+// validators of the expired entry, for the upstream to answer 304 Not Modified
+// instead of the full response if the entry is still valid.
+func (m *RedisCacheMiddleware) addValidators(r *http.Request, options *cacheOptions, cachedData string) {
+	res, err := http.ReadResponse(bufio.NewReader(strings.NewReader(cachedData)), r)
+	if err != nil {
+		return
+	}
+	res.Body.Close()
+
+	etag, lastModified := res.Header.Get(header.ETag), res.Header.Get(header.LastModified)
+	if etag == "" && lastModified == "" {
+		return
+	}
+
+	options.revalidate = cachedData
+	options.ifNoneMatch, options.ifModifiedSince = conditionalHeaders(r)
+
+	r.Header.Del(header.IfNoneMatch)
+	r.Header.Del(header.IfModifiedSince)
+	if etag != "" {
+		r.Header.Set(header.IfNoneMatch, etag)
+	}
+	if lastModified != "" {
+		r.Header.Set(header.IfModifiedSince, lastModified)
+	}
+}
+
+// conditionalHeaders returns the If-None-Match and If-Modified-Since headers
+// sent by the client.
+func conditionalHeaders(r *http.Request) (ifNoneMatch, ifModifiedSince string) {
+	if options := ctxGetCacheOptions(r); options != nil && options.revalidate != "" {
+		return options.ifNoneMatch, options.ifModifiedSince
+	}
+	return r.Header.Get(header.IfNoneMatch), r.Header.Get(header.IfModifiedSince)
+}
+
+// isNotModified reports whether the validators of a response match the
+// conditional request headers. If-None-Match takes precedence over
+// If-Modified-Since and uses the weak comparison of RFC 7232.
+func isNotModified(ifNoneMatch, ifModifiedSince string, h http.Header) bool {
+	if ifNoneMatch != "" {
+		etag := h.Get(header.ETag)
+		if etag == "" {
+			return false
+		}
+
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ifModifiedSince == "" {
+		return false
+	}
+
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(h.Get(header.LastModified))
+	return err == nil && !modified.After(since)
+}
+
+// isStaleUntil reports whether the stale window at index i of the cache entry
+// timestamps is still open. Entries cached without stale windows only have
+// the expiry timestamp.
+func (m *RedisCacheMiddleware) isStaleUntil(timestamps []string, i int) bool {
+	return len(timestamps) > i && !m.isTimeStampExpired(timestamps[i])
 }
 
 // ProcessRequest will run any checks on the request on the way through the system, return an error to have the chain fail
@@ -179,17 +425,24 @@ func (m *RedisCacheMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Req
 		m.Logger().Debug("Not a cached path")
 		return nil, http.StatusOK
 	}
-	token := ctxGetAuthToken(r)
-
-	// No authentication data? use the IP.
-	if token == "" {
-		token = request.RealIP(r)
-	}
 
 	var retBlob string
-	key, err := m.CreateCheckSum(r, token, cacheKeyRegex, m.getCacheKeyFromHeaders(r))
+	var key string
+	var err error
+	if cacheMeta != nil && cacheMeta.CacheKeyTemplate != "" {
+		key, err = m.templateCheckSum(r, cacheMeta.CacheKeyTemplate, cacheKeyRegex)
+	} else {
+		token := ctxGetAuthToken(r)
+
+		// No authentication data? use the IP.
+		if token == "" {
+			token = request.RealIP(r)
+		}
+
+		key, err = m.CreateCheckSum(r, token, cacheKeyRegex, m.getCacheKeyFromHeaders(r))
+	}
 	if err != nil {
-		m.Logger().Debug("Error creating checksum. Skipping cache check")
+		m.Logger().WithError(err).Debug("Error creating checksum. Skipping cache check")
 		return nil, http.StatusOK
 	}
 
@@ -207,36 +460,112 @@ func (m *RedisCacheMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Req
 		}
 	}
 
-	ctxSetCacheOptions(r, &cacheOptions{
+	options := &cacheOptions{
 		key:                    key,
 		cacheOnlyResponseCodes: cacheOnlyResponseCodes,
 		timeout:                timeout,
-	})
+		staleWhileRevalidate:   m.Spec.CacheOptions.StaleWhileRevalidate,
+		staleIfError:           m.Spec.CacheOptions.StaleIfError,
+		path:                   m.Spec.StripListenPath(r.URL.Path),
+		baseKey:                key,
+		header:                 r.Header.Clone(),
+	}
+	options.tags = m.pathTags(options.path)
+	ctxSetCacheOptions(r, options)
+
+	// Fresh entries are served from memory first
+	if m.serveLocal(w, r, key, t1) {
+		return nil, middleware.StatusRespond
+	}
 
 	retBlob, err = m.store.GetKey(key)
+
+	// Select the variant of responses varying on request headers
+	if names, ok := strings.CutPrefix(retBlob, varyMarker); err == nil && ok {
+		key = varyKey(key, strings.Split(names, ","), r.Header)
+		options.key = key
+
+		if m.serveLocal(w, r, key, t1) {
+			return nil, middleware.StatusRespond
+		}
+
+		retBlob, err = m.store.GetKey(key)
+	}
+
 	if err != nil {
 		// Record not found, continue with the middleware chain
-		return nil, http.StatusOK
+		return m.coalesce(w, r, options, t1)
 	}
 
 	cachedData, timestamp, err := m.decodePayload(retBlob)
 	if err != nil {
 		// Tere was an issue with this cache entry - lets remove it:
 		m.store.DeleteKey(key)
-		return nil, http.StatusOK
+		return m.coalesce(w, r, options, t1)
+	}
+
+	if len(cachedData) == 0 {
+		m.store.DeleteKey(key)
+		return m.coalesce(w, r, options, t1)
+	}
+
+	// The expiry is followed by the end of the stale-while-revalidate and
+	// stale-if-error windows.
+	timestamps := strings.Split(timestamp, ",")
+	warning := ""
+	if m.isTimeStampExpired(timestamps[0]) {
+		switch {
+		case m.isStaleUntil(timestamps, 1):
+			warning = staleWarning
+			m.revalidate(r, options, cachedData, m.isStaleUntil(timestamps, 2))
+		case m.isStaleUntil(timestamps, 2):
+			// Go to the upstream, the entry is only served if it fails
+			options.stale = cachedData
+			m.addValidators(r, options, cachedData)
+			return m.coalesce(w, r, options, t1)
+		default:
+			m.store.DeleteKey(key)
+			m.addValidators(r, options, cachedData)
+			return m.coalesce(w, r, options, t1)
+		}
 	}
 
-	if m.isTimeStampExpired(timestamp) || len(cachedData) == 0 {
+	if err := m.writeCachedResponse(w, r, cachedData, t1, warning); err != nil {
+		m.Logger().WithError(err).Error("Could not create response object")
 		m.store.DeleteKey(key)
 		return nil, http.StatusOK
 	}
 
+	if warning == "" {
+		m.setLocal(key, cachedData, timestamps[0])
+	}
+
+	// Stop any further execution after we wrote cache out
+	return nil, middleware.StatusRespond
+}
+
+// serveLocal serves a fresh entry of the local response cache, if any.
+func (m *RedisCacheMiddleware) serveLocal(w http.ResponseWriter, r *http.Request, key string, t1 time.Time) bool {
+	cachedData, ok := m.getLocal(key)
+	if !ok {
+		return false
+	}
+
+	if err := m.writeCachedResponse(w, r, cachedData, t1, ""); err != nil {
+		m.Gw.localResponseCache.Delete(localCacheKey(m.Spec.APIID, key))
+		return false
+	}
+
+	return true
+}
+
+// writeCachedResponse writes a cached response to the client and records it
+// as a cache hit. A warning header is added to stale responses.
+func (m *RedisCacheMiddleware) writeCachedResponse(w http.ResponseWriter, r *http.Request, cachedData string, t1 time.Time, warning string) error {
 	bufData := bufio.NewReader(strings.NewReader(cachedData))
 	newRes, err := http.ReadResponse(bufData, r)
 	if err != nil {
-		m.Logger().WithError(err).Error("Could not create response object")
-		m.store.DeleteKey(key)
-		return nil, http.StatusOK
+		return err
 	}
 
 	nopCloseResponseBody(newRes)
@@ -248,15 +577,14 @@ func (m *RedisCacheMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Req
 
 	m.Gw.limitHeaderFactory(newRes.Header).SendQuotas(ctxGetSession(r), m.Spec.APIID)
 	newRes.Header.Set(cachedResponseHeader, "1")
+	if warning != "" {
+		newRes.Header.Set("Warning", warning)
+	}
 
 	copyHeader(w.Header(), newRes.Header, m.Gw.GetConfig().IgnoreCanonicalMIMEHeaderKey)
 
-	if reqEtag := r.Header.Get("If-None-Match"); reqEtag != "" {
-		if respEtag := newRes.Header.Get("Etag"); respEtag != "" {
-			if strings.Contains(reqEtag, respEtag) {
-				newRes.StatusCode = http.StatusNotModified
-			}
-		}
+	if ifNoneMatch, ifModifiedSince := conditionalHeaders(r); isNotModified(ifNoneMatch, ifModifiedSince, newRes.Header) {
+		newRes.StatusCode = http.StatusNotModified
 	}
 
 	w.WriteHeader(newRes.StatusCode)
@@ -265,7 +593,7 @@ func (m *RedisCacheMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Req
 	}
 
 	// Record analytics and observability signals
-	if !m.Spec.DoNotTrack {
+	if !m.Spec.DoNotTrack && !ctxGetDoNotTrack(r) {
 		ms := DurationToMillisecond(time.Since(t1))
 		latency := analytics.Latency{Total: int64(ms), Upstream: 0, Gateway: int64(ms)}
 		m.sh.RecordHit(r, latency, newRes.StatusCode, newRes, true)
@@ -273,10 +601,206 @@ func (m *RedisCacheMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Req
 		m.sh.Base().RecordMetrics(w, r, newRes.StatusCode, latency, newRes)
 	}
 
-	// Stop any further execution after we wrote cache out
+	return nil
+}
+
+// revalidate refreshes a stale cache entry in the background, sending a copy
+// of the request to the upstream. Only one request per entry is sent at a
+// time. If the entry can still be served on errors, an upstream error doesn't
+// replace it.
+func (m *RedisCacheMiddleware) revalidate(r *http.Request, options *cacheOptions, cachedData string, staleIfError bool) {
+	if _, loaded := m.revalidating.LoadOrStore(options.key, struct{}{}); loaded {
+		return
+	}
+
+	outreq := r.Clone(context.WithoutCancel(r.Context()))
+	if r.Body != nil {
+		body, err := readBody(r)
+		if err != nil {
+			m.revalidating.Delete(options.key)
+			m.Logger().WithError(err).Error("Could not read request body to revalidate cache")
+			return
+		}
+		outreq.Body = ioutil.NopCloser(strings.NewReader(string(body)))
+	}
+
+	refresh := *options
+	if staleIfError {
+		refresh.stale = cachedData
+	}
+	m.addValidators(outreq, &refresh, cachedData)
+	ctxSetCacheOptions(outreq, &refresh)
+	// Keep background requests out of the analytics
+	ctxSetDoNotTrack(outreq, true)
+
+	go func() {
+		defer m.revalidating.Delete(options.key)
+
+		m.Logger().WithField("key", options.key).Debug("Revalidating stale cache entry")
+		m.Proxy.ServeHTTP(&discardResponseWriter{}, outreq)
+	}()
+}
+
+// discardResponseWriter discards the response of a background request, which
+// is only sent to refresh the cache.
+type discardResponseWriter struct {
+	header http.Header
+}
+
+// Header returns the response headers.
+func (w *discardResponseWriter) Header() http.Header {
+	if w.header == nil {
+		w.header = http.Header{}
+	}
+	return w.header
+}
+
+// Write discards the response body.
+func (w *discardResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+// WriteHeader discards the status code.
+func (w *discardResponseWriter) WriteHeader(int) {}
+
+// cacheFlight is a cache miss fetched from the upstream by one request while
+// the concurrent requests for the same entry wait for it.
+type cacheFlight struct {
+	done chan struct{}
+	// response is the cached response, set if the upstream response was cached.
+	response string
+}
+
+// cacheLocker is implemented by the cache stores supporting distributed locks.
+type cacheLocker interface {
+	Lock(key string, timeout time.Duration) (bool, error)
+}
+
+func (m *RedisCacheMiddleware) coalescingTimeout() time.Duration {
+	if timeout := m.Spec.CacheOptions.RequestCoalescingTimeout; timeout > 0 {
+		return time.Duration(timeout) * time.Second
+	}
+	return defaultRequestCoalescingTimeout
+}
+
+func (m *RedisCacheMiddleware) lockKey(key string) string {
+	return "cache-lock-" + key
+}
+
+// coalesce sends only one request per cache miss to the upstream, within the
+// node and, with distributed coalescing, across the cluster. The concurrent
+// requests wait for its response and serve it. Requests go to the upstream
+// themselves once the coalescing timeout is reached or if the response
+// wasn't cached.
+func (m *RedisCacheMiddleware) coalesce(w http.ResponseWriter, r *http.Request, options *cacheOptions, t1 time.Time) (error, int) {
+	if !m.Spec.CacheOptions.EnableRequestCoalescing {
+		return nil, http.StatusOK
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), m.coalescingTimeout())
+	defer cancel()
+
+	flight := &cacheFlight{done: make(chan struct{})}
+	if existing, loaded := m.flights.LoadOrStore(options.key, flight); loaded {
+		flight = existing.(*cacheFlight)
+
+		select {
+		case <-flight.done:
+		case <-ctx.Done():
+			return nil, http.StatusOK
+		}
+
+		return m.writeCoalescedResponse(w, r, flight.response, t1)
+	}
+
+	// This request fetches the entry, it's released once the chain is done
+	options.flight = flight
+
+	if !m.Spec.CacheOptions.DistributedRequestCoalescing {
+		return nil, http.StatusOK
+	}
+
+	locker, ok := m.store.(cacheLocker)
+	if !ok {
+		return nil, http.StatusOK
+	}
+
+	locked, err := locker.Lock(m.lockKey(options.key), m.coalescingTimeout())
+	if err != nil || locked {
+		options.locked = locked
+		return nil, http.StatusOK
+	}
+
+	// Another gateway is fetching the entry, wait for it to be cached
+	flight.response = m.waitForEntry(ctx, options.key)
+	return m.writeCoalescedResponse(w, r, flight.response, t1)
+}
+
+// waitForEntry polls the cache store until a fresh entry is stored under the
+// key, returning the cached response.
+func (m *RedisCacheMiddleware) waitForEntry(ctx context.Context, key string) string {
+	ticker := time.NewTicker(cacheLockPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ""
+		case <-ticker.C:
+		}
+
+		retBlob, err := m.store.GetKey(key)
+		if err != nil {
+			continue
+		}
+
+		// The response varies, it may not be the variant of the request
+		if strings.HasPrefix(retBlob, varyMarker) {
+			return ""
+		}
+
+		cachedData, timestamp, err := m.decodePayload(retBlob)
+		if err != nil || len(cachedData) == 0 {
+			continue
+		}
+
+		if !m.isTimeStampExpired(strings.Split(timestamp, ",")[0]) {
+			return cachedData
+		}
+	}
+}
+
+// writeCoalescedResponse serves the response fetched by the coalescing
+// request, or lets the request go to the upstream if there is none.
+func (m *RedisCacheMiddleware) writeCoalescedResponse(w http.ResponseWriter, r *http.Request, cachedData string, t1 time.Time) (error, int) {
+	if cachedData == "" {
+		return nil, http.StatusOK
+	}
+
+	if err := m.writeCachedResponse(w, r, cachedData, t1, ""); err != nil {
+		m.Logger().WithError(err).Error("Could not create response object")
+		return nil, http.StatusOK
+	}
+
 	return nil, middleware.StatusRespond
 }
 
+// RequestDone releases the requests waiting for the cache miss fetched by the request.
+func (m *RedisCacheMiddleware) RequestDone(r *http.Request) {
+	options := ctxGetCacheOptions(r)
+	if options == nil || options.flight == nil {
+		return
+	}
+
+	if options.locked {
+		m.store.DeleteRawKey(m.lockKey(options.key))
+	}
+
+	m.flights.Delete(options.key)
+	close(options.flight.done)
+	options.flight = nil
+}
+
 func isSafeMethod(method string) bool {
 	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
 }
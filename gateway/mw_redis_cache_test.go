@@ -8,7 +8,9 @@ import (
 	"hash"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -26,6 +28,7 @@ import (
 	"github.com/TykTechnologies/tyk/header"
 	"github.com/TykTechnologies/tyk/internal/otel"
 	"github.com/TykTechnologies/tyk/internal/uuid"
+	"github.com/TykTechnologies/tyk/storage"
 	"github.com/TykTechnologies/tyk/test"
 	"github.com/TykTechnologies/tyk/user"
 )
@@ -590,3 +593,438 @@ func countAccessLogEntries(hook *logrustest.Hook) int {
 	}
 	return count
 }
+
+func TestRedisCacheMiddleware_Stale(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	var requests, failing int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		if atomic.LoadInt32(&failing) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = fmt.Fprintf(w, "response %d", n)
+	}))
+	defer upstream.Close()
+
+	loadAPI := func(targetURL string, staleWhileRevalidate, staleIfError int64) {
+		ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.APIID = "stale-cache-test"
+			spec.Proxy.ListenPath = "/"
+			spec.Proxy.TargetURL = targetURL
+			spec.CacheOptions.EnableCache = true
+			spec.CacheOptions.CacheAllSafeRequests = true
+			spec.CacheOptions.CacheTimeout = 1
+			spec.CacheOptions.StaleWhileRevalidate = staleWhileRevalidate
+			spec.CacheOptions.StaleIfError = staleIfError
+		})
+	}
+
+	// cache waits for a response to be cached and to expire, returning its body.
+	cache := func(t *testing.T, path string) string {
+		t.Helper()
+		atomic.StoreInt32(&failing, 0)
+
+		var body string
+		require.Eventually(t, func() bool {
+			resp, err := ts.Run(t, test.TestCase{Path: path})
+			if err != nil || resp.Header.Get(cachedResponseHeader) != "1" {
+				return false
+			}
+			b, err := io.ReadAll(resp.Body)
+			body = string(b)
+			return err == nil
+		}, 5*time.Second, 10*time.Millisecond, "response was not stored in cache")
+
+		time.Sleep(2 * time.Second)
+		return body
+	}
+
+	t.Run("stale-while-revalidate", func(t *testing.T) {
+		loadAPI(upstream.URL, 60, 0)
+		cached := cache(t, "/swr")
+		sent := atomic.LoadInt32(&requests)
+
+		_, _ = ts.Run(t, test.TestCase{
+			Path:         "/swr",
+			BodyMatch:    cached,
+			HeadersMatch: map[string]string{cachedResponseHeader: "1", "Warning": staleWarning},
+			Code:         http.StatusOK,
+		})
+
+		assert.Eventually(t, func() bool {
+			resp, err := ts.Run(t, test.TestCase{Path: "/swr"})
+			return err == nil && resp.Header.Get(cachedResponseHeader) == "1" && resp.Header.Get("Warning") == ""
+		}, 5*time.Second, 10*time.Millisecond, "the entry was not revalidated")
+		assert.Equal(t, sent+1, atomic.LoadInt32(&requests), "one request revalidates the entry")
+	})
+
+	t.Run("stale-if-error", func(t *testing.T) {
+		loadAPI(upstream.URL, 0, 60)
+		cached := cache(t, "/sie")
+
+		atomic.StoreInt32(&failing, 1)
+		_, _ = ts.Run(t, test.TestCase{
+			Path:         "/sie",
+			BodyMatch:    cached,
+			HeadersMatch: map[string]string{"Warning": revalidationFailedWarning},
+			Code:         http.StatusOK,
+		})
+	})
+
+	t.Run("stale-if-error on proxy error", func(t *testing.T) {
+		loadAPI(upstream.URL, 0, 60)
+		cached := cache(t, "/sie-proxy")
+
+		loadAPI("http://localhost:1", 0, 60)
+		_, _ = ts.Run(t, test.TestCase{
+			Path:         "/sie-proxy",
+			BodyMatch:    cached,
+			HeadersMatch: map[string]string{"Warning": revalidationFailedWarning},
+			Code:         http.StatusOK,
+		})
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		loadAPI(upstream.URL, 0, 0)
+		cache(t, "/expired")
+
+		atomic.StoreInt32(&failing, 1)
+		_, _ = ts.Run(t, test.TestCase{Path: "/expired", Code: http.StatusInternalServerError})
+	})
+}
+
+func TestRedisCacheMiddleware_RequestCoalescing(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	var requests int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		// Hold the response for the concurrent requests to wait for it
+		time.Sleep(300 * time.Millisecond)
+		_, _ = fmt.Fprintf(w, "response %d", n)
+	}))
+	defer upstream.Close()
+
+	for _, distributed := range []bool{false, true} {
+		t.Run(fmt.Sprintf("distributed=%v", distributed), func(t *testing.T) {
+			atomic.StoreInt32(&requests, 0)
+			ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+				spec.Proxy.ListenPath = "/"
+				spec.Proxy.TargetURL = upstream.URL
+				spec.CacheOptions.EnableCache = true
+				spec.CacheOptions.CacheAllSafeRequests = true
+				spec.CacheOptions.CacheTimeout = 60
+				spec.CacheOptions.EnableRequestCoalescing = true
+				spec.CacheOptions.DistributedRequestCoalescing = distributed
+			})
+
+			var wg sync.WaitGroup
+			for i := 0; i < 5; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, _ = ts.Run(t, test.TestCase{Path: "/coalesced", BodyMatch: "response 1", Code: http.StatusOK})
+				}()
+			}
+			wg.Wait()
+
+			assert.EqualValues(t, 1, atomic.LoadInt32(&requests), "only one request goes to the upstream")
+		})
+	}
+}
+
+func TestRedisCacheMiddleware_LocalResponseCache(t *testing.T) {
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.LocalResponseCache.Enabled = true
+	})
+	defer ts.Close()
+
+	var requests int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprintf(w, "response %d", atomic.AddInt32(&requests, 1))
+	}))
+	defer upstream.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "local-cache-test"
+		spec.Proxy.ListenPath = "/"
+		spec.Proxy.TargetURL = upstream.URL
+		spec.CacheOptions.EnableCache = true
+		spec.CacheOptions.CacheAllSafeRequests = true
+		spec.CacheOptions.CacheTimeout = 60
+	})
+
+	cached := map[string]string{cachedResponseHeader: "1"}
+	_, _ = ts.Run(t, test.TestCase{Path: "/local", BodyMatch: "response 1", Code: http.StatusOK})
+	assert.Greater(t, ts.Gw.localResponseCache.Size(), int64(0), "the response is kept in memory")
+
+	// Served from memory once deleted from Redis
+	store := storage.RedisCluster{IsCache: true, ConnectionHandler: ts.Gw.StorageConnectionHandler}
+	store.Connect()
+	require.Eventually(t, func() bool {
+		return store.DeleteScanMatch("cache-local-cache-test*")
+	}, 5*time.Second, 10*time.Millisecond, "response was not stored in cache")
+	_, _ = ts.Run(t, test.TestCase{Path: "/local", BodyMatch: "response 1", HeadersMatch: cached, Code: http.StatusOK})
+
+	_, _ = ts.Run(t, []test.TestCase{
+		{Method: http.MethodDelete, Path: "/tyk/cache/local-cache-test", AdminAuth: true, Code: http.StatusOK},
+		{Path: "/local", BodyMatch: "response 2", HeadersNotMatch: cached, Code: http.StatusOK},
+	}...)
+}
+
+func TestGateway_handlePurgeAPICache(t *testing.T) {
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.LocalResponseCache.Enabled = true
+	})
+	defer ts.Close()
+
+	local := ts.Gw.localResponseCache
+	local.Set(localCacheKey("purged-api", "purged"), "response", time.Minute)
+	local.Set(localCacheKey("purged-api", "kept"), "response", time.Minute)
+
+	ts.Gw.handlePurgeAPICache(`{"api_id":"purged-api","keys":["purged"]}`)
+
+	_, ok := local.Get(localCacheKey("purged-api", "purged"))
+	assert.False(t, ok, "the purged key is evicted")
+	_, ok = local.Get(localCacheKey("purged-api", "kept"))
+	assert.True(t, ok, "the other keys of the API are kept")
+}
+
+func TestGateway_evictLocalAPICache(t *testing.T) {
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.LocalResponseCache.Enabled = true
+	})
+	defer ts.Close()
+
+	local := ts.Gw.localResponseCache
+	local.Set(localCacheKey("abc", "key"), "response", time.Minute)
+	local.Set(localCacheKey("abc-def", "key"), "response", time.Minute)
+
+	ts.Gw.evictLocalAPICache("abc")
+
+	_, ok := local.Get(localCacheKey("abc", "key"))
+	assert.False(t, ok, "the responses of the API are evicted")
+	_, ok = local.Get(localCacheKey("abc-def", "key"))
+	assert.True(t, ok, "the responses of an API with a prefixed ID are kept")
+}
+
+func Test_isNotModified(t *testing.T) {
+	lastModified := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := http.Header{}
+	h.Set(header.ETag, `W/"v1"`)
+	h.Set(header.LastModified, lastModified.Format(http.TimeFormat))
+
+	testcases := []struct {
+		name            string
+		ifNoneMatch     string
+		ifModifiedSince string
+		want            bool
+	}{
+		{name: "no conditions"},
+		{name: "matching etag", ifNoneMatch: `"v1"`, want: true},
+		{name: "etag in list", ifNoneMatch: `"v0", W/"v1"`, want: true},
+		{name: "any etag", ifNoneMatch: "*", want: true},
+		{name: "other etag", ifNoneMatch: `"v2"`},
+		{name: "etag takes precedence", ifNoneMatch: `"v2"`, ifModifiedSince: lastModified.Format(http.TimeFormat)},
+		{name: "not modified since", ifModifiedSince: lastModified.Add(time.Hour).Format(http.TimeFormat), want: true},
+		{name: "modified since", ifModifiedSince: lastModified.Add(-time.Hour).Format(http.TimeFormat)},
+		{name: "invalid date", ifModifiedSince: "yesterday"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, isNotModified(tc.ifNoneMatch, tc.ifModifiedSince, h))
+		})
+	}
+}
+
+func TestRedisCacheMiddleware_ConditionalRequests(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	var revalidated int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/generated" {
+			_, _ = w.Write([]byte("body"))
+			return
+		}
+
+		w.Header().Set(header.ETag, `"v1"`)
+		if r.Header.Get(header.IfNoneMatch) == `"v1"` {
+			atomic.AddInt32(&revalidated, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = w.Write([]byte("body"))
+	}))
+	defer upstream.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+		spec.Proxy.TargetURL = upstream.URL
+		spec.CacheOptions.EnableCache = true
+		spec.CacheOptions.CacheAllSafeRequests = true
+		spec.CacheOptions.CacheTimeout = 1
+		spec.CacheOptions.GenerateWeakETags = true
+	})
+
+	cached := map[string]string{cachedResponseHeader: "1"}
+	cache := func(t *testing.T, path string) *http.Response {
+		t.Helper()
+
+		var resp *http.Response
+		require.Eventually(t, func() bool {
+			var err error
+			resp, err = ts.Run(t, test.TestCase{Path: path})
+			return err == nil && resp.Header.Get(cachedResponseHeader) == "1"
+		}, 5*time.Second, 10*time.Millisecond, "response was not stored in cache")
+
+		return resp
+	}
+
+	t.Run("revalidate expired entry", func(t *testing.T) {
+		cache(t, "/revalidate")
+		time.Sleep(2 * time.Second)
+
+		_, _ = ts.Run(t, test.TestCase{Path: "/revalidate", BodyMatch: "body", HeadersNotMatch: cached, Code: http.StatusOK})
+		assert.EqualValues(t, 1, atomic.LoadInt32(&revalidated), "the upstream validates the entry")
+
+		_, _ = ts.Run(t, test.TestCase{Path: "/revalidate", Headers: map[string]string{header.IfNoneMatch: `"v1"`}, Code: http.StatusNotModified})
+	})
+
+	t.Run("weak etag", func(t *testing.T) {
+		resp := cache(t, "/generated")
+		etag := resp.Header.Get(header.ETag)
+		assert.True(t, strings.HasPrefix(etag, `W/"`), "a weak ETag is generated")
+
+		_, _ = ts.Run(t, test.TestCase{Path: "/generated", Headers: map[string]string{header.IfNoneMatch: etag}, Code: http.StatusNotModified})
+	})
+}
+
+func Test_parseVary(t *testing.T) {
+	assert.Equal(t, []string{"Accept-Language", "X-Tenant"}, parseVary([]string{"x-tenant, Accept-Language", "accept-language"}))
+	assert.Equal(t, []string{"*"}, parseVary([]string{"*"}))
+	assert.Empty(t, parseVary(nil))
+}
+
+func TestRedisCacheMiddleware_Vary(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	var requests int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/any" {
+			w.Header().Set(header.Vary, "*")
+		} else {
+			w.Header().Set(header.Vary, "Accept-Language")
+		}
+		_, _ = fmt.Fprintf(w, "%s %d", r.Header.Get("Accept-Language"), atomic.AddInt32(&requests, 1))
+	}))
+	defer upstream.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+		spec.Proxy.TargetURL = upstream.URL
+		spec.CacheOptions.EnableCache = true
+		spec.CacheOptions.CacheAllSafeRequests = true
+		spec.CacheOptions.CacheTimeout = 60
+	})
+
+	cached := map[string]string{cachedResponseHeader: "1"}
+	cache := func(t *testing.T, path, language string) string {
+		t.Helper()
+
+		var body string
+		require.Eventually(t, func() bool {
+			resp, err := ts.Run(t, test.TestCase{Path: path, Headers: map[string]string{"Accept-Language": language}})
+			if err != nil || resp.Header.Get(cachedResponseHeader) != "1" {
+				return false
+			}
+			b, _ := io.ReadAll(resp.Body)
+			body = string(b)
+			return true
+		}, 5*time.Second, 10*time.Millisecond, "response was not stored in cache")
+
+		return body
+	}
+
+	t.Run("variants", func(t *testing.T) {
+		en := cache(t, "/vary", "en")
+		assert.True(t, strings.HasPrefix(en, "en "), "the variant of the request is served")
+
+		fr := cache(t, "/vary", "fr")
+		assert.True(t, strings.HasPrefix(fr, "fr "), "the variant of the request is served")
+
+		_, _ = ts.Run(t, test.TestCase{Path: "/vary", Headers: map[string]string{"Accept-Language": "en"}, BodyMatch: en, HeadersMatch: cached, Code: http.StatusOK})
+	})
+
+	t.Run("vary on anything", func(t *testing.T) {
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: "/any", HeadersNotMatch: cached, Code: http.StatusOK, Delay: 100 * time.Millisecond},
+			{Path: "/any", HeadersNotMatch: cached, Code: http.StatusOK},
+		}...)
+	})
+}
+
+func TestRedisCacheMiddleware_CacheKeyTemplate(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	var requests int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprintf(w, "response %d", atomic.AddInt32(&requests, 1))
+	}))
+	defer upstream.Close()
+
+	api := ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+		spec.Proxy.TargetURL = upstream.URL
+		spec.UseKeylessAccess = false
+		spec.EnableContextVars = true
+		spec.CacheOptions.EnableCache = true
+		spec.CacheOptions.CacheTimeout = 60
+		UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+			v.ExtendedPaths.AdvanceCacheConfig = []apidef.CacheMeta{{
+				Method:           http.MethodGet,
+				Path:             "/tenant",
+				CacheKeyTemplate: "$tyk_context.headers_X_Tenant",
+			}}
+		})
+	})[0]
+
+	createKey := func() string {
+		_, key := ts.CreateSession(func(s *user.SessionState) {
+			s.AccessRights = map[string]user.AccessDefinition{
+				api.APIID: {APIName: api.Name, APIID: api.APIID},
+			}
+		})
+		return key
+	}
+	key1, key2 := createKey(), createKey()
+
+	cached := map[string]string{cachedResponseHeader: "1"}
+	var body string
+	require.Eventually(t, func() bool {
+		resp, err := ts.Run(t, test.TestCase{Path: "/tenant?b=2&a=1", Headers: map[string]string{header.Authorization: key1, "X-Tenant": "a"}})
+		if err != nil || resp.Header.Get(cachedResponseHeader) != "1" {
+			return false
+		}
+		b, _ := io.ReadAll(resp.Body)
+		body = string(b)
+		return true
+	}, 5*time.Second, 10*time.Millisecond, "response was not stored in cache")
+
+	_, _ = ts.Run(t, []test.TestCase{
+		// Shared by the consumers of the tenant, whatever the order of the query params
+		{Path: "/tenant?a=1&b=2", Headers: map[string]string{header.Authorization: key2, "X-Tenant": "a"}, BodyMatch: body, HeadersMatch: cached, Code: http.StatusOK},
+		{Path: "/tenant?a=1&b=2", Headers: map[string]string{header.Authorization: key1, "X-Tenant": "b"}, HeadersNotMatch: cached, Code: http.StatusOK},
+		// Not cached without the tenant
+		{Path: "/tenant", Headers: map[string]string{header.Authorization: key1}, HeadersNotMatch: cached, Code: http.StatusOK},
+		{Path: "/tenant", Headers: map[string]string{header.Authorization: key2}, HeadersNotMatch: cached, Code: http.StatusOK, Delay: 100 * time.Millisecond},
+		{Path: "/tenant", Headers: map[string]string{header.Authorization: key2}, HeadersNotMatch: cached, Code: http.StatusOK},
+	}...)
+}
@@ -268,6 +268,20 @@ func (gw *Gateway) ReplaceTykVariables(r *http.Request, in string, escape bool)
 	return in
 }
 
+// unresolvedRequestVariable returns the first context or session metadata
+// variable of the template `in` which is missing or empty for the request.
+func (gw *Gateway) unresolvedRequestVariable(r *http.Request, in string) (string, bool) {
+	for _, match := range []*regexp.Regexp{contextMatch, metaMatch} {
+		for _, v := range match.FindAllString(in, -1) {
+			if gw.ReplaceTykVariables(r, v, false) == "" {
+				return v, true
+			}
+		}
+	}
+
+	return "", false
+}
+
 func (gw *Gateway) replaceVariables(in string, vars []string, vals map[string]interface{}, label string, escape bool) string {
 
 	emptyStringFn := func(key, in, val string) string {
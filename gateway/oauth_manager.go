@@ -1189,13 +1189,25 @@ func (r *RedisOsinStorageInterface) SetUser(username string, session *user.Sessi
 
 }
 
+// oauthPurgeStore is implemented by the stores which can purge the lapsed oauth tokens.
+type oauthPurgeStore interface {
+	storage.LockHandler
+	storage.ScanKeysHandler
+	storage.RemoveSortedSetRangeHandler
+}
+
 func (gw *Gateway) purgeLapsedOAuthTokens() error {
 	if gw.GetConfig().OauthTokenExpiredRetainPeriod <= 0 {
 		return nil
 	}
 
-	redisCluster := &storage.RedisCluster{KeyPrefix: "", HashKeys: false, ConnectionHandler: gw.StorageConnectionHandler}
-	redisCluster.Connect()
+	store := gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{KeyPrefix: "", HashKeys: false})
+	store.Connect()
+
+	redisCluster, ok := store.(oauthPurgeStore)
+	if !ok {
+		return errors.New("storage doesn't support purging oauth tokens")
+	}
 
 	ok, err := redisCluster.Lock("oauth-purge-lock", time.Minute)
 	if err != nil {
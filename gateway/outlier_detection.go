@@ -0,0 +1,76 @@
+package gateway
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/TykTechnologies/tyk/internal/loadbalancer"
+)
+
+// newOutlierDetector creates the passive health checker of a load balanced API.
+// Ejected targets are skipped by nextTarget and fire the same HostDown and HostUp
+// events as the uptime tests do. Ejections are local to this gateway.
+func newOutlierDetector(spec *APISpec) *loadbalancer.OutlierDetector {
+	conf := spec.Proxy.LoadBalancing.PassiveHealthCheck
+
+	detector := loadbalancer.NewOutlierDetector(loadbalancer.OutlierConfig{
+		ErrorRatio:      conf.ErrorRatio,
+		MinRequests:     conf.MinRequests,
+		Window:          time.Duration(conf.Window) * time.Second,
+		EjectionTime:    time.Duration(conf.EjectionTime) * time.Second,
+		MaxEjectionTime: time.Duration(conf.MaxEjectionTime) * time.Second,
+	})
+
+	detector.OnEject = func(target string, failed, total int) {
+		log.WithFields(logrus.Fields{
+			"prefix": "passive-health-check",
+			"api_id": spec.APIID,
+		}).Warningf("Ejecting host %s, %d of %d requests failed", target, failed, total)
+
+		spec.FireEvent(EventHOSTDOWN, EventHostStatusMeta{
+			EventMetaDefault: EventMetaDefault{
+				Message: fmt.Sprintf("Passive health check failed, %d of %d requests failed", failed, total),
+			},
+			HostInfo: passiveHealthReport(spec, target),
+		})
+	}
+
+	detector.OnRecover = func(target string) {
+		log.WithFields(logrus.Fields{
+			"prefix": "passive-health-check",
+			"api_id": spec.APIID,
+		}).Info("Returning host to rotation: ", target)
+
+		spec.FireEvent(EventHOSTUP, EventHostStatusMeta{
+			EventMetaDefault: EventMetaDefault{Message: "Passive health check ejection expired"},
+			HostInfo:         passiveHealthReport(spec, target),
+		})
+	}
+
+	return detector
+}
+
+// passiveHealthReport describes an ejected target in the shape of an uptime test report.
+func passiveHealthReport(spec *APISpec, target string) HostHealthReport {
+	checkURL := EnsureTransport(target, spec.Protocol)
+
+	var host string
+	if u, err := url.Parse(checkURL); err == nil {
+		host = u.Host
+	}
+
+	return HostHealthReport{
+		HostData: HostData{
+			CheckURL: checkURL,
+			Protocol: spec.Protocol,
+			MetaData: map[string]string{
+				UnHealthyHostMetaDataTargetKey: checkURL,
+				UnHealthyHostMetaDataAPIKey:    spec.APIID,
+				UnHealthyHostMetaDataHostKey:   host,
+			},
+		},
+	}
+}
@@ -0,0 +1,67 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/internal/loadbalancer"
+)
+
+func TestNextTarget_PassiveHealthCheck(t *testing.T) {
+	gw := &Gateway{}
+
+	spec := &APISpec{APIDefinition: &apidef.APIDefinition{APIID: "api"}}
+	spec.Proxy.EnableLoadBalancing = true
+	spec.Proxy.Targets = []string{"a.test", "b.test"}
+	spec.Proxy.StructuredTargetList = apidef.NewHostListFromList(spec.Proxy.Targets)
+	spec.Proxy.LoadBalancing.PassiveHealthCheck = apidef.PassiveHealthCheck{
+		Enabled:      true,
+		ErrorRatio:   0.5,
+		MinRequests:  2,
+		EjectionTime: 60,
+	}
+	spec.LoadBalancer = loadbalancer.New(loadbalancer.RoundRobin)
+	spec.OutlierDetector = newOutlierDetector(spec)
+
+	events := make(chan config.EventMessage, 1)
+	spec.EventPaths = map[apidef.TykEvent][]config.TykEventHandler{
+		EventHOSTDOWN: {&testEventHandler{func(em config.EventMessage) {
+			events <- em
+		}}},
+	}
+
+	spec.OutlierDetector.Record("a.test", true)
+	spec.OutlierDetector.Record("a.test", true)
+
+	select {
+	case em := <-events:
+		meta, ok := em.Meta.(EventHostStatusMeta)
+		require.True(t, ok)
+		assert.Equal(t, "http://a.test", meta.HostInfo.CheckURL)
+		assert.Equal(t, "api", meta.HostInfo.MetaData[UnHealthyHostMetaDataAPIKey])
+		assert.Equal(t, "a.test", meta.HostInfo.MetaData[UnHealthyHostMetaDataHostKey])
+	case <-time.After(time.Second):
+		t.Fatal("HostDown event was not fired")
+	}
+
+	for i := 0; i < 4; i++ {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		host, err := gw.nextTarget(spec.Proxy.StructuredTargetList, spec, r)
+		require.NoError(t, err)
+		assert.Equal(t, "http://b.test", host)
+		assert.Equal(t, "b.test", ctxGetLoadBalancedTarget(r))
+	}
+
+	spec.OutlierDetector.Record("b.test", true)
+	spec.OutlierDetector.Record("b.test", true)
+
+	_, err := gw.nextTarget(spec.Proxy.StructuredTargetList, spec, nil)
+	assert.Error(t, err)
+}
@@ -355,7 +355,7 @@ func (gw *Gateway) dialWithServiceDiscovery(spec *APISpec, dial dialFn) dialFn {
 			log.Debug("[PROXY] [SERVICE DISCOVERY] received host list ", hostList.All())
 			fallthrough // implies load balancing, with replaced host list
 		case spec.Proxy.EnableLoadBalancing:
-			host, err := gw.nextTarget(hostList, spec)
+			host, err := gw.nextTarget(hostList, spec, nil)
 			if err != nil {
 				log.Error("[PROXY] [LOAD BALANCING] ", err)
 				host = allHostsDownURL
@@ -17,8 +17,9 @@ func (gw *Gateway) newRedisHook() *redisChannelHook {
 	hook := &redisChannelHook{}
 	hook.formatter = new(logrus.JSONFormatter)
 	hook.notifier.channel = "dashboard.ui.messages"
-	hook.notifier.store = &storage.RedisCluster{KeyPrefix: "gateway-notifications:", ConnectionHandler: gw.StorageConnectionHandler}
-	hook.notifier.store.Connect()
+	store := gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{KeyPrefix: "gateway-notifications:"})
+	store.Connect()
+	hook.notifier.store = store
 	return hook
 }
 
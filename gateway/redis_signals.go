@@ -14,7 +14,9 @@ import (
 
 	temporalmodel "github.com/TykTechnologies/storage/temporal/model"
 
+	"github.com/TykTechnologies/tyk/config"
 	"github.com/TykTechnologies/tyk/internal/crypto"
+	"github.com/TykTechnologies/tyk/internal/pubsub"
 	"github.com/TykTechnologies/tyk/storage"
 	"github.com/TykTechnologies/tyk/storage/kv"
 )
@@ -45,6 +47,12 @@ const (
 	NoticeUserKeyReset              NotificationCommand = "UserKeyReset"
 	NoticeInvalidateJWKSCacheForAPI NotificationCommand = "InvalidateJWKSCacheForAPI"
 	NoticeClientIdPChanged          NotificationCommand = "ClientIdPChanged"
+	// NoticePurgeAPICache is the command with which the cached responses of an API purged by tag, path or key are
+	// evicted from the local response cache of the other gateways.
+	NoticePurgeAPICache NotificationCommand = "PurgeAPICache"
+	// NoticeEvictLocalAPICache is the command with which the cached responses of an API invalidated from the
+	// shared store are evicted from the local response cache of the other gateways.
+	NoticeEvictLocalAPICache NotificationCommand = "EvictLocalAPICache"
 )
 
 // Notification is a type that encodes a message published to a pub sub channel (shared between implementations)
@@ -62,14 +70,39 @@ func (n *Notification) Sign() {
 	n.Signature = hex.EncodeToString(hash[:])
 }
 
+// newMessageBus returns the message bus cluster notifications are sent over.
+// It falls back to Redis pub/sub if the configured bus can't be set up.
+func (gw *Gateway) newMessageBus() pubsub.Bus {
+	conf := gw.GetConfig().MessageBus
+
+	switch conf.Type {
+	case "", config.MessageBusRedis:
+	case config.MessageBusNATS:
+		bus, err := pubsub.NewNATS(gw.ctx, conf.NATS)
+		if err == nil {
+			pubSubLog.Info("Using NATS message bus for cluster notifications")
+			return bus
+		}
+		pubSubLog.WithError(err).Error("Could not connect to NATS, falling back to Redis pub/sub")
+	default:
+		pubSubLog.Warningf("Unknown message bus type %q, falling back to Redis pub/sub", conf.Type)
+	}
+
+	store := gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{})
+	store.Connect()
+	return store
+}
+
 func (gw *Gateway) startPubSubLoop() {
-	cacheStore := storage.RedisCluster{ConnectionHandler: gw.StorageConnectionHandler}
-	cacheStore.Connect()
+	bus := gw.MainNotifier.store
+	if bus == nil {
+		bus = gw.newMessageBus()
+	}
 
-	message := "Connection to Redis failed, reconnect in 10s"
+	message := "Connection to the message bus failed, reconnect in 10s"
 
 	for {
-		err := cacheStore.StartPubSubHandler(gw.ctx, RedisPubSubChannel, func(v interface{}) {
+		err := bus.StartPubSubHandler(gw.ctx, RedisPubSubChannel, func(v interface{}) {
 			gw.handleRedisEvent(v, nil, nil)
 		})
 
@@ -159,6 +192,10 @@ func (gw *Gateway) handleRedisEvent(v interface{}, handled func(NotificationComm
 		if ok := gw.invalidateAPICache(notif.Payload); !ok {
 			log.WithError(err).Errorf("cache invalidation failed for: %s", notif.Payload)
 		}
+	case NoticePurgeAPICache:
+		gw.handlePurgeAPICache(notif.Payload)
+	case NoticeEvictLocalAPICache:
+		gw.evictLocalAPICache(notif.Payload)
 	case NoticeInvalidateJWKSCacheForAPI:
 		gw.invalidateJWKSCacheByAPIID(notif.Payload)
 	case NoticeClientIdPChanged:
@@ -238,9 +275,9 @@ func isPayloadSignatureValid(notification Notification) bool {
 	return false
 }
 
-// RedisNotifier will use redis pub/sub channels to send notifications
+// RedisNotifier will use the message bus, Redis pub/sub channels by default, to send notifications
 type RedisNotifier struct {
-	store   *storage.RedisCluster
+	store   pubsub.Bus
 	channel string
 	*Gateway
 }
@@ -1,13 +1,22 @@
 package gateway
 
 import (
+	"bufio"
 	"bytes"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"math"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
+	"unicode"
 
+	"github.com/TykTechnologies/tyk/header"
 	"github.com/TykTechnologies/tyk/storage"
 	"github.com/TykTechnologies/tyk/user"
 )
@@ -15,8 +24,24 @@ import (
 const (
 	upstreamCacheHeader    = "x-tyk-cache-action-set"
 	upstreamCacheTTLHeader = "x-tyk-cache-action-set-ttl"
+
+	// cachePathIndex is the sorted set of the cached entries of an API, as
+	// "path key" members scored by their expiry, used to purge entries by path.
+	cachePathIndex = "-index-paths"
 )
 
+// cacheTagIndex is the sorted set of the keys of the cached entries with a
+// tag, scored by their expiry.
+func cacheTagIndex(tag string) string {
+	return "-index-tag-" + tag
+}
+
+// cacheEntryTags is the key of the tags of a cached entry, used to remove the
+// entry from the tag indexes when it's purged by path.
+func cacheEntryTags(key string) string {
+	return "-index-entry-tags-" + key
+}
+
 // ResponseCacheMiddleware is a caching middleware that will pull data from Redis instead of the upstream proxy
 type ResponseCacheMiddleware struct {
 	BaseTykResponseHandler
@@ -53,6 +78,146 @@ func (m *ResponseCacheMiddleware) encodePayload(payload string, timestamp int64)
 	return sEnc + "|" + fmt.Sprint(timestamp)
 }
 
+// encodeStalePayload encodes the payload with the end of its stale-while-revalidate
+// and stale-if-error windows following the expiry timestamp.
+func (m *ResponseCacheMiddleware) encodeStalePayload(payload string, timestamp, staleWhileRevalidate, staleIfError int64) string {
+	return m.encodePayload(payload, timestamp) + "," + fmt.Sprint(staleWhileRevalidate) + "," + fmt.Sprint(staleIfError)
+}
+
+// parseStaleDirectives reads the RFC 5861 stale-while-revalidate and
+// stale-if-error directives of a Cache-Control header, defaulting to the
+// given windows.
+func parseStaleDirectives(cacheControl string, staleWhileRevalidate, staleIfError int64) (int64, int64) {
+	for _, directive := range strings.Split(cacheControl, ",") {
+		name, value, found := strings.Cut(strings.TrimSpace(directive), "=")
+		if !found {
+			continue
+		}
+
+		seconds, err := strconv.ParseInt(strings.Trim(value, `"`), 10, 64)
+		if err != nil || seconds < 0 {
+			continue
+		}
+
+		switch strings.ToLower(name) {
+		case "stale-while-revalidate":
+			staleWhileRevalidate = seconds
+		case "stale-if-error":
+			staleIfError = seconds
+		}
+	}
+
+	return staleWhileRevalidate, staleIfError
+}
+
+// serveStale replaces an upstream error with the stale cache entry of the request.
+func (m *ResponseCacheMiddleware) serveStale(res *http.Response, r *http.Request, stale string) bool {
+	staleRes, err := http.ReadResponse(bufio.NewReader(strings.NewReader(stale)), r)
+	if err != nil {
+		m.logger().WithError(err).Error("could not read stale cache entry")
+		return false
+	}
+
+	nopCloseResponseBody(staleRes)
+	if res.Body != nil {
+		res.Body.Close()
+	}
+
+	staleRes.Header.Set(cachedResponseHeader, "1")
+	staleRes.Header.Set("Warning", revalidationFailedWarning)
+	*res = *staleRes
+
+	return true
+}
+
+// headerTags returns the tags listed in the tag headers of the response.
+func (m *ResponseCacheMiddleware) headerTags(res *http.Response) []string {
+	var tags []string
+	for _, name := range m.Spec.CacheOptions.CacheTagHeaders {
+		for _, value := range res.Header.Values(name) {
+			tags = append(tags, strings.FieldsFunc(value, func(r rune) bool {
+				return r == ',' || unicode.IsSpace(r)
+			})...)
+		}
+	}
+
+	return tags
+}
+
+// indexEntry records the key of a cached entry under its path and tags, for
+// the entry to be purged by path or tag.
+func (m *ResponseCacheMiddleware) indexEntry(options *cacheOptions, key string, tags []string, ttl int64) {
+	m.addToIndex(cachePathIndex, options.path+" "+key, ttl)
+	for _, tag := range tags {
+		m.addToIndex(cacheTagIndex(tag), key, ttl)
+	}
+
+	if len(tags) > 0 {
+		if err := m.store.SetKey(cacheEntryTags(key), strings.Join(tags, "\n"), ttl); err != nil {
+			m.logger().WithError(err).Error("could not save cache entry tags")
+		}
+	}
+}
+
+// addToIndex adds the member to the index, scored by its expiry, and removes
+// the expired members.
+func (m *ResponseCacheMiddleware) addToIndex(index, member string, ttl int64) {
+	now := time.Now()
+	expiry := math.Inf(1)
+	if ttl > 0 {
+		expiry = float64(now.Unix() + ttl)
+	}
+
+	if err := m.store.RemoveSortedSetRange(index, "-inf", "("+strconv.FormatInt(now.Unix(), 10)); err != nil {
+		m.logger().WithError(err).Error("could not trim cache index")
+	}
+	m.store.AddToSortedSet(index, member, expiry)
+
+	// Keep the index as long as its longest lived entry
+	if exp, err := m.store.GetExp(index); ttl > 0 && (err != nil || exp < ttl) {
+		if err := m.store.SetExp(index, ttl); err != nil {
+			m.logger().WithError(err).Error("could not set cache index expiry")
+		}
+	}
+}
+
+// refreshEntry replaces a 304 Not Modified response of the upstream with the
+// revalidated entry, updating its headers with the ones of the 304.
+func (m *ResponseCacheMiddleware) refreshEntry(res *http.Response, r *http.Request, cachedData string) bool {
+	cachedRes, err := http.ReadResponse(bufio.NewReader(strings.NewReader(cachedData)), r)
+	if err != nil {
+		m.logger().WithError(err).Error("could not read revalidated cache entry")
+		return false
+	}
+
+	nopCloseResponseBody(cachedRes)
+	if res.Body != nil {
+		res.Body.Close()
+	}
+
+	for name, values := range res.Header {
+		if name == header.ContentLength {
+			continue
+		}
+		cachedRes.Header[name] = values
+	}
+	*res = *cachedRes
+
+	return true
+}
+
+// addWeakETag sets a weak ETag computed from the body of the response.
+func (m *ResponseCacheMiddleware) addWeakETag(res *http.Response) {
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		m.logger().WithError(err).Error("error reading cache body")
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	res.Header.Set(header.ETag, `W/"`+hex.EncodeToString(sum[:16])+`"`)
+}
+
 // HandleResponse checks if the http.Response argument can be cached and caches it for future requests.
 func (m *ResponseCacheMiddleware) HandleResponse(w http.ResponseWriter, res *http.Response, r *http.Request, ses *user.SessionState) error {
 	// No cache of empty responses
@@ -73,8 +238,19 @@ func (m *ResponseCacheMiddleware) HandleResponse(w http.ResponseWriter, res *htt
 		return nil
 	}
 
+	// Serve the stale entry instead of the upstream error
+	if options.stale != "" && res.StatusCode >= http.StatusInternalServerError && m.serveStale(res, r, options.stale) {
+		return nil
+	}
+
+	// The upstream confirmed the expired entry is still valid
+	if options.revalidate != "" && res.StatusCode == http.StatusNotModified && !m.refreshEntry(res, r, options.revalidate) {
+		return nil
+	}
+
 	cacheThisRequest := true
 	cacheTTL := options.timeout
+	staleWhileRevalidate, staleIfError := options.staleWhileRevalidate, options.staleIfError
 
 	// make sure the status codes match if specified
 	if len(options.cacheOnlyResponseCodes) > 0 {
@@ -108,6 +284,15 @@ func (m *ResponseCacheMiddleware) HandleResponse(w http.ResponseWriter, res *htt
 				cacheTTL = int64(cacheAsInt)
 			}
 		}
+
+		staleWhileRevalidate, staleIfError = parseStaleDirectives(res.Header.Get("Cache-Control"), staleWhileRevalidate, staleIfError)
+	}
+
+	// Responses varying on request headers are cached per variant, under a
+	// marker listing the headers. Varying on anything can't be cached.
+	vary := parseVary(res.Header.Values(header.Vary))
+	if slices.Contains(vary, "*") {
+		cacheThisRequest = false
 	}
 
 	var toStore string
@@ -120,6 +305,10 @@ func (m *ResponseCacheMiddleware) HandleResponse(w http.ResponseWriter, res *htt
 			return nil
 		}
 
+		if m.Spec.CacheOptions.GenerateWeakETags && res.Header.Get(header.ETag) == "" {
+			m.addWeakETag(res)
+		}
+
 		var wireFormatReq bytes.Buffer
 		if err := res.Write(&wireFormatReq); err != nil {
 			m.logger().WithError(err).Error("error encoding cache")
@@ -127,14 +316,58 @@ func (m *ResponseCacheMiddleware) HandleResponse(w http.ResponseWriter, res *htt
 		}
 
 		ts := m.getTimeTTL(cacheTTL)
-		toStore = m.encodePayload(wireFormatReq.String(), ts)
+		storeTTL := cacheTTL
+		if staleWhileRevalidate > 0 || staleIfError > 0 {
+			// Keep the entry around to be served stale
+			toStore = m.encodeStalePayload(wireFormatReq.String(), ts, ts+staleWhileRevalidate, ts+staleIfError)
+			storeTTL += max(staleWhileRevalidate, staleIfError)
+		} else {
+			toStore = m.encodePayload(wireFormatReq.String(), ts)
+		}
+
+		key, marker := options.baseKey, ""
+		if len(vary) > 0 {
+			key = varyKey(options.baseKey, vary, options.header)
+			marker = varyMarker + strings.Join(vary, ",")
+		}
+
+		// Hand the response over to the requests waiting for it, unless they
+		// wait for the entry of any variant
+		if options.flight != nil && key == options.key {
+			options.flight.response = wireFormatReq.String()
+		}
+
+		tags := append(m.headerTags(res), options.tags...)
 
 		go func() {
-			err := m.store.SetKey(options.key, toStore, cacheTTL)
+			if marker != "" {
+				if err := m.store.SetKey(options.baseKey, marker, storeTTL); err != nil {
+					m.logger().WithError(err).Error("could not save key in cache store")
+					return
+				}
+			}
+
+			err := m.store.SetKey(key, toStore, storeTTL)
 			if err != nil {
 				m.logger().WithError(err).Error("could not save key in cache store")
+				return
 			}
+
+			m.indexEntry(options, key, tags, storeTTL)
 		}()
+
+		if m.Gw != nil && m.Gw.localResponseCache != nil {
+			m.Gw.localResponseCache.Set(localCacheKey(m.Spec.APIID, key), wireFormatReq.String(), time.Duration(cacheTTL)*time.Second)
+		}
+	}
+
+	// Answer the conditional request of the client, replaced to revalidate the entry
+	if options.revalidate != "" && isNotModified(options.ifNoneMatch, options.ifModifiedSince, res.Header) {
+		res.StatusCode = http.StatusNotModified
+		res.Status = http.StatusText(http.StatusNotModified)
+		res.Body = http.NoBody
+		res.ContentLength = 0
+		res.Header.Del(header.ContentLength)
 	}
 
 	/*
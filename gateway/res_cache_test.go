@@ -12,3 +12,13 @@ func TestResponseCacheMiddleware(t *testing.T) {
 
 	assert.NoError(t, err)
 }
+
+func TestParseStaleDirectives(t *testing.T) {
+	swr, sie := parseStaleDirectives(`max-age=60, stale-while-revalidate=30, Stale-If-Error="120"`, 1, 2)
+	assert.Equal(t, int64(30), swr)
+	assert.Equal(t, int64(120), sie)
+
+	swr, sie = parseStaleDirectives("no-cache, stale-while-revalidate=invalid", 1, 2)
+	assert.Equal(t, int64(1), swr)
+	assert.Equal(t, int64(2), sie)
+}
@@ -0,0 +1,87 @@
+package gateway
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/internal/httpctx"
+	"github.com/TykTechnologies/tyk/internal/mcp"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+// MCPContentGuardResponseHandler applies the MCP content guard to the results
+// of the tool calls, prompts and resource reads, so the secrets and personal
+// data of the upstream don't reach the clients.
+type MCPContentGuardResponseHandler struct {
+	BaseTykResponseHandler
+}
+
+// Base returns the base handler for middleware decoration.
+func (h *MCPContentGuardResponseHandler) Base() *BaseTykResponseHandler {
+	return &h.BaseTykResponseHandler
+}
+
+// Name returns the handler name for logging and debugging.
+func (h *MCPContentGuardResponseHandler) Name() string {
+	return "MCPContentGuardResponseHandler"
+}
+
+// Init initializes the handler with the given spec.
+func (h *MCPContentGuardResponseHandler) Init(_ any, spec *APISpec) error {
+	h.Spec = spec
+	return nil
+}
+
+// Enabled returns true for the MCP APIs guarding the content of the results.
+func (h *MCPContentGuardResponseHandler) Enabled() bool {
+	return h.Spec.IsMCP() && h.Spec.MCPResultGuard != nil
+}
+
+// HandleResponse redacts the result of the response, or replaces it with an
+// error when it's blocked. The server-sent event responses are handled by
+// MCPContentGuardSSEHook.
+func (h *MCPContentGuardResponseHandler) HandleResponse(_ http.ResponseWriter, res *http.Response, req *http.Request, ses *user.SessionState) error {
+	state := httpctx.GetJSONRPCRoutingState(req)
+	if state == nil || !mcp.IsGuardedMethod(state.Method) {
+		return nil
+	}
+
+	if ct := res.Header.Get("Content-Type"); strings.HasPrefix(ct, "text/event-stream") {
+		return nil
+	}
+
+	raw, err := readAndCloseBody(res)
+	if err != nil || len(raw) == 0 {
+		return nil //nolint:nilerr // an unreadable body is emptied, nothing to guard
+	}
+
+	// The compressed bodies are guarded decompressed, those which can't be
+	// decompressed are blocked by the guard as they can't be parsed.
+	encoding := res.Header.Get(header.ContentEncoding)
+	res.Body = io.NopCloser(bytes.NewReader(raw))
+
+	var body []byte
+	if reader, ok := decodeMCPBody(res); ok {
+		body, _ = io.ReadAll(reader) //nolint:errcheck // a truncated body is blocked by the guard
+	}
+
+	guarded := guardMCPMessage(h.Spec, req, ses, body)
+	if guarded == nil {
+		res.Body = io.NopCloser(bytes.NewReader(raw))
+		return nil
+	}
+
+	if encoding != "gzip" && encoding != "deflate" {
+		res.Header.Del(header.ContentEncoding)
+	}
+	out := compressBuffer(*bytes.NewBuffer(guarded), encoding)
+
+	res.Body = io.NopCloser(&out)
+	res.ContentLength = int64(out.Len())
+	res.Header.Set("Content-Length", strconv.Itoa(out.Len()))
+	return nil
+}
@@ -47,7 +47,7 @@ func (h *MCPListFilterResponseHandler) HandleResponse(_ http.ResponseWriter, res
 		return nil
 	}
 
-	listCfg := h.listConfig(state.Method)
+	listCfg := mcp.ListFilterConfigForMethod(state.Method)
 	var filter func([]byte) ([]byte, bool)
 	switch {
 	case listCfg != nil:
@@ -95,23 +95,6 @@ func (h *MCPListFilterResponseHandler) HandleResponse(_ http.ResponseWriter, res
 	return nil
 }
 
-// listConfig returns the filter configuration for a given JSON-RPC method,
-// or nil if the method is not a filterable list method.
-func (h *MCPListFilterResponseHandler) listConfig(method string) *mcp.ListFilterConfig {
-	switch method {
-	case mcp.MethodToolsList:
-		return mcp.ListFilterConfigs["tools"]
-	case mcp.MethodPromptsList:
-		return mcp.ListFilterConfigs["prompts"]
-	case mcp.MethodResourcesList:
-		return mcp.ListFilterConfigs["resources"]
-	case mcp.MethodResourcesTemplatesList:
-		return mcp.ListFilterConfigs["resourceTemplates"]
-	default:
-		return nil
-	}
-}
-
 // readAndCloseBody reads the full response body and closes it. On success the
 // caller owns the returned bytes; the original body is always closed.
 // Returns (nil, nil) when the body is nil.
@@ -0,0 +1,62 @@
+package gateway
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/TykTechnologies/tyk/internal/httpctx"
+	"github.com/TykTechnologies/tyk/internal/mcp"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+// MCPToolSchemaResponseHandler learns the input schemas of the tools listed in
+// the upstream tools/list responses, for MCPToolValidationMiddleware to
+// validate the tool calls with.
+type MCPToolSchemaResponseHandler struct {
+	BaseTykResponseHandler
+}
+
+// Base returns the base handler for middleware decoration.
+func (h *MCPToolSchemaResponseHandler) Base() *BaseTykResponseHandler {
+	return &h.BaseTykResponseHandler
+}
+
+// Name returns the handler name for logging and debugging.
+func (h *MCPToolSchemaResponseHandler) Name() string {
+	return "MCPToolSchemaResponseHandler"
+}
+
+// Init initializes the handler with the given spec.
+func (h *MCPToolSchemaResponseHandler) Init(_ any, spec *APISpec) error {
+	h.Spec = spec
+	return nil
+}
+
+// Enabled returns true for the MCP APIs validating the tool calls.
+func (h *MCPToolSchemaResponseHandler) Enabled() bool {
+	return h.Spec.IsMCP() && h.Spec.MCPToolSchemas != nil
+}
+
+// HandleResponse learns the tool schemas of the tools/list responses. The
+// server-sent event responses are handled by MCPToolSchemaSSEHook.
+func (h *MCPToolSchemaResponseHandler) HandleResponse(_ http.ResponseWriter, res *http.Response, req *http.Request, _ *user.SessionState) error {
+	state := httpctx.GetJSONRPCRoutingState(req)
+	if state == nil || state.Method != mcp.MethodToolsList {
+		return nil
+	}
+
+	if ct := res.Header.Get("Content-Type"); strings.HasPrefix(ct, "text/event-stream") {
+		return nil
+	}
+
+	body, err := readAndCloseBody(res)
+	if err != nil {
+		return nil //nolint:nilerr // fail-open: the calls of unknown tools aren't validated
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	h.Spec.MCPToolSchemas.LearnFromResponse(body)
+	return nil
+}
@@ -44,10 +44,12 @@ import (
 	tykerrors "github.com/TykTechnologies/tyk/internal/errors"
 	"github.com/TykTechnologies/tyk/internal/graphengine"
 	"github.com/TykTechnologies/tyk/internal/httputil"
+	"github.com/TykTechnologies/tyk/internal/loadbalancer"
 	"github.com/TykTechnologies/tyk/internal/mcp"
 	"github.com/TykTechnologies/tyk/internal/otel"
 	"github.com/TykTechnologies/tyk/internal/service/core"
 	"github.com/TykTechnologies/tyk/regexp"
+	"github.com/TykTechnologies/tyk/request"
 	"github.com/TykTechnologies/tyk/storage"
 	"github.com/TykTechnologies/tyk/trace"
 	"github.com/TykTechnologies/tyk/user"
@@ -155,9 +157,16 @@ func EnsureTransport(host, protocol string) string {
 	return u.String()
 }
 
-func (gw *Gateway) nextTarget(targetData *apidef.HostList, spec *APISpec) (string, error) {
+// nextTarget picks the upstream target for a request. The request may be nil
+// when no request context is available, such as for TCP proxying, in which
+// case consistent hashing falls back to round-robin.
+func (gw *Gateway) nextTarget(targetData *apidef.HostList, spec *APISpec, r *http.Request) (string, error) {
 	if spec.Proxy.EnableLoadBalancing {
 		log.Debug("[PROXY] [LOAD BALANCING] Load balancer enabled, getting upstream target")
+		if spec.LoadBalancer != nil && spec.LoadBalancer.Algorithm() != loadbalancer.RoundRobin {
+			return gw.balancedTarget(targetData, spec, r)
+		}
+
 		// Use a HostList
 		startPos := spec.RoundRobin.WithLen(targetData.Len())
 		pos := startPos
@@ -167,19 +176,11 @@ func (gw *Gateway) nextTarget(targetData *apidef.HostList, spec *APISpec) (strin
 				return "", err
 			}
 
-			host := EnsureTransport(gotHost, spec.Protocol)
-			if !spec.Proxy.CheckHostAgainstUptimeTests {
-				return host, nil // we don't care if it's up
-			}
-
-			// GlobalHostCheck has not been initialized, return the host picked
-			// by round-robin algorithm.
-			if gw.GlobalHostChecker == nil {
-				return host, nil // we don't care if it's up
-			}
-			// As checked by HostCheckerManager.AmIPolling
-			if !gw.GlobalHostChecker.HostDown(host) {
-				return host, nil // we do care and it's up
+			if !gw.isTargetDown(spec, gotHost) {
+				if r != nil {
+					ctxSetLoadBalancedTarget(r, gotHost)
+				}
+				return EnsureTransport(gotHost, spec.Protocol), nil
 			}
 			// if the host is down, keep trying all the rest
 			// in order from where we started.
@@ -199,6 +200,62 @@ func (gw *Gateway) nextTarget(targetData *apidef.HostList, spec *APISpec) (strin
 	return EnsureTransport(gotHost, spec.Protocol), nil
 }
 
+// balancedTarget picks the upstream target using the API's configured load
+// balancing algorithm, and records the raw target on the request so that the
+// proxy can report load and latency back to the balancer.
+func (gw *Gateway) balancedTarget(targetData *apidef.HostList, spec *APISpec, r *http.Request) (string, error) {
+	var key string
+	if r != nil && spec.LoadBalancer.Algorithm() == loadbalancer.ConsistentHash {
+		key = loadBalancingHashKey(spec.Proxy.LoadBalancing.HashKey, r)
+	}
+
+	gotHost, err := spec.LoadBalancer.Next(targetData.All(), key, func(host string) bool {
+		return gw.isTargetDown(spec, host)
+	})
+	if errors.Is(err, loadbalancer.ErrNoHealthyHosts) {
+		return "", fmt.Errorf("all hosts are down, uptime tests are failing")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if r != nil {
+		ctxSetLoadBalancedTarget(r, gotHost)
+	}
+
+	return EnsureTransport(gotHost, spec.Protocol), nil
+}
+
+// isTargetDown reports whether the target is ejected by the passive health
+// check or considered down by the host checker. The host checker is only
+// consulted when the API opts in with `check_host_against_uptime_tests`.
+func (gw *Gateway) isTargetDown(spec *APISpec, target string) bool {
+	if spec.OutlierDetector != nil && spec.OutlierDetector.Ejected(target) {
+		return true
+	}
+	// GlobalHostCheck has not been initialized, we don't care if it's up.
+	if !spec.Proxy.CheckHostAgainstUptimeTests || gw.GlobalHostChecker == nil {
+		return false
+	}
+	// As checked by HostCheckerManager.AmIPolling
+	return gw.GlobalHostChecker.HostDown(EnsureTransport(target, spec.Protocol))
+}
+
+// loadBalancingHashKey returns the consistent hashing key of the request.
+func loadBalancingHashKey(hashKey apidef.LoadBalancingHashKey, r *http.Request) string {
+	switch hashKey.Source {
+	case apidef.LoadBalancingHashKeyHeader:
+		return r.Header.Get(hashKey.Name)
+	case apidef.LoadBalancingHashKeySession:
+		if session := ctxGetSession(r); session != nil {
+			return session.KeyHash()
+		}
+		return ctxGetAuthToken(r)
+	default:
+		return request.RealIP(r)
+	}
+}
+
 var (
 	onceStartAllHostsDown sync.Once
 
@@ -256,7 +313,7 @@ func (gw *Gateway) TykNewSingleHostReverseProxy(target *url.URL, spec *APISpec,
 			}
 			fallthrough // implies load balancing, with replaced host list
 		case spec.Proxy.EnableLoadBalancing:
-			host, err := gw.nextTarget(hostList, spec)
+			host, err := gw.nextTarget(hostList, spec, req)
 			if err != nil {
 				logger.Error("[PROXY] [LOAD BALANCING] ", err)
 				host = allHostsDownURL
@@ -1081,6 +1138,11 @@ func (p *ReverseProxy) handleOutboundRequest(roundTripper *TykRoundTripper, outr
 		return
 	}
 
+	if p.TykAPISpec.MCPStdioBridge != nil {
+		res, err = p.TykAPISpec.MCPStdioBridge.RoundTrip(outreq)
+		return
+	}
+
 	res, err = p.sendRequestToUpstream(roundTripper, outreq)
 	return
 }
@@ -1213,6 +1275,22 @@ func (p *ReverseProxy) WrappedServeHTTP(rw http.ResponseWriter, req *http.Reques
 	p.Director(outreq)
 	outreq.Close = false
 
+	lbTarget := ctxGetLoadBalancedTarget(outreq)
+	releaseTarget := func() {}
+	if lbTarget != "" && p.TykAPISpec.LoadBalancer != nil {
+		releaseTarget = p.TykAPISpec.LoadBalancer.Acquire(lbTarget)
+	}
+	defer func() {
+		releaseTarget()
+	}()
+
+	releaseHost, hostErr, hostCode := acquireUpstreamHostSlot(req, p.TykAPISpec, outreq.URL.Host)
+	if hostErr != nil {
+		p.ErrorHandler.HandleError(rw, logreq, hostErr.Error(), hostCode, true)
+		return ProxyResponse{}
+	}
+	defer releaseHost()
+
 	p.logger.Debug("Outbound request URL: ", outreq.URL.String())
 
 	reqUpType, outReqUpgrade := p.IsUpgrade(req)
@@ -1255,6 +1333,24 @@ func (p *ReverseProxy) WrappedServeHTTP(rw http.ResponseWriter, req *http.Reques
 	// Circuit breaker
 	breakerEnforced, breakerConf := p.CheckCircuitBreakerEnforced(p.TykAPISpec, req)
 
+	// Retry policy. Streamed bodies, upgrades and GraphQL requests are only sent once.
+	retryEnforced, retryConf := p.CheckRetryPolicyEnforced(p.TykAPISpec, req)
+	replay := outreq.Body
+	if retryEnforced {
+		_, buffered := replayBody(replay)
+		retryEnforced = buffered && !outReqUpgrade && !p.TykAPISpec.GraphQL.Enabled
+	}
+	if retryEnforced && p.Gw.retryBudget != nil {
+		p.Gw.retryBudget.Deposit()
+	}
+
+	// Hedging, with the same restrictions as retries.
+	hedgeEnforced, hedgeConf := p.CheckHedgingEnforced(p.TykAPISpec, req)
+	if hedgeEnforced {
+		_, buffered := replayBody(replay)
+		hedgeEnforced = buffered && !outReqUpgrade && !p.TykAPISpec.GraphQL.Enabled
+	}
+
 	// set up TLS certificates for upstream if needed
 	cert := p.Gw.getUpstreamCertificate(outreq.URL.Host, p.TykAPISpec)
 	if cert != nil {
@@ -1355,24 +1451,81 @@ func (p *ReverseProxy) WrappedServeHTTP(rw http.ResponseWriter, req *http.Reques
 		err             error
 	)
 
-	if breakerEnforced {
-		if !breakerConf.CB.Ready() {
-			p.logger.Debug("ON REQUEST: Circuit Breaker is in OPEN state")
-			errClass := tykerrors.ClassifyCircuitBreakerError(outreq.URL.Host+outreq.URL.Path, "OPEN")
-			ctx.SetErrorClassification(logreq, errClass)
-			p.ErrorHandler.HandleError(rw, logreq, "Service temporarily unavailable.", 503, true)
-			return ProxyResponse{}
+	sendOutbound := p.handleOutboundRequest
+	if hedgeEnforced {
+		sendOutbound = func(roundTripper *TykRoundTripper, r *http.Request, _ http.ResponseWriter) (*http.Response, bool, time.Duration, error) {
+			return p.handleHedgedRequest(roundTripper, r, replay, hedgeConf)
 		}
-		p.logger.Debug("ON REQUEST: Circuit Breaker is in CLOSED or HALF-OPEN state")
+	}
 
-		res, isHijacked, upstreamLatency, err = p.handleOutboundRequest(roundTripper, outreq, rw)
-		if err != nil || res.StatusCode/100 == 5 {
-			breakerConf.CB.Fail()
+	for attempt := 1; ; attempt++ {
+		if breakerEnforced {
+			if !breakerConf.CB.Ready() {
+				p.logger.Debug("ON REQUEST: Circuit Breaker is in OPEN state")
+				errClass := tykerrors.ClassifyCircuitBreakerError(outreq.URL.Host+outreq.URL.Path, "OPEN")
+				ctx.SetErrorClassification(logreq, errClass)
+				p.ErrorHandler.HandleError(rw, logreq, "Service temporarily unavailable.", 503, true)
+				return ProxyResponse{}
+			}
+			p.logger.Debug("ON REQUEST: Circuit Breaker is in CLOSED or HALF-OPEN state")
+
+			res, isHijacked, upstreamLatency, err = sendOutbound(roundTripper, outreq, rw)
+			if err != nil || res.StatusCode/100 == 5 {
+				breakerConf.CB.Fail()
+			} else {
+				breakerConf.CB.Success()
+			}
 		} else {
-			breakerConf.CB.Success()
+			res, isHijacked, upstreamLatency, err = sendOutbound(roundTripper, outreq, rw)
+		}
+
+		if hedgeEnforced && ctxGetHedgedResponse(outreq) {
+			ctxSetHedgedResponse(req)
+			ctxSetHedgedResponse(logreq)
+			lbTarget = ctxGetLoadBalancedTarget(outreq)
+		}
+
+		if lbTarget != "" && p.TykAPISpec.LoadBalancer != nil && err == nil {
+			p.TykAPISpec.LoadBalancer.Observe(lbTarget, upstreamLatency)
+		}
+
+		// Connection errors, timeouts and 5xx responses count as upstream
+		// failures, the requests cancelled by the clients don't.
+		clientCancelled := errors.Is(err, context.Canceled) || errors.Is(req.Context().Err(), context.Canceled)
+		upstreamFailed := (err != nil && !clientCancelled) ||
+			(res != nil && res.StatusCode >= http.StatusInternalServerError)
+
+		if p.TykAPISpec.LoadShedder != nil && !isHijacked {
+			p.TykAPISpec.LoadShedder.Observe(upstreamLatency, upstreamFailed)
+		}
+
+		if lbTarget != "" && p.TykAPISpec.OutlierDetector != nil && !isHijacked {
+			p.TykAPISpec.OutlierDetector.Record(lbTarget, upstreamFailed)
+		}
+
+		if !retryEnforced || isHijacked || !p.shouldRetry(retryConf, attempt, outreq, res, err) {
+			break
+		}
+
+		p.logger.Debugf("Upstream attempt %d failed, retrying", attempt)
+		if err == nil {
+			discardResponse(res)
+		}
+		if err = waitRetryBackoff(outreq.Context(), retryConf.Policy.Backoff(attempt)); err != nil {
+			res = nil
+			break
+		}
+		ctxSetRetryCount(req, attempt)
+		ctxSetRetryCount(logreq, attempt)
+
+		outreq = p.nextAttemptRequest(outreq, replay)
+
+		releaseTarget()
+		releaseTarget = func() {}
+		lbTarget = ctxGetLoadBalancedTarget(outreq)
+		if lbTarget != "" && p.TykAPISpec.LoadBalancer != nil {
+			releaseTarget = p.TykAPISpec.LoadBalancer.Acquire(lbTarget)
 		}
-	} else {
-		res, isHijacked, upstreamLatency, err = p.handleOutboundRequest(roundTripper, outreq, rw)
 	}
 
 	if err != nil {
@@ -1475,9 +1628,16 @@ func (p *ReverseProxy) WrappedServeHTTP(rw http.ResponseWriter, req *http.Reques
 		if p.logger.Logger.IsLevelEnabled(logrus.DebugLevel) {
 			hooks = append(hooks, NewLoggingSSEHook(p.logger))
 		}
+		if schemaHook := NewMCPToolSchemaSSEHook(p.TykAPISpec, req); schemaHook != nil {
+			hooks = append(hooks, schemaHook)
+		}
 		if filterHook := NewMCPListFilterSSEHook(p.TykAPISpec, ses); filterHook != nil {
 			hooks = append(hooks, filterHook)
 		}
+		if guardHook := NewMCPContentGuardSSEHook(p.TykAPISpec, req, ses); guardHook != nil {
+			decodeMCPStream(res)
+			hooks = append(hooks, guardHook)
+		}
 		res.Body = NewSSETap(res.Body, hooks...)
 		// Hooks may modify event data, changing the body length. Remove
 		// Content-Length so the client doesn't expect the original size.
@@ -2070,10 +2230,7 @@ func (p *ReverseProxy) initUpstreamCertBatcher() {
 		Debug("Initializing upstream certificate expiry check batcher")
 
 	// Initialize Redis store for cooldowns
-	store := &storage.RedisCluster{
-		KeyPrefix:         certcheck.CertCooldownKeyPrefix,
-		ConnectionHandler: p.Gw.StorageConnectionHandler,
-	}
+	store := p.Gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{KeyPrefix: certcheck.CertCooldownKeyPrefix})
 	store.Connect()
 
 	apiData := certcheck.APIMetaData{
@@ -0,0 +1,153 @@
+package gateway
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CheckHedgingEnforced returns the hedging configuration of the requested
+// endpoint. Hedging requires load balancing, as the hedged request is sent
+// to another target.
+func (p *ReverseProxy) CheckHedgingEnforced(spec *APISpec, req *http.Request) (bool, *ExtendedHedgingMeta) {
+	if !spec.HedgingEnabled || !spec.Proxy.EnableLoadBalancing {
+		return false, nil
+	}
+
+	versionInfo, _ := spec.Version(req)
+	versionPaths := spec.RxPaths[versionInfo.Name]
+	found, meta := spec.CheckSpecMatchesStatus(req, versionPaths, Hedging)
+	if found {
+		exMeta := meta.(*ExtendedHedgingMeta)
+		p.logger.Debug("Hedging enforced for path: ", exMeta.Path)
+		return true, exMeta
+	}
+
+	return false, nil
+}
+
+// hedgeResult is the outcome of one request of a hedged round trip.
+type hedgeResult struct {
+	req     *http.Request
+	res     *http.Response
+	err     error
+	latency time.Duration
+	hedged  bool
+	cancel  context.CancelFunc
+}
+
+// handleHedgedRequest sends outreq upstream and, if no response arrived within
+// the hedging delay, a second request with the replayed body to the next load
+// balanced target. The first response wins and the slower request is
+// cancelled. When the hedged request wins, outreq is updated with its target
+// and flagged as hedged.
+func (p *ReverseProxy) handleHedgedRequest(roundTripper *TykRoundTripper, outreq *http.Request, body io.ReadCloser, conf *ExtendedHedgingMeta) (*http.Response, bool, time.Duration, error) {
+	begin := time.Now()
+	results := make(chan hedgeResult, 2)
+
+	send := func(req *http.Request, hedged bool, release func()) {
+		reqCtx, cancel := context.WithCancel(req.Context())
+		req = req.WithContext(reqCtx)
+
+		// The hedged request holds its load balancer slot until it's cancelled.
+		var once sync.Once
+		done := func() {
+			cancel()
+			once.Do(release)
+		}
+
+		go func() {
+			start := time.Now()
+			res, err := p.sendRequestToUpstream(roundTripper, req)
+			results <- hedgeResult{req: req, res: res, err: err, latency: time.Since(start), hedged: hedged, cancel: done}
+		}()
+	}
+
+	send(outreq, false, func() {})
+	inflight := 1
+
+	var hedgeAfter <-chan time.Time
+	if delay, ok := conf.Policy.HedgeAfter(); ok {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		hedgeAfter = timer.C
+	}
+
+	for {
+		select {
+		case <-hedgeAfter:
+			hedgeAfter = nil
+
+			hedgeReq := p.nextAttemptRequest(outreq, body)
+			hedgeTarget := ctxGetLoadBalancedTarget(hedgeReq)
+			if hedgeTarget == "" || hedgeTarget == ctxGetLoadBalancedTarget(outreq) {
+				p.logger.Debug("[PROXY] [HEDGING] No other target available, not hedging")
+				continue
+			}
+
+			release := func() {}
+			if p.TykAPISpec.LoadBalancer != nil {
+				release = p.TykAPISpec.LoadBalancer.Acquire(hedgeTarget)
+			}
+
+			p.logger.Debug("[PROXY] [HEDGING] Upstream is slow, sending hedged request to: ", hedgeTarget)
+			send(hedgeReq, true, release)
+			inflight++
+		case result := <-results:
+			inflight--
+
+			// A failed request waits for the other one, if any.
+			if result.err != nil && inflight > 0 {
+				result.cancel()
+				continue
+			}
+
+			if inflight > 0 {
+				go func() {
+					loser := <-results
+					loser.cancel()
+					if loser.err == nil {
+						discardResponse(loser.res)
+					}
+				}()
+			}
+
+			if result.err != nil {
+				result.cancel()
+				return nil, false, result.latency, result.err
+			}
+
+			// The policy tracks the latency of the first request, a hedged
+			// win only tells it took longer than the time waited so far.
+			if result.hedged {
+				p.logger.Debug("[PROXY] [HEDGING] Hedged request won")
+				conf.Policy.Observe(time.Since(begin))
+				ctxSetLoadBalancedTarget(outreq, ctxGetLoadBalancedTarget(result.req))
+				ctxSetHedgedResponse(outreq)
+			} else {
+				conf.Policy.Observe(result.latency)
+			}
+
+			// The winning request is only cancelled once its body is closed.
+			result.res.Body = &cancelOnCloseBody{ReadCloser: result.res.Body, cancel: result.cancel}
+
+			return result.res, false, result.latency, nil
+		}
+	}
+}
+
+// cancelOnCloseBody cancels the context of a request once its response body
+// is closed.
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close closes the body and cancels the request context.
+func (b *cancelOnCloseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
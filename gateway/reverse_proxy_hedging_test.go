@@ -0,0 +1,118 @@
+package gateway
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	tyktime "github.com/TykTechnologies/tyk/internal/time"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestReverseProxy_Hedging(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	var cancelled int32
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(2 * time.Second):
+			_, _ = w.Write([]byte("slow"))
+		case <-r.Context().Done():
+			atomic.AddInt32(&cancelled, 1)
+		}
+	}))
+	defer slow.Close()
+
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("fast"))
+	}))
+	defer fast.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+		spec.Proxy.EnableLoadBalancing = true
+		spec.Proxy.Targets = []string{slow.URL, fast.URL}
+		spec.UseKeylessAccess = true
+		UpdateAPIVersion(spec, "", func(version *apidef.VersionInfo) {
+			version.UseExtendedPaths = true
+			version.ExtendedPaths.Hedging = []apidef.HedgingMeta{{
+				Path:   "/hedged",
+				Method: http.MethodGet,
+				Delay:  tyktime.ReadableDuration(20 * time.Millisecond),
+			}}
+		})
+	})
+
+	for i := 0; i < 4; i++ {
+		start := time.Now()
+		_, _ = ts.Run(t, test.TestCase{Path: "/hedged", Code: http.StatusOK, BodyMatch: "fast"})
+		assert.Less(t, time.Since(start), time.Second)
+	}
+
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&cancelled) > 0
+	}, time.Second, 10*time.Millisecond, "the slower request should be cancelled")
+}
+
+func TestReverseProxy_HedgingReplaysBody(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(2 * time.Second):
+			_, _ = w.Write([]byte("slow"))
+		case <-r.Context().Done():
+		}
+	}))
+	defer slow.Close()
+
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(append([]byte("fast:"), body...))
+	}))
+	defer fast.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+		spec.Proxy.EnableLoadBalancing = true
+		spec.Proxy.Targets = []string{slow.URL, fast.URL}
+		spec.UseKeylessAccess = true
+		UpdateAPIVersion(spec, "", func(version *apidef.VersionInfo) {
+			version.UseExtendedPaths = true
+			version.ExtendedPaths.Hedging = []apidef.HedgingMeta{{
+				Path:   "/hedged",
+				Method: http.MethodPost,
+				Delay:  tyktime.ReadableDuration(20 * time.Millisecond),
+			}}
+		})
+	})
+
+	for i := 0; i < 4; i++ {
+		_, _ = ts.Run(t, test.TestCase{Method: http.MethodPost, Path: "/hedged", Data: "payload", Code: http.StatusOK, BodyMatch: "fast:payload"})
+	}
+}
+
+func TestCancelOnCloseBody(t *testing.T) {
+	var cancelled bool
+	body := &cancelOnCloseBody{
+		ReadCloser: io.NopCloser(strings.NewReader("body")),
+		cancel:     func() { cancelled = true },
+	}
+
+	data, err := io.ReadAll(body)
+	assert.NoError(t, err)
+	assert.Equal(t, "body", string(data))
+	assert.False(t, cancelled)
+
+	assert.NoError(t, body.Close())
+	assert.True(t, cancelled)
+}
@@ -0,0 +1,155 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/ctx"
+	"github.com/TykTechnologies/tyk/internal/retry"
+)
+
+// newRetryBudget creates the retry budget of the gateway from its configuration.
+func newRetryBudget(conf config.RetryBudgetConfig) *retry.Budget {
+	return retry.NewBudget(retry.BudgetConfig{
+		Ratio:               conf.Ratio,
+		MinRetriesPerSecond: conf.MinRetriesPerSecond,
+		TTL:                 time.Duration(conf.TTL) * time.Second,
+	})
+}
+
+// CheckRetryPolicyEnforced returns the retry policy configured for the requested endpoint.
+func (p *ReverseProxy) CheckRetryPolicyEnforced(spec *APISpec, req *http.Request) (bool, *ExtendedRetryPolicyMeta) {
+	if !spec.RetryPolicyEnabled {
+		return false, nil
+	}
+
+	versionInfo, _ := spec.Version(req)
+	versionPaths := spec.RxPaths[versionInfo.Name]
+	found, meta := spec.CheckSpecMatchesStatus(req, versionPaths, RetryPolicy)
+	if found {
+		exMeta := meta.(*ExtendedRetryPolicyMeta)
+		p.logger.Debug("Retry policy enforced for path: ", exMeta.Path)
+		return true, exMeta
+	}
+
+	return false, nil
+}
+
+// shouldRetry reports whether a failed attempt is retried. Retries are
+// withdrawn from the retry budget of the gateway.
+func (p *ReverseProxy) shouldRetry(conf *ExtendedRetryPolicyMeta, attempt int, outreq *http.Request, res *http.Response, err error) bool {
+	if attempt >= conf.Policy.MaxAttempts {
+		return false
+	}
+
+	status := 0
+	if err == nil && res != nil {
+		status = res.StatusCode
+	}
+	if !conf.Policy.Retryable(outreq.Method, status, err) {
+		return false
+	}
+
+	if budget := p.Gw.retryBudget; budget != nil && !budget.Withdraw() {
+		p.logger.Debug("Retry budget exhausted, not retrying upstream request")
+		return false
+	}
+
+	return true
+}
+
+// nextAttemptRequest prepares another attempt of outreq, used by retries and
+// hedged requests, with a fresh copy of the request body. With load balancing
+// enabled, the attempt is moved to the next target.
+func (p *ReverseProxy) nextAttemptRequest(outreq *http.Request, body io.ReadCloser) *http.Request {
+	retryReq := outreq.Clone(outreq.Context())
+	retryReq.Body, _ = replayBody(body)
+
+	spec := p.TykAPISpec
+	if !spec.Proxy.EnableLoadBalancing || retryReq.Context().Value(ctx.RetainHost) == true {
+		return retryReq
+	}
+
+	hostList := spec.Proxy.StructuredTargetList
+	if spec.Proxy.ServiceDiscovery.UseDiscoveryService {
+		var err error
+		if hostList, err = urlFromService(spec, p.Gw); err != nil {
+			p.logger.Debug("[PROXY] [LOAD BALANCING] Failed target lookup, using the same target: ", err)
+			return retryReq
+		}
+	}
+
+	host, err := p.Gw.nextTarget(hostList, spec, retryReq)
+	if err != nil {
+		p.logger.Debug("[PROXY] [LOAD BALANCING] No other target available, using the same target: ", err)
+		return retryReq
+	}
+
+	target, err := url.Parse(host)
+	if err != nil {
+		p.logger.Error("[PROXY] [LOAD BALANCING] Couldn't parse target URL: ", err)
+		return retryReq
+	}
+
+	retryReq.URL.Scheme = target.Scheme
+	retryReq.URL.Host = target.Host
+	switch retryReq.URL.Scheme {
+	case "ws", "h2c":
+		retryReq.URL.Scheme = "http"
+	case "wss":
+		retryReq.URL.Scheme = "https"
+	}
+	if !spec.Proxy.PreserveHostHeader {
+		retryReq.Host = target.Host
+	}
+
+	return retryReq
+}
+
+// replayBody returns a reader over the buffered request body. It reports
+// false if the body was streamed and can't be sent again.
+func replayBody(body io.ReadCloser) (io.ReadCloser, bool) {
+	if body == nil || body == http.NoBody {
+		return body, true
+	}
+
+	buffered, ok := body.(*nopCloserBuffer)
+	if !ok {
+		return nil, false
+	}
+	if err := buffered.copy(); err != nil {
+		return nil, false
+	}
+
+	return io.NopCloser(bytes.NewReader(buffered.buf.Bytes())), true
+}
+
+// discardResponse drains and closes the response of a retried attempt so
+// the upstream connection can be reused.
+func discardResponse(res *http.Response) {
+	if res == nil || res.Body == nil {
+		return
+	}
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+	res.Body.Close()
+}
+
+// waitRetryBackoff sleeps for the backoff, returning early with an error
+// when the request is cancelled or times out.
+func waitRetryBackoff(reqCtx context.Context, backoff time.Duration) error {
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-reqCtx.Done():
+		return reqCtx.Err()
+	}
+}
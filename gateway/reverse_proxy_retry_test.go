@@ -0,0 +1,165 @@
+package gateway
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/config"
+	tyktime "github.com/TykTechnologies/tyk/internal/time"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestReverseProxy_RetryPolicy(t *testing.T) {
+	ts := StartTest(func(c *config.Config) {
+		c.RetryBudget.MinRetriesPerSecond = 100
+	})
+	defer ts.Close()
+
+	var hits int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if atomic.AddInt32(&hits, 1)%3 != 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write(append([]byte("ok:"), body...))
+	}))
+	defer upstream.Close()
+
+	loadAPI := func(policy apidef.RetryPolicyMeta) {
+		ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.Proxy.ListenPath = "/"
+			spec.Proxy.TargetURL = upstream.URL
+			spec.UseKeylessAccess = true
+			UpdateAPIVersion(spec, "", func(version *apidef.VersionInfo) {
+				version.UseExtendedPaths = true
+				version.ExtendedPaths.RetryPolicy = []apidef.RetryPolicyMeta{policy}
+			})
+		})
+	}
+
+	policy := apidef.RetryPolicyMeta{
+		Path:               "/retry",
+		Method:             http.MethodPut,
+		MaxAttempts:        3,
+		RetryOnStatusCodes: []int{http.StatusServiceUnavailable},
+		BaseBackoff:        tyktime.ReadableDuration(1),
+	}
+
+	t.Run("retries until success and replays the body", func(t *testing.T) {
+		atomic.StoreInt32(&hits, 0)
+		loadAPI(policy)
+
+		_, _ = ts.Run(t, test.TestCase{
+			Method:    http.MethodPut,
+			Path:      "/retry",
+			Data:      "payload",
+			Code:      http.StatusOK,
+			BodyMatch: "ok:payload",
+		})
+		assert.Equal(t, int32(3), atomic.LoadInt32(&hits))
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		atomic.StoreInt32(&hits, 0)
+		limited := policy
+		limited.MaxAttempts = 2
+		loadAPI(limited)
+
+		_, _ = ts.Run(t, test.TestCase{Method: http.MethodPut, Path: "/retry", Code: http.StatusServiceUnavailable})
+		assert.Equal(t, int32(2), atomic.LoadInt32(&hits))
+	})
+
+	t.Run("non-idempotent methods are not retried by default", func(t *testing.T) {
+		atomic.StoreInt32(&hits, 0)
+		post := policy
+		post.Method = http.MethodPost
+		loadAPI(post)
+
+		_, _ = ts.Run(t, test.TestCase{Method: http.MethodPost, Path: "/retry", Code: http.StatusServiceUnavailable})
+		assert.Equal(t, int32(1), atomic.LoadInt32(&hits))
+
+		atomic.StoreInt32(&hits, 0)
+		post.RetryNonIdempotent = true
+		loadAPI(post)
+
+		_, _ = ts.Run(t, test.TestCase{Method: http.MethodPost, Path: "/retry", Code: http.StatusOK})
+		assert.Equal(t, int32(3), atomic.LoadInt32(&hits))
+	})
+}
+
+func TestReverseProxy_RetryPolicy_NextTarget(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	var failingHits, healthyHits int32
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&failingHits, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&healthyHits, 1)
+		_, _ = w.Write([]byte("healthy"))
+	}))
+	defer healthy.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+		spec.Proxy.EnableLoadBalancing = true
+		spec.Proxy.Targets = []string{failing.URL, healthy.URL}
+		spec.UseKeylessAccess = true
+		UpdateAPIVersion(spec, "", func(version *apidef.VersionInfo) {
+			version.UseExtendedPaths = true
+			version.ExtendedPaths.RetryPolicy = []apidef.RetryPolicyMeta{{
+				Path:               "/",
+				Method:             http.MethodGet,
+				MaxAttempts:        2,
+				RetryOnStatusCodes: []int{http.StatusBadGateway},
+				BaseBackoff:        tyktime.ReadableDuration(1),
+			}}
+		})
+	})
+
+	for i := 0; i < 4; i++ {
+		_, _ = ts.Run(t, test.TestCase{Path: "/", Code: http.StatusOK, BodyMatch: "healthy"})
+	}
+
+	// Every attempt that lands on the failing target is retried on the
+	// healthy one, so all requests succeed.
+	assert.Equal(t, int32(4), atomic.LoadInt32(&healthyHits))
+	assert.NotZero(t, atomic.LoadInt32(&failingHits))
+}
+
+func TestReplayBody(t *testing.T) {
+	body, ok := replayBody(nil)
+	assert.True(t, ok)
+	assert.Nil(t, body)
+
+	buffered, _ := newNopCloserBuffer(io.NopCloser(strings.NewReader("payload")))
+	for i := 0; i < 2; i++ {
+		body, ok = replayBody(buffered)
+		assert.True(t, ok)
+		data, _ := io.ReadAll(body)
+		assert.Equal(t, "payload", string(data))
+	}
+
+	_, ok = replayBody(io.NopCloser(strings.NewReader("streamed")))
+	assert.False(t, ok)
+}
+
+func TestAddRetryTag(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	assert.Empty(t, addRetryTag(r, nil))
+
+	ctxSetRetryCount(r, 2)
+	assert.Equal(t, []string{"retries-2"}, addRetryTag(r, nil))
+}
@@ -34,6 +34,7 @@ import (
 	"github.com/TykTechnologies/tyk/ctx"
 	"github.com/TykTechnologies/tyk/dnscache"
 	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/internal/loadbalancer"
 	tyktime "github.com/TykTechnologies/tyk/internal/time"
 	"github.com/TykTechnologies/tyk/request"
 	"github.com/TykTechnologies/tyk/test"
@@ -1813,6 +1814,74 @@ func TestEnsureTransport(t *testing.T) {
 	}
 }
 
+func TestNextTarget_LoadBalancingAlgorithm(t *testing.T) {
+	gw := &Gateway{}
+
+	newSpec := func(lb apidef.LoadBalancingConfig, targets ...string) *APISpec {
+		spec := &APISpec{APIDefinition: &apidef.APIDefinition{}}
+		spec.Proxy.EnableLoadBalancing = true
+		spec.Proxy.Targets = targets
+		spec.Proxy.LoadBalancing = lb
+		spec.Proxy.StructuredTargetList = apidef.NewHostListFromList(targets)
+		spec.LoadBalancer = loadbalancer.New(loadbalancer.Algorithm(lb.Algorithm))
+		return spec
+	}
+
+	t.Run("least connections records the picked target", func(t *testing.T) {
+		spec := newSpec(apidef.LoadBalancingConfig{Algorithm: apidef.LoadBalancingLeastConnections}, "a.test", "b.test")
+
+		release := spec.LoadBalancer.Acquire("a.test")
+		defer release()
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		host, err := gw.nextTarget(spec.Proxy.StructuredTargetList, spec, r)
+		require.NoError(t, err)
+		assert.Equal(t, "http://b.test", host)
+		assert.Equal(t, "b.test", ctxGetLoadBalancedTarget(r))
+	})
+
+	t.Run("consistent hash on header", func(t *testing.T) {
+		spec := newSpec(apidef.LoadBalancingConfig{
+			Algorithm: apidef.LoadBalancingConsistentHash,
+			HashKey: apidef.LoadBalancingHashKey{
+				Source: apidef.LoadBalancingHashKeyHeader,
+				Name:   "X-Tenant",
+			},
+		}, "a.test", "b.test", "c.test")
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("X-Tenant", "tenant-1")
+
+		first, err := gw.nextTarget(spec.Proxy.StructuredTargetList, spec, r)
+		require.NoError(t, err)
+
+		for i := 0; i < 10; i++ {
+			host, err := gw.nextTarget(spec.Proxy.StructuredTargetList, spec, r)
+			require.NoError(t, err)
+			assert.Equal(t, first, host)
+		}
+	})
+
+	t.Run("hash key sources", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = "10.0.0.1:1234"
+		r.Header.Set("X-Tenant", "tenant-1")
+
+		assert.Equal(t, "10.0.0.1", loadBalancingHashKey(apidef.LoadBalancingHashKey{}, r))
+		assert.Equal(t, "tenant-1", loadBalancingHashKey(apidef.LoadBalancingHashKey{
+			Source: apidef.LoadBalancingHashKeyHeader,
+			Name:   "X-Tenant",
+		}, r))
+
+		session := user.NewSessionState()
+		session.KeyID = "my-key"
+		ctxSetSession(r, session, false, false)
+		assert.Equal(t, session.KeyHash(), loadBalancingHashKey(apidef.LoadBalancingHashKey{
+			Source: apidef.LoadBalancingHashKeySession,
+		}, r))
+	})
+}
+
 func TestReverseProxyWebSocketCancelation(t *testing.T) {
 	conf := func(globalConf *config.Config) {
 		globalConf.HttpServerOptions.EnableWebSockets = true
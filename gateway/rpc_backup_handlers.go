@@ -42,7 +42,7 @@ func (gw *Gateway) LoadDefinitionsFromRPCBackup() ([]*APISpec, error) {
 	tagList := getTagListAsString(gw.GetConfig().DBAppConfOptions.Tags)
 	checkKey := BackupApiKeyBase + tagList
 
-	store := &storage.RedisCluster{KeyPrefix: RPCKeyPrefix, ConnectionHandler: gw.StorageConnectionHandler}
+	store := gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{KeyPrefix: RPCKeyPrefix})
 	connected := store.Connect()
 
 	log.Info("[RPC] --> Loading API definitions from backup")
@@ -78,7 +78,7 @@ func (gw *Gateway) saveRPCDefinitionsBackup(list string) error {
 
 	log.Info("--> Connecting to DB")
 
-	store := &storage.RedisCluster{KeyPrefix: RPCKeyPrefix, ConnectionHandler: gw.StorageConnectionHandler}
+	store := gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{KeyPrefix: RPCKeyPrefix})
 	connected := store.Connect()
 
 	log.Info("--> Connected to DB")
@@ -164,7 +164,7 @@ func (gw *Gateway) LoadIdPsFromRPCBackup() ([]IdP, error) {
 	tagList := getTagListAsString(gw.GetConfig().DBAppConfOptions.Tags)
 	checkKey := BackupClientIdPKeyBase + tagList
 
-	store := &storage.RedisCluster{KeyPrefix: RPCKeyPrefix, ConnectionHandler: gw.StorageConnectionHandler}
+	store := gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{KeyPrefix: RPCKeyPrefix})
 	connected := store.Connect()
 
 	log.Info("[RPC] --> Loading client IdPs from backup")
@@ -197,7 +197,7 @@ func (gw *Gateway) saveRPCIdPsBackup(list string) error {
 
 	tagList := getTagListAsString(gw.GetConfig().DBAppConfOptions.Tags)
 
-	store := storage.RedisCluster{KeyPrefix: RPCKeyPrefix, ConnectionHandler: gw.StorageConnectionHandler}
+	store := gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{KeyPrefix: RPCKeyPrefix})
 	connected := store.Connect()
 	if !connected {
 		return errors.New("--> RPC Client-IdP Backup save failed: redis connection failed")
@@ -216,7 +216,7 @@ func (gw *Gateway) LoadPoliciesFromRPCBackup() ([]user.Policy, error) {
 	tagList := getTagListAsString(gw.GetConfig().DBAppConfOptions.Tags)
 	checkKey := BackupPolicyKeyBase + tagList
 
-	store := &storage.RedisCluster{KeyPrefix: RPCKeyPrefix, ConnectionHandler: gw.StorageConnectionHandler}
+	store := gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{KeyPrefix: RPCKeyPrefix})
 	connected := store.Connect()
 
 	log.Info("[RPC] Loading Policies from backup")
@@ -258,7 +258,7 @@ func (gw *Gateway) saveRPCPoliciesBackup(list string) error {
 
 	log.Info("--> Connecting to DB")
 
-	store := storage.RedisCluster{KeyPrefix: RPCKeyPrefix, ConnectionHandler: gw.StorageConnectionHandler}
+	store := gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{KeyPrefix: RPCKeyPrefix})
 	connected := store.Connect()
 
 	log.Info("--> Connected to DB")
@@ -18,6 +18,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"runtime/pprof"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -54,6 +55,7 @@ import (
 	"github.com/TykTechnologies/tyk/header"
 	"github.com/TykTechnologies/tyk/internal/cache"
 	"github.com/TykTechnologies/tyk/internal/compression"
+	"github.com/TykTechnologies/tyk/internal/concurrency"
 	"github.com/TykTechnologies/tyk/internal/crypto"
 	"github.com/TykTechnologies/tyk/internal/httputil"
 	"github.com/TykTechnologies/tyk/internal/mcp"
@@ -62,6 +64,7 @@ import (
 	"github.com/TykTechnologies/tyk/internal/netutil"
 	"github.com/TykTechnologies/tyk/internal/otel"
 	"github.com/TykTechnologies/tyk/internal/rate"
+	"github.com/TykTechnologies/tyk/internal/retry"
 	"github.com/TykTechnologies/tyk/internal/scheduler"
 	"github.com/TykTechnologies/tyk/internal/service/newrelic"
 	"github.com/TykTechnologies/tyk/internal/uuid"
@@ -152,12 +155,30 @@ type Gateway struct {
 	SessionLimiter SessionLimiter
 	SessionMonitor Monitor
 
+	// concurrencyLimiter counts the in-flight requests of concurrency limits,
+	// it's shared by all APIs so that upstream host limits apply across them.
+	concurrencyLimiter *concurrency.Local
+
+	// retryBudget limits the retries of the retry policies, it's shared by all
+	// APIs and kept across reloads.
+	retryBudget *retry.Budget
+
+	// webhookRetries starts the retries of the queued webhook deliveries.
+	webhookRetries sync.Once
+
 	// RPCGlobalCache stores keys
 	RPCGlobalCache cache.Repository
 	// RPCCertCache stores certificates
 	RPCCertCache cache.Repository
 	// key session memory cache
 	SessionCache cache.Repository
+	// localResponseCache holds fresh cached API responses in front of Redis,
+	// it's nil unless enabled.
+	localResponseCache *cache.LRU
+	// cloudEventsSinks holds the connections to the CloudEvents sinks by
+	// configuration, shared by the event handlers across reloads.
+	cloudEventsSinks   map[string]*cloudEventsSinkConn
+	cloudEventsSinksMu sync.Mutex
 	// org session memory cache
 	ExpiryCache cache.Repository
 	// memory cache to store arbitrary items
@@ -171,6 +192,7 @@ type Gateway struct {
 
 	apisMu          sync.RWMutex
 	apiSpecs        []*APISpec
+	invalidAPISpecs []apiLoadFailure
 	apisByID        map[string]*APISpec
 	apisHandlesByID *sync.Map
 	mcpPairingIndex pairing.Index
@@ -285,6 +307,8 @@ func NewGateway(config config.Config, ctx context.Context) *Gateway {
 		Timeout: 500 * time.Millisecond,
 	}
 	gw.ConnectionWatcher = httputil.NewConnectionWatcher()
+	gw.concurrencyLimiter = concurrency.NewLocal()
+	gw.retryBudget = newRetryBudget(config.RetryBudget)
 
 	gw.cacheCreate()
 
@@ -350,6 +374,15 @@ func (gw *Gateway) cacheCreate() {
 
 	gw.RPCGlobalCache = cache.New(int64(conf.SlaveOptions.RPCGlobalCacheExpiration), 15)
 	gw.RPCCertCache = cache.New(int64(conf.SlaveOptions.RPCCertCacheExpiration), 15)
+
+	gw.localResponseCache = nil
+	if conf.LocalResponseCache.Enabled {
+		maxSize := conf.LocalResponseCache.MaxSize
+		if maxSize <= 0 {
+			maxSize = defaultLocalResponseCacheSize
+		}
+		gw.localResponseCache = cache.NewLRU(maxSize)
+	}
 }
 
 // cacheClose will close the caches in *Gateway, cleaning up the goroutines.
@@ -486,6 +519,13 @@ func (gw *Gateway) getAPIDefinition(apiID string) (*apidef.APIDefinition, error)
 	return apiSpec.APIDefinition, nil
 }
 
+// getInvalidAPISpecs returns the API specs which failed validation on the last sync.
+func (gw *Gateway) getInvalidAPISpecs() []apiLoadFailure {
+	gw.apisMu.RLock()
+	defer gw.apisMu.RUnlock()
+	return slices.Clone(gw.invalidAPISpecs)
+}
+
 func (gw *Gateway) apisByIDLen() int {
 	gw.apisMu.RLock()
 	defer gw.apisMu.RUnlock()
@@ -526,7 +566,7 @@ func (gw *Gateway) setupGlobals() {
 			mainLog.Warn("Running Uptime checks in a management node.")
 		}
 
-		healthCheckStore := &storage.RedisCluster{KeyPrefix: "host-checker:", IsAnalytics: true, ConnectionHandler: gw.StorageConnectionHandler}
+		healthCheckStore := gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{KeyPrefix: "host-checker:", IsAnalytics: true})
 		healthCheckStore.Connect()
 
 		gw.InitHostCheckManager(gw.ctx, healthCheckStore)
@@ -534,12 +574,12 @@ func (gw *Gateway) setupGlobals() {
 
 	gw.initHealthCheck(gw.ctx)
 
-	redisStore := &storage.RedisCluster{KeyPrefix: "apikey-", HashKeys: gwConfig.HashKeys, ConnectionHandler: gw.StorageConnectionHandler}
+	redisStore := gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{KeyPrefix: "apikey-", HashKeys: gwConfig.HashKeys})
 	redisStore.Connect()
 
 	gw.GlobalSessionManager.Init(redisStore)
 
-	versionStore := &storage.RedisCluster{KeyPrefix: "version-check-", ConnectionHandler: gw.StorageConnectionHandler}
+	versionStore := gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{KeyPrefix: "version-check-"})
 	versionStore.Connect()
 
 	err := versionStore.SetKey("gateway", VERSION, 0)
@@ -596,17 +636,14 @@ func (gw *Gateway) setupGlobals() {
 	// Get the notifier ready
 	mainLog.Debug("Notifier will not work in hybrid mode")
 
-	mainNotifierStore := &storage.RedisCluster{ConnectionHandler: gw.StorageConnectionHandler}
-	mainNotifierStore.Connect()
-
-	gw.MainNotifier = RedisNotifier{mainNotifierStore, RedisPubSubChannel, gw}
+	gw.MainNotifier = RedisNotifier{gw.newMessageBus(), RedisPubSubChannel, gw}
 
 	if gwConfig.Monitor.EnableTriggerMonitors {
 		h := &WebHookHandler{Gw: gw}
 		if err := h.Init(gwConfig.Monitor.Config); err != nil {
 			mainLog.Error("Failed to initialise monitor! ", err)
 		} else {
-			gw.MonitoringHandler = h
+			gw.MonitoringHandler = newFilteredEventHandler(h, gwConfig.Monitor.Filter, gwConfig.Monitor.Aggregate, nil)
 		}
 	}
 
@@ -621,7 +658,7 @@ func (gw *Gateway) setupGlobals() {
 		certificateSecret = gw.GetConfig().Security.PrivateCertificateEncodingSecret
 	}
 
-	storeCert := &storage.RedisCluster{KeyPrefix: "cert-", HashKeys: false, ConnectionHandler: gw.StorageConnectionHandler}
+	storeCert := gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{KeyPrefix: "cert-", HashKeys: false})
 	storeCert.Connect()
 
 	conf := gw.GetConfig()
@@ -741,10 +778,14 @@ func (gw *Gateway) syncAPISpecs() (int, error) {
 			s[i].SessionProvider = gw.GetConfig().AuthOverride.SessionProvider
 		}
 	}
-	var filter []*APISpec
+	var (
+		filter  []*APISpec
+		invalid []apiLoadFailure
+	)
 	for _, v := range s {
 		if err := v.Validate(gw.GetConfig().OAS); err != nil {
 			mainLog.WithError(err).WithField("spec", v.Name).Error("Skipping loading spec because it failed validation")
+			invalid = append(invalid, apiLoadFailure{spec: v, err: err})
 			continue
 		}
 		filter = append(filter, v)
@@ -752,6 +793,7 @@ func (gw *Gateway) syncAPISpecs() (int, error) {
 
 	gw.apisMu.Lock()
 	gw.apiSpecs = filter
+	gw.invalidAPISpecs = invalid
 	apiLen := len(gw.apiSpecs)
 	tlsConfigCache.Flush()
 	gw.apisMu.Unlock()
@@ -949,6 +991,9 @@ func (gw *Gateway) loadControlAPIEndpoints(muxer *mux.Router) {
 	r.HandleFunc("/cache/jwks/{apiID}", gw.invalidateJWKSCacheForAPIID).Methods("DELETE")
 	r.HandleFunc("/cache/jwks", gw.invalidateJWKSCacheForAllAPIs).Methods("DELETE")
 	r.HandleFunc("/cache/{apiID}", gw.invalidateCacheHandler).Methods("DELETE")
+	r.HandleFunc("/webhooks/dead-letters", gw.webhookDeadLettersHandler).Methods("GET")
+	r.HandleFunc("/webhooks/dead-letters/{deliveryID}", gw.webhookDeadLetterHandler).Methods("DELETE")
+	r.HandleFunc("/webhooks/dead-letters/{deliveryID}/replay", gw.webhookDeadLetterHandler).Methods("POST")
 	r.HandleFunc("/keys", gw.keyHandler).Methods("POST", "PUT", "GET", "DELETE")
 	r.HandleFunc("/keys/preview", gw.previewKeyHandler).Methods("POST")
 	r.HandleFunc("/keys/{keyName:[^/]*}", gw.keyHandler).Methods("POST", "PUT", "GET", "DELETE")
@@ -1013,7 +1058,7 @@ func (gw *Gateway) addOAuthHandlers(spec *APISpec, muxer *mux.Router) *OAuthMana
 	storageManager := gw.getGlobalMDCBStorageHandler(prefix, false)
 	storageManager.Connect()
 
-	storageDriver := &storage.RedisCluster{KeyPrefix: prefix, HashKeys: false, ConnectionHandler: gw.StorageConnectionHandler}
+	storageDriver := gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{KeyPrefix: prefix, HashKeys: false})
 	storageDriver.Connect()
 
 	osinStorage := &RedisOsinStorageInterface{
@@ -1173,6 +1218,8 @@ func (gw *Gateway) createResponseMiddlewareChain(
 	)
 	decorate := makeDefaultDecorator(log)
 
+	gw.responseMWAppendEnabled(&responseMWChain, decorate(&MCPToolSchemaResponseHandler{BaseTykResponseHandler: baseHandler}))
+	gw.responseMWAppendEnabled(&responseMWChain, decorate(&MCPContentGuardResponseHandler{BaseTykResponseHandler: baseHandler}))
 	gw.responseMWAppendEnabled(&responseMWChain, decorate(&MCPListFilterResponseHandler{BaseTykResponseHandler: baseHandler}))
 	gw.responseMWAppendEnabled(&responseMWChain, decorate(&ResponseTransformMiddleware{BaseTykResponseHandler: baseHandler}))
 	headerInjector := decorate(&HeaderInjector{BaseTykResponseHandler: baseHandler})
@@ -1238,7 +1285,7 @@ func (gw *Gateway) createResponseMiddlewareChain(
 		decorate(&ResponseErrorOverrideMiddleware{BaseTykResponseHandler: baseHandler}))
 
 	keyPrefix := "cache-" + spec.APIID
-	cacheStore := &storage.RedisCluster{KeyPrefix: keyPrefix, IsCache: true, ConnectionHandler: gw.StorageConnectionHandler}
+	cacheStore := gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{KeyPrefix: keyPrefix, IsCache: true})
 	cacheStore.Connect()
 
 	// Add cache writer as the final step of the response middleware chain
@@ -1282,6 +1329,7 @@ func (gw *Gateway) DoReloadWithError() error {
 	defer gw.reloadMu.Unlock()
 
 	start := time.Now()
+	changes := gw.newReloadChanges()
 
 	// Always record the current config state (loaded API and policy counts)
 	// even if the reload fails partway through. This ensures gauges report 0
@@ -1321,11 +1369,13 @@ func (gw *Gateway) DoReloadWithError() error {
 		if count == 0 && gw.apisByIDLen() == 0 {
 			mainLog.Warning("No API Definitions found, not reloading")
 			gw.performedSuccessfulReload = true
+			changes.fire(gw.getInvalidAPISpecs())
 			return nil
 		}
 	}
 
-	gw.loadGlobalApps()
+	failed := gw.loadGlobalApps()
+	gw.closeUnusedCloudEventsSinks()
 
 	// Refresh the client-IdP registry AFTER loadGlobalApps populates apisByID.
 	// The segment-aware backstop indexes only bindings whose api_id is present
@@ -1343,6 +1393,8 @@ func (gw *Gateway) DoReloadWithError() error {
 
 	gw.performedSuccessfulReload = true
 	mainLog.Info("API reload complete")
+
+	changes.fire(append(gw.getInvalidAPISpecs(), failed...))
 	return nil
 }
 
@@ -1708,13 +1760,28 @@ func (gw *Gateway) initSystem() error {
 	overrideTykErrors(gw)
 
 	gwConfig = gw.GetConfig()
-	if gwConfig.Storage.Type != "redis" {
+	switch gwConfig.Storage.Type {
+	case config.StorageTypeRedis:
+	case config.StorageTypeEmbedded:
+		mainLog.Info("Using embedded storage, data is kept local to this Gateway")
+	default:
 		mainLog.Fatal("Redis connection details not set, please ensure that the storage type is set to Redis and that the connection parameters are correct.")
 	}
 
-	go gw.StorageConnectionHandler.Connect(gw.ctx, func() {
+	onConnect := func() {
 		gw.reloadURLStructure(func() {})
-	}, &gwConfig)
+	}
+
+	if gwConfig.Storage.Type == config.StorageTypeEmbedded {
+		// The storage handlers are chosen by the connection handler, so the
+		// embedded database must be open before any of them is created.
+		gw.StorageConnectionHandler.Connect(gw.ctx, onConnect, &gwConfig)
+		if !gw.StorageConnectionHandler.Connected() {
+			mainLog.Fatal("storage: couldn't open the embedded storage")
+		}
+	} else {
+		go gw.StorageConnectionHandler.Connect(gw.ctx, onConnect, &gwConfig)
+	}
 
 	timeout, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
@@ -2158,7 +2225,7 @@ func (gw *Gateway) getHostDetails() {
 }
 
 func (gw *Gateway) getGlobalMDCBStorageHandler(keyPrefix string, hashKeys bool) storage.Handler {
-	localStorage := &storage.RedisCluster{KeyPrefix: keyPrefix, HashKeys: hashKeys, ConnectionHandler: gw.StorageConnectionHandler}
+	localStorage := gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{KeyPrefix: keyPrefix, HashKeys: hashKeys})
 	localStorage.Connect()
 
 	logger := tyklog.Get().WithFields(logrus.Fields{"prefix": "mdcb-storage-handler"})
@@ -2186,7 +2253,7 @@ func (gw *Gateway) getGlobalStorageHandler(keyPrefix string, hashKeys bool) stor
 			Gw:        gw,
 		}
 	}
-	handler := &storage.RedisCluster{KeyPrefix: keyPrefix, HashKeys: hashKeys, ConnectionHandler: gw.StorageConnectionHandler}
+	handler := gw.StorageConnectionHandler.NewHandler(&storage.RedisCluster{KeyPrefix: keyPrefix, HashKeys: hashKeys})
 	handler.Connect()
 	return handler
 }
@@ -2345,6 +2412,8 @@ func Start() {
 
 	// set var as global so we can export TykTriggerEvent(CEventName, CPayload *C.char)
 	GatewayFireSystemEvent = gw.FireSystemEvent
+	// and TykStoreData/TykGetData with the storage of the gateway
+	GatewayStorageConnectionHandler = gw.StorageConnectionHandler
 	// TODO: replace goagain with something that support multiple listeners
 	// Example: https://gravitational.com/blog/golang-ssh-bastion-graceful-restarts/
 	gw.startServer()
@@ -2470,7 +2539,7 @@ func (gw *Gateway) startDRL() {
 
 	gw.drlOnce.Do(func() {
 		drlManager := &drl.DRL{}
-		gw.SessionLimiter = NewSessionLimiter(gw.ctx, &gwConfig, drlManager, &gwConfig.ExternalServices)
+		gw.SessionLimiter = NewSessionLimiter(gw.ctx, &gwConfig, drlManager, &gwConfig.ExternalServices, gw.StorageConnectionHandler.Embedded())
 
 		gw.DRLManager = drlManager
 
@@ -2494,7 +2563,7 @@ func (gw *Gateway) startDRL() {
 func (gw *Gateway) isDRLDisabled() bool {
 	gwConfig := gw.GetConfig()
 
-	return gwConfig.ManagementNode || gwConfig.EnableSentinelRateLimiter || gwConfig.EnableRedisRollingLimiter || gwConfig.EnableFixedWindowRateLimiter
+	return gwConfig.ManagementNode || gwConfig.EnableSentinelRateLimiter || gwConfig.EnableRedisRollingLimiter || gwConfig.EnableFixedWindowRateLimiter || gwConfig.EnableGCRARateLimiter
 }
 
 func (gw *Gateway) setupPortsWhitelist() {
@@ -56,6 +56,9 @@ type SessionLimiter struct {
 	bucketStore    model.BucketStorage
 	limiterStorage redis.UniversalClient
 	smoothing      *rate.Smoothing
+
+	// embedded keeps the quotas when the embedded storage is in use.
+	embedded *storage.EmbeddedDB
 }
 
 // NewSessionLimiter initializes the session limiter.
@@ -64,12 +67,15 @@ type SessionLimiter struct {
 // It supports two storage types: `redis` and `local`. If redis storage is
 // configured, then redis will be used. If local storage is configured, then
 // in-memory counters will be used. If no storage is configured, it falls
-// back onto the default gateway storage configuration.
+// back onto the default gateway storage configuration. With the embedded
+// storage, the rate limiters use in-memory counters and the quotas are kept
+// in the embedded database.
 func NewSessionLimiter(
 	ctx context.Context,
 	conf *config.Config,
 	drlManager *drl.DRL,
 	externalServicesConfig *config.ExternalServiceConfig,
+	embedded *storage.EmbeddedDB,
 ) SessionLimiter {
 
 	sessionLimiter := SessionLimiter{
@@ -93,6 +99,15 @@ func NewSessionLimiter(
 			log.WithError(err).Fatal("[RATELIMIT] failed to initialise rate limiter redis storage")
 		}
 		sessionLimiter.limiterStorage = limiterStorage
+	case config.StorageTypeEmbedded:
+		if embedded == nil {
+			log.Fatal("[RATELIMIT] the embedded rate limiter storage requires the embedded gateway storage")
+		}
+		// The sliding log of these rate limiters is only implemented with redis.
+		if conf.EnableSentinelRateLimiter || conf.EnableRedisRollingLimiter || conf.DRLEnableSentinelRateLimiter {
+			log.Fatal("[RATELIMIT] the sentinel and redis rolling rate limiters can't be used with the embedded storage")
+		}
+		sessionLimiter.embedded = embedded
 	}
 
 	sessionLimiter.smoothing = rate.NewSmoothing(sessionLimiter.limiterStorage)
@@ -369,6 +384,10 @@ func (l *SessionLimiter) newRateLimitChecker(
 	limiterFn := rate.Limiter(l.config, l.limiterStorage)
 
 	switch {
+	case l.config.EnableGCRARateLimiter:
+		// GCRA reports the remaining requests, so it's not used through limiterFn.
+		return rate.GCRAChecker(r.Context(), l.limiterStorage, limiterKey, apiLimit.Rate, apiLimit.Per)
+
 	case limiterFn != nil:
 
 		return rate.AnonChecker(func() (rate.Stats, bool, error) {
@@ -412,7 +431,8 @@ func (l *SessionLimiter) newRateLimitChecker(
 			c = 5
 		}
 
-		if n <= 1 || n*c < cost {
+		// Without redis, the leaky bucket is used whatever the number of servers.
+		if n <= 1 || n*c < cost || l.limiterStorage == nil {
 			// If we have 1 server, there is no need to strain redis at all the leaky
 			// bucket algorithm will suffice.
 
@@ -477,6 +497,10 @@ func (l *SessionLimiter) RedisQuotaExceeded(
 		quotaRenewalRate = time.Second * time.Duration(limit.QuotaRenewalRate)
 	}
 
+	if l.embedded != nil {
+		return l.embeddedQuotaExceeded(r, session, scope, rawKey, limit, quotaRenewalRate, enableCtxVars)
+	}
+
 	conn := l.limiterStorage
 
 	var expired, exists bool
@@ -567,6 +591,49 @@ func (l *SessionLimiter) RedisQuotaExceeded(
 	return increment()
 }
 
+// embeddedQuotaExceeded is RedisQuotaExceeded with the embedded storage. The
+// embedded database is local to the gateway, so the quota is renewed without
+// a distributed lock.
+func (l *SessionLimiter) embeddedQuotaExceeded(
+	r *http.Request,
+	session *user.SessionState,
+	scope, rawKey string,
+	limit *user.APILimit,
+	quotaRenewalRate time.Duration,
+	enableCtxVars bool,
+) bool {
+	now := time.Now()
+	ttl := l.embedded.TTL(rawKey)
+	expiredAt := now.Add(time.Duration(ttl) * time.Second)
+
+	// The TTL is -2 if the key doesn't exist and -1 if it doesn't expire.
+	if ttl < 0 && quotaRenewalRate > 0 {
+		if ttl == -2 {
+			l.embedded.SetIfNotExists(rawKey, "0", quotaRenewalRate)
+		} else {
+			l.embedded.Set(rawKey, "0", quotaRenewalRate)
+		}
+		expiredAt = now.Add(quotaRenewalRate)
+	}
+
+	quota, err := l.embedded.IncrBy(rawKey, 1)
+	if err != nil {
+		log.WithError(err).Error("error incrementing quota key")
+		return true
+	}
+
+	blocked := quota-1 >= limit.QuotaMax
+	remaining := limit.QuotaMax - quota
+	if blocked {
+		remaining = 0
+	}
+
+	l.updateSessionQuota(session, scope, remaining, expiredAt.Unix())
+	l.extendContextWithQuota(r, int(limit.QuotaMax), int(remaining), int(expiredAt.Unix()), enableCtxVars)
+
+	return blocked
+}
+
 func GetAccessDefinitionByAPIIDOrSession(session *user.SessionState, api *APISpec) (accessDef *user.AccessDefinition, allowanceScope string, err error) {
 	accessDef = &user.AccessDefinition{}
 	if len(session.AccessRights) > 0 {
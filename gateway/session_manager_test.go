@@ -491,7 +491,7 @@ func TestSessionLimiter(t *testing.T) {
 
 		cfg := tc.Gw.GetConfig()
 		drlManager := &drl.DRL{}
-		return NewSessionLimiter(tc.Gw.ctx, &cfg, drlManager, &cfg.ExternalServices)
+		return NewSessionLimiter(tc.Gw.ctx, &cfg, drlManager, &cfg.ExternalServices, nil)
 	}
 
 	limiter := newSessionLimiter(t)
@@ -725,6 +725,28 @@ func TestSessionLimiter(t *testing.T) {
 	})
 }
 
+func TestSessionLimiter_EmbeddedQuota(t *testing.T) {
+	db, err := storage.OpenEmbeddedDB("", time.Hour)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	limiter := NewSessionLimiter(t.Context(), &config.Config{
+		Storage: config.StorageOptionsConf{Type: config.StorageTypeEmbedded},
+	}, &drl.DRL{}, nil, db)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	session := &user.SessionState{KeyID: "embedded-quota"}
+	limit := &user.APILimit{QuotaMax: 2, QuotaRenewalRate: 60}
+
+	assert.False(t, limiter.RedisQuotaExceeded(r, session, "", "", limit, false, false))
+	assert.False(t, limiter.RedisQuotaExceeded(r, session, "", "", limit, false, false))
+	assert.True(t, limiter.RedisQuotaExceeded(r, session, "", "", limit, false, false))
+
+	assert.Equal(t, int64(60), db.TTL(QuotaKeyPrefix+"embedded-quota"))
+}
+
 // TestNewBucketStateChecker verifies the conversion from token-based bucket state
 // to request-based rate limit statistics. The DRL uses tokens internally where
 // each request consumes multiple tokens, but the API returns request-based stats.
@@ -0,0 +1,59 @@
+package gateway
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/TykTechnologies/tyk/internal/httpctx"
+	"github.com/TykTechnologies/tyk/internal/mcp"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+// MCPContentGuardSSEHook applies the MCP content guard to the JSON-RPC
+// messages streamed as server-sent events: the results are redacted or
+// replaced with an error event, and the server notifications and requests are
+// redacted.
+type MCPContentGuardSSEHook struct {
+	spec *APISpec
+	req  *http.Request
+	ses  *user.SessionState
+}
+
+// NewMCPContentGuardSSEHook creates a hook guarding the events of the stream
+// answering the request. Returns nil if the API doesn't guard the content, or
+// the request isn't a tool call, prompt or resource read nor a GET stream.
+func NewMCPContentGuardSSEHook(spec *APISpec, req *http.Request, ses *user.SessionState) *MCPContentGuardSSEHook {
+	if spec == nil || spec.MCPResultGuard == nil {
+		return nil
+	}
+
+	if state := httpctx.GetJSONRPCRoutingState(req); state != nil && !mcp.IsGuardedMethod(state.Method) {
+		return nil
+	}
+	return &MCPContentGuardSSEHook{spec: spec, req: req, ses: ses}
+}
+
+// FilterEvent guards the JSON-RPC message of the event.
+func (h *MCPContentGuardSSEHook) FilterEvent(event *SSEEvent) (bool, *SSEEvent) {
+	if event.Event != "" && event.Event != "message" {
+		return true, nil
+	}
+
+	data := strings.Join(event.Data, "\n")
+	if len(data) == 0 {
+		return true, nil
+	}
+
+	guarded := guardMCPMessage(h.spec, h.req, h.ses, []byte(data))
+	if guarded == nil {
+		return true, nil
+	}
+
+	modified := &SSEEvent{
+		ID:    event.ID,
+		Event: event.Event,
+		Data:  []string{string(guarded)},
+		Retry: event.Retry,
+	}
+	return true, modified
+}
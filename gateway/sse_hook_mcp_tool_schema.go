@@ -0,0 +1,55 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/TykTechnologies/tyk/internal/httpctx"
+	"github.com/TykTechnologies/tyk/internal/mcp"
+)
+
+// MCPToolSchemaSSEHook learns the input schemas of the tools listed in the
+// tools/list responses streamed as server-sent events. Events are never modified.
+type MCPToolSchemaSSEHook struct {
+	spec *APISpec
+	id   any
+}
+
+// NewMCPToolSchemaSSEHook creates a hook learning the tool schemas for the
+// given API from the stream answering the tools/list request. Returns nil if
+// the API doesn't validate the tool calls or the request isn't a tools/list.
+func NewMCPToolSchemaSSEHook(spec *APISpec, req *http.Request) *MCPToolSchemaSSEHook {
+	if spec == nil || spec.MCPToolSchemas == nil {
+		return nil
+	}
+
+	state := httpctx.GetJSONRPCRoutingState(req)
+	if state == nil || state.Method != mcp.MethodToolsList {
+		return nil
+	}
+	return &MCPToolSchemaSSEHook{spec: spec, id: state.ID}
+}
+
+// FilterEvent learns the tool schemas of the response to the tools/list
+// request. The other messages of the stream, such as the server requests and
+// notifications, are ignored.
+func (h *MCPToolSchemaSSEHook) FilterEvent(event *SSEEvent) (bool, *SSEEvent) {
+	if event.Event != "" && event.Event != "message" {
+		return true, nil
+	}
+
+	data := strings.Join(event.Data, "\n")
+	if !strings.Contains(data, `"tools"`) {
+		return true, nil
+	}
+
+	var envelope mcp.JSONRPCResponse
+	if err := json.Unmarshal([]byte(data), &envelope); err != nil || !reflect.DeepEqual(envelope.ID, h.id) {
+		return true, nil
+	}
+
+	h.spec.MCPToolSchemas.LearnFromResponse([]byte(data))
+	return true, nil
+}
@@ -0,0 +1,58 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/internal/httpctx"
+	"github.com/TykTechnologies/tyk/internal/mcp"
+)
+
+func TestMCPToolSchemaSSEHook(t *testing.T) {
+	newSpec := func() *APISpec {
+		return &APISpec{MCPToolSchemas: mcp.NewToolSchemas()}
+	}
+	toolsList := `{"jsonrpc":"2.0","id":1,"result":{"tools":[{"name":"search","inputSchema":{"type":"object"}}]}}`
+
+	t.Run("requests other than tools/list", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/mcp", nil)
+		assert.Nil(t, NewMCPToolSchemaSSEHook(newSpec(), req))
+
+		httpctx.SetJSONRPCRoutingState(req, &httpctx.JSONRPCRoutingState{Method: mcp.MethodToolsCall, ID: float64(1)})
+		assert.Nil(t, NewMCPToolSchemaSSEHook(newSpec(), req))
+	})
+
+	t.Run("learns the response to the request", func(t *testing.T) {
+		spec := newSpec()
+		req := httptest.NewRequest("POST", "/mcp", nil)
+		httpctx.SetJSONRPCRoutingState(req, &httpctx.JSONRPCRoutingState{Method: mcp.MethodToolsList, ID: float64(1)})
+
+		hook := NewMCPToolSchemaSSEHook(spec, req)
+		require.NotNil(t, hook)
+
+		allowed, modified := hook.FilterEvent(&SSEEvent{Event: "message", Data: []string{toolsList}})
+		assert.True(t, allowed)
+		assert.Nil(t, modified)
+
+		_, known := spec.MCPToolSchemas.Validate("search", json.RawMessage(`{}`))
+		assert.True(t, known)
+	})
+
+	t.Run("ignores the other messages", func(t *testing.T) {
+		spec := newSpec()
+		req := httptest.NewRequest("POST", "/mcp", nil)
+		httpctx.SetJSONRPCRoutingState(req, &httpctx.JSONRPCRoutingState{Method: mcp.MethodToolsList, ID: "list"})
+
+		hook := NewMCPToolSchemaSSEHook(spec, req)
+		require.NotNil(t, hook)
+
+		hook.FilterEvent(&SSEEvent{Event: "message", Data: []string{toolsList}})
+
+		_, known := spec.MCPToolSchemas.Validate("search", json.RawMessage(`{}`))
+		assert.False(t, known)
+	})
+}
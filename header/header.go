@@ -18,6 +18,12 @@ const (
 	Cookie                  = "Cookie"
 	TransferEncoding        = "Transfer-Encoding"
 	Host                    = "Host"
+	RetryAfter              = "Retry-After"
+	ETag                    = "ETag"
+	LastModified            = "Last-Modified"
+	IfNoneMatch             = "If-None-Match"
+	IfModifiedSince         = "If-Modified-Since"
+	Vary                    = "Vary"
 )
 
 const (
@@ -47,6 +53,8 @@ const (
 	XTykAuthorization     = "X-Tyk-Authorization"
 	XTykAcceptExampleName = "X-Tyk-Accept-Example-Name"
 	XTykAcceptExampleCode = "X-Tyk-Accept-Example-Code"
+	XTykWebhookSignature  = "X-Tyk-Webhook-Signature"
+	XTykWebhookTimestamp  = "X-Tyk-Webhook-Timestamp"
 )
 
 // upgrade and websocket
@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LRU holds string values with a TTL, bounded by their total size in bytes.
+// The least recently used items are evicted first once the size is reached.
+type LRU struct {
+	maxSize int64
+
+	mu    sync.Mutex
+	size  int64
+	order *list.List
+	items map[string]*list.Element
+}
+
+type lruItem struct {
+	key        string
+	value      string
+	expiration int64
+}
+
+func (item *lruItem) size() int64 {
+	return int64(len(item.key) + len(item.value))
+}
+
+// NewLRU creates a new *LRU holding up to maxSize bytes of keys and values.
+func NewLRU(maxSize int64) *LRU {
+	return &LRU{
+		maxSize: maxSize,
+		order:   list.New(),
+		items:   make(map[string]*list.Element),
+	}
+}
+
+// Set adds a value to the cache, replacing any existing value, for the
+// duration d. Values larger than the cache size aren't stored.
+func (c *LRU) Set(k, v string, d time.Duration) {
+	item := &lruItem{key: k, value: v, expiration: time.Now().Add(d).UnixNano()}
+	if d <= 0 || item.size() > c.maxSize {
+		c.Delete(k)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[k]; ok {
+		c.remove(elem)
+	}
+
+	c.items[k] = c.order.PushFront(item)
+	c.size += item.size()
+
+	for c.size > c.maxSize {
+		c.remove(c.order.Back())
+	}
+}
+
+// Get returns an unexpired value from the cache, marking it as recently used.
+func (c *LRU) Get(k string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[k]
+	if !ok {
+		return "", false
+	}
+
+	item := elem.Value.(*lruItem)
+	if time.Now().UnixNano() > item.expiration {
+		c.remove(elem)
+		return "", false
+	}
+
+	c.order.MoveToFront(elem)
+	return item.value, true
+}
+
+// Delete removes a value from the cache.
+func (c *LRU) Delete(k string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[k]; ok {
+		c.remove(elem)
+	}
+}
+
+// DeletePrefix removes the values with keys starting with the prefix.
+func (c *LRU) DeletePrefix(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k, elem := range c.items {
+		if strings.HasPrefix(k, prefix) {
+			c.remove(elem)
+		}
+	}
+}
+
+// Size returns the size of the cached keys and values in bytes.
+func (c *LRU) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.size
+}
+
+func (c *LRU) remove(elem *list.Element) {
+	item := elem.Value.(*lruItem)
+	c.order.Remove(elem)
+	delete(c.items, item.key)
+	c.size -= item.size()
+}
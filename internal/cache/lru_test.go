@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU(t *testing.T) {
+	t.Run("evicts least recently used", func(t *testing.T) {
+		lru := NewLRU(12)
+		lru.Set("a", "12345", time.Minute)
+		lru.Set("b", "12345", time.Minute)
+
+		_, ok := lru.Get("a")
+		assert.True(t, ok)
+
+		lru.Set("c", "12345", time.Minute)
+
+		_, ok = lru.Get("b")
+		assert.False(t, ok, "b is the least recently used")
+		_, ok = lru.Get("a")
+		assert.True(t, ok)
+		_, ok = lru.Get("c")
+		assert.True(t, ok)
+		assert.Equal(t, int64(12), lru.Size())
+	})
+
+	t.Run("replaces values", func(t *testing.T) {
+		lru := NewLRU(100)
+		lru.Set("a", "one", time.Minute)
+		lru.Set("a", "three", time.Minute)
+
+		v, ok := lru.Get("a")
+		assert.True(t, ok)
+		assert.Equal(t, "three", v)
+		assert.Equal(t, int64(6), lru.Size())
+	})
+
+	t.Run("skips values over the size", func(t *testing.T) {
+		lru := NewLRU(4)
+		lru.Set("a", "12345", time.Minute)
+
+		_, ok := lru.Get("a")
+		assert.False(t, ok)
+		assert.Zero(t, lru.Size())
+	})
+
+	t.Run("expires", func(t *testing.T) {
+		lru := NewLRU(100)
+		lru.Set("a", "1", time.Millisecond)
+		lru.Set("b", "1", 0)
+
+		time.Sleep(2 * time.Millisecond)
+
+		_, ok := lru.Get("a")
+		assert.False(t, ok)
+		_, ok = lru.Get("b")
+		assert.False(t, ok)
+		assert.Zero(t, lru.Size())
+	})
+
+	t.Run("deletes by prefix", func(t *testing.T) {
+		lru := NewLRU(100)
+		lru.Set("api1-a", "1", time.Minute)
+		lru.Set("api1-b", "1", time.Minute)
+		lru.Set("api2-a", "1", time.Minute)
+
+		lru.DeletePrefix("api1-")
+		lru.Delete("api2-a")
+
+		assert.Zero(t, lru.Size())
+	})
+}
@@ -0,0 +1,111 @@
+// Package cloudevents encodes events in the CloudEvents 1.0 JSON format and
+// sends them to HTTP, Kafka, NATS or file sinks.
+package cloudevents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/TykTechnologies/tyk/internal/uuid"
+)
+
+const (
+	// SpecVersion is the CloudEvents specification version of the events.
+	SpecVersion = "1.0"
+	// ContentType is the media type of the events in structured mode.
+	ContentType = "application/cloudevents+json"
+	// DataContentType is the media type of the event data.
+	DataContentType = "application/json"
+)
+
+// Sink types.
+const (
+	SinkHTTP  = "http"
+	SinkKafka = "kafka"
+	SinkNATS  = "nats"
+	SinkFile  = "file"
+)
+
+var (
+	// ErrUnknownSink is returned when the sink type isn't supported.
+	ErrUnknownSink = errors.New("unknown cloudevents sink")
+	// ErrSinkQueueFull is returned when an event is dropped as the sink can't keep up.
+	ErrSinkQueueFull = errors.New("cloudevents sink queue is full, event dropped")
+)
+
+// Event is a CloudEvents 1.0 event.
+type Event struct {
+	SpecVersion     string    `json:"specversion"`
+	ID              string    `json:"id"`
+	Source          string    `json:"source"`
+	Type            string    `json:"type"`
+	Subject         string    `json:"subject,omitempty"`
+	Time            time.Time `json:"time"`
+	DataContentType string    `json:"datacontenttype,omitempty"`
+	Data            any       `json:"data,omitempty"`
+}
+
+// New creates an event with a unique ID, occurring now, with JSON data.
+func New(source, eventType string, data any) Event {
+	return Event{
+		SpecVersion:     SpecVersion,
+		ID:              uuid.New(),
+		Source:          source,
+		Type:            eventType,
+		Time:            time.Now().UTC(),
+		DataContentType: DataContentType,
+		Data:            data,
+	}
+}
+
+// Sink sends events to a destination.
+type Sink interface {
+	// Send sends the event, returning once it's acknowledged.
+	Send(ctx context.Context, event Event) error
+	// Close releases the resources of the sink.
+	Close() error
+}
+
+// Config is the configuration of a sink.
+type Config struct {
+	// Type is the type of the sink: http, kafka, nats or file.
+	Type string `json:"type"`
+	// URL is the HTTP endpoint or the NATS server the events are sent to.
+	URL string `json:"url,omitempty"`
+	// Headers are set on the HTTP requests.
+	Headers map[string]string `json:"headers,omitempty"`
+	// Brokers are the Kafka brokers.
+	Brokers []string `json:"brokers,omitempty"`
+	// Topic is the Kafka topic or the NATS subject.
+	Topic string `json:"topic,omitempty"`
+	// Path is the file the events are appended to.
+	Path string `json:"path,omitempty"`
+}
+
+// Validate checks the sink has the settings its type requires.
+func (c Config) Validate() error {
+	switch c.Type {
+	case SinkHTTP:
+		if c.URL == "" {
+			return errors.New("http sink requires an url")
+		}
+	case SinkKafka:
+		if len(c.Brokers) == 0 || c.Topic == "" {
+			return errors.New("kafka sink requires brokers and a topic")
+		}
+	case SinkNATS:
+		if c.URL == "" || c.Topic == "" {
+			return errors.New("nats sink requires an url and a topic")
+		}
+	case SinkFile:
+		if c.Path == "" {
+			return errors.New("file sink requires a path")
+		}
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownSink, c.Type)
+	}
+
+	return nil
+}
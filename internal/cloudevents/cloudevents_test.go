@@ -0,0 +1,130 @@
+package cloudevents
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name  string
+		conf  Config
+		valid bool
+	}{
+		{"http", Config{Type: SinkHTTP, URL: "http://localhost"}, true},
+		{"http without url", Config{Type: SinkHTTP}, false},
+		{"kafka", Config{Type: SinkKafka, Brokers: []string{"localhost:9092"}, Topic: "events"}, true},
+		{"kafka without topic", Config{Type: SinkKafka, Brokers: []string{"localhost:9092"}}, false},
+		{"nats", Config{Type: SinkNATS, URL: "nats://localhost:4222", Topic: "events"}, true},
+		{"nats without subject", Config{Type: SinkNATS, URL: "nats://localhost:4222"}, false},
+		{"file", Config{Type: SinkFile, Path: "events.log"}, true},
+		{"unknown", Config{Type: "smtp"}, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.conf.Validate()
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+
+	assert.ErrorIs(t, Config{Type: "smtp"}.Validate(), ErrUnknownSink)
+}
+
+func TestHTTPSink(t *testing.T) {
+	var received Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, ContentType, r.Header.Get("Content-Type"))
+		assert.Equal(t, "secret", r.Header.Get("Authorization"))
+
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &received); err != nil || received.Type == "io.tyk.gateway.fail" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	sink, err := NewSink(Config{Type: SinkHTTP, URL: server.URL, Headers: map[string]string{"Authorization": "secret"}}, server.Client())
+	require.NoError(t, err)
+	defer sink.Close()
+
+	event := New("urn:tyk:gateway:test", "io.tyk.gateway.QuotaExceeded", map[string]string{"key": "abc"})
+	require.NoError(t, sink.Send(context.Background(), event))
+
+	assert.Equal(t, SpecVersion, received.SpecVersion)
+	assert.Equal(t, event.ID, received.ID)
+	assert.Equal(t, "urn:tyk:gateway:test", received.Source)
+	assert.Equal(t, DataContentType, received.DataContentType)
+	assert.Equal(t, map[string]any{"key": "abc"}, received.Data)
+
+	assert.Error(t, sink.Send(context.Background(), New("urn:tyk:gateway:test", "io.tyk.gateway.fail", nil)))
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.log")
+
+	sink, err := NewSink(Config{Type: SinkFile, Path: path}, nil)
+	require.NoError(t, err)
+
+	first, second := New("test", "first", nil), New("test", "second", nil)
+	require.NoError(t, sink.Send(context.Background(), first))
+	require.NoError(t, sink.Send(context.Background(), second))
+	require.NoError(t, sink.Close())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 2)
+
+	var event Event
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &event))
+	assert.Equal(t, second.ID, event.ID)
+	assert.Equal(t, "second", event.Type)
+}
+
+// blockedProducer is a Kafka producer whose sends don't complete until it's closed.
+type blockedProducer struct {
+	sarama.SyncProducer
+	closed chan struct{}
+}
+
+func (p blockedProducer) SendMessage(*sarama.ProducerMessage) (int32, int64, error) {
+	<-p.closed
+	return 0, 0, sarama.ErrClosedClient
+}
+
+func (p blockedProducer) Close() error {
+	close(p.closed)
+	return nil
+}
+
+func TestKafkaSink_Timeout(t *testing.T) {
+	sink := newKafkaSink(blockedProducer{closed: make(chan struct{})}, "events", 1)
+	defer sink.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, sink.Send(ctx, New("test", "test", nil)), context.DeadlineExceeded)
+
+	// The worker is sending the first event, the second is queued.
+	assert.ErrorIs(t, sink.Send(ctx, New("test", "test", nil)), context.DeadlineExceeded)
+	assert.ErrorIs(t, sink.Send(ctx, New("test", "test", nil)), ErrSinkQueueFull, "the events are dropped once the queue is full")
+}
@@ -0,0 +1,255 @@
+package cloudevents
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/IBM/sarama"
+	nats "github.com/nats-io/nats.go"
+)
+
+// NewSink creates the sink of the configuration. The HTTP sink sends the
+// events with client.
+func NewSink(conf Config, client *http.Client) (Sink, error) {
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch conf.Type {
+	case SinkHTTP:
+		return &HTTPSink{url: conf.URL, headers: conf.Headers, client: client}, nil
+	case SinkKafka:
+		return NewKafkaSink(conf.Brokers, conf.Topic)
+	case SinkNATS:
+		return NewNATSSink(conf.URL, conf.Topic)
+	default:
+		return NewFileSink(conf.Path)
+	}
+}
+
+// HTTPSink posts the events in structured mode to an HTTP endpoint.
+type HTTPSink struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// Send posts the event, failing unless the endpoint answers with a 2xx status code.
+func (s *HTTPSink) Send(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	for name, value := range s.headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("Content-Type", ContentType)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Close is a no-op, the HTTP client is shared.
+func (s *HTTPSink) Close() error {
+	return nil
+}
+
+// kafkaQueueSize is the number of events a Kafka sink queues while the
+// producer sends the previous one.
+const kafkaQueueSize = 1024
+
+// KafkaSink produces the events in structured mode to a Kafka topic, keyed by
+// event ID. The events are sent one at a time by a worker, from a bounded
+// queue; the events are dropped while the queue is full.
+type KafkaSink struct {
+	producer sarama.SyncProducer
+	topic    string
+
+	queue     chan kafkaMessage
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// kafkaMessage is a queued event, the result of its send is written to sent.
+type kafkaMessage struct {
+	msg  *sarama.ProducerMessage
+	sent chan error
+}
+
+// NewKafkaSink connects to the Kafka brokers.
+func NewKafkaSink(brokers []string, topic string) (*KafkaSink, error) {
+	conf := sarama.NewConfig()
+	conf.ClientID = "tyk-gateway"
+	conf.Producer.Return.Successes = true
+	conf.Producer.RequiredAcks = sarama.WaitForLocal
+
+	producer, err := sarama.NewSyncProducer(brokers, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	return newKafkaSink(producer, topic, kafkaQueueSize), nil
+}
+
+func newKafkaSink(producer sarama.SyncProducer, topic string, queueSize int) *KafkaSink {
+	s := &KafkaSink{
+		producer: producer,
+		topic:    topic,
+		queue:    make(chan kafkaMessage, queueSize),
+		done:     make(chan struct{}),
+	}
+	go s.run()
+
+	return s
+}
+
+// run sends the queued events until the sink is closed.
+func (s *KafkaSink) run() {
+	for {
+		select {
+		case m := <-s.queue:
+			_, _, err := s.producer.SendMessage(m.msg)
+			m.sent <- err
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// Send queues the event, returning once the partition leader acknowledged it
+// or ctx is done. The event is still sent after ctx is done. It fails with
+// ErrSinkQueueFull when the queue is full.
+func (s *KafkaSink) Send(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	m := kafkaMessage{
+		msg: &sarama.ProducerMessage{
+			Topic: s.topic,
+			Key:   sarama.StringEncoder(event.ID),
+			Value: sarama.ByteEncoder(body),
+			Headers: []sarama.RecordHeader{
+				{Key: []byte("content-type"), Value: []byte(ContentType)},
+			},
+		},
+		sent: make(chan error, 1),
+	}
+
+	select {
+	case s.queue <- m:
+	default:
+		return ErrSinkQueueFull
+	}
+
+	select {
+	case err := <-m.sent:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops the worker and closes the producer, the queued events are dropped.
+func (s *KafkaSink) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+	return s.producer.Close()
+}
+
+// NATSSink publishes the events in structured mode to a NATS subject.
+type NATSSink struct {
+	conn    *nats.Conn
+	subject string
+}
+
+// NewNATSSink connects to the NATS server.
+func NewNATSSink(url, subject string) (*NATSSink, error) {
+	conn, err := nats.Connect(url, nats.Name("tyk-gateway"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &NATSSink{conn: conn, subject: subject}, nil
+}
+
+// Send publishes the event, returning once the server received it.
+func (s *NATSSink) Send(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(s.subject)
+	msg.Header.Set("Content-Type", ContentType)
+	msg.Data = body
+
+	if err := s.conn.PublishMsg(msg); err != nil {
+		return err
+	}
+	return s.conn.FlushWithContext(ctx)
+}
+
+// Close closes the connection.
+func (s *NATSSink) Close() error {
+	s.conn.Close()
+	return nil
+}
+
+// FileSink appends the events to a file, one JSON event per line.
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink opens the file, creating it if needed.
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileSink{file: file}, nil
+}
+
+// Send appends the event to the file.
+func (s *FileSink) Send(_ context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err = s.file.Write(append(body, '\n'))
+	return err
+}
+
+// Close closes the file.
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
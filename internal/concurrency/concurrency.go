@@ -0,0 +1,303 @@
+// Package concurrency limits the number of requests in flight at the same
+// time, per key.
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrLimitReached is returned when no slot is available before the context is done.
+	ErrLimitReached = errors.New("concurrency limit reached")
+	// ErrCounter is returned when the distributed counter can't be updated.
+	ErrCounter = errors.New("concurrency counter unavailable")
+)
+
+const (
+	// defaultPollInterval is how soon a queued request first checks a distributed counter for a free slot.
+	defaultPollInterval = 10 * time.Millisecond
+	// maxPollBackoff is how many times the poll interval a queued request backs off to at most.
+	maxPollBackoff = 16
+	// defaultCounterTTL is how long a distributed counter lives once no gateway holds its slots.
+	defaultCounterTTL = time.Minute
+)
+
+// Limiter limits the requests in flight per key.
+type Limiter interface {
+	// Acquire takes one of limit slots for key, waiting for a slot to be
+	// released until ctx is done. The returned release func must be called
+	// once the request is done.
+	Acquire(ctx context.Context, key string, limit int64) (release func(), err error)
+}
+
+// Local is a Limiter counting in-flight requests in memory. It is safe for
+// concurrent use.
+type Local struct {
+	mu    sync.Mutex
+	slots map[string]*localSlots
+}
+
+// localSlots are the in-flight requests of a key. released is closed and
+// replaced whenever a slot is released, waking up the waiting requests.
+type localSlots struct {
+	inFlight int64
+	waiting  int
+	released chan struct{}
+}
+
+var _ Limiter = (*Local)(nil)
+
+// NewLocal creates an in-memory Limiter.
+func NewLocal() *Local {
+	return &Local{slots: make(map[string]*localSlots)}
+}
+
+// Acquire implements Limiter.
+func (l *Local) Acquire(ctx context.Context, key string, limit int64) (func(), error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	s, ok := l.slots[key]
+	if !ok {
+		s = &localSlots{released: make(chan struct{})}
+		l.slots[key] = s
+	}
+
+	for s.inFlight >= limit {
+		released := s.released
+
+		s.waiting++
+		l.mu.Unlock()
+
+		var err error
+		select {
+		case <-released:
+		case <-ctx.Done():
+			err = ErrLimitReached
+		}
+
+		l.mu.Lock()
+		s.waiting--
+
+		if err != nil {
+			l.cleanup(key, s)
+			return nil, err
+		}
+	}
+
+	s.inFlight++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.release(key, s)
+		})
+	}, nil
+}
+
+func (l *Local) release(key string, s *localSlots) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	s.inFlight--
+	close(s.released)
+	s.released = make(chan struct{})
+
+	l.cleanup(key, s)
+}
+
+// cleanup removes the slots of key once unused. It must be called with mu held.
+func (l *Local) cleanup(key string, s *localSlots) {
+	if s.inFlight == 0 && s.waiting == 0 {
+		delete(l.slots, key)
+	}
+}
+
+// Counter is a shared counter, implemented by storage.Handler. Keys passed
+// to the methods must resolve to the same stored key. IncrememntWithExpire
+// returns 0 when the counter can't be incremented.
+type Counter interface {
+	IncrememntWithExpire(key string, expire int64) int64
+	Decrement(key string)
+	SetExp(key string, expire int64) error
+}
+
+// BoundedCounter is implemented by the counters which check and increment in
+// one atomic step, like storage.RedisCluster. IncrementBelow increments key
+// unless it reached limit, counting a negative value as zero, and reports
+// whether it was incremented.
+type BoundedCounter interface {
+	IncrementBelow(key string, limit, expire int64) (bool, error)
+}
+
+// Distributed is a Limiter counting in-flight requests in a Counter shared
+// by gateways. Queued requests poll the counter for a free slot, backing off
+// up to maxPollBackoff times the poll interval. When the counter is a
+// BoundedCounter the polls never take it over the limit. The
+// counters expire after TTL, so the slots of a gateway which stopped without
+// releasing them are eventually freed; the gateways refresh the TTL of the
+// counters while they hold slots.
+type Distributed struct {
+	Counter Counter
+	// PollInterval is how soon queued requests first check for a free slot.
+	PollInterval time.Duration
+	// TTL is the expiry of the counters, a minute when zero.
+	TTL time.Duration
+
+	mu         sync.Mutex
+	held       map[string]int
+	refreshing bool
+}
+
+var _ Limiter = (*Distributed)(nil)
+
+// NewDistributed creates a Limiter backed by counter.
+func NewDistributed(counter Counter) *Distributed {
+	return &Distributed{
+		Counter:      counter,
+		PollInterval: defaultPollInterval,
+	}
+}
+
+// Acquire implements Limiter. It fails with ErrCounter when the counter
+// can't be incremented, no slot is taken then.
+func (d *Distributed) Acquire(ctx context.Context, key string, limit int64) (func(), error) {
+	interval := d.PollInterval
+	for {
+		ok, err := d.increment(key, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			if err := d.hold(key); err != nil {
+				d.Counter.Decrement(key)
+				return nil, err
+			}
+
+			var once sync.Once
+			return func() {
+				once.Do(func() {
+					d.unhold(key)
+					d.Counter.Decrement(key)
+				})
+			}, nil
+		}
+
+		// The jitter spreads the polls of the requests queued at the same time.
+		wait := interval/2 + rand.N(interval/2+1)
+		interval = min(2*interval, maxPollBackoff*d.PollInterval)
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, ErrLimitReached
+		}
+	}
+}
+
+// increment takes a slot of key in the counter, reporting whether one was free.
+func (d *Distributed) increment(key string, limit int64) (bool, error) {
+	if counter, ok := d.Counter.(BoundedCounter); ok {
+		incremented, err := counter.IncrementBelow(key, limit, d.ttl())
+		if err != nil {
+			return false, ErrCounter
+		}
+		return incremented, nil
+	}
+
+	// A counter left below one by a released slot after it expired is
+	// brought back up by the failed acquires, as they aren't undone.
+	n := d.Counter.IncrememntWithExpire(key, d.ttl())
+	if n <= 0 {
+		return false, ErrCounter
+	}
+
+	if n > limit {
+		d.Counter.Decrement(key)
+		return false, nil
+	}
+	return true, nil
+}
+
+// ttl returns the expiry of the counters in seconds.
+func (d *Distributed) ttl() int64 {
+	ttl := d.TTL
+	if ttl <= 0 {
+		ttl = defaultCounterTTL
+	}
+	if ttl < time.Second {
+		ttl = time.Second
+	}
+	return int64(ttl / time.Second)
+}
+
+// hold records a slot of key held by this gateway, so its counter is
+// refreshed. The TTL of the counter is refreshed right away when this
+// gateway didn't hold any of its slots, as it may be about to expire.
+func (d *Distributed) hold(key string) error {
+	d.mu.Lock()
+	if d.held == nil {
+		d.held = make(map[string]int)
+	}
+
+	first := d.held[key] == 0
+	d.held[key]++
+
+	if !d.refreshing {
+		d.refreshing = true
+		go d.refresh()
+	}
+	d.mu.Unlock()
+
+	if first {
+		if err := d.Counter.SetExp(key, d.ttl()); err != nil {
+			d.unhold(key)
+			return ErrCounter
+		}
+	}
+
+	return nil
+}
+
+// unhold forgets a slot of key released by this gateway.
+func (d *Distributed) unhold(key string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.held[key]--; d.held[key] <= 0 {
+		delete(d.held, key)
+	}
+}
+
+// refresh extends the TTL of the counters whose slots are held by this
+// gateway, until it holds none.
+func (d *Distributed) refresh() {
+	ttl := d.ttl()
+	ticker := time.NewTicker(time.Duration(ttl) * time.Second / 2)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		d.mu.Lock()
+		if len(d.held) == 0 {
+			d.refreshing = false
+			d.mu.Unlock()
+			return
+		}
+
+		keys := make([]string, 0, len(d.held))
+		for key := range d.held {
+			keys = append(keys, key)
+		}
+		d.mu.Unlock()
+
+		for _, key := range keys {
+			// A failed refresh is retried on the next tick.
+			_ = d.Counter.SetExp(key, ttl) //nolint:errcheck
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memCounter is an in-memory Counter. The increments fail while down is set.
+type memCounter struct {
+	mu      sync.Mutex
+	values  map[string]int64
+	expires map[string]int64
+	down    bool
+}
+
+func (c *memCounter) IncrememntWithExpire(key string, expire int64) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.down {
+		return 0
+	}
+	c.values[key]++
+	if c.values[key] == 1 {
+		c.expires[key] = expire
+	}
+	return c.values[key]
+}
+
+func (c *memCounter) SetExp(key string, expire int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.down {
+		return errors.New("down")
+	}
+	c.expires[key] = expire
+	return nil
+}
+
+func (c *memCounter) Decrement(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[key]--
+}
+
+// boundedCounter is a memCounter checking the limit when incrementing, it
+// records the highest value the counters reached.
+type boundedCounter struct {
+	*memCounter
+	highest int64
+}
+
+func (c *boundedCounter) IncrementBelow(key string, limit, expire int64) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.down {
+		return false, errors.New("down")
+	}
+	if c.values[key] >= limit {
+		return false, nil
+	}
+	c.values[key] = max(c.values[key], 0) + 1
+	if c.values[key] == 1 {
+		c.expires[key] = expire
+	}
+	c.highest = max(c.highest, c.values[key])
+	return true, nil
+}
+
+func newMemCounter() *memCounter {
+	return &memCounter{values: make(map[string]int64), expires: make(map[string]int64)}
+}
+
+func expired() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}
+
+func TestLimiters(t *testing.T) {
+	counter := newMemCounter()
+
+	limiters := map[string]Limiter{
+		"local":       NewLocal(),
+		"distributed": &Distributed{Counter: counter, PollInterval: time.Millisecond},
+	}
+
+	for name, limiter := range limiters {
+		t.Run(name, func(t *testing.T) {
+			release1, err := limiter.Acquire(expired(), "key", 2)
+			require.NoError(t, err)
+			release2, err := limiter.Acquire(expired(), "key", 2)
+			require.NoError(t, err)
+
+			_, err = limiter.Acquire(expired(), "key", 2)
+			assert.ErrorIs(t, err, ErrLimitReached)
+
+			release, err := limiter.Acquire(expired(), "other", 2)
+			require.NoError(t, err, "keys are limited separately")
+			release()
+
+			acquired := make(chan error, 1)
+			go func() {
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+
+				release, err := limiter.Acquire(ctx, "key", 2)
+				if err == nil {
+					release()
+				}
+				acquired <- err
+			}()
+
+			release1()
+			release1()
+			assert.NoError(t, <-acquired, "queued requests get the released slot")
+
+			release2()
+
+			release, err = limiter.Acquire(expired(), "key", 2)
+			require.NoError(t, err)
+			release()
+		})
+	}
+
+	assert.Equal(t, int64(0), counter.values["key"])
+	assert.Empty(t, limiters["local"].(*Local).slots, "unused keys are removed")
+}
+
+func TestDistributed(t *testing.T) {
+	t.Run("expiry", func(t *testing.T) {
+		counter := newMemCounter()
+		limiter := &Distributed{Counter: counter, TTL: 30 * time.Second}
+
+		release, err := limiter.Acquire(expired(), "key", 2)
+		require.NoError(t, err)
+		defer release()
+
+		assert.Equal(t, int64(30), counter.expires["key"])
+	})
+
+	t.Run("counter failure", func(t *testing.T) {
+		counter := newMemCounter()
+		counter.down = true
+		limiter := NewDistributed(counter)
+
+		_, err := limiter.Acquire(expired(), "key", 2)
+		assert.ErrorIs(t, err, ErrCounter)
+		assert.Zero(t, counter.values["key"], "a failed acquire isn't released")
+	})
+
+	t.Run("drifted counter", func(t *testing.T) {
+		counter := newMemCounter()
+		counter.values["key"] = -1
+		limiter := NewDistributed(counter)
+
+		_, err := limiter.Acquire(expired(), "key", 2)
+		assert.ErrorIs(t, err, ErrCounter)
+
+		release, err := limiter.Acquire(expired(), "key", 2)
+		require.NoError(t, err, "the failed acquire brought the counter back up")
+		release()
+		assert.Zero(t, counter.values["key"])
+	})
+
+	t.Run("bounded counter", func(t *testing.T) {
+		counter := &boundedCounter{memCounter: newMemCounter()}
+		limiter := &Distributed{Counter: counter, PollInterval: time.Millisecond}
+
+		var wg sync.WaitGroup
+		for range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+
+				release, err := limiter.Acquire(ctx, "key", 2)
+				if assert.NoError(t, err) {
+					time.Sleep(5 * time.Millisecond)
+					release()
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int64(2), counter.highest, "the queued requests don't take the counter over the limit")
+		assert.Zero(t, counter.values["key"])
+	})
+
+	t.Run("refresh", func(t *testing.T) {
+		counter := newMemCounter()
+		limiter := &Distributed{Counter: counter, TTL: time.Second}
+
+		release, err := limiter.Acquire(expired(), "key", 2)
+		require.NoError(t, err)
+
+		counter.mu.Lock()
+		counter.expires["key"] = 0
+		counter.mu.Unlock()
+
+		assert.Eventually(t, func() bool {
+			counter.mu.Lock()
+			defer counter.mu.Unlock()
+			return counter.expires["key"] == 1
+		}, 2*time.Second, 10*time.Millisecond, "the held counter is refreshed")
+
+		release()
+
+		assert.Eventually(t, func() bool {
+			limiter.mu.Lock()
+			defer limiter.mu.Unlock()
+			return !limiter.refreshing
+		}, 2*time.Second, 10*time.Millisecond, "the refresh stops once no slot is held")
+	})
+}
@@ -49,6 +49,22 @@ const (
 	// CertificateExpired is the event triggered when a certificate is expired.
 	CertificateExpired Event = "CertificateExpired"
 
+	// APILoaded is the event triggered when an API is added or updated on reload.
+	APILoaded Event = "APILoaded"
+	// APILoadFailed is the event triggered when an API fails validation or fails to load on reload.
+	APILoadFailed Event = "APILoadFailed"
+	// APIRemoved is the event triggered when an API is unloaded on reload.
+	APIRemoved Event = "APIRemoved"
+	// PolicyUpdated is the event triggered when a policy is added, updated or removed on reload.
+	PolicyUpdated Event = "PolicyUpdated"
+	// ReloadCompleted is the event triggered when a reload completes, summarising its changes.
+	ReloadCompleted Event = "ReloadCompleted"
+
+	// MCPContentRedacted is the event triggered when content of an MCP result was redacted.
+	MCPContentRedacted Event = "MCPContentRedacted"
+	// MCPContentBlocked is the event triggered when an MCP result was blocked for its size or content types.
+	MCPContentBlocked Event = "MCPContentBlocked"
+
 	// OAuth2ScopeCheckFailed fires when an OAS-native scope check
 	// rejects a request (insufficient_scope per RFC 6750 §3.1).
 	OAuth2ScopeCheckFailed Event = "OAuth2ScopeCheckFailed"
@@ -103,6 +119,8 @@ const (
 	JSVMHandler HandlerName = "eh_dynamic_handler"
 	// CoProcessHandler is the HandlerName used in classic API definition for coprocess event handler.
 	CoProcessHandler HandlerName = "cp_dynamic_handler"
+	// CloudEventsHandler is the HandlerName used in classic API definition for the CloudEvents event handler.
+	CloudEventsHandler HandlerName = "eh_cloud_events_handler"
 )
 
 // Kind is the action to be performed when an event is triggered, to be used in OAS API definition.
@@ -0,0 +1,107 @@
+// Package hedge implements the delay policy of hedged upstream requests: how
+// long to wait for a response before a speculative request is sent to
+// another upstream.
+package hedge
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// sampleSize is the number of recent latencies kept per endpoint.
+	sampleSize = 256
+	// minSamples is the number of latencies observed before the
+	// percentile is trusted over the fixed delay.
+	minSamples = 20
+	// recomputeSamples is the number of latencies observed before the
+	// cached percentile is recomputed.
+	recomputeSamples = 16
+)
+
+// Policy decides when a hedged request is sent. It is safe for concurrent use.
+type Policy struct {
+	// Delay is the time to wait for the first response before hedging. With
+	// a percentile it is used until enough latencies are observed, and
+	// requests aren't hedged until then when it's zero.
+	Delay time.Duration
+	// Percentile hedges once the first request is slower than this
+	// percentile of recently observed latencies, e.g. 95. Zero disables it.
+	Percentile float64
+
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+	// sorted is the buffer the samples are sorted in.
+	sorted []time.Duration
+	// cached is the percentile computed for cachedFor, stale is the number of
+	// latencies observed since.
+	cached    time.Duration
+	cachedFor float64
+	stale     int
+}
+
+// NewPolicy creates a hedging Policy.
+func NewPolicy(delay time.Duration, percentile float64) *Policy {
+	return &Policy{
+		Delay:      delay,
+		Percentile: percentile,
+	}
+}
+
+// Observe records the latency of an upstream response.
+func (p *Policy) Observe(latency time.Duration) {
+	if p.Percentile <= 0 {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.stale++
+	if len(p.samples) < sampleSize {
+		p.samples = append(p.samples, latency)
+		return
+	}
+	p.samples[p.next] = latency
+	p.next = (p.next + 1) % sampleSize
+}
+
+// HedgeAfter returns how long to wait for the first response before sending
+// a hedged request. It returns false when the request shouldn't be hedged,
+// i.e. the percentile isn't known yet and there's no fixed delay.
+func (p *Policy) HedgeAfter() (time.Duration, bool) {
+	if p.Percentile <= 0 {
+		return p.Delay, true
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.samples) < minSamples {
+		return p.Delay, p.Delay > 0
+	}
+
+	if p.cachedFor != p.Percentile || p.stale >= recomputeSamples {
+		p.cached = p.percentile()
+		p.cachedFor = p.Percentile
+		p.stale = 0
+	}
+
+	return p.cached, true
+}
+
+// percentile computes the percentile of the samples. It must be called with
+// mu held.
+func (p *Policy) percentile() time.Duration {
+	p.sorted = append(p.sorted[:0], p.samples...)
+	sort.Slice(p.sorted, func(i, j int) bool { return p.sorted[i] < p.sorted[j] })
+
+	idx := int(p.Percentile / 100 * float64(len(p.sorted)))
+	if idx >= len(p.sorted) {
+		idx = len(p.sorted) - 1
+	}
+
+	return p.sorted[idx]
+}
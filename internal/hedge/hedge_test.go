@@ -0,0 +1,110 @@
+package hedge
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy_HedgeAfter(t *testing.T) {
+	t.Run("fixed delay", func(t *testing.T) {
+		p := NewPolicy(50*time.Millisecond, 0)
+		p.Observe(time.Second)
+
+		delay, ok := p.HedgeAfter()
+		assert.True(t, ok)
+		assert.Equal(t, 50*time.Millisecond, delay)
+	})
+
+	t.Run("percentile falls back to delay until warmed up", func(t *testing.T) {
+		p := NewPolicy(50*time.Millisecond, 90)
+		for i := 0; i < minSamples-1; i++ {
+			p.Observe(time.Millisecond)
+		}
+
+		delay, ok := p.HedgeAfter()
+		assert.True(t, ok)
+		assert.Equal(t, 50*time.Millisecond, delay)
+	})
+
+	t.Run("percentile doesn't hedge until warmed up", func(t *testing.T) {
+		p := NewPolicy(0, 90)
+		for i := 0; i < minSamples-1; i++ {
+			p.Observe(time.Millisecond)
+		}
+
+		_, ok := p.HedgeAfter()
+		assert.False(t, ok)
+
+		p.Observe(time.Millisecond)
+		delay, ok := p.HedgeAfter()
+		assert.True(t, ok)
+		assert.Equal(t, time.Millisecond, delay)
+	})
+
+	t.Run("percentile", func(t *testing.T) {
+		p := NewPolicy(50*time.Millisecond, 90)
+		for i := 1; i <= 100; i++ {
+			p.Observe(time.Duration(i) * time.Millisecond)
+		}
+
+		delay, _ := p.HedgeAfter()
+		assert.Equal(t, 91*time.Millisecond, delay)
+
+		p.Percentile = 100
+		delay, _ = p.HedgeAfter()
+		assert.Equal(t, 100*time.Millisecond, delay)
+	})
+
+	t.Run("keeps recent samples", func(t *testing.T) {
+		p := NewPolicy(0, 50)
+		for i := 0; i < sampleSize; i++ {
+			p.Observe(time.Second)
+		}
+		for i := 0; i < sampleSize; i++ {
+			p.Observe(time.Millisecond)
+		}
+
+		delay, _ := p.HedgeAfter()
+		assert.Equal(t, time.Millisecond, delay)
+	})
+
+	t.Run("recomputes the percentile periodically", func(t *testing.T) {
+		p := NewPolicy(0, 90)
+		for i := 0; i < minSamples; i++ {
+			p.Observe(time.Millisecond)
+		}
+
+		delay, _ := p.HedgeAfter()
+		assert.Equal(t, time.Millisecond, delay)
+
+		for i := 0; i < recomputeSamples-1; i++ {
+			p.Observe(time.Second)
+		}
+		delay, _ = p.HedgeAfter()
+		assert.Equal(t, time.Millisecond, delay, "the cached percentile is used")
+
+		p.Observe(time.Second)
+		delay, _ = p.HedgeAfter()
+		assert.Equal(t, time.Second, delay)
+	})
+}
+
+func TestPolicy_Concurrent(t *testing.T) {
+	p := NewPolicy(time.Millisecond, 95)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				p.Observe(time.Duration(j) * time.Millisecond)
+				_, _ = p.HedgeAfter()
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,181 @@
+// Package jsonpath implements the subset of JSON path used to select values
+// in decoded JSON documents.
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// segmentKind is the kind of a JSON path segment.
+type segmentKind int
+
+const (
+	// segmentChild selects the named member of an object.
+	segmentChild segmentKind = iota
+	// segmentIndex selects an element of an array, from its end when negative.
+	segmentIndex
+	// segmentWildcard selects all the members of an object or elements of an array.
+	segmentWildcard
+	// segmentDescendant selects the named members at any depth, or all values with a wildcard.
+	segmentDescendant
+)
+
+type pathSegment struct {
+	kind  segmentKind
+	name  string
+	index int
+}
+
+// Path is a compiled JSON path supporting the `$` root, `.name`,
+// `['name']`, `[n]`, `.*`, `[*]` and `..name` segments.
+type Path struct {
+	expr     string
+	segments []pathSegment
+}
+
+// Parse compiles a JSON path such as `$.structuredContent..email` or `$.content[*].text`.
+func Parse(expr string) (*Path, error) {
+	if !strings.HasPrefix(expr, "$") {
+		return nil, fmt.Errorf("invalid JSON path %q: must start with $", expr)
+	}
+
+	path := &Path{expr: expr}
+	rest := expr[1:]
+	for rest != "" {
+		var (
+			segment pathSegment
+			err     error
+		)
+
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			var name string
+			name, rest = readName(rest[2:])
+			if name == "" {
+				return nil, fmt.Errorf("invalid JSON path %q: missing name after ..", expr)
+			}
+			segment = pathSegment{kind: segmentDescendant, name: name}
+		case rest[0] == '.':
+			var name string
+			name, rest = readName(rest[1:])
+			switch name {
+			case "":
+				return nil, fmt.Errorf("invalid JSON path %q: missing name after .", expr)
+			case "*":
+				segment = pathSegment{kind: segmentWildcard}
+			default:
+				segment = pathSegment{kind: segmentChild, name: name}
+			}
+		case rest[0] == '[':
+			segment, rest, err = readBracket(rest)
+			if err != nil {
+				return nil, fmt.Errorf("invalid JSON path %q: %w", expr, err)
+			}
+		default:
+			return nil, fmt.Errorf("invalid JSON path %q: unexpected %q", expr, rest[0])
+		}
+
+		path.segments = append(path.segments, segment)
+	}
+
+	return path, nil
+}
+
+// readName reads a dot-notation name, up to the next segment.
+func readName(s string) (name, rest string) {
+	end := strings.IndexAny(s, ".[")
+	if end < 0 {
+		return s, ""
+	}
+	return s[:end], s[end:]
+}
+
+// readBracket reads a bracket segment: `[n]`, `[*]` or a quoted name.
+func readBracket(s string) (pathSegment, string, error) {
+	end := strings.IndexByte(s, ']')
+	if end < 0 {
+		return pathSegment{}, "", fmt.Errorf("unclosed [")
+	}
+
+	inner, rest := s[1:end], s[end+1:]
+	switch {
+	case inner == "*":
+		return pathSegment{kind: segmentWildcard}, rest, nil
+	case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+		return pathSegment{kind: segmentChild, name: inner[1 : len(inner)-1]}, rest, nil
+	}
+
+	index, err := strconv.Atoi(inner)
+	if err != nil {
+		return pathSegment{}, "", fmt.Errorf("invalid index %q", inner)
+	}
+	return pathSegment{kind: segmentIndex, index: index}, rest, nil
+}
+
+// String returns the expression of the path.
+func (p *Path) String() string {
+	return p.expr
+}
+
+// Modify replaces the values selected by the path in the decoded JSON value
+// with the result of fn, and returns the modified value.
+func (p *Path) Modify(value any, fn func(any) any) any {
+	return modify(value, p.segments, fn)
+}
+
+func modify(value any, segments []pathSegment, fn func(any) any) any {
+	if len(segments) == 0 {
+		return fn(value)
+	}
+
+	segment, rest := segments[0], segments[1:]
+	switch segment.kind {
+	case segmentChild:
+		if obj, ok := value.(map[string]any); ok {
+			if child, ok := obj[segment.name]; ok {
+				obj[segment.name] = modify(child, rest, fn)
+			}
+		}
+	case segmentIndex:
+		if arr, ok := value.([]any); ok {
+			index := segment.index
+			if index < 0 {
+				index += len(arr)
+			}
+			if index >= 0 && index < len(arr) {
+				arr[index] = modify(arr[index], rest, fn)
+			}
+		}
+	case segmentWildcard:
+		EachChild(value, func(child any) any {
+			return modify(child, rest, fn)
+		})
+	case segmentDescendant:
+		step := pathSegment{kind: segmentChild, name: segment.name}
+		if segment.name == "*" {
+			step = pathSegment{kind: segmentWildcard}
+		}
+		value = modify(value, append([]pathSegment{step}, rest...), fn)
+		EachChild(value, func(child any) any {
+			return modify(child, segments, fn)
+		})
+	}
+
+	return value
+}
+
+// EachChild replaces the members of an object or the elements of an array with the result of fn.
+func EachChild(value any, fn func(any) any) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			v[key] = fn(child)
+		}
+	case []any:
+		for i, child := range v {
+			v[i] = fn(child)
+		}
+	}
+}
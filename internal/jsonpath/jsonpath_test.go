@@ -0,0 +1,46 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPath_Modify(t *testing.T) {
+	const doc = `{"user":{"email":"a@b.c","name":"Ann"},"items":[{"email":"x@y.z","id":1},{"id":2}],"list":["a","b","c"]}`
+
+	testCases := []struct {
+		path     string
+		expected string
+	}{
+		{path: "$.user.email", expected: `{"user":{"email":"X","name":"Ann"},"items":[{"email":"x@y.z","id":1},{"id":2}],"list":["a","b","c"]}`},
+		{path: "$['user']['name']", expected: `{"user":{"email":"a@b.c","name":"X"},"items":[{"email":"x@y.z","id":1},{"id":2}],"list":["a","b","c"]}`},
+		{path: "$.items[*].id", expected: `{"user":{"email":"a@b.c","name":"Ann"},"items":[{"email":"x@y.z","id":"X"},{"id":"X"}],"list":["a","b","c"]}`},
+		{path: "$.list[-1]", expected: `{"user":{"email":"a@b.c","name":"Ann"},"items":[{"email":"x@y.z","id":1},{"id":2}],"list":["a","b","X"]}`},
+		{path: "$..email", expected: `{"user":{"email":"X","name":"Ann"},"items":[{"email":"X","id":1},{"id":2}],"list":["a","b","c"]}`},
+		{path: "$.user.*", expected: `{"user":{"email":"X","name":"X"},"items":[{"email":"x@y.z","id":1},{"id":2}],"list":["a","b","c"]}`},
+		{path: "$.missing.email", expected: doc},
+		{path: "$.list[7]", expected: doc},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			path, err := Parse(tc.path)
+			require.NoError(t, err)
+
+			var value any
+			require.NoError(t, json.Unmarshal([]byte(doc), &value))
+
+			modified, err := json.Marshal(path.Modify(value, func(any) any { return "X" }))
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.expected, string(modified))
+		})
+	}
+
+	for _, invalid := range []string{"user.email", "$.", "$..", "$[1", "$[x]", "$x"} {
+		_, err := Parse(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
@@ -24,6 +24,8 @@ const (
 	CodeQuotaExceeded = -32005
 	// CodeIPBlocked indicates the IP address is blocked (-32006).
 	CodeIPBlocked = -32006
+	// CodeContentBlocked indicates the response content was blocked by the gateway (-32007).
+	CodeContentBlocked = -32007
 )
 
 // MapHTTPStatusToJSONRPCCode maps HTTP status codes to JSON-RPC error codes.
@@ -0,0 +1,302 @@
+package loadbalancer
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Algorithm names the strategy used to pick an upstream host.
+type Algorithm string
+
+const (
+	// RoundRobin cycles through the host list in order. Hosts that appear
+	// several times in the list receive a proportional share of traffic.
+	RoundRobin Algorithm = "round_robin"
+	// WeightedRoundRobin uses smooth weighted round-robin, spreading the
+	// picks of heavier hosts evenly instead of sending them in bursts.
+	WeightedRoundRobin Algorithm = "weighted_round_robin"
+	// LeastConnections picks the host with the fewest in-flight requests
+	// relative to its weight.
+	LeastConnections Algorithm = "least_connections"
+	// EWMA picks the host with the lowest exponentially weighted moving
+	// average latency, scaled by the number of in-flight requests.
+	EWMA Algorithm = "ewma"
+	// ConsistentHash maps a request key onto a hash ring so that the same
+	// key keeps hitting the same host while the host list is stable.
+	ConsistentHash Algorithm = "consistent_hash"
+)
+
+// ErrNoHealthyHosts is returned when every host in the list is skipped.
+var ErrNoHealthyHosts = errors.New("no healthy hosts available")
+
+// ErrEmptyHostList is returned when the host list is empty.
+var ErrEmptyHostList = errors.New("host list is empty")
+
+// defaultDecay is the time constant for the EWMA latency average.
+const defaultDecay = 10 * time.Second
+
+// Valid reports whether a is a known algorithm. The empty value is valid
+// and behaves as RoundRobin.
+func (a Algorithm) Valid() bool {
+	switch a {
+	case "", RoundRobin, WeightedRoundRobin, LeastConnections, EWMA, ConsistentHash:
+		return true
+	}
+	return false
+}
+
+// Balancer selects hosts from a host list and keeps the per-host load
+// statistics that the selection strategies rely on. It is safe for
+// concurrent use.
+type Balancer struct {
+	algorithm Algorithm
+	decay     time.Duration
+	now       func() time.Time
+
+	pos uint32
+
+	mu    sync.Mutex
+	stats map[string]*hostStats
+	ring  *hashRing
+}
+
+type hostStats struct {
+	inflight      int64
+	latency       float64
+	lastObserved  time.Time
+	currentWeight int
+}
+
+// weightedHost is a unique host together with the number of times it
+// appears in the host list.
+type weightedHost struct {
+	host   string
+	weight int
+}
+
+// New creates a Balancer using the given algorithm. An empty or unknown
+// algorithm falls back to RoundRobin.
+func New(algorithm Algorithm) *Balancer {
+	if algorithm == "" || !algorithm.Valid() {
+		algorithm = RoundRobin
+	}
+
+	return &Balancer{
+		algorithm: algorithm,
+		decay:     defaultDecay,
+		now:       time.Now,
+		stats:     make(map[string]*hostStats),
+	}
+}
+
+// Algorithm returns the algorithm used by the balancer.
+func (b *Balancer) Algorithm() Algorithm {
+	return b.algorithm
+}
+
+// Next returns a host from hosts. Hosts for which skip returns true are not
+// considered; skip may be nil. The key is only used by ConsistentHash, an
+// empty key falls back to round-robin selection.
+func (b *Balancer) Next(hosts []string, key string, skip func(host string) bool) (string, error) {
+	if len(hosts) == 0 {
+		return "", ErrEmptyHostList
+	}
+
+	if skip == nil {
+		skip = func(string) bool { return false }
+	}
+
+	switch b.algorithm {
+	case WeightedRoundRobin:
+		return b.nextWeighted(hosts, skip)
+	case LeastConnections, EWMA:
+		return b.nextLeastLoaded(hosts, skip)
+	case ConsistentHash:
+		if key != "" {
+			return b.nextHashed(hosts, key, skip)
+		}
+	}
+
+	return b.nextRoundRobin(hosts, skip)
+}
+
+// Acquire marks a request to host as in flight. The returned function
+// must be called exactly once when the request completes.
+func (b *Balancer) Acquire(host string) func() {
+	b.mu.Lock()
+	s := b.statsFor(host)
+	s.inflight++
+	b.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			s.inflight--
+			b.mu.Unlock()
+		})
+	}
+}
+
+// Observe records the upstream latency of a completed request to host.
+func (b *Balancer) Observe(host string, latency time.Duration) {
+	now := b.now()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := b.statsFor(host)
+	if s.lastObserved.IsZero() {
+		s.latency = float64(latency)
+		s.lastObserved = now
+		return
+	}
+
+	elapsed := now.Sub(s.lastObserved)
+	if elapsed < 0 {
+		elapsed = 0
+	}
+
+	w := math.Exp(-float64(elapsed) / float64(b.decay))
+	s.latency = s.latency*w + float64(latency)*(1-w)
+	s.lastObserved = now
+}
+
+// InFlight returns the number of in-flight requests recorded for host.
+func (b *Balancer) InFlight(host string) int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if s, ok := b.stats[host]; ok {
+		return s.inflight
+	}
+	return 0
+}
+
+func (b *Balancer) statsFor(host string) *hostStats {
+	s, ok := b.stats[host]
+	if !ok {
+		s = &hostStats{}
+		b.stats[host] = s
+	}
+	return s
+}
+
+func (b *Balancer) nextRoundRobin(hosts []string, skip func(string) bool) (string, error) {
+	// -1 to start at 0, not 1
+	start := int((atomic.AddUint32(&b.pos, 1) - 1) % uint32(len(hosts)))
+	for i := 0; i < len(hosts); i++ {
+		host := hosts[(start+i)%len(hosts)]
+		if !skip(host) {
+			return host, nil
+		}
+	}
+	return "", ErrNoHealthyHosts
+}
+
+func (b *Balancer) nextWeighted(hosts []string, skip func(string) bool) (string, error) {
+	weighted := groupHosts(hosts)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var (
+		best  *hostStats
+		pick  string
+		total int
+	)
+
+	for _, wh := range weighted {
+		if skip(wh.host) {
+			continue
+		}
+
+		s := b.statsFor(wh.host)
+		s.currentWeight += wh.weight
+		total += wh.weight
+
+		if best == nil || s.currentWeight > best.currentWeight {
+			best, pick = s, wh.host
+		}
+	}
+
+	if best == nil {
+		return "", ErrNoHealthyHosts
+	}
+
+	best.currentWeight -= total
+	return pick, nil
+}
+
+func (b *Balancer) nextLeastLoaded(hosts []string, skip func(string) bool) (string, error) {
+	weighted := groupHosts(hosts)
+
+	// Rotate the starting point so that ties are spread across hosts.
+	start := int((atomic.AddUint32(&b.pos, 1) - 1) % uint32(len(weighted)))
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var (
+		pick      string
+		bestScore = math.Inf(1)
+	)
+
+	for i := 0; i < len(weighted); i++ {
+		wh := weighted[(start+i)%len(weighted)]
+		if skip(wh.host) {
+			continue
+		}
+
+		if score := b.score(b.statsFor(wh.host), wh.weight); score < bestScore {
+			bestScore, pick = score, wh.host
+		}
+	}
+
+	if pick == "" {
+		return "", ErrNoHealthyHosts
+	}
+
+	return pick, nil
+}
+
+func (b *Balancer) score(s *hostStats, weight int) float64 {
+	load := float64(s.inflight + 1)
+	if b.algorithm == EWMA {
+		// Hosts without samples score lowest, so they get probed first.
+		load *= s.latency + 1
+	}
+	return load / float64(weight)
+}
+
+func (b *Balancer) nextHashed(hosts []string, key string, skip func(string) bool) (string, error) {
+	b.mu.Lock()
+	if b.ring == nil || !b.ring.matches(hosts) {
+		b.ring = newHashRing(hosts)
+	}
+	ring := b.ring
+	b.mu.Unlock()
+
+	return ring.get(key, skip)
+}
+
+// groupHosts collapses duplicate hosts into a single entry with a weight
+// equal to the number of occurrences, preserving first-seen order.
+func groupHosts(hosts []string) []weightedHost {
+	index := make(map[string]int, len(hosts))
+	weighted := make([]weightedHost, 0, len(hosts))
+
+	for _, host := range hosts {
+		if i, ok := index[host]; ok {
+			weighted[i].weight++
+			continue
+		}
+		index[host] = len(weighted)
+		weighted = append(weighted, weightedHost{host: host, weight: 1})
+	}
+
+	return weighted
+}
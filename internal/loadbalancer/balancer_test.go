@@ -0,0 +1,157 @@
+package loadbalancer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_DefaultsToRoundRobin(t *testing.T) {
+	assert.Equal(t, RoundRobin, New("").Algorithm())
+	assert.Equal(t, RoundRobin, New("unknown").Algorithm())
+	assert.Equal(t, EWMA, New(EWMA).Algorithm())
+}
+
+func TestBalancer_Next_EmptyList(t *testing.T) {
+	_, err := New(RoundRobin).Next(nil, "", nil)
+	assert.ErrorIs(t, err, ErrEmptyHostList)
+}
+
+func TestBalancer_RoundRobin(t *testing.T) {
+	b := New(RoundRobin)
+	hosts := []string{"a", "b", "c"}
+
+	var got []string
+	for i := 0; i < 6; i++ {
+		host, err := b.Next(hosts, "", nil)
+		require.NoError(t, err)
+		got = append(got, host)
+	}
+
+	assert.Equal(t, []string{"a", "b", "c", "a", "b", "c"}, got)
+}
+
+func TestBalancer_SkipsDownHosts(t *testing.T) {
+	down := func(host string) bool { return host != "c" }
+
+	for _, algorithm := range []Algorithm{RoundRobin, WeightedRoundRobin, LeastConnections, EWMA, ConsistentHash} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			b := New(algorithm)
+			for i := 0; i < 5; i++ {
+				host, err := b.Next([]string{"a", "b", "c"}, "key", down)
+				require.NoError(t, err)
+				assert.Equal(t, "c", host)
+			}
+
+			_, err := b.Next([]string{"a", "b"}, "key", down)
+			assert.ErrorIs(t, err, ErrNoHealthyHosts)
+		})
+	}
+}
+
+func TestBalancer_WeightedRoundRobin(t *testing.T) {
+	b := New(WeightedRoundRobin)
+	hosts := []string{"a", "a", "a", "b"}
+
+	var got []string
+	for i := 0; i < 8; i++ {
+		host, err := b.Next(hosts, "", nil)
+		require.NoError(t, err)
+		got = append(got, host)
+	}
+
+	// Smooth weighted round-robin interleaves the light host instead of
+	// sending the heavy host's picks in a single burst.
+	assert.Equal(t, []string{"a", "a", "b", "a", "a", "a", "b", "a"}, got)
+}
+
+func TestBalancer_LeastConnections(t *testing.T) {
+	b := New(LeastConnections)
+	hosts := []string{"a", "b"}
+
+	releaseA := b.Acquire("a")
+	releaseA2 := b.Acquire("a")
+	assert.Equal(t, int64(2), b.InFlight("a"))
+
+	for i := 0; i < 3; i++ {
+		host, err := b.Next(hosts, "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "b", host)
+	}
+
+	releaseA()
+	releaseA()
+	releaseA2()
+	assert.Equal(t, int64(0), b.InFlight("a"))
+
+	releaseB := b.Acquire("b")
+	defer releaseB()
+
+	host, err := b.Next(hosts, "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "a", host)
+}
+
+func TestBalancer_EWMA(t *testing.T) {
+	now := time.Now()
+	b := New(EWMA)
+	b.now = func() time.Time { return now }
+
+	b.Observe("a", 200*time.Millisecond)
+	b.Observe("b", 10*time.Millisecond)
+
+	for i := 0; i < 3; i++ {
+		host, err := b.Next([]string{"a", "b"}, "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "b", host)
+	}
+
+	now = now.Add(time.Minute)
+	b.Observe("b", time.Second)
+
+	host, err := b.Next([]string{"a", "b"}, "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "a", host)
+}
+
+func TestBalancer_ConsistentHash(t *testing.T) {
+	b := New(ConsistentHash)
+	hosts := []string{"a", "b", "c", "d"}
+
+	picks := map[string]string{}
+	for _, key := range []string{"tenant-1", "tenant-2", "tenant-3", "10.0.0.1"} {
+		host, err := b.Next(hosts, key, nil)
+		require.NoError(t, err)
+		picks[key] = host
+
+		for i := 0; i < 5; i++ {
+			again, err := b.Next(hosts, key, nil)
+			require.NoError(t, err)
+			assert.Equal(t, host, again)
+		}
+	}
+
+	// Removing a host only remaps the keys which were on it.
+	shrunk := []string{"a", "b", "c"}
+	for key, host := range picks {
+		if host == "d" {
+			continue
+		}
+		again, err := b.Next(shrunk, key, nil)
+		require.NoError(t, err)
+		assert.Equal(t, host, again)
+	}
+}
+
+func TestBalancer_ConsistentHash_EmptyKeyFallsBack(t *testing.T) {
+	b := New(ConsistentHash)
+
+	first, err := b.Next([]string{"a", "b"}, "", nil)
+	require.NoError(t, err)
+	second, err := b.Next([]string{"a", "b"}, "", nil)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, first, second)
+}
@@ -0,0 +1,86 @@
+package loadbalancer
+
+import (
+	"hash/crc32"
+	"sort"
+	"strconv"
+)
+
+// replicasPerWeight is the number of virtual nodes placed on the ring for
+// each unit of host weight.
+const replicasPerWeight = 64
+
+type ringNode struct {
+	hash uint32
+	host string
+}
+
+// hashRing is an immutable consistent hash ring built from a host list.
+type hashRing struct {
+	hosts []string
+	nodes []ringNode
+}
+
+func newHashRing(hosts []string) *hashRing {
+	weighted := groupHosts(hosts)
+
+	r := &hashRing{
+		hosts: append([]string(nil), hosts...),
+		nodes: make([]ringNode, 0, len(hosts)*replicasPerWeight),
+	}
+
+	for _, wh := range weighted {
+		for i := 0; i < wh.weight*replicasPerWeight; i++ {
+			r.nodes = append(r.nodes, ringNode{
+				hash: crc32.ChecksumIEEE([]byte(wh.host + "#" + strconv.Itoa(i))),
+				host: wh.host,
+			})
+		}
+	}
+
+	sort.Slice(r.nodes, func(i, j int) bool {
+		return r.nodes[i].hash < r.nodes[j].hash
+	})
+
+	return r
+}
+
+// matches reports whether the ring was built from the same host list.
+func (r *hashRing) matches(hosts []string) bool {
+	if len(r.hosts) != len(hosts) {
+		return false
+	}
+	for i := range hosts {
+		if r.hosts[i] != hosts[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// get walks the ring clockwise from the position of key and returns the
+// first host that is not skipped.
+func (r *hashRing) get(key string, skip func(string) bool) (string, error) {
+	if len(r.nodes) == 0 {
+		return "", ErrEmptyHostList
+	}
+
+	h := crc32.ChecksumIEEE([]byte(key))
+	start := sort.Search(len(r.nodes), func(i int) bool {
+		return r.nodes[i].hash >= h
+	})
+
+	tried := make(map[string]struct{})
+	for i := 0; i < len(r.nodes); i++ {
+		host := r.nodes[(start+i)%len(r.nodes)].host
+		if _, ok := tried[host]; ok {
+			continue
+		}
+		if !skip(host) {
+			return host, nil
+		}
+		tried[host] = struct{}{}
+	}
+
+	return "", ErrNoHealthyHosts
+}
@@ -0,0 +1,162 @@
+package loadbalancer
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	defaultOutlierWindow       = 10 * time.Second
+	defaultOutlierEjectionTime = 30 * time.Second
+	defaultOutlierMinRequests  = 5
+)
+
+// OutlierConfig configures passive outlier detection.
+type OutlierConfig struct {
+	// ErrorRatio is the fraction of failed requests within Window at which
+	// a host is ejected, in the range (0, 1].
+	ErrorRatio float64
+	// MinRequests is the number of requests a host must serve within
+	// Window before its error ratio is evaluated.
+	MinRequests int
+	// Window is the interval over which results are counted.
+	Window time.Duration
+	// EjectionTime is how long a host is ejected for the first time. It
+	// doubles for each consecutive ejection, up to MaxEjectionTime.
+	EjectionTime time.Duration
+	// MaxEjectionTime caps the ejection backoff. Defaults to ten times
+	// EjectionTime when unset.
+	MaxEjectionTime time.Duration
+}
+
+// OutlierDetector ejects hosts whose recent error ratio crosses a threshold,
+// based on the outcome of proxied requests rather than active probes. It is
+// safe for concurrent use.
+type OutlierDetector struct {
+	conf OutlierConfig
+	now  func() time.Time
+
+	// OnEject is called when a host gets ejected.
+	OnEject func(host string, failed, total int)
+	// OnRecover is called when an ejected host returns to rotation.
+	OnRecover func(host string)
+
+	mu    sync.Mutex
+	hosts map[string]*outlierState
+}
+
+type outlierState struct {
+	windowStart  time.Time
+	total        int
+	failed       int
+	ejectedUntil time.Time
+	ejected      bool
+	ejections    int
+}
+
+// NewOutlierDetector creates an OutlierDetector, applying defaults for
+// unset configuration values.
+func NewOutlierDetector(conf OutlierConfig) *OutlierDetector {
+	if conf.ErrorRatio <= 0 || conf.ErrorRatio > 1 {
+		conf.ErrorRatio = 0.5
+	}
+	if conf.MinRequests <= 0 {
+		conf.MinRequests = defaultOutlierMinRequests
+	}
+	if conf.Window <= 0 {
+		conf.Window = defaultOutlierWindow
+	}
+	if conf.EjectionTime <= 0 {
+		conf.EjectionTime = defaultOutlierEjectionTime
+	}
+	if conf.MaxEjectionTime < conf.EjectionTime {
+		conf.MaxEjectionTime = 10 * conf.EjectionTime
+	}
+
+	return &OutlierDetector{
+		conf:  conf,
+		now:   time.Now,
+		hosts: make(map[string]*outlierState),
+	}
+}
+
+// Record registers the outcome of a request to host.
+func (d *OutlierDetector) Record(host string, failed bool) {
+	now := d.now()
+
+	d.mu.Lock()
+	s, ok := d.hosts[host]
+	if !ok {
+		s = &outlierState{windowStart: now}
+		d.hosts[host] = s
+	}
+
+	if s.ejected {
+		// In-flight requests sent before the ejection don't count.
+		d.mu.Unlock()
+		return
+	}
+
+	if now.Sub(s.windowStart) >= d.conf.Window {
+		if s.total > 0 && s.failed == 0 {
+			// A clean window resets the ejection backoff.
+			s.ejections = 0
+		}
+		s.windowStart, s.total, s.failed = now, 0, 0
+	}
+
+	s.total++
+	if failed {
+		s.failed++
+	}
+
+	eject := s.total >= d.conf.MinRequests &&
+		float64(s.failed)/float64(s.total) >= d.conf.ErrorRatio
+
+	var total, failures int
+	if eject {
+		backoff := d.conf.EjectionTime << s.ejections
+		if backoff <= 0 || backoff > d.conf.MaxEjectionTime {
+			backoff = d.conf.MaxEjectionTime
+		}
+
+		total, failures = s.total, s.failed
+		s.ejected = true
+		s.ejectedUntil = now.Add(backoff)
+		s.ejections++
+		s.windowStart, s.total, s.failed = now, 0, 0
+	}
+	d.mu.Unlock()
+
+	if eject && d.OnEject != nil {
+		d.OnEject(host, failures, total)
+	}
+}
+
+// Ejected reports whether host is currently ejected. A host whose ejection
+// period has elapsed is returned to rotation and OnRecover is called.
+func (d *OutlierDetector) Ejected(host string) bool {
+	now := d.now()
+
+	d.mu.Lock()
+	s, ok := d.hosts[host]
+	if !ok || !s.ejected {
+		d.mu.Unlock()
+		return false
+	}
+
+	if now.Before(s.ejectedUntil) {
+		d.mu.Unlock()
+		return true
+	}
+
+	s.ejected = false
+	s.windowStart = now
+	d.mu.Unlock()
+
+	if d.OnRecover != nil {
+		d.OnRecover(host)
+	}
+
+	return false
+}
@@ -0,0 +1,103 @@
+package loadbalancer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutlierDetector(t *testing.T) {
+	now := time.Now()
+
+	var ejected, recovered []string
+
+	d := NewOutlierDetector(OutlierConfig{
+		ErrorRatio:      0.5,
+		MinRequests:     4,
+		Window:          10 * time.Second,
+		EjectionTime:    time.Second,
+		MaxEjectionTime: 3 * time.Second,
+	})
+	d.now = func() time.Time { return now }
+	d.OnEject = func(host string, failed, total int) {
+		ejected = append(ejected, host)
+		assert.Equal(t, 2, failed)
+		assert.Equal(t, 4, total)
+	}
+	d.OnRecover = func(host string) {
+		recovered = append(recovered, host)
+	}
+
+	fail := func() {
+		d.Record("a", false)
+		d.Record("a", true)
+		d.Record("a", false)
+		d.Record("a", true)
+	}
+
+	t.Run("below min requests", func(t *testing.T) {
+		d.Record("a", true)
+		d.Record("a", true)
+		d.Record("a", true)
+		assert.False(t, d.Ejected("a"))
+		assert.Empty(t, ejected)
+	})
+
+	t.Run("ejects at error ratio", func(t *testing.T) {
+		now = now.Add(10 * time.Second)
+		fail()
+		assert.True(t, d.Ejected("a"))
+		assert.False(t, d.Ejected("b"))
+		assert.Equal(t, []string{"a"}, ejected)
+
+		// Results arriving while ejected are ignored.
+		d.Record("a", true)
+		assert.Len(t, ejected, 1)
+	})
+
+	t.Run("recovers after backoff", func(t *testing.T) {
+		now = now.Add(999 * time.Millisecond)
+		assert.True(t, d.Ejected("a"))
+
+		now = now.Add(time.Millisecond)
+		assert.False(t, d.Ejected("a"))
+		assert.Equal(t, []string{"a"}, recovered)
+	})
+
+	t.Run("backoff grows and is capped", func(t *testing.T) {
+		fail()
+		now = now.Add(1999 * time.Millisecond)
+		assert.True(t, d.Ejected("a"))
+		now = now.Add(time.Millisecond)
+		assert.False(t, d.Ejected("a"))
+
+		fail()
+		now = now.Add(2999 * time.Millisecond)
+		assert.True(t, d.Ejected("a"))
+		now = now.Add(time.Millisecond)
+		assert.False(t, d.Ejected("a"))
+
+		fail()
+		now = now.Add(3 * time.Second)
+		assert.False(t, d.Ejected("a"))
+	})
+
+	t.Run("clean window resets backoff", func(t *testing.T) {
+		d.Record("a", false)
+		now = now.Add(10 * time.Second)
+		fail()
+		now = now.Add(time.Second)
+		assert.False(t, d.Ejected("a"))
+	})
+}
+
+func TestNewOutlierDetector_Defaults(t *testing.T) {
+	d := NewOutlierDetector(OutlierConfig{})
+
+	assert.Equal(t, 0.5, d.conf.ErrorRatio)
+	assert.Equal(t, defaultOutlierMinRequests, d.conf.MinRequests)
+	assert.Equal(t, defaultOutlierWindow, d.conf.Window)
+	assert.Equal(t, defaultOutlierEjectionTime, d.conf.EjectionTime)
+	assert.Equal(t, 10*defaultOutlierEjectionTime, d.conf.MaxEjectionTime)
+}
@@ -0,0 +1,200 @@
+// Package loadshed sheds load with a concurrency limit adjusted from the
+// observed upstream latency and errors, in the spirit of Netflix
+// concurrency-limits.
+package loadshed
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Algorithm adjusts the concurrency limit.
+type Algorithm string
+
+const (
+	// AIMD increases the limit by one for every successful request and
+	// decreases it multiplicatively on errors or requests slower than the
+	// latency threshold.
+	AIMD Algorithm = "aimd"
+	// Gradient scales the limit with the ratio between the long term and the
+	// current latency, shrinking it as soon as the upstream slows down.
+	Gradient Algorithm = "gradient"
+)
+
+const (
+	defaultInitialLimit    = 20
+	defaultMinLimit        = 1
+	defaultMaxLimit        = 1000
+	defaultBackoffRatio    = 0.9
+	defaultPriorityReserve = 0.1
+
+	// gradientSmoothing is the weight of a new limit over the current one.
+	gradientSmoothing = 0.2
+	// gradientLongWindow is the number of samples of the long term latency average.
+	gradientLongWindow = 600
+)
+
+// Config configures a Limiter.
+type Config struct {
+	Algorithm    Algorithm
+	InitialLimit int
+	MinLimit     int
+	MaxLimit     int
+	// LatencyThreshold is the latency over which AIMD decreases the limit.
+	// Zero only decreases it on errors.
+	LatencyThreshold time.Duration
+	// BackoffRatio is the factor the limit is multiplied by on overload.
+	BackoffRatio float64
+	// PriorityReserve is the share of the limit only priority requests can use.
+	// Nil reserves the default share, zero reserves none.
+	PriorityReserve *float64
+}
+
+// Limiter admits requests up to a dynamic concurrency limit. It is safe for
+// concurrent use.
+type Limiter struct {
+	conf Config
+	// priorityReserve is the share of the limit reserved to priority requests.
+	priorityReserve float64
+
+	mu       sync.Mutex
+	limit    float64
+	inFlight int
+	// longRTT is the long term latency average used by Gradient, in nanoseconds.
+	longRTT float64
+	samples int
+}
+
+// New creates a Limiter, applying defaults for unset values.
+func New(conf Config) *Limiter {
+	if conf.Algorithm != Gradient {
+		conf.Algorithm = AIMD
+	}
+	if conf.MinLimit <= 0 {
+		conf.MinLimit = defaultMinLimit
+	}
+	if conf.MaxLimit <= 0 {
+		conf.MaxLimit = defaultMaxLimit
+	}
+	if conf.MaxLimit < conf.MinLimit {
+		conf.MaxLimit = conf.MinLimit
+	}
+	if conf.InitialLimit <= 0 {
+		conf.InitialLimit = defaultInitialLimit
+	}
+	if conf.BackoffRatio <= 0 || conf.BackoffRatio >= 1 {
+		conf.BackoffRatio = defaultBackoffRatio
+	}
+	priorityReserve := defaultPriorityReserve
+	if conf.PriorityReserve != nil && *conf.PriorityReserve >= 0 && *conf.PriorityReserve < 1 {
+		priorityReserve = *conf.PriorityReserve
+	}
+
+	l := &Limiter{conf: conf, priorityReserve: priorityReserve}
+	l.limit = l.clamp(float64(conf.InitialLimit))
+
+	return l
+}
+
+// Acquire admits a request if the in-flight requests are under the limit.
+// Requests without priority are shed first, they can't use the reserved
+// share of the limit. The returned release func must be called once the
+// request is done.
+func (l *Limiter) Acquire(priority bool) (release func(), ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limit := l.limit
+	if !priority {
+		limit = math.Max(1, limit*(1-l.priorityReserve))
+	}
+
+	if float64(l.inFlight) >= limit {
+		return nil, false
+	}
+
+	l.inFlight++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			l.inFlight--
+			l.mu.Unlock()
+		})
+	}, true
+}
+
+// Observe adjusts the limit with the outcome of an upstream request.
+func (l *Limiter) Observe(latency time.Duration, failed bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if failed {
+		l.limit = l.clamp(l.limit * l.conf.BackoffRatio)
+		return
+	}
+
+	switch l.conf.Algorithm {
+	case Gradient:
+		l.observeGradient(float64(latency))
+	default:
+		l.observeAIMD(latency)
+	}
+}
+
+func (l *Limiter) observeAIMD(latency time.Duration) {
+	if l.conf.LatencyThreshold > 0 && latency > l.conf.LatencyThreshold {
+		l.limit = l.clamp(l.limit * l.conf.BackoffRatio)
+		return
+	}
+
+	// Only grow the limit when it's being used.
+	if float64(l.inFlight)*2 >= l.limit {
+		l.limit = l.clamp(l.limit + 1)
+	}
+}
+
+func (l *Limiter) observeGradient(rtt float64) {
+	if rtt <= 0 {
+		return
+	}
+
+	if l.samples < gradientLongWindow {
+		l.samples++
+	}
+	if l.longRTT == 0 {
+		l.longRTT = rtt
+	} else {
+		l.longRTT += (rtt - l.longRTT) / float64(l.samples)
+	}
+
+	// Let the long term average catch up once the latency drops.
+	if l.longRTT/rtt > 2 {
+		l.longRTT *= 0.95
+	}
+
+	gradient := math.Max(0.5, math.Min(1, l.longRTT/rtt))
+	queueSize := math.Sqrt(l.limit)
+	newLimit := l.limit*gradient + queueSize
+
+	// Don't grow the limit when it isn't being used.
+	if newLimit > l.limit && float64(l.inFlight)*2 < l.limit {
+		return
+	}
+
+	l.limit = l.clamp(l.limit*(1-gradientSmoothing) + newLimit*gradientSmoothing)
+}
+
+func (l *Limiter) clamp(limit float64) float64 {
+	return math.Max(float64(l.conf.MinLimit), math.Min(float64(l.conf.MaxLimit), limit))
+}
+
+// Limit returns the current concurrency limit.
+func (l *Limiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return int(l.limit)
+}
@@ -0,0 +1,98 @@
+package loadshed
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter_Acquire(t *testing.T) {
+	reserve := 0.2
+	l := New(Config{InitialLimit: 10, PriorityReserve: &reserve})
+
+	var releases []func()
+	for i := 0; i < 8; i++ {
+		release, ok := l.Acquire(false)
+		require.True(t, ok)
+		releases = append(releases, release)
+	}
+
+	_, ok := l.Acquire(false)
+	assert.False(t, ok, "requests without priority can't use the reserve")
+
+	for i := 0; i < 2; i++ {
+		release, ok := l.Acquire(true)
+		require.True(t, ok)
+		releases = append(releases, release)
+	}
+
+	_, ok = l.Acquire(true)
+	assert.False(t, ok, "the limit is reached")
+
+	releases[0]()
+	releases[0]()
+
+	_, ok = l.Acquire(true)
+	assert.True(t, ok, "released slots are available again")
+}
+
+func TestLimiter_NoPriorityReserve(t *testing.T) {
+	reserve := 0.0
+	l := New(Config{InitialLimit: 2, MinLimit: 2, MaxLimit: 2, PriorityReserve: &reserve})
+
+	for i := 0; i < 2; i++ {
+		_, ok := l.Acquire(false)
+		require.True(t, ok, "the whole limit is available without a reserve")
+	}
+
+	_, ok := l.Acquire(true)
+	assert.False(t, ok, "the limit is reached")
+}
+
+func TestLimiter_AIMD(t *testing.T) {
+	l := New(Config{Algorithm: AIMD, InitialLimit: 10, MinLimit: 5, MaxLimit: 12, LatencyThreshold: 100 * time.Millisecond})
+
+	for i := 0; i < 6; i++ {
+		_, ok := l.Acquire(true)
+		require.True(t, ok)
+	}
+
+	l.Observe(10*time.Millisecond, false)
+	assert.Equal(t, 11, l.Limit())
+	l.Observe(10*time.Millisecond, false)
+	l.Observe(10*time.Millisecond, false)
+	assert.Equal(t, 12, l.Limit(), "the limit is capped")
+
+	l.Observe(200*time.Millisecond, false)
+	assert.Equal(t, 10, l.Limit(), "slow requests decrease the limit")
+
+	l.Observe(10*time.Millisecond, true)
+	assert.Equal(t, 9, l.Limit(), "failed requests decrease the limit")
+
+	for i := 0; i < 20; i++ {
+		l.Observe(0, true)
+	}
+	assert.Equal(t, 5, l.Limit(), "the limit has a floor")
+}
+
+func TestLimiter_Gradient(t *testing.T) {
+	l := New(Config{Algorithm: Gradient, InitialLimit: 20, MaxLimit: 100})
+
+	for i := 0; i < 20; i++ {
+		_, ok := l.Acquire(true)
+		require.True(t, ok)
+	}
+
+	for i := 0; i < 50; i++ {
+		l.Observe(10*time.Millisecond, false)
+	}
+	grown := l.Limit()
+	assert.Greater(t, grown, 20, "a steady latency grows the limit")
+
+	for i := 0; i < 20; i++ {
+		l.Observe(50*time.Millisecond, false)
+	}
+	assert.Less(t, l.Limit(), grown, "an increased latency shrinks the limit")
+}
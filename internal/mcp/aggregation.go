@@ -0,0 +1,146 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+)
+
+// HeaderSessionID is the Streamable HTTP header carrying the MCP session ID.
+const HeaderSessionID = "Mcp-Session-Id"
+
+// MethodPing is the MCP liveness check method.
+const MethodPing = "ping"
+
+// AggregationMember is a member server of an aggregated MCP server.
+type AggregationMember struct {
+	// ID identifies the member, the API ID for gateway members.
+	ID string
+	// Prefix is prepended to the names of the member's tools and prompts and to the URIs of its resources.
+	Prefix string
+}
+
+// ListFilterConfigForMethod returns the list configuration of a list method,
+// or nil if the method doesn't list primitives.
+func ListFilterConfigForMethod(method string) *ListFilterConfig {
+	switch method {
+	case MethodToolsList:
+		return ListFilterConfigs["tools"]
+	case MethodPromptsList:
+		return ListFilterConfigs["prompts"]
+	case MethodResourcesList:
+		return ListFilterConfigs["resources"]
+	case MethodResourcesTemplatesList:
+		return ListFilterConfigs["resourceTemplates"]
+	default:
+		return nil
+	}
+}
+
+// PrefixItems prepends the prefix to the name field of the list items. Items
+// whose name field cannot be extracted are returned unmodified.
+func PrefixItems(items []json.RawMessage, nameField, prefix string) []json.RawMessage {
+	if prefix == "" {
+		return items
+	}
+
+	prefixed := make([]json.RawMessage, 0, len(items))
+	for _, item := range items {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(item, &obj); err != nil {
+			prefixed = append(prefixed, item)
+			continue
+		}
+
+		var name string
+		if err := json.Unmarshal(obj[nameField], &name); err != nil || name == "" {
+			prefixed = append(prefixed, item)
+			continue
+		}
+
+		obj[nameField], _ = json.Marshal(prefix + name)
+		raw, err := json.Marshal(obj)
+		if err != nil {
+			prefixed = append(prefixed, item)
+			continue
+		}
+		prefixed = append(prefixed, raw)
+	}
+	return prefixed
+}
+
+// ResolveAggregationMember returns the member owning the prefixed name, with
+// the name the member knows the primitive by. The longest matching prefix wins,
+// a member without prefix owns the names no other prefix matches.
+func ResolveAggregationMember(members []AggregationMember, name string) (AggregationMember, string, bool) {
+	var (
+		owner AggregationMember
+		found bool
+	)
+
+	for _, member := range members {
+		if !strings.HasPrefix(name, member.Prefix) {
+			continue
+		}
+		if !found || len(member.Prefix) > len(owner.Prefix) {
+			owner, found = member, true
+		}
+	}
+
+	if !found {
+		return AggregationMember{}, "", false
+	}
+	return owner, strings.TrimPrefix(name, owner.Prefix), true
+}
+
+// SetStringParam returns the params object with the key set to value.
+func SetStringParam(params json.RawMessage, key, value string) (json.RawMessage, error) {
+	obj := map[string]json.RawMessage{}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &obj); err != nil {
+			return nil, err
+		}
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	obj[key] = raw
+
+	return json.Marshal(obj)
+}
+
+// EncodeAggregateSessionID encodes the session IDs of the members, by member
+// ID, into the session ID of the aggregated server. It returns an empty string
+// when no member uses sessions.
+func EncodeAggregateSessionID(sessions map[string]string) string {
+	if len(sessions) == 0 {
+		return ""
+	}
+
+	raw, err := json.Marshal(sessions)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeAggregateSessionID decodes the member session IDs of an aggregated
+// server session ID. Invalid session IDs decode to no sessions.
+func DecodeAggregateSessionID(sessionID string) map[string]string {
+	sessions := map[string]string{}
+	if sessionID == "" {
+		return sessions
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(sessionID)
+	if err != nil {
+		return sessions
+	}
+
+	if err := json.Unmarshal(raw, &sessions); err != nil {
+		return map[string]string{}
+	}
+	return sessions
+}
@@ -0,0 +1,86 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefixItems(t *testing.T) {
+	items := []json.RawMessage{
+		json.RawMessage(`{"name":"search","description":"Search issues"}`),
+		json.RawMessage(`{"description":"no name"}`),
+		json.RawMessage(`not json`),
+	}
+
+	prefixed := PrefixItems(items, "name", "github_")
+	require.Len(t, prefixed, 3)
+	assert.JSONEq(t, `{"name":"github_search","description":"Search issues"}`, string(prefixed[0]))
+	assert.Equal(t, items[1], prefixed[1])
+	assert.Equal(t, items[2], prefixed[2])
+
+	assert.Equal(t, items, PrefixItems(items, "name", ""))
+}
+
+func TestResolveAggregationMember(t *testing.T) {
+	members := []AggregationMember{
+		{ID: "github", Prefix: "gh_"},
+		{ID: "github-enterprise", Prefix: "gh_ent_"},
+		{ID: "default"},
+	}
+
+	tests := []struct {
+		name       string
+		member     string
+		memberName string
+	}{
+		{"gh_search", "github", "search"},
+		{"gh_ent_search", "github-enterprise", "search"},
+		{"search", "default", "search"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			member, memberName, ok := ResolveAggregationMember(members, tc.name)
+			require.True(t, ok)
+			assert.Equal(t, tc.member, member.ID)
+			assert.Equal(t, tc.memberName, memberName)
+		})
+	}
+
+	_, _, ok := ResolveAggregationMember(members[:2], "jira_search")
+	assert.False(t, ok)
+}
+
+func TestSetStringParam(t *testing.T) {
+	params, err := SetStringParam(json.RawMessage(`{"name":"gh_search","arguments":{"q":"bug"}}`), ParamKeyName, "search")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"search","arguments":{"q":"bug"}}`, string(params))
+
+	params, err = SetStringParam(nil, "cursor", "next")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"cursor":"next"}`, string(params))
+
+	_, err = SetStringParam(json.RawMessage(`[]`), ParamKeyName, "search")
+	assert.Error(t, err)
+}
+
+func TestAggregateSessionID(t *testing.T) {
+	sessions := map[string]string{"github": "abc", "jira": "def"}
+
+	sessionID := EncodeAggregateSessionID(sessions)
+	assert.NotEmpty(t, sessionID)
+	assert.Equal(t, sessions, DecodeAggregateSessionID(sessionID))
+
+	assert.Empty(t, EncodeAggregateSessionID(nil))
+	assert.Empty(t, DecodeAggregateSessionID(""))
+	assert.Empty(t, DecodeAggregateSessionID("not a session"))
+}
+
+func TestListFilterConfigForMethod(t *testing.T) {
+	assert.Equal(t, ListFilterConfigs["tools"], ListFilterConfigForMethod(MethodToolsList))
+	assert.Equal(t, ListFilterConfigs["resourceTemplates"], ListFilterConfigForMethod(MethodResourcesTemplatesList))
+	assert.Nil(t, ListFilterConfigForMethod(MethodToolsCall))
+}
@@ -0,0 +1,335 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/TykTechnologies/tyk/internal/jsonpath"
+)
+
+const (
+	// DefaultRedactionReplacement replaces the redacted content when a rule doesn't set a replacement.
+	DefaultRedactionReplacement = "[REDACTED]"
+	// ErrMsgContentBlocked is the message of the errors replacing the blocked results.
+	ErrMsgContentBlocked = "response content blocked"
+)
+
+// Kinds of the content guard violations.
+const (
+	// ViolationRedaction is reported when content was redacted.
+	ViolationRedaction = "redaction"
+	// ViolationResultSize is reported when a result exceeds the maximum size.
+	ViolationResultSize = "result_size"
+	// ViolationContentType is reported when a result embeds a blocked content type.
+	ViolationContentType = "content_type"
+	// ViolationInvalidMessage is reported when a message isn't a JSON object, so its content can't be guarded.
+	ViolationInvalidMessage = "invalid_message"
+)
+
+// RedactionRule redacts content of the MCP results. With a pattern only, the
+// matches are redacted in all the strings of the result. With a path only, the
+// selected values are replaced. With both, the matches are redacted in the
+// strings under the selected values.
+type RedactionRule struct {
+	// Name identifies the rule in the violations, the path or pattern when empty.
+	Name string
+	// Pattern is a regular expression; the replacement can reference its groups, e.g. `$1`.
+	Pattern string
+	// Path is a JSON path into the result, e.g. `$.structuredContent..email`.
+	Path string
+	// Replacement replaces the redacted content, DefaultRedactionReplacement when empty.
+	Replacement string
+}
+
+// ContentGuardConfig configures a ContentGuard.
+type ContentGuardConfig struct {
+	Redactions []RedactionRule
+	// MaxResultSize is the maximum size in bytes of a result, unlimited when 0.
+	MaxResultSize int
+	// BlockedContentTypes are the content types results can't embed, e.g. `image` or `resource`.
+	BlockedContentTypes []string
+}
+
+// ContentViolation describes how a message broke the content guard. It never
+// carries the guarded content.
+type ContentViolation struct {
+	Kind string `json:"kind"`
+	// Rule is the name of the redaction rule.
+	Rule string `json:"rule,omitempty"`
+	// Count is the number of redacted values or matches.
+	Count int `json:"count,omitempty"`
+	// ContentType is the blocked content type.
+	ContentType string `json:"content_type,omitempty"`
+	// Size is the size of the result exceeding the maximum.
+	Size int `json:"size,omitempty"`
+}
+
+// ContentGuardOutcome is the outcome of guarding a JSON-RPC message.
+type ContentGuardOutcome struct {
+	// ID is the ID of the message.
+	ID json.RawMessage
+	// Message is the redacted message, nil when unchanged.
+	Message []byte
+	// Violations are the violations of the message, empty when it was let through unchanged.
+	Violations []ContentViolation
+	// Blocked reports whether the result must not reach the client.
+	Blocked bool
+}
+
+type compiledRedaction struct {
+	name        string
+	pattern     *regexp.Regexp
+	path        *jsonpath.Path
+	replacement string
+}
+
+// ContentGuard redacts, size-limits and filters the content types of the
+// results of MCP responses, so the upstream data reaching the clients can be
+// restricted.
+type ContentGuard struct {
+	redactions    []compiledRedaction
+	maxResultSize int
+	blockedTypes  map[string]bool
+}
+
+// NewContentGuard compiles the patterns and paths of the configuration.
+func NewContentGuard(cfg ContentGuardConfig) (*ContentGuard, error) {
+	g := &ContentGuard{
+		maxResultSize: cfg.MaxResultSize,
+		blockedTypes:  make(map[string]bool, len(cfg.BlockedContentTypes)),
+	}
+
+	for _, contentType := range cfg.BlockedContentTypes {
+		g.blockedTypes[contentType] = true
+	}
+
+	for i, rule := range cfg.Redactions {
+		if rule.Pattern == "" && rule.Path == "" {
+			return nil, fmt.Errorf("redaction %d: pattern or path is required", i)
+		}
+
+		compiled := compiledRedaction{name: rule.Name, replacement: rule.Replacement}
+		if compiled.replacement == "" {
+			compiled.replacement = DefaultRedactionReplacement
+		}
+
+		if rule.Pattern != "" {
+			// The standard library is used, the contents of the responses are
+			// too varied for the result caches of the tyk regexp package.
+			pattern, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("redaction %d: %w", i, err)
+			}
+			compiled.pattern = pattern
+		}
+
+		if rule.Path != "" {
+			path, err := jsonpath.Parse(rule.Path)
+			if err != nil {
+				return nil, fmt.Errorf("redaction %d: %w", i, err)
+			}
+			compiled.path = path
+		}
+
+		if compiled.name == "" {
+			compiled.name = rule.Path
+		}
+		if compiled.name == "" {
+			compiled.name = rule.Pattern
+		}
+
+		g.redactions = append(g.redactions, compiled)
+	}
+
+	return g, nil
+}
+
+// IsGuardedMethod reports whether the results of the method are guarded: the
+// tool calls, the prompts and the resource reads.
+func IsGuardedMethod(method string) bool {
+	switch method {
+	case MethodToolsCall, MethodPromptsGet, MethodResourcesRead:
+		return true
+	default:
+		return false
+	}
+}
+
+// Guard applies the guard to a JSON-RPC message. The result of a response is
+// checked for its size and content types, then redacted. The params of the
+// server requests and notifications and the errors are redacted. Messages
+// which aren't JSON objects are blocked, as their content can't be guarded.
+func (g *ContentGuard) Guard(msg []byte) ContentGuardOutcome {
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(msg, &envelope); err != nil || envelope == nil {
+		return ContentGuardOutcome{
+			Violations: []ContentViolation{{Kind: ViolationInvalidMessage}},
+			Blocked:    true,
+		}
+	}
+
+	outcome := ContentGuardOutcome{ID: envelope["id"]}
+
+	key := "result"
+	switch {
+	case envelope["result"] != nil:
+		outcome.Violations = g.check(envelope["result"])
+		if len(outcome.Violations) > 0 {
+			outcome.Blocked = true
+			return outcome
+		}
+	case envelope["params"] != nil:
+		key = "params"
+	case envelope["error"] != nil:
+		key = "error"
+	default:
+		return outcome
+	}
+
+	redacted, violations := g.redact(envelope[key])
+	if len(violations) == 0 {
+		return outcome
+	}
+
+	envelope[key] = redacted
+	message, err := marshalJSON(envelope)
+	if err != nil {
+		return outcome
+	}
+
+	outcome.Message = message
+	outcome.Violations = violations
+	return outcome
+}
+
+// check returns the size and content type violations of the result.
+func (g *ContentGuard) check(result json.RawMessage) []ContentViolation {
+	if g.maxResultSize > 0 && len(result) > g.maxResultSize {
+		return []ContentViolation{{Kind: ViolationResultSize, Size: len(result)}}
+	}
+
+	if len(g.blockedTypes) == 0 {
+		return nil
+	}
+
+	var parsed struct {
+		Content  []contentItem `json:"content"`
+		Messages []struct {
+			Content contentItem `json:"content"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal(result, &parsed); err != nil {
+		return nil
+	}
+
+	items := parsed.Content
+	for _, message := range parsed.Messages {
+		items = append(items, message.Content)
+	}
+
+	var violations []ContentViolation
+	seen := map[string]bool{}
+	for _, item := range items {
+		if g.blockedTypes[item.Type] && !seen[item.Type] {
+			seen[item.Type] = true
+			violations = append(violations, ContentViolation{Kind: ViolationContentType, ContentType: item.Type})
+		}
+	}
+	return violations
+}
+
+// contentItem is an MCP content item of a tool call result or prompt message.
+type contentItem struct {
+	Type string `json:"type"`
+}
+
+// redact applies the redaction rules to the value.
+func (g *ContentGuard) redact(raw json.RawMessage) (json.RawMessage, []ContentViolation) {
+	if len(g.redactions) == 0 {
+		return raw, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return raw, nil
+	}
+
+	var violations []ContentViolation
+	for _, rule := range g.redactions {
+		var count int
+		value, count = rule.apply(value)
+		if count > 0 {
+			violations = append(violations, ContentViolation{Kind: ViolationRedaction, Rule: rule.name, Count: count})
+		}
+	}
+
+	if len(violations) == 0 {
+		return raw, nil
+	}
+
+	redacted, err := marshalJSON(value)
+	if err != nil {
+		return raw, nil
+	}
+	return redacted, violations
+}
+
+// apply redacts the value, returning it along with the number of redactions.
+func (r compiledRedaction) apply(value any) (any, int) {
+	var count int
+
+	redactMatches := func(v any) any {
+		return mapJSONStrings(v, func(s string) string {
+			matches := len(r.pattern.FindAllStringIndex(s, -1))
+			if matches == 0 {
+				return s
+			}
+			count += matches
+			return r.pattern.ReplaceAllString(s, r.replacement)
+		})
+	}
+
+	switch {
+	case r.path == nil:
+		value = redactMatches(value)
+	case r.pattern == nil:
+		value = r.path.Modify(value, func(any) any {
+			count++
+			return r.replacement
+		})
+	default:
+		value = r.path.Modify(value, redactMatches)
+	}
+
+	return value, count
+}
+
+// mapJSONStrings replaces the strings of the decoded JSON value with the result of fn.
+func mapJSONStrings(value any, fn func(string) string) any {
+	switch v := value.(type) {
+	case string:
+		return fn(v)
+	case map[string]any, []any:
+		jsonpath.EachChild(v, func(child any) any {
+			return mapJSONStrings(child, fn)
+		})
+	}
+	return value
+}
+
+// marshalJSON encodes the value without escaping HTML characters, which the
+// redacted content may contain.
+func marshalJSON(value any) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
@@ -73,4 +73,5 @@ const (
 	ErrMsgEmptyParamName    = "parameter 'name' cannot be empty"
 	ErrMsgEmptyParamURI     = "parameter 'uri' cannot be empty"
 	ErrMsgInvalidParamsType = "invalid params: expected object"
+	ErrMsgUnknownTool       = "unknown tool"
 )
@@ -0,0 +1,684 @@
+// Package stdio bridges Streamable HTTP MCP traffic to MCP servers that only
+// speak JSON-RPC over the standard input and output of a local process.
+//
+// The Bridge is an http.RoundTripper: the gateway proxies the requests of an
+// MCP API to it as it would to a remote upstream, so the access control, rate
+// limiting and analytics of the API apply unchanged. The processes are spawned
+// per MCP session, or shared by all clients as a pool.
+package stdio
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/TykTechnologies/tyk/internal/mcp"
+	"github.com/TykTechnologies/tyk/internal/uuid"
+)
+
+const (
+	// ModeSession spawns a process per MCP session.
+	ModeSession = "session"
+	// ModePool shares a fixed number of processes among all clients.
+	ModePool = "pool"
+
+	// DefaultRestartBackoff is the delay before respawning a process that exited unexpectedly.
+	DefaultRestartBackoff = time.Second
+	// DefaultMaxRestartBackoff caps the doubling restart delay.
+	DefaultMaxRestartBackoff = 30 * time.Second
+	// DefaultMaxSessions is the limit of concurrent sessions.
+	DefaultMaxSessions = 64
+	// DefaultIdleTimeout is the duration after which unused processes are stopped.
+	DefaultIdleTimeout = 10 * time.Minute
+
+	// protocolVersion is the MCP protocol version the bridge initializes pooled processes with.
+	protocolVersion = "2025-06-18"
+	// methodInitialized is the notification completing the initialization.
+	methodInitialized = "notifications/initialized"
+
+	headerContentType = "Content-Type"
+	contentTypeJSON   = "application/json"
+	contentTypeSSE    = "text/event-stream"
+)
+
+var (
+	// errBackingOff is returned while the bridge waits to respawn a process.
+	errBackingOff = errors.New("MCP stdio server is restarting")
+	// errClosed is returned once the bridge is closed.
+	errClosed = errors.New("MCP stdio bridge is closed")
+	// errTooManySessions is returned when the sessions limit is reached.
+	errTooManySessions = errors.New("too many MCP sessions")
+)
+
+// Config configures a Bridge.
+type Config struct {
+	// Command is the executable of the MCP server.
+	Command string
+	// Args are the arguments of the command.
+	Args []string
+	// Env are the environment variables of the process, as KEY=value.
+	Env []string
+	// Dir is the working directory of the process.
+	Dir string
+
+	// Mode is ModeSession or ModePool, ModeSession when empty.
+	Mode string
+	// PoolSize is the number of processes of the pool, 1 when not positive.
+	PoolSize int
+	// MaxSessions limits the concurrent sessions, DefaultMaxSessions when not positive.
+	MaxSessions int
+	// IdleTimeout stops the processes unused for the duration, DefaultIdleTimeout when not positive.
+	IdleTimeout time.Duration
+	// RestartBackoff is the initial delay before respawning a process, DefaultRestartBackoff when zero.
+	RestartBackoff time.Duration
+	// MaxRestartBackoff caps the restart delay, DefaultMaxRestartBackoff when zero.
+	MaxRestartBackoff time.Duration
+
+	// Owner returns the client of a request. A session can only be used by
+	// the client which started it. The sessions are shared when it's nil.
+	Owner func(*http.Request) string
+
+	// Logger logs the lifecycle and the standard error of the processes.
+	Logger *logrus.Entry
+}
+
+// Bridge serves MCP requests with local MCP stdio servers.
+type Bridge struct {
+	cfg    Config
+	logger *logrus.Entry
+
+	mu       sync.Mutex
+	sessions map[string]*process
+	pool     []*process
+	next     int
+	backoff  time.Duration
+	retryAt  time.Time
+	closed   bool
+
+	stopJanitor chan struct{}
+}
+
+// New returns a bridge running the configured command. No process is
+// started until a client needs it.
+func New(cfg Config) *Bridge {
+	if cfg.Mode == "" {
+		cfg.Mode = ModeSession
+	}
+	if cfg.PoolSize <= 0 {
+		cfg.PoolSize = 1
+	}
+	if cfg.MaxSessions <= 0 {
+		cfg.MaxSessions = DefaultMaxSessions
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = DefaultIdleTimeout
+	}
+	if cfg.RestartBackoff <= 0 {
+		cfg.RestartBackoff = DefaultRestartBackoff
+	}
+	if cfg.MaxRestartBackoff <= 0 {
+		cfg.MaxRestartBackoff = DefaultMaxRestartBackoff
+	}
+	if cfg.MaxRestartBackoff < cfg.RestartBackoff {
+		cfg.MaxRestartBackoff = cfg.RestartBackoff
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = logrus.NewEntry(logrus.StandardLogger())
+	}
+
+	b := &Bridge{
+		cfg:         cfg,
+		logger:      cfg.Logger.WithField("command", cfg.Command),
+		sessions:    map[string]*process{},
+		pool:        make([]*process, cfg.PoolSize),
+		stopJanitor: make(chan struct{}),
+	}
+
+	go b.janitor()
+
+	return b
+}
+
+// Close stops the processes of the bridge. Later requests fail.
+func (b *Bridge) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	close(b.stopJanitor)
+
+	processes := make([]*process, 0, len(b.sessions)+len(b.pool))
+	for _, p := range b.sessions {
+		processes = append(processes, p)
+	}
+	for _, p := range b.pool {
+		if p != nil {
+			processes = append(processes, p)
+		}
+	}
+	b.sessions = map[string]*process{}
+	b.pool = make([]*process, b.cfg.PoolSize)
+	b.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, p := range processes {
+		wg.Add(1)
+		go func(p *process) {
+			defer wg.Done()
+			p.stop()
+		}(p)
+	}
+	wg.Wait()
+}
+
+// RoundTrip serves the Streamable HTTP request with an MCP stdio server.
+func (b *Bridge) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		defer req.Body.Close()
+	}
+
+	switch req.Method {
+	case http.MethodPost:
+		return b.post(req)
+	case http.MethodDelete:
+		return b.delete(req), nil
+	default:
+		res := newResponse(req, http.StatusMethodNotAllowed, nil)
+		res.Header.Set("Allow", "POST, DELETE")
+		return res, nil
+	}
+}
+
+// post relays the JSON-RPC message of the client to the process serving it.
+func (b *Bridge) post(req *http.Request) (*http.Response, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var msg map[string]json.RawMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return errorResponse(req, http.StatusBadRequest, nil, mcp.JSONRPCParseError, mcp.ErrMsgParseError), nil
+	}
+
+	var envelope message
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return errorResponse(req, http.StatusBadRequest, nil, mcp.JSONRPCInvalidRequest, mcp.ErrMsgInvalidRequest), nil
+	}
+
+	if b.cfg.Mode == ModePool {
+		return b.postPool(req, msg, envelope)
+	}
+	return b.postSession(req, msg, envelope)
+}
+
+// postSession serves the message with the process of its session. The
+// initialize requests without session start a new process.
+func (b *Bridge) postSession(req *http.Request, msg map[string]json.RawMessage, envelope message) (*http.Response, error) {
+	sessionID := req.Header.Get(mcp.HeaderSessionID)
+
+	var p *process
+	switch {
+	case sessionID != "":
+		p = b.session(req, sessionID)
+		if p == nil {
+			return errorResponse(req, http.StatusNotFound, envelope.ID, mcp.JSONRPCInvalidRequest, "session not found"), nil
+		}
+	case envelope.Method == mcp.MethodInitialize:
+		var err error
+		sessionID, p, err = b.startSession(b.owner(req))
+		if err != nil {
+			return b.startError(req, envelope.ID, err), nil
+		}
+	default:
+		return errorResponse(req, http.StatusBadRequest, envelope.ID, mcp.JSONRPCInvalidRequest, "missing session"), nil
+	}
+
+	res, err := b.relay(req, p, msg, envelope, acceptsSSE(req))
+	if res != nil {
+		res.Header.Set(mcp.HeaderSessionID, sessionID)
+	}
+	return res, err
+}
+
+// postPool serves the message with a process of the pool. The bridge
+// initializes the pooled processes itself, so the initialization of the
+// clients is answered without reaching them.
+func (b *Bridge) postPool(req *http.Request, msg map[string]json.RawMessage, envelope message) (*http.Response, error) {
+	if envelope.Method == methodInitialized {
+		return newResponse(req, http.StatusAccepted, nil), nil
+	}
+
+	p, err := b.pooled(req.Context())
+	if err != nil {
+		return b.startError(req, envelope.ID, err), nil
+	}
+
+	if envelope.Method == mcp.MethodInitialize && envelope.isRequest() {
+		return resultResponse(req, envelope.ID, p.initResult), nil
+	}
+
+	return b.relay(req, p, msg, envelope, false)
+}
+
+// relay sends the message to the process. Requests are answered with the
+// response of the process, as server-sent events along with the messages the
+// process sends meanwhile with stream. Other messages are accepted.
+func (b *Bridge) relay(req *http.Request, p *process, msg map[string]json.RawMessage, envelope message, stream bool) (*http.Response, error) {
+	if !envelope.isRequest() {
+		if err := p.send(mustMarshal(msg)); err != nil {
+			return errorResponse(req, http.StatusBadGateway, nil, mcp.JSONRPCInternalError, errProcessExited.Error()), nil
+		}
+		return newResponse(req, http.StatusAccepted, nil), nil
+	}
+
+	if stream {
+		c, err := p.stream(envelope.ID, msg)
+		if err != nil {
+			return errorResponse(req, http.StatusBadGateway, envelope.ID, mcp.JSONRPCInternalError, errProcessExited.Error()), nil
+		}
+		return b.streamResponse(req, p, c), nil
+	}
+
+	res, err := p.request(req.Context(), envelope.ID, msg)
+	if err != nil {
+		if errors.Is(err, errProcessExited) {
+			return errorResponse(req, http.StatusBadGateway, envelope.ID, mcp.JSONRPCInternalError, err.Error()), nil
+		}
+		return nil, err
+	}
+
+	b.recovered()
+	return jsonResponse(req, http.StatusOK, res), nil
+}
+
+// streamResponse returns the events of the call as server-sent events, ending
+// with its response.
+func (b *Bridge) streamResponse(req *http.Request, p *process, c *call) *http.Response {
+	reader, writer := io.Pipe()
+
+	go func() {
+		defer p.release(c)
+
+		write := func(data []byte) bool {
+			_, err := fmt.Fprintf(writer, "event: message\ndata: %s\n\n", data)
+			return err == nil
+		}
+
+		for {
+			select {
+			case event := <-c.events:
+				if !write(event) {
+					writer.Close()
+					return
+				}
+			case res := <-c.response:
+				b.recovered()
+				write(res)
+				writer.Close()
+				return
+			case <-p.done:
+				select {
+				case res := <-c.response:
+					write(res)
+					writer.Close()
+				default:
+					writer.CloseWithError(errProcessExited)
+				}
+				return
+			case <-req.Context().Done():
+				writer.CloseWithError(req.Context().Err())
+				return
+			}
+		}
+	}()
+
+	res := newResponse(req, http.StatusOK, reader)
+	res.Header.Set(headerContentType, contentTypeSSE)
+	res.Header.Set("Cache-Control", "no-cache")
+	res.ContentLength = -1
+	return res
+}
+
+// delete terminates the session, stopping its process.
+func (b *Bridge) delete(req *http.Request) *http.Response {
+	if b.cfg.Mode == ModePool {
+		return newResponse(req, http.StatusMethodNotAllowed, nil)
+	}
+
+	sessionID := req.Header.Get(mcp.HeaderSessionID)
+
+	p := b.session(req, sessionID)
+	if p == nil {
+		return newResponse(req, http.StatusNotFound, nil)
+	}
+
+	b.mu.Lock()
+	if b.sessions[sessionID] == p {
+		delete(b.sessions, sessionID)
+	}
+	b.mu.Unlock()
+
+	go p.stop()
+	return newResponse(req, http.StatusOK, nil)
+}
+
+// owner returns the client of the request.
+func (b *Bridge) owner(req *http.Request) string {
+	if b.cfg.Owner == nil {
+		return ""
+	}
+	return b.cfg.Owner(req)
+}
+
+// session returns the process of the session, nil if it doesn't exist or
+// was started by another client.
+func (b *Bridge) session(req *http.Request, sessionID string) *process {
+	b.mu.Lock()
+	p := b.sessions[sessionID]
+	b.mu.Unlock()
+
+	if p == nil || p.owner != b.owner(req) {
+		return nil
+	}
+	return p
+}
+
+// startSession spawns the process of a new session of the client.
+func (b *Bridge) startSession(owner string) (string, *process, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return "", nil, errClosed
+	}
+	if len(b.sessions) >= b.cfg.MaxSessions {
+		return "", nil, errTooManySessions
+	}
+
+	p, err := b.spawnLocked()
+	if err != nil {
+		return "", nil, err
+	}
+	p.owner = owner
+
+	sessionID := uuid.New()
+	b.sessions[sessionID] = p
+	return sessionID, p, nil
+}
+
+// pooled returns the next process of the pool, spawning and initializing it
+// if it isn't running.
+func (b *Bridge) pooled(ctx context.Context) (*process, error) {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil, errClosed
+	}
+
+	slot := b.next
+	b.next = (b.next + 1) % len(b.pool)
+	if p := b.pool[slot]; p != nil && p.alive() {
+		b.mu.Unlock()
+		return b.waitReady(ctx, p)
+	}
+
+	p, err := b.spawnLocked()
+	if err != nil {
+		b.mu.Unlock()
+		return nil, err
+	}
+	b.pool[slot] = p
+	b.mu.Unlock()
+
+	err = b.initialize(ctx, p)
+	close(p.ready)
+	if err != nil {
+		b.logger.WithError(err).Error("Failed to initialize MCP stdio server")
+		b.mu.Lock()
+		if b.pool[slot] == p {
+			b.pool[slot] = nil
+		}
+		b.failedLocked()
+		b.mu.Unlock()
+		go p.stop()
+		return nil, errBackingOff
+	}
+
+	return p, nil
+}
+
+// waitReady waits for the pooled process to be initialized by the request that spawned it.
+func (b *Bridge) waitReady(ctx context.Context, p *process) (*process, error) {
+	select {
+	case <-p.ready:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if p.initResult == nil {
+		return nil, errBackingOff
+	}
+	return p, nil
+}
+
+// initialize initializes a pooled process on behalf of its clients.
+func (b *Bridge) initialize(ctx context.Context, p *process) error {
+	params, err := json.Marshal(map[string]any{
+		"protocolVersion": protocolVersion,
+		"capabilities":    map[string]any{},
+		"clientInfo":      map[string]any{"name": "tyk-gateway"},
+	})
+	if err != nil {
+		return err
+	}
+
+	raw, err := p.request(ctx, json.RawMessage(`0`), map[string]json.RawMessage{
+		"jsonrpc": json.RawMessage(`"2.0"`),
+		"method":  json.RawMessage(`"` + mcp.MethodInitialize + `"`),
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+
+	var res struct {
+		Result json.RawMessage `json:"result"`
+		Error  json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return err
+	}
+	if res.Result == nil {
+		return fmt.Errorf("initialize failed: %s", res.Error)
+	}
+
+	p.initResult = res.Result
+	return p.send([]byte(`{"jsonrpc":"2.0","method":"` + methodInitialized + `"}`))
+}
+
+// spawnLocked starts a process unless the bridge backs off after failures.
+func (b *Bridge) spawnLocked() (*process, error) {
+	if time.Now().Before(b.retryAt) {
+		return nil, errBackingOff
+	}
+
+	p, err := startProcess(b.cfg, b.logger, b.exited)
+	if err != nil {
+		b.logger.WithError(err).Error("Failed to start MCP stdio server")
+		b.failedLocked()
+		return nil, errBackingOff
+	}
+
+	return p, nil
+}
+
+// exited forgets the process. An unexpected exit delays the next spawn.
+func (b *Bridge) exited(p *process) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for id, sp := range b.sessions {
+		if sp == p {
+			delete(b.sessions, id)
+		}
+	}
+	for i, pp := range b.pool {
+		if pp == p {
+			b.pool[i] = nil
+		}
+	}
+
+	if !p.stopped.Load() && !b.closed {
+		b.failedLocked()
+	}
+}
+
+// failedLocked doubles the restart delay, up to its maximum.
+func (b *Bridge) failedLocked() {
+	switch {
+	case b.backoff == 0:
+		b.backoff = b.cfg.RestartBackoff
+	case b.backoff < b.cfg.MaxRestartBackoff:
+		b.backoff *= 2
+	}
+	if b.backoff > b.cfg.MaxRestartBackoff {
+		b.backoff = b.cfg.MaxRestartBackoff
+	}
+
+	b.retryAt = time.Now().Add(b.backoff)
+	b.logger.WithField("backoff", b.backoff).Warn("Delaying the restart of MCP stdio server")
+}
+
+// recovered resets the restart delay once a process responds.
+func (b *Bridge) recovered() {
+	b.mu.Lock()
+	b.backoff = 0
+	b.mu.Unlock()
+}
+
+// janitor stops the processes idle for longer than the idle timeout.
+func (b *Bridge) janitor() {
+	interval := b.cfg.IdleTimeout / 2
+	if interval < 10*time.Millisecond {
+		interval = 10 * time.Millisecond
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.stopJanitor:
+			return
+		case now := <-ticker.C:
+			for _, p := range b.idle(now) {
+				go p.stop()
+			}
+		}
+	}
+}
+
+// idle removes and returns the processes idle for longer than the idle timeout.
+func (b *Bridge) idle(now time.Time) []*process {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	isIdle := func(p *process) bool {
+		since := p.idleSince()
+		return !since.IsZero() && now.Sub(since) > b.cfg.IdleTimeout
+	}
+
+	var idle []*process
+	for id, p := range b.sessions {
+		if isIdle(p) {
+			delete(b.sessions, id)
+			idle = append(idle, p)
+		}
+	}
+	for i, p := range b.pool {
+		if p != nil && isIdle(p) {
+			b.pool[i] = nil
+			idle = append(idle, p)
+		}
+	}
+
+	if len(idle) > 0 {
+		b.logger.WithField("count", len(idle)).Debug("Stopping idle MCP stdio servers")
+	}
+	return idle
+}
+
+// startError returns the response to a request no process could serve.
+func (b *Bridge) startError(req *http.Request, id json.RawMessage, err error) *http.Response {
+	return errorResponse(req, http.StatusServiceUnavailable, id, mcp.JSONRPCInternalError, err.Error())
+}
+
+// acceptsSSE reports whether the client accepts server-sent events.
+func acceptsSSE(req *http.Request) bool {
+	return strings.Contains(req.Header.Get("Accept"), contentTypeSSE)
+}
+
+// newResponse returns a response with the body, empty if nil.
+func newResponse(req *http.Request, status int, body io.ReadCloser) *http.Response {
+	if body == nil {
+		body = http.NoBody
+	}
+
+	return &http.Response{
+		Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode: status,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{},
+		Body:       body,
+		Request:    req,
+	}
+}
+
+// jsonResponse returns a response with the JSON body.
+func jsonResponse(req *http.Request, status int, body []byte) *http.Response {
+	res := newResponse(req, status, io.NopCloser(strings.NewReader(string(body))))
+	res.Header.Set(headerContentType, contentTypeJSON)
+	res.ContentLength = int64(len(body))
+	return res
+}
+
+// resultResponse returns a JSON-RPC response with the result.
+func resultResponse(req *http.Request, id, result json.RawMessage) *http.Response {
+	return jsonResponse(req, http.StatusOK, mustMarshal(map[string]json.RawMessage{
+		"jsonrpc": json.RawMessage(`"2.0"`),
+		"id":      id,
+		"result":  result,
+	}))
+}
+
+// errorResponse returns a JSON-RPC error response.
+func errorResponse(req *http.Request, status int, id json.RawMessage, code int, message string) *http.Response {
+	if len(id) == 0 {
+		id = json.RawMessage(`null`)
+	}
+
+	return jsonResponse(req, status, mustMarshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"error":   map[string]any{"code": code, "message": message},
+	}))
+}
+
+// mustMarshal encodes a value that can't fail to encode.
+func mustMarshal(v any) []byte {
+	raw, _ := json.Marshal(v) //nolint:errchkjson
+	return raw
+}
@@ -0,0 +1,337 @@
+package stdio
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/internal/mcp"
+)
+
+const helperEnv = "TYK_TEST_MCP_STDIO_SERVER=1"
+
+func TestMain(m *testing.M) {
+	if os.Getenv("TYK_TEST_MCP_STDIO_SERVER") == "1" {
+		runHelperServer()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runHelperServer is a minimal MCP stdio server. Its echo method returns its
+// pid and the request params, notify sends a notification before responding,
+// ask waits for the answer of the client to a request, and exit crashes it.
+// flood writes a message over maxMessageSize and noisy a long standard error
+// line before responding.
+func runHelperServer() {
+	out := json.NewEncoder(os.Stdout)
+	in := bufio.NewScanner(os.Stdin)
+
+	respond := func(id json.RawMessage, result any) {
+		_ = out.Encode(map[string]any{"jsonrpc": "2.0", "id": id, "result": result})
+	}
+
+	for in.Scan() {
+		var msg struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+			Result json.RawMessage `json:"result"`
+		}
+		if err := json.Unmarshal(in.Bytes(), &msg); err != nil || msg.ID == nil {
+			continue
+		}
+
+		switch msg.Method {
+		case mcp.MethodInitialize:
+			respond(msg.ID, map[string]any{"protocolVersion": protocolVersion, "serverInfo": map[string]any{"name": "helper"}})
+		case "echo":
+			respond(msg.ID, map[string]any{"pid": os.Getpid(), "params": msg.Params})
+		case "notify":
+			_ = out.Encode(map[string]any{"jsonrpc": "2.0", "method": "notifications/message", "params": map[string]any{"data": "working"}})
+			respond(msg.ID, "done")
+		case "ask":
+			_ = out.Encode(map[string]any{"jsonrpc": "2.0", "id": "server-1", "method": "roots/list"})
+			for in.Scan() {
+				var answer struct {
+					Result json.RawMessage `json:"result"`
+					Error  json.RawMessage `json:"error"`
+				}
+				if json.Unmarshal(in.Bytes(), &answer) == nil && (answer.Result != nil || answer.Error != nil) {
+					respond(msg.ID, map[string]any{"answer": answer.Result, "error": answer.Error})
+					break
+				}
+			}
+		case "exit":
+			os.Exit(1)
+		case "flood":
+			_, _ = os.Stdout.WriteString(strings.Repeat("a", maxMessageSize+1) + "\n")
+			respond(msg.ID, "flooded")
+		case "noisy":
+			_, _ = os.Stderr.WriteString(strings.Repeat("a", 1<<20) + "\n")
+			respond(msg.ID, "done")
+		}
+	}
+}
+
+func newTestBridge(t *testing.T, cfg Config) *Bridge {
+	t.Helper()
+
+	cfg.Command = os.Args[0]
+	cfg.Env = append(cfg.Env, helperEnv)
+	bridge := New(cfg)
+	t.Cleanup(bridge.Close)
+
+	return bridge
+}
+
+type rpcResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func post(t *testing.T, bridge *Bridge, sessionID, body string) (*http.Response, rpcResponse) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+	req.Header.Set("Accept", contentTypeJSON)
+	if sessionID != "" {
+		req.Header.Set(mcp.HeaderSessionID, sessionID)
+	}
+
+	res, err := bridge.RoundTrip(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	var rpc rpcResponse
+	raw, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	if len(raw) > 0 {
+		require.NoError(t, json.Unmarshal(raw, &rpc), string(raw))
+	}
+
+	return res, rpc
+}
+
+func echoPID(t *testing.T, rpc rpcResponse) int {
+	t.Helper()
+
+	var result struct {
+		PID int `json:"pid"`
+	}
+	require.NoError(t, json.Unmarshal(rpc.Result, &result))
+	return result.PID
+}
+
+const (
+	initializeRequest = `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`
+	echoRequest       = `{"jsonrpc":"2.0","id":"e","method":"echo","params":{"n":1}}`
+)
+
+func TestBridge_Session(t *testing.T) {
+	bridge := newTestBridge(t, Config{MaxSessions: 2})
+
+	res, rpc := post(t, bridge, "", initializeRequest)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.JSONEq(t, `1`, string(rpc.ID))
+	first := res.Header.Get(mcp.HeaderSessionID)
+	require.NotEmpty(t, first)
+
+	res, _ = post(t, bridge, "", initializeRequest)
+	second := res.Header.Get(mcp.HeaderSessionID)
+	require.NotEmpty(t, second)
+	assert.NotEqual(t, first, second)
+
+	res, _ = post(t, bridge, "", initializeRequest)
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode, "max sessions")
+
+	// The sessions have their own process and keep the request IDs of the client.
+	res, rpc = post(t, bridge, first, echoRequest)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.JSONEq(t, `"e"`, string(rpc.ID))
+	assert.Contains(t, string(rpc.Result), `"params":{"n":1}`)
+	_, rpc2 := post(t, bridge, second, echoRequest)
+	assert.NotEqual(t, echoPID(t, rpc), echoPID(t, rpc2))
+
+	res, _ = post(t, bridge, first, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	assert.Equal(t, http.StatusAccepted, res.StatusCode)
+
+	res, _ = post(t, bridge, "", echoRequest)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	res, _ = post(t, bridge, "unknown", echoRequest)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	res, rpc = post(t, bridge, first, `[`)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	assert.Equal(t, mcp.JSONRPCParseError, rpc.Error.Code)
+
+	req := httptest.NewRequest(http.MethodDelete, "/mcp", nil)
+	req.Header.Set(mcp.HeaderSessionID, first)
+	res, err := bridge.RoundTrip(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	res, _ = post(t, bridge, first, echoRequest)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	res, err = bridge.RoundTrip(httptest.NewRequest(http.MethodGet, "/mcp", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+}
+
+func TestBridge_SessionStream(t *testing.T) {
+	bridge := newTestBridge(t, Config{})
+
+	res, _ := post(t, bridge, "", initializeRequest)
+	sessionID := res.Header.Get(mcp.HeaderSessionID)
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":2,"method":"notify"}`))
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	req.Header.Set(mcp.HeaderSessionID, sessionID)
+
+	res, err := bridge.RoundTrip(req)
+	require.NoError(t, err)
+	assert.Equal(t, contentTypeSSE, res.Header.Get(headerContentType))
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	events := strings.Split(strings.TrimSpace(string(body)), "\n\n")
+	require.Len(t, events, 2)
+	assert.Contains(t, events[0], `"method":"notifications/message"`)
+	assert.Contains(t, events[1], `"id":2`)
+	assert.Contains(t, events[1], `"result":"done"`)
+}
+
+func TestBridge_Pool(t *testing.T) {
+	bridge := newTestBridge(t, Config{Mode: ModePool, PoolSize: 2})
+
+	// The bridge answers the initialization of the clients itself.
+	res, rpc := post(t, bridge, "", initializeRequest)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Empty(t, res.Header.Get(mcp.HeaderSessionID))
+	assert.Contains(t, string(rpc.Result), `"helper"`)
+
+	res, _ = post(t, bridge, "", `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	assert.Equal(t, http.StatusAccepted, res.StatusCode)
+
+	pids := map[int]struct{}{}
+	for i := 0; i < 4; i++ {
+		_, rpc = post(t, bridge, "", echoRequest)
+		pids[echoPID(t, rpc)] = struct{}{}
+	}
+	assert.Len(t, pids, 2)
+
+	// The requests of the processes are answered, there's no client to relay them to.
+	_, rpc = post(t, bridge, "", `{"jsonrpc":"2.0","id":3,"method":"ask"}`)
+	assert.Contains(t, string(rpc.Result), fmt.Sprintf(`"code":%d`, mcp.JSONRPCMethodNotFound))
+}
+
+func TestBridge_IdleTimeout(t *testing.T) {
+	bridge := newTestBridge(t, Config{IdleTimeout: 50 * time.Millisecond})
+
+	res, _ := post(t, bridge, "", initializeRequest)
+	sessionID := res.Header.Get(mcp.HeaderSessionID)
+
+	assert.Eventually(t, func() bool {
+		bridge.mu.Lock()
+		defer bridge.mu.Unlock()
+		return bridge.sessions[sessionID] == nil
+	}, 2*time.Second, 10*time.Millisecond)
+
+	res, _ = post(t, bridge, sessionID, echoRequest)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestBridge_RestartBackoff(t *testing.T) {
+	bridge := newTestBridge(t, Config{Mode: ModePool, RestartBackoff: 200 * time.Millisecond})
+
+	_, rpc := post(t, bridge, "", echoRequest)
+	pid := echoPID(t, rpc)
+
+	res, rpc := post(t, bridge, "", `{"jsonrpc":"2.0","id":4,"method":"exit"}`)
+	assert.Equal(t, http.StatusBadGateway, res.StatusCode)
+	assert.JSONEq(t, `4`, string(rpc.ID))
+
+	assert.Eventually(t, func() bool {
+		res, _ := post(t, bridge, "", echoRequest)
+		return res.StatusCode == http.StatusServiceUnavailable
+	}, time.Second, 10*time.Millisecond, "restart delayed")
+
+	assert.Eventually(t, func() bool {
+		res, rpc := post(t, bridge, "", echoRequest)
+		return res.StatusCode == http.StatusOK && echoPID(t, rpc) != pid
+	}, 2*time.Second, 50*time.Millisecond, "restarted")
+}
+
+func TestBridge_OversizedMessage(t *testing.T) {
+	bridge := newTestBridge(t, Config{Mode: ModePool})
+
+	// The process is killed when its output can't be read, failing the call.
+	res, rpc := post(t, bridge, "", `{"jsonrpc":"2.0","id":5,"method":"flood"}`)
+	assert.Equal(t, http.StatusBadGateway, res.StatusCode)
+	assert.JSONEq(t, `5`, string(rpc.ID))
+}
+
+func TestBridge_Stderr(t *testing.T) {
+	bridge := newTestBridge(t, Config{Mode: ModePool})
+
+	// The standard error is drained past its long lines.
+	res, rpc := post(t, bridge, "", `{"jsonrpc":"2.0","id":6,"method":"noisy"}`)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.JSONEq(t, `"done"`, string(rpc.Result))
+}
+
+func TestBridge_SessionOwner(t *testing.T) {
+	bridge := newTestBridge(t, Config{Owner: func(req *http.Request) string {
+		return req.Header.Get("X-Owner")
+	}})
+
+	postAs := func(owner, sessionID, body string) *http.Response {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+		req.Header.Set("X-Owner", owner)
+		req.Header.Set(mcp.HeaderSessionID, sessionID)
+
+		res, err := bridge.RoundTrip(req)
+		require.NoError(t, err)
+		res.Body.Close()
+		return res
+	}
+
+	res := postAs("alice", "", initializeRequest)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	sessionID := res.Header.Get(mcp.HeaderSessionID)
+
+	assert.Equal(t, http.StatusNotFound, postAs("bob", sessionID, echoRequest).StatusCode)
+	assert.Equal(t, http.StatusOK, postAs("alice", sessionID, echoRequest).StatusCode)
+
+	req := httptest.NewRequest(http.MethodDelete, "/mcp", nil)
+	req.Header.Set("X-Owner", "bob")
+	req.Header.Set(mcp.HeaderSessionID, sessionID)
+	res, err := bridge.RoundTrip(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	assert.Equal(t, http.StatusOK, postAs("alice", sessionID, echoRequest).StatusCode)
+}
+
+func TestNew_Defaults(t *testing.T) {
+	bridge := New(Config{})
+	defer bridge.Close()
+
+	assert.Equal(t, DefaultMaxSessions, bridge.cfg.MaxSessions)
+	assert.Equal(t, DefaultIdleTimeout, bridge.cfg.IdleTimeout)
+}
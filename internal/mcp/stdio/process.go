@@ -0,0 +1,409 @@
+package stdio
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/TykTechnologies/tyk/internal/mcp"
+)
+
+const (
+	// maxMessageSize bounds the size of a message written by the process.
+	maxMessageSize = 16 << 20
+	// maxStderrLine bounds the size of a logged line of the standard error, longer lines are truncated.
+	maxStderrLine = 64 << 10
+	// stopGracePeriod is how long a stopped process has to exit once its stdin is closed.
+	stopGracePeriod = 5 * time.Second
+	// eventBuffer is the number of messages buffered for a streamed call.
+	eventBuffer = 64
+)
+
+// errProcessExited is returned for the calls the process exited before answering.
+var errProcessExited = errors.New("MCP stdio server exited")
+
+// message is the envelope of a JSON-RPC message.
+type message struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+}
+
+// isRequest reports whether the message expects a response.
+func (m message) isRequest() bool {
+	return m.Method != "" && len(m.ID) > 0 && string(m.ID) != "null"
+}
+
+// call is a request of a client waiting for the process' response.
+type call struct {
+	// key is the request ID of the process.
+	key string
+	// id is the request ID of the client.
+	id json.RawMessage
+	// response receives the response, with the client's request ID.
+	response chan json.RawMessage
+	// events receives the messages the process sends during the call, nil when they aren't relayed.
+	events chan json.RawMessage
+}
+
+// process is a running MCP stdio server. The request IDs of the clients are
+// replaced with IDs of the process, so clients sharing a process can reuse IDs.
+type process struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	logger *logrus.Entry
+
+	// owner is the client which started the session of the process, empty for pooled processes.
+	owner string
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	nextID  int64
+	pending map[string]*call
+	relays  map[*call]struct{}
+
+	lastUsed atomic.Int64
+	inflight atomic.Int64
+	stopped  atomic.Bool
+
+	// initResult is the result of the initialize request the bridge sent, for
+	// pooled processes. It is set before ready is closed.
+	initResult json.RawMessage
+	ready      chan struct{}
+
+	done chan struct{}
+}
+
+// startProcess spawns the MCP stdio server. The process only inherits the PATH
+// and HOME of the gateway environment, along with the configured variables.
+func startProcess(cfg Config, logger *logrus.Entry, onExit func(p *process)) (*process, error) {
+	cmd := exec.Command(cfg.Command, cfg.Args...) //nolint:gosec // the commands are allowed by the gateway configuration
+	cmd.Dir = cfg.Dir
+	cmd.Env = append([]string{"PATH=" + os.Getenv("PATH"), "HOME=" + os.Getenv("HOME")}, cfg.Env...)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	p := &process{
+		cmd:     cmd,
+		stdin:   stdin,
+		logger:  logger.WithField("pid", cmd.Process.Pid),
+		pending: map[string]*call{},
+		relays:  map[*call]struct{}{},
+		ready:   make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	p.touch()
+
+	go p.logStderr(stderr)
+	go func() {
+		p.read(stdout)
+		err := cmd.Wait()
+		p.exited()
+		if !p.stopped.Load() {
+			p.logger.WithError(err).Warn("MCP stdio server exited")
+		}
+		onExit(p)
+	}()
+
+	p.logger.Debug("Started MCP stdio server")
+	return p, nil
+}
+
+// touch records the use of the process for the idle timeout.
+func (p *process) touch() {
+	p.lastUsed.Store(time.Now().UnixNano())
+}
+
+// idleSince returns when the process was last used, zero while it serves calls.
+func (p *process) idleSince() time.Time {
+	if p.inflight.Load() > 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, p.lastUsed.Load())
+}
+
+// alive reports whether the process is running.
+func (p *process) alive() bool {
+	select {
+	case <-p.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// write sends a message to the process, one message per line.
+func (p *process) write(msg []byte) error {
+	p.writeMu.Lock()
+	defer p.writeMu.Unlock()
+
+	if _, err := p.stdin.Write(append(msg, '\n')); err != nil {
+		return err
+	}
+	return nil
+}
+
+// send writes a notification or a response to the process.
+func (p *process) send(msg []byte) error {
+	p.touch()
+	return p.write(msg)
+}
+
+// request sends the request to the process and waits for its response, which
+// carries the client's request ID.
+func (p *process) request(ctx context.Context, id json.RawMessage, msg map[string]json.RawMessage) (json.RawMessage, error) {
+	c, err := p.start(id, msg, false)
+	if err != nil {
+		return nil, err
+	}
+	defer p.release(c)
+
+	select {
+	case res := <-c.response:
+		return res, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-p.done:
+		select {
+		case res := <-c.response:
+			return res, nil
+		default:
+			return nil, errProcessExited
+		}
+	}
+}
+
+// stream sends the request to the process. The messages the process sends
+// until it responds are passed to the events of the call. The call must be
+// released once its response is read.
+func (p *process) stream(id json.RawMessage, msg map[string]json.RawMessage) (*call, error) {
+	return p.start(id, msg, true)
+}
+
+// start registers the call and writes its request with an ID of the process.
+func (p *process) start(id json.RawMessage, msg map[string]json.RawMessage, relay bool) (*call, error) {
+	p.inflight.Add(1)
+	p.touch()
+
+	c := &call{id: id, response: make(chan json.RawMessage, 1)}
+	if relay {
+		c.events = make(chan json.RawMessage, eventBuffer)
+	}
+
+	p.mu.Lock()
+	p.nextID++
+	c.key = strconv.FormatInt(p.nextID, 10)
+	p.pending[c.key] = c
+	if relay {
+		p.relays[c] = struct{}{}
+	}
+	p.mu.Unlock()
+
+	msg["id"] = json.RawMessage(c.key)
+	raw, err := json.Marshal(msg)
+	if err == nil {
+		err = p.write(raw)
+	}
+	if err != nil {
+		p.release(c)
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// release forgets the call.
+func (p *process) release(c *call) {
+	p.mu.Lock()
+	delete(p.pending, c.key)
+	delete(p.relays, c)
+	p.mu.Unlock()
+
+	p.touch()
+	p.inflight.Add(-1)
+}
+
+// read dispatches the messages of the process until its stdout closes.
+func (p *process) read(stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxMessageSize)
+
+	for scanner.Scan() {
+		line := append([]byte(nil), scanner.Bytes()...)
+		if len(line) == 0 {
+			continue
+		}
+
+		var msg message
+		if err := json.Unmarshal(line, &msg); err != nil {
+			p.logger.WithError(err).Debug("Ignoring invalid message of MCP stdio server")
+			continue
+		}
+
+		if msg.Method != "" {
+			p.serverMessage(msg, line)
+			continue
+		}
+
+		p.response(msg, line)
+	}
+
+	// The process can't be read anymore, it's killed so it doesn't block
+	// writing to its stdout and its calls fail.
+	if err := scanner.Err(); err != nil {
+		p.logger.WithError(err).Warn("Failed to read MCP stdio server output, killing it")
+		_ = p.cmd.Process.Kill() //nolint:errcheck // the process may have exited meanwhile
+	}
+}
+
+// response passes the response to its call, with the client's request ID.
+func (p *process) response(msg message, line []byte) {
+	p.mu.Lock()
+	c, ok := p.pending[string(msg.ID)]
+	p.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(line, &obj); err != nil {
+		return
+	}
+	obj["id"] = c.id
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return
+	}
+
+	select {
+	case c.response <- raw:
+	default:
+	}
+}
+
+// serverMessage relays the notifications and requests of the process to the
+// streamed calls. The requests no call relays are answered with an error, so
+// the process doesn't wait for them.
+func (p *process) serverMessage(msg message, line []byte) {
+	p.mu.Lock()
+	relayed := false
+	for c := range p.relays {
+		select {
+		case c.events <- line:
+			relayed = true
+		default:
+		}
+	}
+	p.mu.Unlock()
+
+	if relayed || !msg.isRequest() {
+		return
+	}
+
+	raw, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      msg.ID,
+		"error":   map[string]any{"code": mcp.JSONRPCMethodNotFound, "message": "no client to handle " + msg.Method},
+	})
+	if err == nil {
+		_ = p.write(raw) //nolint:errcheck // the process exit is handled by the reader
+	}
+}
+
+// exited marks the process as exited, failing its pending calls.
+func (p *process) exited() {
+	close(p.done)
+}
+
+// logStderr logs the standard error of the process. It's drained until it
+// closes, so the process never blocks writing to it.
+func (p *process) logStderr(stderr io.Reader) {
+	w := &lineLogger{logger: p.logger}
+	_, _ = io.Copy(w, stderr) //nolint:errcheck // the process exit is handled by the reader
+	w.flush()
+}
+
+// stop closes the stdin of the process, and kills it if it doesn't exit in time.
+func (p *process) stop() {
+	if !p.stopped.CompareAndSwap(false, true) {
+		return
+	}
+
+	// The write lock isn't taken, closing stdin fails a write blocked on a
+	// process which doesn't read it.
+	p.stdin.Close()
+
+	select {
+	case <-p.done:
+	case <-time.After(stopGracePeriod):
+		_ = p.cmd.Process.Kill() //nolint:errcheck // the process may have exited meanwhile
+	}
+}
+
+// lineLogger logs what's written to it line by line, truncating the lines
+// longer than maxStderrLine.
+type lineLogger struct {
+	logger *logrus.Entry
+	line   []byte
+}
+
+func (l *lineLogger) Write(b []byte) (int, error) {
+	n := len(b)
+
+	for len(b) > 0 {
+		end := bytes.IndexByte(b, '\n')
+		chunk := b
+		if end >= 0 {
+			chunk = b[:end]
+		}
+
+		if room := maxStderrLine - len(l.line); room > 0 {
+			if len(chunk) > room {
+				chunk = chunk[:room]
+			}
+			l.line = append(l.line, chunk...)
+		}
+
+		if end < 0 {
+			break
+		}
+		l.flush()
+		b = b[end+1:]
+	}
+
+	return n, nil
+}
+
+// flush logs the buffered line.
+func (l *lineLogger) flush() {
+	if len(l.line) > 0 {
+		l.logger.Debug(string(l.line))
+	}
+	l.line = l.line[:0]
+}
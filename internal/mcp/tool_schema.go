@@ -0,0 +1,220 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TykTechnologies/tyk/internal/service/gojsonschema"
+)
+
+// ParamKeyArguments is the tools/call parameter carrying the tool arguments.
+const ParamKeyArguments = "arguments"
+
+// toolsFetchInterval is how often the tools of an MCP server are fetched at
+// most to learn the unknown schemas.
+const toolsFetchInterval = time.Minute
+
+// ArgumentError is a tools/call argument failing the tool's input schema.
+type ArgumentError struct {
+	// Pointer is the JSON pointer of the failing value within the arguments, empty for the arguments object.
+	Pointer string `json:"pointer"`
+	// Message describes the failure.
+	Message string `json:"message"`
+}
+
+type toolSchema struct {
+	raw    []byte
+	schema *gojsonschema.Schema
+}
+
+// ToolSchemas holds the compiled input schemas of the tools of an MCP server,
+// by tool name. It is safe for concurrent use.
+type ToolSchemas struct {
+	mu      sync.RWMutex
+	schemas map[string]toolSchema
+
+	fetchMu   sync.Mutex
+	fetchedAt time.Time
+}
+
+// NewToolSchemas returns an empty tool schema store.
+func NewToolSchemas() *ToolSchemas {
+	return &ToolSchemas{schemas: map[string]toolSchema{}}
+}
+
+// Set compiles and stores the input schema of the tool. The schema is only
+// recompiled when it differs from the stored one. An invalid schema removes
+// the tool's schema, so its calls aren't validated. The schema can't
+// reference other documents, such as remote or local files.
+func (s *ToolSchemas) Set(name string, schema json.RawMessage) error {
+	s.mu.RLock()
+	current, ok := s.schemas[name]
+	s.mu.RUnlock()
+	if ok && bytes.Equal(current.raw, schema) {
+		return nil
+	}
+
+	compiled, err := gojsonschema.NewSchema(localLoader{gojsonschema.NewBytesLoader(schema)})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err != nil {
+		delete(s.schemas, name)
+		return err
+	}
+	s.schemas[name] = toolSchema{raw: append([]byte(nil), schema...), schema: compiled}
+	return nil
+}
+
+// Seed stores the schemas of the tools known to from and not to s. It keeps
+// the schemas learnt by a previous version of the API across reloads.
+func (s *ToolSchemas) Seed(from *ToolSchemas) {
+	if from == s {
+		return
+	}
+
+	from.mu.RLock()
+	defer from.mu.RUnlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for name, schema := range from.schemas {
+		if _, ok := s.schemas[name]; !ok {
+			s.schemas[name] = schema
+		}
+	}
+}
+
+// Fetch runs fetch to learn the schemas from the MCP server, unless it ran
+// within the last minute. The concurrent callers wait for the running fetch.
+func (s *ToolSchemas) Fetch(fetch func()) {
+	s.fetchMu.Lock()
+	defer s.fetchMu.Unlock()
+
+	if !s.fetchedAt.IsZero() && time.Since(s.fetchedAt) < toolsFetchInterval {
+		return
+	}
+	s.fetchedAt = time.Now()
+
+	fetch()
+}
+
+// localLoader loads a schema which can only reference itself. The tool
+// schemas come from the MCP servers and mustn't make the gateway fetch URLs
+// or read files.
+type localLoader struct {
+	gojsonschema.JSONLoader
+}
+
+// LoaderFactory returns the factory loading the referenced documents.
+func (localLoader) LoaderFactory() gojsonschema.JSONLoaderFactory {
+	return refusedLoaderFactory{}
+}
+
+type refusedLoaderFactory struct{}
+
+// New returns a loader failing to load the referenced document.
+func (refusedLoaderFactory) New(source string) gojsonschema.JSONLoader {
+	return refusedLoader{JSONLoader: gojsonschema.NewRefLoader(source), source: source}
+}
+
+type refusedLoader struct {
+	gojsonschema.JSONLoader
+	source string
+}
+
+// LoadJSON refuses to load the referenced document.
+func (l refusedLoader) LoadJSON() (interface{}, error) {
+	return nil, fmt.Errorf("reference to %q isn't allowed, tool schemas can only reference themselves", l.source)
+}
+
+// Learn stores the input schemas of the tools of a tools/list result. Tools
+// without name or input schema are skipped, as are the invalid schemas.
+func (s *ToolSchemas) Learn(tools []json.RawMessage) {
+	for _, raw := range tools {
+		var tool struct {
+			Name        string          `json:"name"`
+			InputSchema json.RawMessage `json:"inputSchema"`
+		}
+		if err := json.Unmarshal(raw, &tool); err != nil || tool.Name == "" || len(tool.InputSchema) == 0 {
+			continue
+		}
+		_ = s.Set(tool.Name, tool.InputSchema) //nolint:errcheck // invalid upstream schemas leave the tool unvalidated
+	}
+}
+
+// LearnFromResponse stores the input schemas of the tools of a JSON-RPC
+// tools/list response. Other responses are ignored.
+func (s *ToolSchemas) LearnFromResponse(body []byte) {
+	var envelope JSONRPCResponse
+	if err := json.Unmarshal(body, &envelope); err != nil || envelope.Result == nil {
+		return
+	}
+
+	var result struct {
+		Tools []json.RawMessage `json:"tools"`
+	}
+	if err := json.Unmarshal(envelope.Result, &result); err != nil {
+		return
+	}
+	s.Learn(result.Tools)
+}
+
+// Validate validates the arguments of a tools/call request against the input
+// schema of the tool. Missing arguments validate as an empty object. It
+// reports false when the tool's schema is unknown.
+func (s *ToolSchemas) Validate(name string, arguments json.RawMessage) ([]ArgumentError, bool) {
+	s.mu.RLock()
+	current, ok := s.schemas[name]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+
+	if len(arguments) == 0 || string(arguments) == "null" {
+		arguments = json.RawMessage(`{}`)
+	}
+
+	result, err := current.schema.Validate(gojsonschema.NewBytesLoader(arguments))
+	if err != nil {
+		return []ArgumentError{{Message: err.Error()}}, true
+	}
+	if result.Valid() {
+		return nil, true
+	}
+
+	errs := make([]ArgumentError, 0, len(result.Errors()))
+	for _, resultErr := range result.Errors() {
+		errs = append(errs, ArgumentError{
+			Pointer: argumentPointer(resultErr),
+			Message: resultErr.Description(),
+		})
+	}
+	return errs, true
+}
+
+// argumentPointer converts the context of a validation error, such as
+// (root).items.0, to a JSON pointer, such as /items/0. The missing required
+// properties point at the property.
+func argumentPointer(resultErr gojsonschema.ResultError) string {
+	path := strings.TrimPrefix(resultErr.Context().String("/"), "(root)")
+	if property, ok := resultErr.Details()["property"].(string); ok && resultErr.Type() == "required" {
+		path += "/" + property
+	}
+
+	var b strings.Builder
+	for _, segment := range strings.Split(strings.TrimPrefix(path, "/"), "/") {
+		if segment == "" {
+			continue
+		}
+		b.WriteString("/")
+		b.WriteString(strings.ReplaceAll(segment, "~", "~0"))
+	}
+	return b.String()
+}
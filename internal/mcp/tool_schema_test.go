@@ -0,0 +1,140 @@
+package mcp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolSchemas(t *testing.T) {
+	schemas := NewToolSchemas()
+	schemas.Learn([]json.RawMessage{
+		json.RawMessage(`{"name":"search","inputSchema":{"type":"object","properties":{"query":{"type":"string"},"labels":{"type":"array","items":{"type":"string"}}},"required":["query"]}}`),
+		json.RawMessage(`{"name":"ping"}`),
+		json.RawMessage(`{"name":"broken","inputSchema":{"type":7}}`),
+	})
+
+	t.Run("valid arguments", func(t *testing.T) {
+		errs, known := schemas.Validate("search", json.RawMessage(`{"query":"bug","labels":["p1"]}`))
+		assert.True(t, known)
+		assert.Empty(t, errs)
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		errs, known := schemas.Validate("search", json.RawMessage(`{"labels":["p1",2]}`))
+		assert.True(t, known)
+
+		pointers := make([]string, 0, len(errs))
+		for _, err := range errs {
+			pointers = append(pointers, err.Pointer)
+			assert.NotEmpty(t, err.Message)
+		}
+		assert.ElementsMatch(t, []string{"/query", "/labels/1"}, pointers)
+	})
+
+	t.Run("missing arguments", func(t *testing.T) {
+		errs, known := schemas.Validate("search", nil)
+		assert.True(t, known)
+		require.Len(t, errs, 1)
+		assert.Equal(t, "/query", errs[0].Pointer)
+	})
+
+	t.Run("unknown tools", func(t *testing.T) {
+		for _, name := range []string{"ping", "broken", "missing"} {
+			_, known := schemas.Validate(name, json.RawMessage(`{}`))
+			assert.False(t, known, name)
+		}
+	})
+
+	t.Run("schema updates", func(t *testing.T) {
+		require.NoError(t, schemas.Set("search", json.RawMessage(`{"type":"object"}`)))
+		errs, known := schemas.Validate("search", json.RawMessage(`{}`))
+		assert.True(t, known)
+		assert.Empty(t, errs)
+
+		assert.Error(t, schemas.Set("search", json.RawMessage(`{"type":7}`)))
+		_, known = schemas.Validate("search", json.RawMessage(`{}`))
+		assert.False(t, known)
+	})
+}
+
+func TestToolSchemas_References(t *testing.T) {
+	schemas := NewToolSchemas()
+
+	t.Run("local references", func(t *testing.T) {
+		require.NoError(t, schemas.Set("local", json.RawMessage(`{"type":"object","properties":{"id":{"$ref":"#/definitions/id"}},"definitions":{"id":{"type":"integer"}}}`)))
+
+		errs, known := schemas.Validate("local", json.RawMessage(`{"id":"one"}`))
+		assert.True(t, known)
+		require.Len(t, errs, 1)
+		assert.Equal(t, "/id", errs[0].Pointer)
+	})
+
+	t.Run("remote references", func(t *testing.T) {
+		var fetched bool
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			fetched = true
+			_, _ = w.Write([]byte(`{"type":"string"}`))
+		}))
+		defer srv.Close()
+
+		for _, ref := range []string{srv.URL + "/schema.json", "file:///etc/hosts"} {
+			err := schemas.Set("remote", json.RawMessage(`{"type":"object","properties":{"id":{"$ref":"`+ref+`"}}}`))
+			assert.ErrorContains(t, err, "isn't allowed", ref)
+
+			_, known := schemas.Validate("remote", json.RawMessage(`{}`))
+			assert.False(t, known, ref)
+		}
+		assert.False(t, fetched)
+	})
+}
+
+func TestToolSchemas_Seed(t *testing.T) {
+	previous := NewToolSchemas()
+	require.NoError(t, previous.Set("search", json.RawMessage(`{"type":"object","required":["query"]}`)))
+	require.NoError(t, previous.Set("ping", json.RawMessage(`{"type":"object"}`)))
+
+	schemas := NewToolSchemas()
+	require.NoError(t, schemas.Set("ping", json.RawMessage(`{"type":"object","required":["host"]}`)))
+	schemas.Seed(previous)
+
+	errs, known := schemas.Validate("search", json.RawMessage(`{}`))
+	assert.True(t, known)
+	assert.Len(t, errs, 1)
+
+	errs, known = schemas.Validate("ping", json.RawMessage(`{}`))
+	assert.True(t, known)
+	assert.Len(t, errs, 1, "the current schemas are kept")
+}
+
+func TestToolSchemas_LearnFromResponse(t *testing.T) {
+	schemas := NewToolSchemas()
+	schemas.LearnFromResponse([]byte(`{"jsonrpc":"2.0","id":1,"result":{"tools":[{"name":"search","inputSchema":{"type":"object","required":["query"]}}]}}`))
+	schemas.LearnFromResponse([]byte(`{"jsonrpc":"2.0","id":2,"result":{"prompts":[{"name":"review"}]}}`))
+	schemas.LearnFromResponse([]byte(`not json`))
+
+	errs, known := schemas.Validate("search", json.RawMessage(`{}`))
+	assert.True(t, known)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "/query", errs[0].Pointer)
+
+	_, known = schemas.Validate("review", json.RawMessage(`{}`))
+	assert.False(t, known)
+}
+
+func TestToolSchemas_Fetch(t *testing.T) {
+	schemas := NewToolSchemas()
+
+	fetches := 0
+	schemas.Fetch(func() { fetches++ })
+	schemas.Fetch(func() { fetches++ })
+	assert.Equal(t, 1, fetches, "the tools are fetched at most once a minute")
+
+	schemas.fetchedAt = schemas.fetchedAt.Add(-toolsFetchInterval)
+	schemas.Fetch(func() { fetches++ })
+	assert.Equal(t, 2, fetches)
+}
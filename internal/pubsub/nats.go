@@ -0,0 +1,100 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	nats "github.com/nats-io/nats.go"
+
+	"github.com/TykTechnologies/tyk/config"
+)
+
+// ErrNATSClosed is returned when the NATS connection was closed.
+var ErrNATSClosed = errors.New("pubsub: NATS connection is closed")
+
+// NATS is a Bus sending messages over NATS core subjects, the channel
+// being used as the subject.
+type NATS struct {
+	conn   *nats.Conn
+	closed chan struct{}
+}
+
+var _ Bus = (*NATS)(nil)
+
+// NewNATS connects to NATS, failing if the server can't be reached. Once
+// connected, the connection is kept open, reconnecting as needed, until ctx
+// is done.
+func NewNATS(ctx context.Context, conf config.NATSConfig) (*NATS, error) {
+	n := &NATS{closed: make(chan struct{})}
+
+	conn, err := nats.Connect(conf.URL, natsOptions(conf, n.closed)...)
+	if err != nil {
+		return nil, err
+	}
+	n.conn = conn
+
+	go func() {
+		<-ctx.Done()
+		n.conn.Close()
+	}()
+
+	return n, nil
+}
+
+func natsOptions(conf config.NATSConfig, closed chan struct{}) []nats.Option {
+	opts := []nats.Option{
+		nats.Name("tyk-gateway"),
+		nats.MaxReconnects(-1),
+		nats.ReconnectWait(time.Second),
+		nats.ClosedHandler(func(*nats.Conn) {
+			close(closed)
+		}),
+	}
+
+	if conf.Username != "" {
+		opts = append(opts, nats.UserInfo(conf.Username, conf.Password))
+	}
+
+	if conf.Token != "" {
+		opts = append(opts, nats.Token(conf.Token))
+	}
+
+	if conf.CAFile != "" {
+		opts = append(opts, nats.RootCAs(conf.CAFile))
+	}
+
+	if conf.CertFile != "" && conf.KeyFile != "" {
+		opts = append(opts, nats.ClientCert(conf.CertFile, conf.KeyFile))
+	}
+
+	return opts
+}
+
+// Publish sends message to the subscribers of channel.
+func (n *NATS) Publish(channel, message string) error {
+	return n.conn.Publish(channel, []byte(message))
+}
+
+// StartPubSubHandler subscribes to channel and runs callback for every
+// message received, until ctx is done or the connection is closed.
+func (n *NATS) StartPubSubHandler(ctx context.Context, channel string, callback func(interface{})) error {
+	sub, err := n.conn.Subscribe(channel, func(msg *nats.Msg) {
+		if callback != nil {
+			callback(&message{channel: msg.Subject, payload: string(msg.Data)})
+		}
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = sub.Unsubscribe()
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case <-n.closed:
+		return ErrNATSClosed
+	}
+}
@@ -0,0 +1,75 @@
+package pubsub
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	natscon "github.com/testcontainers/testcontainers-go/modules/nats"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/TykTechnologies/storage/temporal/model"
+
+	"github.com/TykTechnologies/tyk/config"
+)
+
+func TestNATS(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	natsContainer, err := natscon.Run(
+		ctx,
+		"nats:2.9",
+		testcontainers.WithWaitStrategy(wait.ForAll(
+			wait.ForLog("Server is ready"),
+			wait.ForListeningPort("4222/tcp"),
+		).WithDeadline(30*time.Second)))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = testcontainers.TerminateContainer(natsContainer)
+	})
+
+	url, err := natsContainer.ConnectionString(ctx)
+	require.NoError(t, err)
+
+	bus, err := NewNATS(ctx, config.NATSConfig{URL: url})
+	require.NoError(t, err)
+
+	received := make(chan model.Message, 1)
+	handlerCtx, stopHandler := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() {
+		done <- bus.StartPubSubHandler(handlerCtx, "tyk.cluster.notifications", func(v interface{}) {
+			received <- v.(model.Message)
+		})
+	}()
+
+	assert.Eventually(t, func() bool {
+		require.NoError(t, bus.Publish("tyk.cluster.notifications", "reload"))
+		select {
+		case msg := <-received:
+			payload, err := msg.Payload()
+			assert.NoError(t, err)
+			assert.Equal(t, "reload", payload)
+			assert.Equal(t, model.MessageTypeMessage, msg.Type())
+			return true
+		case <-time.After(50 * time.Millisecond):
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+
+	stopHandler()
+	assert.NoError(t, <-done)
+
+	cancel()
+	handlerErr := bus.StartPubSubHandler(context.Background(), "tyk.cluster.notifications", nil)
+	assert.Error(t, handlerErr, "the handler stops once the connection is closed")
+}
+
+func TestNATS_Unreachable(t *testing.T) {
+	_, err := NewNATS(context.Background(), config.NATSConfig{URL: "nats://127.0.0.1:1"})
+	assert.Error(t, err, "the connection fails right away so the caller can fall back")
+}
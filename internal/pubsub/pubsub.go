@@ -0,0 +1,41 @@
+// Package pubsub provides the message buses cluster notifications are sent over.
+package pubsub
+
+import (
+	"context"
+
+	"github.com/TykTechnologies/storage/temporal/model"
+)
+
+// Bus publishes messages to, and receives messages from, named channels.
+// It is implemented by storage.RedisCluster for Redis pub/sub.
+type Bus interface {
+	// Publish sends message to the subscribers of channel.
+	Publish(channel, message string) error
+	// StartPubSubHandler runs callback with a model.Message for every message
+	// published to channel. It blocks until ctx is done or the bus fails.
+	StartPubSubHandler(ctx context.Context, channel string, callback func(interface{})) error
+}
+
+// message is a received message, compatible with the Redis pub/sub messages.
+type message struct {
+	channel string
+	payload string
+}
+
+var _ model.Message = (*message)(nil)
+
+// Type returns the message type.
+func (m *message) Type() string {
+	return model.MessageTypeMessage
+}
+
+// Channel returns the channel the message was received on.
+func (m *message) Channel() (string, error) {
+	return m.channel, nil
+}
+
+// Payload returns the message payload.
+func (m *message) Payload() (string, error) {
+	return m.payload, nil
+}
@@ -13,6 +13,10 @@ type Stats struct {
 	Limit     int
 	Remaining int
 	Count     int
+
+	// RetryAfter is the time until the next request is allowed. It's only
+	// set by rate limiters which can tell it when a request is blocked.
+	RetryAfter time.Duration
 }
 
 func NewEmptyStats() Stats {
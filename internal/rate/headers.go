@@ -1,6 +1,7 @@
 package rate
 
 import (
+	"math"
 	"net/http"
 	"strconv"
 	"time"
@@ -89,4 +90,10 @@ func (r *rateLimitSender) SendRateLimits(limits Stats) {
 	// for client compatibility and to match industry conventions.
 	resetTime := time.Now().Add(limits.Reset).Unix()
 	r.hdr.Set(header.XRateLimitReset, strconv.FormatInt(resetTime, 10))
+
+	// Retry-After is sent in whole seconds, rounded up so clients don't retry too early.
+	if limits.RetryAfter > 0 {
+		retryAfter := int64(math.Ceil(limits.RetryAfter.Seconds()))
+		r.hdr.Set(header.RetryAfter, strconv.FormatInt(retryAfter, 10))
+	}
 }
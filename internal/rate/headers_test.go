@@ -94,5 +94,28 @@ func Test_rateLimitSender(t *testing.T) {
 				assert.Equal(t, "0", hdr.Get(header.XRateLimitRemaining))
 			})
 		})
+
+		t.Run("sends retry after in seconds, rounded up", func(t *testing.T) {
+			hdr := http.Header{}
+			rls := &rateLimitSender{hdr: hdr}
+
+			rls.SendRateLimits(Stats{
+				Limit:      200,
+				Remaining:  0,
+				Reset:      5 * time.Second,
+				RetryAfter: 1500 * time.Millisecond,
+			})
+
+			assert.Equal(t, "2", hdr.Get(header.RetryAfter))
+		})
+
+		t.Run("doesn't send retry after if unknown", func(t *testing.T) {
+			hdr := http.Header{}
+			rls := &rateLimitSender{hdr: hdr}
+
+			rls.SendRateLimits(Stats{Limit: 200, Remaining: 100, Reset: 5 * time.Second})
+
+			assert.Empty(t, hdr.Get(header.RetryAfter))
+		})
 	})
 }
@@ -1,6 +1,8 @@
 package rate
 
 import (
+	"context"
+
 	"github.com/TykTechnologies/tyk/config"
 	"github.com/TykTechnologies/tyk/internal/rate/limiter"
 	"github.com/TykTechnologies/tyk/internal/redis"
@@ -25,17 +27,45 @@ func Limiter(gwConfig *config.Config, rClient redis.UniversalClient) limiter.Fun
 		return res.FixedWindow
 	case LimitSlidingWindow:
 		return res.SlidingWindow
+	case LimitGCRA:
+		return res.GCRA
 	}
 
 	return nil
 }
 
+// GCRAChecker returns a Checker for the GCRA rate limiter. Unlike the
+// limiter.Func returned by Limiter, it reports the remaining requests
+// and the time until the rate limit resets.
+func GCRAChecker(ctx context.Context, rClient redis.UniversalClient, key string, rate float64, per float64) Checker {
+	res := limiter.NewLimiter(rClient)
+
+	return AnonChecker(func() (Stats, bool, error) {
+		result, err := res.GCRAResult(ctx, key, rate, per)
+		if err != nil {
+			return NewEmptyStats(), true, err
+		}
+
+		stats := Stats{
+			Limit:      int(rate),
+			Remaining:  result.Remaining,
+			Reset:      result.ResetAfter,
+			RetryAfter: result.RetryAfter,
+		}
+
+		return stats, !result.Allowed, nil
+	})
+}
+
 // limiterKind returns the kind of rate limiter enabled by config.
 // This function is used for release builds.
 func limiterKind(c *config.Config) (string, bool) {
 	if c.EnableFixedWindowRateLimiter {
 		return LimitFixedWindow, true
 	}
+	if c.EnableGCRARateLimiter {
+		return LimitGCRA, true
+	}
 	return "", false
 }
 
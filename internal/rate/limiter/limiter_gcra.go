@@ -0,0 +1,140 @@
+package limiter
+
+import (
+	"context"
+	"embed"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/samber/lo"
+
+	"github.com/TykTechnologies/tyk/internal/redis"
+)
+
+//go:embed scripts/*.lua
+var scripts embed.FS
+
+var gcraScript = redis.NewScript(string(lo.Must(scripts.ReadFile("scripts/gcra.lua"))))
+
+// gcraLocal keeps the theoretical arrival times when redis isn't in use.
+var gcraLocal = &gcraStore{tats: make(map[string]time.Time)}
+
+// GCRAResult is the outcome of a GCRA rate limit check.
+type GCRAResult struct {
+	// Allowed is true if the request is within the rate limit.
+	Allowed bool
+	// Remaining is the number of requests that can be made right away.
+	Remaining int
+	// RetryAfter is the time until the next request is allowed, set when blocked.
+	RetryAfter time.Duration
+	// ResetAfter is the time until the full rate limit is available again.
+	ResetAfter time.Duration
+}
+
+// GCRA implements the Generic Cell Rate Algorithm. It only keeps the
+// theoretical arrival time of the next request per key, allowing bursts
+// of up to rate requests while spacing requests evenly over per seconds.
+func (l *Limiter) GCRA(ctx context.Context, key string, rate float64, per float64) (time.Duration, error) {
+	res, err := l.GCRAResult(ctx, key, rate, per)
+	if err != nil {
+		return 0, err
+	}
+
+	if !res.Allowed {
+		return res.RetryAfter, ErrLimitExhausted
+	}
+
+	return 0, nil
+}
+
+// GCRAResult runs the GCRA rate limit check for key, returning the details
+// needed for the rate limit headers.
+func (l *Limiter) GCRAResult(ctx context.Context, key string, rate float64, per float64) (GCRAResult, error) {
+	if rate <= 0 || per <= 0 {
+		return GCRAResult{Allowed: true}, nil
+	}
+
+	var (
+		tolerance        = time.Duration(per * float64(time.Second))
+		emissionInterval = time.Duration(float64(tolerance) / rate)
+		now              = l.clock.Now()
+	)
+
+	if emissionInterval < time.Microsecond {
+		return GCRAResult{Allowed: true, Remaining: int(rate)}, nil
+	}
+
+	if l.redis == nil {
+		return gcraLocal.take(key, now, emissionInterval, tolerance), nil
+	}
+
+	res, err := gcraScript.Run(
+		ctx, l.redis, []string{key},
+		strconv.FormatInt(now.UnixMicro(), 10),
+		strconv.FormatInt(emissionInterval.Microseconds(), 10),
+		strconv.FormatInt(tolerance.Microseconds(), 10),
+	).Int64Slice()
+	if err != nil {
+		return GCRAResult{}, err
+	}
+
+	return GCRAResult{
+		Allowed:    res[0] == 1,
+		Remaining:  int(res[1]),
+		RetryAfter: time.Duration(res[2]) * time.Microsecond,
+		ResetAfter: time.Duration(res[3]) * time.Microsecond,
+	}, nil
+}
+
+// gcraStore is the in-memory GCRA state.
+type gcraStore struct {
+	mu        sync.Mutex
+	tats      map[string]time.Time
+	lastSweep time.Time
+}
+
+func (s *gcraStore) take(key string, now time.Time, emissionInterval, tolerance time.Duration) GCRAResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	tat := s.tats[key]
+	if tat.Before(now) {
+		tat = now
+	}
+
+	newTat := tat.Add(emissionInterval)
+	allowAt := newTat.Add(-tolerance)
+
+	if now.Before(allowAt) {
+		return GCRAResult{
+			RetryAfter: allowAt.Sub(now),
+			ResetAfter: tat.Sub(now),
+		}
+	}
+
+	s.tats[key] = newTat
+
+	return GCRAResult{
+		Allowed:    true,
+		Remaining:  int(now.Sub(allowAt) / emissionInterval),
+		ResetAfter: newTat.Sub(now),
+	}
+}
+
+// sweep removes the keys which are back to their full rate limit, at most
+// once a minute. It must be called with mu held.
+func (s *gcraStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, tat := range s.tats {
+		if !tat.After(now) {
+			delete(s.tats, key)
+		}
+	}
+}
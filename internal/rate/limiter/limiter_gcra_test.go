@@ -0,0 +1,43 @@
+package limiter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGCRAStore_Take(t *testing.T) {
+	store := &gcraStore{tats: make(map[string]time.Time)}
+	now := time.Now()
+
+	// 10 requests per second, one every 100ms with a burst of 10.
+	emissionInterval, tolerance := 100*time.Millisecond, time.Second
+
+	for i := 0; i < 10; i++ {
+		res := store.take("key", now, emissionInterval, tolerance)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, 9-i, res.Remaining)
+		assert.Equal(t, time.Duration(i+1)*emissionInterval, res.ResetAfter)
+	}
+
+	res := store.take("key", now, emissionInterval, tolerance)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+	assert.Equal(t, emissionInterval, res.RetryAfter)
+	assert.Equal(t, tolerance, res.ResetAfter)
+
+	res = store.take("other", now, emissionInterval, tolerance)
+	assert.True(t, res.Allowed, "keys are limited separately")
+
+	now = now.Add(emissionInterval)
+	res = store.take("key", now, emissionInterval, tolerance)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+
+	now = now.Add(2 * time.Minute)
+	res = store.take("other", now, emissionInterval, tolerance)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 9, res.Remaining)
+	assert.NotContains(t, store.tats, "key", "expired keys are swept")
+}
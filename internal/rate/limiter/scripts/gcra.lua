@@ -0,0 +1,27 @@
+local key = KEYS[1]
+
+-- All times are in microseconds, which keeps them within the
+-- integer precision of Lua numbers.
+local now = tonumber(ARGV[1])
+local emission_interval = tonumber(ARGV[2])
+local tolerance = tonumber(ARGV[3])
+
+local tat = now
+local stored = redis.call("GET", key)
+if stored then
+	tat = math.max(tonumber(stored), now)
+end
+
+local new_tat = tat + emission_interval
+local allow_at = new_tat - tolerance
+
+if now < allow_at then
+	return { 0, 0, allow_at - now, tat - now }
+end
+
+local ttl_ms = math.max(1, math.ceil((new_tat - now) / 1000))
+redis.call("SET", key, new_tat, "PX", ttl_ms)
+
+local remaining = math.floor((now - allow_at) / emission_interval)
+
+return { 1, remaining, 0, new_tat - now }
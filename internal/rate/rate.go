@@ -17,6 +17,7 @@ const (
 	LimitTokenBucket   string = "token-bucket"
 	LimitFixedWindow   string = "fixed-window"
 	LimitSlidingWindow string = "sliding-window"
+	LimitGCRA          string = "gcra"
 )
 
 const (
@@ -0,0 +1,107 @@
+package retry
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	defaultBudgetRatio         = 0.2
+	defaultMinRetriesPerSecond = 10
+	defaultBudgetTTL           = 10 * time.Second
+)
+
+// BudgetConfig configures a retry budget.
+type BudgetConfig struct {
+	// Ratio is the number of retries allowed per request, over TTL.
+	Ratio float64
+	// MinRetriesPerSecond is a floor of retries always allowed, so that low
+	// traffic endpoints can still retry. A negative value disables it.
+	MinRetriesPerSecond int
+	// TTL is the window over which requests and retries are counted.
+	TTL time.Duration
+}
+
+// Budget limits retries to a ratio of the requests sent over a sliding
+// window. Once an upstream fails most requests, retries stop as soon as the
+// budget is spent instead of multiplying the load on it. It is safe for
+// concurrent use.
+type Budget struct {
+	conf BudgetConfig
+	now  func() time.Time
+
+	mu      sync.Mutex
+	buckets []budgetBucket
+}
+
+// budgetBucket counts the requests and retries of one second.
+type budgetBucket struct {
+	second   int64
+	requests int
+	retries  int
+}
+
+// NewBudget creates a Budget, applying defaults for unset values.
+func NewBudget(conf BudgetConfig) *Budget {
+	if conf.Ratio <= 0 {
+		conf.Ratio = defaultBudgetRatio
+	}
+	switch {
+	case conf.MinRetriesPerSecond == 0:
+		conf.MinRetriesPerSecond = defaultMinRetriesPerSecond
+	case conf.MinRetriesPerSecond < 0:
+		// A negative value disables the floor.
+		conf.MinRetriesPerSecond = 0
+	}
+	if conf.TTL < time.Second {
+		conf.TTL = defaultBudgetTTL
+	}
+
+	return &Budget{
+		conf:    conf,
+		now:     time.Now,
+		buckets: make([]budgetBucket, int(conf.TTL/time.Second)),
+	}
+}
+
+// Deposit records a request, adding to the retries the budget allows.
+func (b *Budget) Deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.bucket(b.now().Unix()).requests++
+}
+
+// Withdraw reports whether a retry is within budget, and records it if so.
+func (b *Budget) Withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now().Unix()
+
+	var requests, retries int
+	for _, bucket := range b.buckets {
+		if now-bucket.second < int64(len(b.buckets)) {
+			requests += bucket.requests
+			retries += bucket.retries
+		}
+	}
+
+	allowed := b.conf.Ratio*float64(requests) +
+		float64(b.conf.MinRetriesPerSecond*len(b.buckets))
+	if float64(retries) >= allowed {
+		return false
+	}
+
+	b.bucket(now).retries++
+	return true
+}
+
+// bucket returns the bucket of the given second, resetting a stale one.
+func (b *Budget) bucket(second int64) *budgetBucket {
+	bucket := &b.buckets[second%int64(len(b.buckets))]
+	if bucket.second != second {
+		*bucket = budgetBucket{second: second}
+	}
+	return bucket
+}
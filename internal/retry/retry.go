@@ -0,0 +1,157 @@
+// Package retry implements the upstream retry policy: deciding which failed
+// attempts may be retried, exponential backoff with jitter, and retry budgets
+// which stop retries from amplifying an outage.
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// ErrorKind is a class of network error which can be retried.
+type ErrorKind string
+
+const (
+	// ErrorConnect is a failure to establish a connection to the upstream.
+	ErrorConnect ErrorKind = "connect_failure"
+	// ErrorReset is a connection closed or reset by the upstream before a
+	// response was received.
+	ErrorReset ErrorKind = "reset"
+	// ErrorTimeout is an upstream which didn't respond in time.
+	ErrorTimeout ErrorKind = "timeout"
+)
+
+const (
+	defaultBaseBackoff = 25 * time.Millisecond
+	defaultMaxBackoff  = 250 * time.Millisecond
+)
+
+// Policy describes when and how often a request is retried.
+type Policy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts int
+	// StatusCodes lists the upstream response codes which are retried.
+	StatusCodes []int
+	// Errors lists the network errors which are retried.
+	Errors []ErrorKind
+	// NonIdempotent allows retrying requests with non-idempotent methods,
+	// such as POST and PATCH.
+	NonIdempotent bool
+	// BaseBackoff is the backoff before the first retry. It doubles with
+	// every further retry.
+	BaseBackoff time.Duration
+	// MaxBackoff caps the backoff between retries.
+	MaxBackoff time.Duration
+}
+
+// Retryable reports whether an attempt which ended with the given status
+// code or error may be retried. It doesn't take the attempt count or the
+// retry budget into account.
+func (p Policy) Retryable(method string, status int, err error) bool {
+	if !p.NonIdempotent && !isIdempotent(method) {
+		return false
+	}
+
+	if err != nil {
+		kind, ok := Classify(err)
+		if !ok {
+			return false
+		}
+		for _, k := range p.Errors {
+			if k == kind {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, code := range p.StatusCodes {
+		if code == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Backoff returns the delay before the given retry, starting at 1. It uses
+// exponential backoff with full jitter, so concurrent clients don't retry in
+// lockstep.
+func (p Policy) Backoff(retry int) time.Duration {
+	base, max := p.BaseBackoff, p.MaxBackoff
+	if base <= 0 {
+		base = defaultBaseBackoff
+	}
+	if max <= 0 {
+		max = defaultMaxBackoff
+	}
+	if max < base {
+		max = base
+	}
+
+	ceiling := max
+	if retry < 1 {
+		retry = 1
+	}
+	if shift := retry - 1; shift < 32 {
+		if d := base << shift; d > 0 && d < max {
+			ceiling = d
+		}
+	}
+
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+// Classify returns the kind of a network error. Errors caused by the client
+// going away aren't retryable and report false.
+func Classify(err error) (ErrorKind, bool) {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return "", false
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return ErrorConnect, true
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return ErrorConnect, true
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorTimeout, true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrorTimeout, true
+	}
+
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return ErrorReset, true
+	}
+
+	return "", false
+}
+
+// ValidErrorKind reports whether kind is a known error kind.
+func ValidErrorKind(kind ErrorKind) bool {
+	switch kind {
+	case ErrorConnect, ErrorReset, ErrorTimeout:
+		return true
+	}
+	return false
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "timeout awaiting response headers" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		kind ErrorKind
+		ok   bool
+	}{
+		{"nil", nil, "", false},
+		{"canceled", fmt.Errorf("proxy: %w", context.Canceled), "", false},
+		{"dial", &net.OpError{Op: "dial", Err: errors.New("no route to host")}, ErrorConnect, true},
+		{"refused", fmt.Errorf("proxy: %w", syscall.ECONNREFUSED), ErrorConnect, true},
+		{"reset", &net.OpError{Op: "read", Err: syscall.ECONNRESET}, ErrorReset, true},
+		{"eof", fmt.Errorf("proxy: %w", io.EOF), ErrorReset, true},
+		{"deadline", fmt.Errorf("proxy: %w", context.DeadlineExceeded), ErrorTimeout, true},
+		{"timeout", timeoutError{}, ErrorTimeout, true},
+		{"other", errors.New("mock: error"), "", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			kind, ok := Classify(tc.err)
+			assert.Equal(t, tc.kind, kind)
+			assert.Equal(t, tc.ok, ok)
+		})
+	}
+}
+
+func TestPolicy_Retryable(t *testing.T) {
+	policy := Policy{
+		MaxAttempts: 3,
+		StatusCodes: []int{http.StatusBadGateway, http.StatusServiceUnavailable},
+		Errors:      []ErrorKind{ErrorConnect},
+	}
+	reset := &net.OpError{Op: "read", Err: syscall.ECONNRESET}
+	refused := &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}
+
+	assert.True(t, policy.Retryable(http.MethodGet, http.StatusBadGateway, nil))
+	assert.False(t, policy.Retryable(http.MethodGet, http.StatusInternalServerError, nil))
+	assert.True(t, policy.Retryable(http.MethodPut, 0, refused))
+	assert.False(t, policy.Retryable(http.MethodGet, 0, reset))
+
+	// Non-idempotent methods are only retried when opted in.
+	assert.False(t, policy.Retryable(http.MethodPost, http.StatusBadGateway, nil))
+	policy.NonIdempotent = true
+	assert.True(t, policy.Retryable(http.MethodPost, http.StatusBadGateway, nil))
+}
+
+func TestPolicy_Backoff(t *testing.T) {
+	policy := Policy{BaseBackoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond}
+
+	for i := 0; i < 100; i++ {
+		assert.LessOrEqual(t, policy.Backoff(1), 10*time.Millisecond)
+		assert.LessOrEqual(t, policy.Backoff(2), 20*time.Millisecond)
+		assert.LessOrEqual(t, policy.Backoff(3), 40*time.Millisecond)
+		assert.LessOrEqual(t, policy.Backoff(4), 50*time.Millisecond)
+		assert.LessOrEqual(t, policy.Backoff(100), 50*time.Millisecond)
+		assert.GreaterOrEqual(t, policy.Backoff(100), time.Duration(0))
+	}
+
+	assert.LessOrEqual(t, Policy{}.Backoff(10), defaultMaxBackoff)
+}
+
+func TestBudget(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	b := NewBudget(BudgetConfig{Ratio: 0.5, MinRetriesPerSecond: -1, TTL: 2 * time.Second})
+	b.now = func() time.Time { return now }
+
+	assert.False(t, b.Withdraw(), "no requests, no floor")
+
+	for i := 0; i < 4; i++ {
+		b.Deposit()
+	}
+	assert.True(t, b.Withdraw())
+	assert.True(t, b.Withdraw())
+	assert.False(t, b.Withdraw())
+
+	// Spent retries age out with the window.
+	now = now.Add(time.Second)
+	b.Deposit()
+	b.Deposit()
+	assert.True(t, b.Withdraw())
+	assert.False(t, b.Withdraw())
+
+	now = now.Add(2 * time.Second)
+	assert.False(t, b.Withdraw())
+	b.Deposit()
+	b.Deposit()
+	assert.True(t, b.Withdraw())
+}
+
+func TestBudget_MinRetriesPerSecond(t *testing.T) {
+	b := NewBudget(BudgetConfig{MinRetriesPerSecond: 1, TTL: time.Second})
+	b.now = func() time.Time { return time.Unix(1000, 0) }
+
+	assert.True(t, b.Withdraw())
+	assert.False(t, b.Withdraw())
+}
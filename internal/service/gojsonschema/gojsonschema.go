@@ -6,8 +6,10 @@ import (
 
 type (
 	JSONLoader              = gojsonschema.JSONLoader
+	JSONLoaderFactory       = gojsonschema.JSONLoaderFactory
 	ResultError             = gojsonschema.ResultError
 	Result                  = gojsonschema.Result
+	Schema                  = gojsonschema.Schema
 	FormatCheckerChain      = gojsonschema.FormatCheckerChain
 	DoesNotMatchFormatError = gojsonschema.DoesNotMatchFormatError
 )
@@ -15,6 +17,8 @@ type (
 var (
 	NewBytesLoader = gojsonschema.NewBytesLoader
 	NewGoLoader    = gojsonschema.NewGoLoader
+	NewRefLoader   = gojsonschema.NewReferenceLoader
+	NewSchema      = gojsonschema.NewSchema
 	FormatCheckers = gojsonschema.FormatCheckers
 	Validate       = gojsonschema.Validate
 )
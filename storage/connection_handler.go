@@ -21,6 +21,10 @@ type ConnectionHandler struct {
 	connections   map[string]model.Connector
 	connectionsMu *sync.RWMutex
 
+	// embedded is the embedded database, used instead of the
+	// connections when the embedded storage type is configured.
+	embedded *EmbeddedDB
+
 	storageUp      atomic.Value
 	disableStorage atomic.Value
 
@@ -59,6 +63,11 @@ func (rc *ConnectionHandler) DisableStorage(setStorageDown bool) {
 	rc.disableStorage.Store(false)
 	rc.storageUp.Store(false)
 
+	// There's no status check for the embedded database, it's up as long as it's open.
+	if rc.Embedded() != nil {
+		rc.storageUp.Store(true)
+	}
+
 	ctx, cancel := context.WithTimeout(rc.ctx, 5*time.Second)
 	defer cancel()
 
@@ -101,8 +110,31 @@ func (rc *ConnectionHandler) enabled() bool {
 	return ok
 }
 
+// Embedded returns the embedded database, or nil if the embedded storage isn't in use.
+func (rc *ConnectionHandler) Embedded() *EmbeddedDB {
+	rc.connectionsMu.RLock()
+	defer rc.connectionsMu.RUnlock()
+	return rc.embedded
+}
+
+// NewHandler returns the storage handler for the configured storage type. It's
+// the embedded storage, with the key prefix and hashing of cluster, when the
+// embedded storage is in use, and cluster talking to Redis otherwise.
+func (rc *ConnectionHandler) NewHandler(cluster *RedisCluster) Handler {
+	if db := rc.Embedded(); db != nil {
+		return &EmbeddedStorage{KeyPrefix: cluster.KeyPrefix, HashKeys: cluster.HashKeys, DB: db}
+	}
+
+	cluster.ConnectionHandler = rc
+	return cluster
+}
+
 // Disconnect closes the connection to the storage
 func (rc *ConnectionHandler) Disconnect() error {
+	if db := rc.Embedded(); db != nil {
+		return db.Close()
+	}
+
 	for _, v := range rc.connections {
 		if v != nil {
 			if err := v.Disconnect(context.Background()); err != nil {
@@ -129,6 +161,11 @@ func (rc *ConnectionHandler) recoverLoop(ctx context.Context, onReconnect func()
 //
 // onConnect will be called when we have established a successful storage reconnection
 func (rc *ConnectionHandler) Connect(ctx context.Context, onConnect func(), conf *config.Config) {
+	if conf.Storage.Type == config.StorageTypeEmbedded {
+		rc.connectEmbedded(ctx, onConnect, conf.Storage.Embedded)
+		return
+	}
+
 	err := rc.initConnection(*conf)
 	if err != nil {
 		log.WithError(err).Error("Could not initialize connection to Redis cluster")
@@ -153,6 +190,30 @@ func (rc *ConnectionHandler) Connect(ctx context.Context, onConnect func(), conf
 	go rc.statusCheck(ctx)
 }
 
+// connectEmbedded opens the embedded database. It's closed when ctx is done.
+func (rc *ConnectionHandler) connectEmbedded(ctx context.Context, onConnect func(), conf config.EmbeddedStorageConfig) {
+	db, err := OpenEmbeddedDB(conf.Path, time.Duration(conf.SyncInterval)*time.Second)
+	if err != nil {
+		log.WithError(err).Errorf("Could not open embedded storage at %q", conf.Path)
+		return
+	}
+
+	rc.connectionsMu.Lock()
+	rc.embedded = db
+	rc.connectionsMu.Unlock()
+
+	rc.storageUp.Store(true)
+	go rc.recoverLoop(ctx, onConnect)
+
+	go func() {
+		<-ctx.Done()
+		rc.storageUp.Store(false)
+		if err := db.Close(); err != nil {
+			log.WithError(err).Error("Could not close embedded storage")
+		}
+	}()
+}
+
 // initConnection initializes the connection singletons.
 func (rc *ConnectionHandler) initConnection(conf config.Config) (err error) {
 	rc.connectionsMu.Lock()
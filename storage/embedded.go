@@ -0,0 +1,465 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TykTechnologies/storage/temporal/model"
+)
+
+var (
+	_ Handler          = (*EmbeddedStorage)(nil)
+	_ AnalyticsHandler = (*EmbeddedStorage)(nil)
+)
+
+// ErrEmbeddedClosed is returned when the embedded storage was closed.
+var ErrEmbeddedClosed = errors.New("storage: embedded storage is closed")
+
+// EmbeddedStorage is a storage handler backed by an EmbeddedDB. It handles
+// keys like RedisCluster does, so it can be used wherever the gateway would
+// otherwise talk to Redis.
+type EmbeddedStorage struct {
+	KeyPrefix string
+	HashKeys  bool
+
+	DB *EmbeddedDB
+}
+
+func (e *EmbeddedStorage) hashKey(in string) string {
+	if !e.HashKeys {
+		// Not hashing? Return the raw key
+		return in
+	}
+	return HashStr(in)
+}
+
+func (e *EmbeddedStorage) fixKey(keyName string) string {
+	return e.KeyPrefix + e.hashKey(keyName)
+}
+
+func (e *EmbeddedStorage) cleanKey(keyName string) string {
+	return strings.Replace(keyName, e.KeyPrefix, "", 1)
+}
+
+// Connect reports whether the embedded database is available.
+func (e *EmbeddedStorage) Connect() bool {
+	return e.DB != nil
+}
+
+// GetKey will retrieve a key from the database
+func (e *EmbeddedStorage) GetKey(keyName string) (string, error) {
+	return e.GetRawKey(e.fixKey(keyName))
+}
+
+// GetMultiKey gets multiple keys from the database
+func (e *EmbeddedStorage) GetMultiKey(keys []string) ([]string, error) {
+	result := make([]string, len(keys))
+	found := false
+	for i, key := range keys {
+		value, err := e.DB.Get(e.fixKey(key))
+		if err == nil {
+			result[i] = value
+			found = true
+		}
+	}
+
+	if !found {
+		return nil, ErrKeyNotFound
+	}
+
+	return result, nil
+}
+
+// GetRawKey will retrieve a key from the database without prefixing it.
+func (e *EmbeddedStorage) GetRawKey(keyName string) (string, error) {
+	value, err := e.DB.Get(keyName)
+	if err != nil {
+		if !errors.Is(err, ErrKeyNotFound) {
+			log.Debug("Error trying to get value:", err)
+		}
+		return "", ErrKeyNotFound
+	}
+
+	return value, nil
+}
+
+// GetKeyTTL returns the remaining time to live of a key in seconds.
+func (e *EmbeddedStorage) GetKeyTTL(keyName string) (int64, error) {
+	return e.DB.TTL(e.fixKey(keyName)), nil
+}
+
+// GetExp returns the remaining time to live of a key in seconds.
+func (e *EmbeddedStorage) GetExp(keyName string) (int64, error) {
+	return e.GetKeyTTL(keyName)
+}
+
+// SetExp sets the time to live of a key in seconds.
+func (e *EmbeddedStorage) SetExp(keyName string, timeout int64) error {
+	e.DB.Expire(e.fixKey(keyName), time.Duration(timeout)*time.Second)
+	return nil
+}
+
+// SetKey will create (or update) a key value in the store
+func (e *EmbeddedStorage) SetKey(keyName, session string, timeout int64) error {
+	return e.SetRawKey(e.fixKey(keyName), session, timeout)
+}
+
+// SetRawKey will create (or update) a key value in the store without prefixing it.
+func (e *EmbeddedStorage) SetRawKey(keyName, session string, timeout int64) error {
+	e.DB.Set(keyName, session, time.Duration(timeout)*time.Second)
+	return nil
+}
+
+// SetKeyEx will update a key value in the store if value already exist.
+func (e *EmbeddedStorage) SetKeyEx(keyName, session string, timeout int64) error {
+	return e.SetRawKeyEx(e.fixKey(keyName), session, timeout)
+}
+
+// SetRawKeyEx will update a raw key value in the store if value already exist.
+func (e *EmbeddedStorage) SetRawKeyEx(keyName, session string, timeout int64) error {
+	e.DB.SetIfExists(keyName, session, time.Duration(timeout)*time.Second)
+	return nil
+}
+
+// Lock sets the key if it doesn't exist, reporting whether the lock was acquired.
+func (e *EmbeddedStorage) Lock(key string, timeout time.Duration) (bool, error) {
+	return e.DB.SetIfNotExists(key, "1", timeout), nil
+}
+
+// Decrement will decrement a key
+func (e *EmbeddedStorage) Decrement(keyName string) {
+	if _, err := e.DB.IncrBy(e.fixKey(keyName), -1); err != nil {
+		log.Error("Error trying to decrement value:", err)
+	}
+}
+
+// IncrememntWithExpire will increment a raw key, setting its expiry when it's created.
+func (e *EmbeddedStorage) IncrememntWithExpire(keyName string, expire int64) int64 {
+	val, err := e.DB.IncrBy(keyName, 1)
+	if err != nil {
+		log.Error("Error trying to increment value:", err)
+		return 0
+	}
+
+	if val == 1 && expire > 0 {
+		e.DB.Expire(keyName, time.Duration(expire)*time.Second)
+	}
+
+	return val
+}
+
+// IncrementBelow increments a raw key unless it reached limit, setting its
+// expiry when it's created. It reports whether the key was incremented.
+func (e *EmbeddedStorage) IncrementBelow(keyName string, limit, expire int64) (bool, error) {
+	return e.DB.IncrBelow(keyName, limit, time.Duration(expire)*time.Second)
+}
+
+// GetKeys will return all keys according to the filter (filter is a prefix - e.g. tyk.keys.*)
+func (e *EmbeddedStorage) GetKeys(filter string) []string {
+	filterHash := ""
+	if filter != "" {
+		filterHash = e.hashKey(filter)
+	}
+
+	keys := e.DB.Keys(e.KeyPrefix + filterHash + "*")
+	for i, v := range keys {
+		keys[i] = e.cleanKey(v)
+	}
+
+	return keys
+}
+
+// ScanKeys will return all keys according to the pattern.
+func (e *EmbeddedStorage) ScanKeys(pattern string) ([]string, error) {
+	return e.DB.Keys(pattern), nil
+}
+
+// GetKeysAndValuesWithFilter will return all keys and their values with a filter
+func (e *EmbeddedStorage) GetKeysAndValuesWithFilter(filter string) map[string]string {
+	if filter != "" && !strings.Contains(filter, e.KeyPrefix) {
+		filter = e.KeyPrefix + filter
+	}
+
+	m := make(map[string]string)
+	for _, key := range e.DB.Keys(filter + "*") {
+		value, err := e.DB.Get(key)
+		if err != nil {
+			continue
+		}
+		m[e.cleanKey(key)] = value
+	}
+
+	return m
+}
+
+// GetKeysAndValues will return all keys and their values - not to be used lightly
+func (e *EmbeddedStorage) GetKeysAndValues() map[string]string {
+	return e.GetKeysAndValuesWithFilter("")
+}
+
+// DeleteKey will remove a key from the database
+func (e *EmbeddedStorage) DeleteKey(keyName string) bool {
+	return e.DB.Delete(e.fixKey(keyName)) > 0
+}
+
+// DeleteAllKeys will remove all keys from the database.
+func (e *EmbeddedStorage) DeleteAllKeys() bool {
+	e.DB.FlushAll()
+	return true
+}
+
+// DeleteRawKey will remove a key from the database without prefixing it.
+func (e *EmbeddedStorage) DeleteRawKey(keyName string) bool {
+	e.DB.Delete(keyName)
+	return true
+}
+
+// DeleteScanMatch will remove all keys matching the pattern.
+func (e *EmbeddedStorage) DeleteScanMatch(pattern string) bool {
+	e.DB.Delete(e.DB.Keys(pattern)...)
+	return true
+}
+
+// DeleteRawKeys will remove a group of keys without prefixing them.
+func (e *EmbeddedStorage) DeleteRawKeys(keys []string) bool {
+	return e.DB.Delete(keys...) > 0
+}
+
+// DeleteKeys will remove a group of keys in bulk
+func (e *EmbeddedStorage) DeleteKeys(keys []string) bool {
+	fixedKeys := make([]string, len(keys))
+	for i, v := range keys {
+		fixedKeys[i] = e.fixKey(v)
+	}
+
+	return e.DB.Delete(fixedKeys...) > 0
+}
+
+// Exists check if keyName exists
+func (e *EmbeddedStorage) Exists(keyName string) (bool, error) {
+	return e.DB.Exists(e.fixKey(keyName)), nil
+}
+
+// StartPubSubHandler will listen for a signal and run the callback for
+// every message published to the channel.
+func (e *EmbeddedStorage) StartPubSubHandler(ctx context.Context, channel string, callback func(interface{})) error {
+	sub := e.DB.Subscribe(channel)
+	defer sub.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-sub.Messages():
+			if !ok {
+				return ErrEmbeddedClosed
+			}
+			if callback != nil {
+				callback(&embeddedMessage{channel: channel, payload: msg})
+			}
+		}
+	}
+}
+
+// Publish sends a message to the subscribers of channel.
+func (e *EmbeddedStorage) Publish(channel, message string) error {
+	e.DB.Publish(channel, message)
+	return nil
+}
+
+// GetAndDeleteSet returns and removes the list stored at keyName.
+func (e *EmbeddedStorage) GetAndDeleteSet(keyName string) []interface{} {
+	values, err := e.DB.ListPopAll(e.fixKey(keyName))
+	if err != nil {
+		log.Error("Error trying to get and delete set: ", err)
+		return nil
+	}
+
+	result := make([]interface{}, len(values))
+	for i, v := range values {
+		result[i] = v
+	}
+
+	return result
+}
+
+// AppendToSet appends value to the list stored at keyName.
+func (e *EmbeddedStorage) AppendToSet(keyName, value string) {
+	if err := e.DB.ListAppend(e.fixKey(keyName), value); err != nil {
+		log.WithError(err).Error("Error trying to append to set keys")
+	}
+}
+
+// AppendToSetPipelined appends values to the list stored at key.
+func (e *EmbeddedStorage) AppendToSetPipelined(key string, values [][]byte) {
+	if len(values) == 0 {
+		return
+	}
+
+	strValues := make([]string, len(values))
+	for i, v := range values {
+		strValues[i] = string(v)
+	}
+
+	if err := e.DB.ListAppend(e.fixKey(key), strValues...); err != nil {
+		log.WithError(err).Error("Error trying to append to set keys")
+	}
+}
+
+// RemoveFromList delete an value from a list idetinfied with the keyName
+func (e *EmbeddedStorage) RemoveFromList(keyName, value string) error {
+	_, err := e.DB.ListRemove(e.fixKey(keyName), value)
+	return err
+}
+
+// GetListRange gets range of elements of list identified by keyName
+func (e *EmbeddedStorage) GetListRange(keyName string, from, to int64) ([]string, error) {
+	return e.DB.ListRange(e.fixKey(keyName), from, to)
+}
+
+// GetSet returns the members of the set stored at keyName.
+func (e *EmbeddedStorage) GetSet(keyName string) (map[string]string, error) {
+	members, err := e.DB.SetMembers(e.fixKey(keyName))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]string)
+	for i, value := range members {
+		result[strconv.Itoa(i)] = value
+	}
+
+	return result, nil
+}
+
+// AddToSet adds value to the set stored at keyName.
+func (e *EmbeddedStorage) AddToSet(keyName, value string) {
+	if err := e.DB.SetAdd(e.fixKey(keyName), value); err != nil {
+		log.Error("Error trying to append to set: ", err)
+	}
+}
+
+// RemoveFromSet removes value from the set stored at keyName.
+func (e *EmbeddedStorage) RemoveFromSet(keyName, value string) {
+	if err := e.DB.SetRemove(e.fixKey(keyName), value); err != nil {
+		log.Error("Error trying to remove keys: ", err)
+	}
+}
+
+// IsMemberOfSet reports whether value is in the set stored at keyName.
+func (e *EmbeddedStorage) IsMemberOfSet(keyName, value string) bool {
+	ok, err := e.DB.SetIsMember(e.fixKey(keyName), value)
+	if err != nil {
+		log.Error("Error trying to check set member: ", err)
+	}
+	return ok
+}
+
+// SetRollingWindow will append to a sorted set and extract a timed window of values
+func (e *EmbeddedStorage) SetRollingWindow(keyName string, per int64, valueOverride string, _ bool) (int, []interface{}) {
+	now := time.Now()
+	onePeriodAgo := now.Add(time.Duration(-1*per) * time.Second)
+
+	values, err := e.rollingWindow(keyName, onePeriodAgo)
+	if err != nil {
+		log.Error("Error trying to set rolling window: ", err)
+		return 0, nil
+	}
+
+	member := valueOverride
+	if member == "-1" {
+		member = strconv.Itoa(int(now.UnixNano()))
+	}
+
+	if err := e.DB.SortedSetAdd(keyName, member, float64(now.UnixNano())); err != nil {
+		log.Error("Error trying to set rolling window: ", err)
+		return 0, nil
+	}
+	e.DB.Expire(keyName, time.Duration(per)*time.Second)
+
+	return len(values), values
+}
+
+// GetRollingWindow returns the values of a timed window.
+func (e *EmbeddedStorage) GetRollingWindow(keyName string, per int64, _ bool) (int, []interface{}) {
+	onePeriodAgo := time.Now().Add(time.Duration(-1*per) * time.Second)
+
+	values, err := e.rollingWindow(keyName, onePeriodAgo)
+	if err != nil {
+		log.Error("Error trying to get rolling window: ", err)
+		return 0, nil
+	}
+
+	return len(values), values
+}
+
+// rollingWindow drops the values of the window older than since and returns the rest.
+func (e *EmbeddedStorage) rollingWindow(keyName string, since time.Time) ([]interface{}, error) {
+	if _, err := e.DB.SortedSetRemoveRangeByScore(keyName, "-inf", strconv.Itoa(int(since.UnixNano()))); err != nil {
+		return nil, err
+	}
+
+	members, err := e.DB.SortedSetMembers(keyName)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, len(members))
+	for i, v := range members {
+		values[i] = v
+	}
+
+	return values, nil
+}
+
+// GetKeyPrefix returns storage key prefix
+func (e *EmbeddedStorage) GetKeyPrefix() string {
+	return e.KeyPrefix
+}
+
+// AddToSortedSet adds value with given score to sorted set identified by keyName
+func (e *EmbeddedStorage) AddToSortedSet(keyName, value string, score float64) {
+	if err := e.DB.SortedSetAdd(e.fixKey(keyName), value, score); err != nil {
+		log.WithError(err).Error("Error trying to add to sorted set")
+	}
+}
+
+// GetSortedSetRange gets range of elements of sorted set identified by keyName
+func (e *EmbeddedStorage) GetSortedSetRange(keyName, scoreFrom, scoreTo string) ([]string, []float64, error) {
+	return e.DB.SortedSetRangeByScore(e.fixKey(keyName), scoreFrom, scoreTo)
+}
+
+// RemoveSortedSetRange removes range of elements from sorted set identified by keyName
+func (e *EmbeddedStorage) RemoveSortedSetRange(keyName, scoreFrom, scoreTo string) error {
+	_, err := e.DB.SortedSetRemoveRangeByScore(e.fixKey(keyName), scoreFrom, scoreTo)
+	return err
+}
+
+// RemoveFromSortedSet removes value from sorted set identified by keyName
+func (e *EmbeddedStorage) RemoveFromSortedSet(keyName, value string) error {
+	return e.DB.SortedSetRemove(e.fixKey(keyName), value)
+}
+
+// embeddedMessage is a pub/sub message delivered by the embedded storage.
+type embeddedMessage struct {
+	channel string
+	payload string
+}
+
+// Type returns the message type.
+func (m *embeddedMessage) Type() string {
+	return model.MessageTypeMessage
+}
+
+// Channel returns the channel the message was received on.
+func (m *embeddedMessage) Channel() (string, error) {
+	return m.channel, nil
+}
+
+// Payload returns the message payload.
+func (m *embeddedMessage) Payload() (string, error) {
+	return m.payload, nil
+}
@@ -0,0 +1,884 @@
+package storage
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultEmbeddedSyncInterval is how often expired keys are evicted and
+	// the log of the embedded database is flushed to disk.
+	defaultEmbeddedSyncInterval = 5 * time.Second
+
+	// embeddedSubscriptionBuffer is the number of pub/sub messages buffered
+	// per subscriber before further messages are dropped.
+	embeddedSubscriptionBuffer = 128
+)
+
+var (
+	// ErrEmbeddedWrongType is returned when an operation is run against a key
+	// holding a different kind of value, e.g. a list operation on a string.
+	ErrEmbeddedWrongType = errors.New("storage: operation against a key holding the wrong kind of value")
+
+	// ErrEmbeddedNotInteger is returned when incrementing a value which isn't an integer.
+	ErrEmbeddedNotInteger = errors.New("storage: value is not an integer")
+
+	// ErrEmbeddedInvalidScore is returned for a sorted set score range which can't be parsed.
+	ErrEmbeddedInvalidScore = errors.New("storage: invalid sorted set score")
+)
+
+// embeddedKind is the kind of value stored under a key.
+type embeddedKind uint8
+
+const (
+	embeddedString embeddedKind = iota
+	embeddedList
+	embeddedSet
+	embeddedSortedSet
+)
+
+// embeddedEntry is a value stored in the embedded database. Sets and sorted
+// sets share Members, plain sets having a zero score for every member.
+type embeddedEntry struct {
+	Kind      embeddedKind
+	Value     string
+	List      []string
+	Members   map[string]float64
+	ExpiresAt int64
+}
+
+// EmbeddedDB is an in-process key value database implementing the subset of
+// Redis the gateway relies on: strings with expiry, counters, lists, sets,
+// sorted sets and pub/sub. Data is kept in memory and, when a path is set,
+// every write is appended to a log on disk which is replayed on start. It is
+// safe for concurrent use.
+type EmbeddedDB struct {
+	path string
+	now  func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*embeddedEntry
+	// log is where the writes are appended, it's nil when the data is only
+	// kept in memory. rewrite is the compacted log being written, if any.
+	log           *embeddedLog
+	rewrite       *embeddedLog
+	compactedSize int64
+
+	// fileMu serialises the flushes of the log with its replacement.
+	fileMu sync.Mutex
+
+	subsMu sync.RWMutex
+	subs   map[string]map[*EmbeddedSubscription]struct{}
+
+	done      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// OpenEmbeddedDB opens the embedded database persisted at path, creating it
+// if it doesn't exist. An empty path keeps data in memory only. Expired keys
+// are evicted, and the log flushed to disk, every syncInterval.
+func OpenEmbeddedDB(path string, syncInterval time.Duration) (*EmbeddedDB, error) {
+	if syncInterval <= 0 {
+		syncInterval = defaultEmbeddedSyncInterval
+	}
+
+	db := &EmbeddedDB{
+		path:    path,
+		now:     time.Now,
+		entries: make(map[string]*embeddedEntry),
+		subs:    make(map[string]map[*EmbeddedSubscription]struct{}),
+		done:    make(chan struct{}),
+	}
+
+	if err := db.load(); err != nil {
+		return nil, err
+	}
+
+	// Start from a compacted log, the previous one may end with a
+	// truncated write.
+	if path != "" {
+		if err := db.compact(); err != nil {
+			return nil, err
+		}
+	}
+
+	db.wg.Add(1)
+	go db.syncLoop(syncInterval)
+
+	return db, nil
+}
+
+// Close stops the background sync, flushes and closes the log and closes all
+// subscriptions.
+func (db *EmbeddedDB) Close() error {
+	var err error
+	db.closeOnce.Do(func() {
+		close(db.done)
+		db.wg.Wait()
+		err = db.Sync()
+
+		db.mu.Lock()
+		if db.log != nil {
+			if closeErr := db.log.file.Close(); err == nil {
+				err = closeErr
+			}
+			db.log = nil
+		}
+		db.mu.Unlock()
+
+		db.subsMu.Lock()
+		for channel, subs := range db.subs {
+			for sub := range subs {
+				close(sub.messages)
+			}
+			delete(db.subs, channel)
+		}
+		db.subsMu.Unlock()
+	})
+	return err
+}
+
+func (db *EmbeddedDB) syncLoop(interval time.Duration) {
+	defer db.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-db.done:
+			return
+		case <-ticker.C:
+			db.evictExpired()
+			if err := db.Sync(); err != nil {
+				log.WithError(err).Error("Could not persist embedded storage")
+			}
+			if db.needsCompaction() {
+				if err := db.compact(); err != nil {
+					log.WithError(err).Error("Could not compact embedded storage")
+				}
+			}
+		}
+	}
+}
+
+func (db *EmbeddedDB) evictExpired() {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	now := db.now().UnixNano()
+	for key, entry := range db.entries {
+		if entry.ExpiresAt != 0 && entry.ExpiresAt <= now {
+			delete(db.entries, key)
+		}
+	}
+}
+
+// lookup returns the live entry of key. It must be called with mu held.
+func (db *EmbeddedDB) lookup(key string) *embeddedEntry {
+	entry, ok := db.entries[key]
+	if !ok {
+		return nil
+	}
+
+	if entry.ExpiresAt != 0 && entry.ExpiresAt <= db.now().UnixNano() {
+		delete(db.entries, key)
+		return nil
+	}
+
+	return entry
+}
+
+// lookupKind returns the live entry of key, creating it with kind if it
+// doesn't exist and create is set. It must be called with mu held.
+func (db *EmbeddedDB) lookupKind(key string, kind embeddedKind, create bool) (*embeddedEntry, error) {
+	entry := db.lookup(key)
+	if entry == nil {
+		if !create {
+			return nil, nil
+		}
+		entry = &embeddedEntry{Kind: kind}
+		if kind == embeddedSet || kind == embeddedSortedSet {
+			entry.Members = make(map[string]float64)
+		}
+		db.entries[key] = entry
+	}
+
+	if entry.Kind != kind {
+		return nil, ErrEmbeddedWrongType
+	}
+
+	return entry, nil
+}
+
+func (db *EmbeddedDB) expiresAt(ttl time.Duration) int64 {
+	if ttl <= 0 {
+		return 0
+	}
+	return db.now().Add(ttl).UnixNano()
+}
+
+// Get returns the string value of key.
+func (db *EmbeddedDB) Get(key string) (string, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	entry, err := db.lookupKind(key, embeddedString, false)
+	if err != nil {
+		return "", err
+	}
+	if entry == nil {
+		return "", ErrKeyNotFound
+	}
+
+	return entry.Value, nil
+}
+
+// Set sets the string value of key. A ttl of zero or less never expires.
+func (db *EmbeddedDB) Set(key, value string, ttl time.Duration) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.entries[key] = &embeddedEntry{Kind: embeddedString, Value: value, ExpiresAt: db.expiresAt(ttl)}
+	db.record(embeddedOp{Kind: embeddedOpSet, Key: key, Value: value, TTL: ttl})
+}
+
+// SetIfExists sets the string value of key only if the key exists.
+func (db *EmbeddedDB) SetIfExists(key, value string, ttl time.Duration) bool {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.lookup(key) == nil {
+		return false
+	}
+
+	db.entries[key] = &embeddedEntry{Kind: embeddedString, Value: value, ExpiresAt: db.expiresAt(ttl)}
+	db.record(embeddedOp{Kind: embeddedOpSet, Key: key, Value: value, TTL: ttl})
+	return true
+}
+
+// SetIfNotExists sets the string value of key only if the key doesn't exist.
+func (db *EmbeddedDB) SetIfNotExists(key, value string, ttl time.Duration) bool {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.lookup(key) != nil {
+		return false
+	}
+
+	db.entries[key] = &embeddedEntry{Kind: embeddedString, Value: value, ExpiresAt: db.expiresAt(ttl)}
+	db.record(embeddedOp{Kind: embeddedOpSet, Key: key, Value: value, TTL: ttl})
+	return true
+}
+
+// TTL returns the remaining time to live of key in seconds, -1 if the key
+// doesn't expire and -2 if it doesn't exist.
+func (db *EmbeddedDB) TTL(key string) int64 {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	entry := db.lookup(key)
+	switch {
+	case entry == nil:
+		return -2
+	case entry.ExpiresAt == 0:
+		return -1
+	}
+
+	remaining := time.Duration(entry.ExpiresAt - db.now().UnixNano())
+	return int64(math.Ceil(remaining.Seconds()))
+}
+
+// Expire sets the time to live of key. A ttl of zero or less removes the
+// expiry. It returns false if the key doesn't exist.
+func (db *EmbeddedDB) Expire(key string, ttl time.Duration) bool {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	entry := db.lookup(key)
+	if entry == nil {
+		return false
+	}
+
+	entry.ExpiresAt = db.expiresAt(ttl)
+	db.record(embeddedOp{Kind: embeddedOpExpire, Key: key, TTL: ttl})
+	return true
+}
+
+// IncrBy increments the integer value of key by delta, starting from zero
+// if the key doesn't exist, and returns the new value.
+func (db *EmbeddedDB) IncrBy(key string, delta int64) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	entry, err := db.lookupKind(key, embeddedString, true)
+	if err != nil {
+		return 0, err
+	}
+
+	var val int64
+	if entry.Value != "" {
+		if val, err = strconv.ParseInt(entry.Value, 10, 64); err != nil {
+			return 0, ErrEmbeddedNotInteger
+		}
+	}
+
+	val += delta
+	entry.Value = strconv.FormatInt(val, 10)
+	db.record(embeddedOp{Kind: embeddedOpIncrBy, Key: key, Delta: delta})
+
+	return val, nil
+}
+
+// IncrBelow increments the integer value of key unless it reached limit,
+// setting the expiry of the key to ttl when it's created. A negative value
+// counts as zero. It reports whether the value was incremented.
+func (db *EmbeddedDB) IncrBelow(key string, limit int64, ttl time.Duration) (bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	entry, err := db.lookupKind(key, embeddedString, true)
+	if err != nil {
+		return false, err
+	}
+
+	var cur int64
+	if entry.Value != "" {
+		if cur, err = strconv.ParseInt(entry.Value, 10, 64); err != nil {
+			return false, ErrEmbeddedNotInteger
+		}
+	}
+
+	val := max(cur, 0)
+	if val >= limit {
+		return false, nil
+	}
+
+	entry.Value = strconv.FormatInt(val+1, 10)
+	db.record(embeddedOp{Kind: embeddedOpIncrBy, Key: key, Delta: val + 1 - cur})
+
+	if entry.ExpiresAt == 0 && ttl > 0 {
+		entry.ExpiresAt = db.expiresAt(ttl)
+		db.record(embeddedOp{Kind: embeddedOpExpire, Key: key, TTL: ttl})
+	}
+
+	return true, nil
+}
+
+// Delete removes keys and returns the number of keys removed.
+func (db *EmbeddedDB) Delete(keys ...string) int {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	deleted := 0
+	for _, key := range keys {
+		if db.lookup(key) != nil {
+			delete(db.entries, key)
+			deleted++
+		}
+	}
+
+	if deleted > 0 {
+		db.record(embeddedOp{Kind: embeddedOpDelete, Keys: keys})
+	}
+
+	return deleted
+}
+
+// Exists reports whether key exists.
+func (db *EmbeddedDB) Exists(key string) bool {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return db.lookup(key) != nil
+}
+
+// Keys returns the keys matching a glob style pattern, where `*` matches any
+// sequence of characters and `?` a single character.
+func (db *EmbeddedDB) Keys(pattern string) []string {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	keys := []string{}
+	for key := range db.entries {
+		if matchGlob(pattern, key) && db.lookup(key) != nil {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// FlushAll removes all keys.
+func (db *EmbeddedDB) FlushAll() {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.entries = make(map[string]*embeddedEntry)
+	db.record(embeddedOp{Kind: embeddedOpFlushAll})
+}
+
+// ListAppend appends values to the list stored at key.
+func (db *EmbeddedDB) ListAppend(key string, values ...string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	entry, err := db.lookupKind(key, embeddedList, true)
+	if err != nil {
+		return err
+	}
+
+	entry.List = append(entry.List, values...)
+	db.record(embeddedOp{Kind: embeddedOpListAppend, Key: key, Values: values})
+	return nil
+}
+
+// ListPopAll removes the list stored at key and returns its values.
+func (db *EmbeddedDB) ListPopAll(key string) ([]string, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	entry, err := db.lookupKind(key, embeddedList, false)
+	if err != nil || entry == nil {
+		return nil, err
+	}
+
+	delete(db.entries, key)
+	db.record(embeddedOp{Kind: embeddedOpDelete, Keys: []string{key}})
+	return entry.List, nil
+}
+
+// ListRemove removes all occurrences of value from the list stored at key and
+// returns the number of removed values.
+func (db *EmbeddedDB) ListRemove(key, value string) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	entry, err := db.lookupKind(key, embeddedList, false)
+	if err != nil || entry == nil {
+		return 0, err
+	}
+
+	kept := entry.List[:0]
+	for _, v := range entry.List {
+		if v != value {
+			kept = append(kept, v)
+		}
+	}
+
+	removed := len(entry.List) - len(kept)
+	entry.List = kept
+	if len(entry.List) == 0 {
+		delete(db.entries, key)
+	}
+	if removed > 0 {
+		db.record(embeddedOp{Kind: embeddedOpListRemove, Key: key, Value: value})
+	}
+
+	return removed, nil
+}
+
+// ListRange returns the values of the list stored at key between the from and
+// to indexes, inclusive. Negative indexes count from the end of the list.
+func (db *EmbeddedDB) ListRange(key string, from, to int64) ([]string, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	entry, err := db.lookupKind(key, embeddedList, false)
+	if err != nil || entry == nil {
+		return []string{}, err
+	}
+
+	n := int64(len(entry.List))
+	if from < 0 {
+		from = max(n+from, 0)
+	}
+	if to < 0 {
+		to = n + to
+	}
+	if to >= n {
+		to = n - 1
+	}
+	if from > to {
+		return []string{}, nil
+	}
+
+	values := make([]string, to-from+1)
+	copy(values, entry.List[from:to+1])
+	return values, nil
+}
+
+// SetAdd adds member to the set stored at key.
+func (db *EmbeddedDB) SetAdd(key, member string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	entry, err := db.lookupKind(key, embeddedSet, true)
+	if err != nil {
+		return err
+	}
+
+	entry.Members[member] = 0
+	db.record(embeddedOp{Kind: embeddedOpSetAdd, Key: key, Value: member})
+	return nil
+}
+
+// SetRemove removes member from the set stored at key.
+func (db *EmbeddedDB) SetRemove(key, member string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	entry, err := db.lookupKind(key, embeddedSet, false)
+	if err != nil || entry == nil {
+		return err
+	}
+
+	delete(entry.Members, member)
+	if len(entry.Members) == 0 {
+		delete(db.entries, key)
+	}
+	db.record(embeddedOp{Kind: embeddedOpSetRemove, Key: key, Value: member})
+	return nil
+}
+
+// SetMembers returns the members of the set stored at key.
+func (db *EmbeddedDB) SetMembers(key string) ([]string, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	entry, err := db.lookupKind(key, embeddedSet, false)
+	if err != nil || entry == nil {
+		return []string{}, err
+	}
+
+	members := make([]string, 0, len(entry.Members))
+	for member := range entry.Members {
+		members = append(members, member)
+	}
+
+	sort.Strings(members)
+	return members, nil
+}
+
+// SetIsMember reports whether member is in the set stored at key.
+func (db *EmbeddedDB) SetIsMember(key, member string) (bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	entry, err := db.lookupKind(key, embeddedSet, false)
+	if err != nil || entry == nil {
+		return false, err
+	}
+
+	_, ok := entry.Members[member]
+	return ok, nil
+}
+
+// SortedSetAdd adds member with score to the sorted set stored at key,
+// updating the score of an existing member.
+func (db *EmbeddedDB) SortedSetAdd(key, member string, score float64) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	entry, err := db.lookupKind(key, embeddedSortedSet, true)
+	if err != nil {
+		return err
+	}
+
+	entry.Members[member] = score
+	db.record(embeddedOp{Kind: embeddedOpSortedSetAdd, Key: key, Value: member, Score: score})
+	return nil
+}
+
+// SortedSetRangeByScore returns the members of the sorted set stored at key
+// with a score between scoreFrom and scoreTo, ordered by score. Bounds accept `-inf`,
+// `+inf` and a `(` prefix for an exclusive bound.
+func (db *EmbeddedDB) SortedSetRangeByScore(key, scoreFrom, scoreTo string) ([]string, []float64, error) {
+	lower, err := parseScoreBound(scoreFrom)
+	if err != nil {
+		return nil, nil, err
+	}
+	upper, err := parseScoreBound(scoreTo)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	entry, err := db.lookupKind(key, embeddedSortedSet, false)
+	if err != nil || entry == nil {
+		return nil, nil, err
+	}
+
+	members := sortedMembers(entry.Members)
+
+	var (
+		values []string
+		scores []float64
+	)
+	for _, member := range members {
+		score := entry.Members[member]
+		if lower.below(score) && upper.above(score) {
+			values = append(values, member)
+			scores = append(scores, score)
+		}
+	}
+
+	return values, scores, nil
+}
+
+// SortedSetRemoveRangeByScore removes the members of the sorted set stored at
+// key with a score between scoreFrom and scoreTo, and returns the number removed.
+func (db *EmbeddedDB) SortedSetRemoveRangeByScore(key, scoreFrom, scoreTo string) (int, error) {
+	lower, err := parseScoreBound(scoreFrom)
+	if err != nil {
+		return 0, err
+	}
+	upper, err := parseScoreBound(scoreTo)
+	if err != nil {
+		return 0, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	entry, err := db.lookupKind(key, embeddedSortedSet, false)
+	if err != nil || entry == nil {
+		return 0, err
+	}
+
+	removed := 0
+	for member, score := range entry.Members {
+		if lower.below(score) && upper.above(score) {
+			delete(entry.Members, member)
+			removed++
+		}
+	}
+
+	if len(entry.Members) == 0 {
+		delete(db.entries, key)
+	}
+	if removed > 0 {
+		db.record(embeddedOp{Kind: embeddedOpSortedSetRemoveRange, Key: key, From: scoreFrom, To: scoreTo})
+	}
+
+	return removed, nil
+}
+
+// SortedSetRemove removes member from the sorted set stored at key.
+func (db *EmbeddedDB) SortedSetRemove(key, member string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	entry, err := db.lookupKind(key, embeddedSortedSet, false)
+	if err != nil || entry == nil {
+		return err
+	}
+
+	delete(entry.Members, member)
+	if len(entry.Members) == 0 {
+		delete(db.entries, key)
+	}
+	db.record(embeddedOp{Kind: embeddedOpSortedSetRemove, Key: key, Value: member})
+	return nil
+}
+
+// SortedSetMembers returns all members of the sorted set stored at key,
+// ordered by score.
+func (db *EmbeddedDB) SortedSetMembers(key string) ([]string, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	entry, err := db.lookupKind(key, embeddedSortedSet, false)
+	if err != nil || entry == nil {
+		return []string{}, err
+	}
+
+	return sortedMembers(entry.Members), nil
+}
+
+// sortedMembers returns the members ordered by score, then lexically.
+func sortedMembers(scores map[string]float64) []string {
+	members := make([]string, 0, len(scores))
+	for member := range scores {
+		members = append(members, member)
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		si, sj := scores[members[i]], scores[members[j]]
+		if si != sj {
+			return si < sj
+		}
+		return members[i] < members[j]
+	})
+
+	return members
+}
+
+// scoreBound is one end of a sorted set score range.
+type scoreBound struct {
+	value     float64
+	exclusive bool
+}
+
+func parseScoreBound(s string) (scoreBound, error) {
+	var bound scoreBound
+	if strings.HasPrefix(s, "(") {
+		bound.exclusive = true
+		s = s[1:]
+	}
+
+	switch s {
+	case "-inf":
+		bound.value = math.Inf(-1)
+	case "+inf", "inf":
+		bound.value = math.Inf(1)
+	default:
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return bound, ErrEmbeddedInvalidScore
+		}
+		bound.value = v
+	}
+
+	return bound, nil
+}
+
+// below reports whether score is above the bound, used as a lower bound.
+func (b scoreBound) below(score float64) bool {
+	if b.exclusive {
+		return b.value < score
+	}
+	return b.value <= score
+}
+
+// above reports whether score is below the bound, used as an upper bound.
+func (b scoreBound) above(score float64) bool {
+	if b.exclusive {
+		return score < b.value
+	}
+	return score <= b.value
+}
+
+// EmbeddedSubscription receives the messages published to a channel.
+type EmbeddedSubscription struct {
+	db       *EmbeddedDB
+	channel  string
+	messages chan string
+	once     sync.Once
+}
+
+// Messages returns the channel delivering published messages. It is closed
+// when the subscription or the database is closed.
+func (s *EmbeddedSubscription) Messages() <-chan string {
+	return s.messages
+}
+
+// Close unsubscribes from the channel.
+func (s *EmbeddedSubscription) Close() {
+	s.once.Do(func() {
+		s.db.subsMu.Lock()
+		defer s.db.subsMu.Unlock()
+
+		if subs, ok := s.db.subs[s.channel]; ok {
+			if _, ok := subs[s]; ok {
+				delete(subs, s)
+				close(s.messages)
+			}
+		}
+	})
+}
+
+// Subscribe subscribes to the messages published to channel.
+func (db *EmbeddedDB) Subscribe(channel string) *EmbeddedSubscription {
+	sub := &EmbeddedSubscription{
+		db:       db,
+		channel:  channel,
+		messages: make(chan string, embeddedSubscriptionBuffer),
+	}
+
+	db.subsMu.Lock()
+	defer db.subsMu.Unlock()
+
+	select {
+	case <-db.done:
+		close(sub.messages)
+		return sub
+	default:
+	}
+
+	if db.subs[channel] == nil {
+		db.subs[channel] = make(map[*EmbeddedSubscription]struct{})
+	}
+	db.subs[channel][sub] = struct{}{}
+
+	return sub
+}
+
+// Publish sends message to the subscribers of channel and returns the number
+// of subscribers which received it. Subscribers which fall behind miss messages.
+func (db *EmbeddedDB) Publish(channel, message string) int {
+	db.subsMu.RLock()
+	defer db.subsMu.RUnlock()
+
+	received := 0
+	for sub := range db.subs[channel] {
+		select {
+		case sub.messages <- message:
+			received++
+		default:
+			log.WithField("channel", channel).Warning("Embedded storage subscriber is falling behind, dropping message")
+		}
+	}
+
+	return received
+}
+
+// matchGlob reports whether s matches pattern, where `*` matches any
+// sequence of characters and `?` any single character.
+func matchGlob(pattern, s string) bool {
+	var px, sx int
+	nextPx, nextSx := -1, -1
+
+	for px < len(pattern) || sx < len(s) {
+		if px < len(pattern) {
+			switch c := pattern[px]; c {
+			case '*':
+				// Try matching an empty sequence first, and backtrack to
+				// consume one more character on mismatch.
+				nextPx, nextSx = px, sx+1
+				px++
+				continue
+			case '?':
+				if sx < len(s) {
+					px++
+					sx++
+					continue
+				}
+			default:
+				if sx < len(s) && s[sx] == c {
+					px++
+					sx++
+					continue
+				}
+			}
+		}
+
+		if nextSx > 0 && nextSx <= len(s) {
+			px, sx = nextPx, nextSx
+			continue
+		}
+
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,272 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/gob"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// embeddedCompactMinSize is the size the log of the embedded database must
+	// reach before it's compacted.
+	embeddedCompactMinSize = 64 << 20
+
+	// embeddedCompactBatch is the number of keys written to the compacted log
+	// per lock of the database.
+	embeddedCompactBatch = 1000
+)
+
+// embeddedOpKind is the kind of write appended to the log.
+type embeddedOpKind uint8
+
+const (
+	embeddedOpSet embeddedOpKind = iota
+	embeddedOpExpire
+	embeddedOpIncrBy
+	embeddedOpDelete
+	embeddedOpFlushAll
+	embeddedOpListAppend
+	embeddedOpListRemove
+	embeddedOpSetAdd
+	embeddedOpSetRemove
+	embeddedOpSortedSetAdd
+	embeddedOpSortedSetRemove
+	embeddedOpSortedSetRemoveRange
+	// embeddedOpRestore replaces the entry of a key, it's written by the
+	// compaction.
+	embeddedOpRestore
+)
+
+// embeddedOp is a write of the embedded database. It's replayed at the time
+// it was made, so that the keys expire as they did.
+type embeddedOp struct {
+	Kind   embeddedOpKind
+	Time   int64
+	Key    string
+	Keys   []string
+	Value  string
+	Values []string
+	Score  float64
+	TTL    time.Duration
+	Delta  int64
+	From   string
+	To     string
+	Entry  *embeddedEntry
+}
+
+// embeddedLog is a log file the writes are appended to, as a gob stream.
+type embeddedLog struct {
+	file *os.File
+	enc  *gob.Encoder
+	size int64
+	// err is the first append failure, the log is compacted on the next
+	// sync as the stream can't be appended to anymore.
+	err error
+}
+
+func newEmbeddedLog(file *os.File) *embeddedLog {
+	l := &embeddedLog{file: file}
+	l.enc = gob.NewEncoder(l)
+	return l
+}
+
+func (l *embeddedLog) Write(p []byte) (int, error) {
+	n, err := l.file.Write(p)
+	l.size += int64(n)
+	return n, err
+}
+
+func (l *embeddedLog) append(op *embeddedOp) {
+	if l.err != nil {
+		return
+	}
+
+	if err := l.enc.Encode(op); err != nil {
+		l.err = err
+		log.WithError(err).Error("Could not append to the embedded storage log")
+	}
+}
+
+// record appends the write to the log. It must be called with mu held.
+func (db *EmbeddedDB) record(op embeddedOp) {
+	if db.log == nil {
+		return
+	}
+
+	op.Time = db.now().UnixNano()
+	db.log.append(&op)
+	if db.rewrite != nil {
+		db.rewrite.append(&op)
+	}
+}
+
+// load replays the log, if any. A truncated write at the end of the log, left
+// by a crash, is ignored.
+func (db *EmbeddedDB) load() error {
+	if db.path == "" {
+		return nil
+	}
+
+	file, err := os.Open(db.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	now := db.now
+	defer func() {
+		db.now = now
+		db.evictExpired()
+	}()
+
+	dec := gob.NewDecoder(bufio.NewReader(file))
+	for {
+		var op embeddedOp
+		err := dec.Decode(&op)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			log.Warning("Embedded storage log ends with a truncated write, ignoring it")
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		db.now = func() time.Time { return time.Unix(0, op.Time) }
+		db.replay(&op)
+	}
+
+	return nil
+}
+
+// replay applies a write of the log.
+func (db *EmbeddedDB) replay(op *embeddedOp) {
+	switch op.Kind {
+	case embeddedOpSet:
+		db.Set(op.Key, op.Value, op.TTL)
+	case embeddedOpExpire:
+		db.Expire(op.Key, op.TTL)
+	case embeddedOpIncrBy:
+		_, _ = db.IncrBy(op.Key, op.Delta)
+	case embeddedOpDelete:
+		db.Delete(op.Keys...)
+	case embeddedOpFlushAll:
+		db.FlushAll()
+	case embeddedOpListAppend:
+		_ = db.ListAppend(op.Key, op.Values...)
+	case embeddedOpListRemove:
+		_, _ = db.ListRemove(op.Key, op.Value)
+	case embeddedOpSetAdd:
+		_ = db.SetAdd(op.Key, op.Value)
+	case embeddedOpSetRemove:
+		_ = db.SetRemove(op.Key, op.Value)
+	case embeddedOpSortedSetAdd:
+		_ = db.SortedSetAdd(op.Key, op.Value, op.Score)
+	case embeddedOpSortedSetRemove:
+		_ = db.SortedSetRemove(op.Key, op.Value)
+	case embeddedOpSortedSetRemoveRange:
+		_, _ = db.SortedSetRemoveRangeByScore(op.Key, op.From, op.To)
+	case embeddedOpRestore:
+		if op.Entry != nil {
+			db.mu.Lock()
+			db.entries[op.Key] = op.Entry
+			db.mu.Unlock()
+		}
+	}
+}
+
+// Sync flushes the log to disk.
+func (db *EmbeddedDB) Sync() error {
+	db.fileMu.Lock()
+	defer db.fileMu.Unlock()
+
+	db.mu.Lock()
+	l := db.log
+	db.mu.Unlock()
+
+	if l == nil {
+		return nil
+	}
+	return l.file.Sync()
+}
+
+// needsCompaction reports whether the log doubled in size since it was last
+// compacted, or can't be appended to anymore.
+func (db *EmbeddedDB) needsCompaction() bool {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return db.log != nil && (db.log.err != nil || db.log.size > max(embeddedCompactMinSize, 2*db.compactedSize))
+}
+
+// compact replaces the log with the entries of the live keys. The keys are
+// written in batches, the writes made meanwhile are appended to both logs, so
+// the database is only locked for a batch at a time.
+func (db *EmbeddedDB) compact() error {
+	tmp, err := os.CreateTemp(filepath.Dir(db.path), filepath.Base(db.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	rewrite := newEmbeddedLog(tmp)
+
+	db.mu.Lock()
+	db.rewrite = rewrite
+	keys := make([]string, 0, len(db.entries))
+	for key := range db.entries {
+		keys = append(keys, key)
+	}
+	db.mu.Unlock()
+
+	for len(keys) > 0 {
+		batch := keys[:min(len(keys), embeddedCompactBatch)]
+		keys = keys[len(batch):]
+
+		db.mu.Lock()
+		for _, key := range batch {
+			if entry := db.lookup(key); entry != nil {
+				rewrite.append(&embeddedOp{Kind: embeddedOpRestore, Time: db.now().UnixNano(), Key: key, Entry: entry})
+			}
+		}
+		db.mu.Unlock()
+	}
+
+	err = tmp.Sync()
+
+	db.fileMu.Lock()
+	defer db.fileMu.Unlock()
+
+	db.mu.Lock()
+	db.rewrite = nil
+	if err == nil {
+		err = rewrite.err
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), db.path)
+	}
+	if err != nil {
+		db.mu.Unlock()
+		tmp.Close()
+		return err
+	}
+
+	previous := db.log
+	db.log = rewrite
+	db.compactedSize = rewrite.size
+	db.mu.Unlock()
+
+	if previous != nil {
+		return previous.file.Close()
+	}
+	return nil
+}
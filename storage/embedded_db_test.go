@@ -0,0 +1,276 @@
+package storage
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEmbeddedDB(t *testing.T, path string) *EmbeddedDB {
+	t.Helper()
+
+	db, err := OpenEmbeddedDB(path, time.Hour)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	return db
+}
+
+func TestEmbeddedDB_Strings(t *testing.T) {
+	db := newTestEmbeddedDB(t, "")
+
+	_, err := db.Get("missing")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	db.Set("key", "value", 0)
+	value, err := db.Get("key")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", value)
+	assert.Equal(t, int64(-1), db.TTL("key"))
+	assert.Equal(t, int64(-2), db.TTL("missing"))
+
+	assert.False(t, db.SetIfNotExists("key", "other", 0))
+	assert.True(t, db.SetIfExists("key", "other", 0))
+	assert.False(t, db.SetIfExists("missing", "other", 0))
+	assert.True(t, db.SetIfNotExists("lock", "1", 0))
+
+	value, _ = db.Get("key")
+	assert.Equal(t, "other", value)
+
+	val, err := db.IncrBy("counter", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), val)
+	val, err = db.IncrBy("counter", -1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), val)
+
+	_, err = db.IncrBy("key", 1)
+	assert.ErrorIs(t, err, ErrEmbeddedNotInteger)
+
+	ok, err := db.IncrBelow("counter", 2, 0)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = db.IncrBelow("counter", 2, 0)
+	assert.NoError(t, err)
+	assert.False(t, ok, "the counter reached the limit")
+
+	assert.Equal(t, []string{"counter", "key"}, db.Keys("*e*"))
+	assert.Equal(t, 2, db.Delete("key", "counter", "missing"))
+	assert.False(t, db.Exists("key"))
+
+	db.FlushAll()
+	assert.Empty(t, db.Keys("*"))
+}
+
+func TestEmbeddedDB_Expiry(t *testing.T) {
+	db := newTestEmbeddedDB(t, "")
+
+	now := time.Now()
+	db.now = func() time.Time { return now }
+
+	db.Set("key", "value", 10*time.Second)
+	assert.Equal(t, int64(10), db.TTL("key"))
+
+	now = now.Add(9500 * time.Millisecond)
+	assert.Equal(t, int64(1), db.TTL("key"))
+	assert.True(t, db.Exists("key"))
+
+	now = now.Add(time.Second)
+	assert.False(t, db.Exists("key"))
+	_, err := db.Get("key")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	db.Set("key", "value", time.Second)
+	assert.True(t, db.Expire("key", 0))
+	assert.Equal(t, int64(-1), db.TTL("key"))
+	assert.False(t, db.Expire("missing", time.Second))
+}
+
+func TestEmbeddedDB_Lists(t *testing.T) {
+	db := newTestEmbeddedDB(t, "")
+
+	assert.NoError(t, db.ListAppend("list", "a", "b", "c", "b"))
+
+	values, err := db.ListRange("list", 1, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b", "c", "b"}, values)
+
+	removed, err := db.ListRemove("list", "b")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, removed)
+
+	values, err = db.ListPopAll("list")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "c"}, values)
+	assert.False(t, db.Exists("list"))
+
+	db.Set("key", "value", 0)
+	assert.ErrorIs(t, db.ListAppend("key", "a"), ErrEmbeddedWrongType)
+}
+
+func TestEmbeddedDB_Sets(t *testing.T) {
+	db := newTestEmbeddedDB(t, "")
+
+	assert.NoError(t, db.SetAdd("set", "b"))
+	assert.NoError(t, db.SetAdd("set", "a"))
+	assert.NoError(t, db.SetAdd("set", "a"))
+
+	members, err := db.SetMembers("set")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, members)
+
+	ok, err := db.SetIsMember("set", "a")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	assert.NoError(t, db.SetRemove("set", "a"))
+	ok, _ = db.SetIsMember("set", "a")
+	assert.False(t, ok)
+}
+
+func TestEmbeddedDB_SortedSets(t *testing.T) {
+	db := newTestEmbeddedDB(t, "")
+
+	assert.NoError(t, db.SortedSetAdd("zset", "three", 3))
+	assert.NoError(t, db.SortedSetAdd("zset", "one", 1))
+	assert.NoError(t, db.SortedSetAdd("zset", "two", 2))
+
+	members, scores, err := db.SortedSetRangeByScore("zset", "(1", "+inf")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"two", "three"}, members)
+	assert.Equal(t, []float64{2, 3}, scores)
+
+	_, _, err = db.SortedSetRangeByScore("zset", "one", "+inf")
+	assert.ErrorIs(t, err, ErrEmbeddedInvalidScore)
+
+	removed, err := db.SortedSetRemoveRangeByScore("zset", "-inf", "2")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, removed)
+
+	members, err = db.SortedSetMembers("zset")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"three"}, members)
+
+	assert.NoError(t, db.SortedSetRemove("zset", "three"))
+	assert.NoError(t, db.SortedSetRemove("zset", "missing"))
+	members, err = db.SortedSetMembers("zset")
+	assert.NoError(t, err)
+	assert.Empty(t, members)
+}
+
+func TestEmbeddedDB_PubSub(t *testing.T) {
+	db := newTestEmbeddedDB(t, "")
+
+	sub := db.Subscribe("channel")
+	assert.Equal(t, 1, db.Publish("channel", "hello"))
+	assert.Equal(t, 0, db.Publish("other", "hello"))
+	assert.Equal(t, "hello", <-sub.Messages())
+
+	sub.Close()
+	assert.Equal(t, 0, db.Publish("channel", "hello"))
+	_, ok := <-sub.Messages()
+	assert.False(t, ok)
+
+	sub = db.Subscribe("channel")
+	assert.NoError(t, db.Close())
+	_, ok = <-sub.Messages()
+	assert.False(t, ok, "subscriptions are closed with the database")
+}
+
+func TestEmbeddedDB_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tyk.db")
+
+	db, err := OpenEmbeddedDB(path, time.Hour)
+	require.NoError(t, err)
+
+	db.Set("key", "value", 0)
+	db.Set("expiring", "value", time.Millisecond)
+	assert.NoError(t, db.ListAppend("list", "a", "b"))
+	assert.NoError(t, db.SortedSetAdd("zset", "one", 1))
+	assert.NoError(t, db.Close())
+
+	time.Sleep(5 * time.Millisecond)
+
+	db = newTestEmbeddedDB(t, path)
+
+	value, err := db.Get("key")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", value)
+	assert.False(t, db.Exists("expiring"))
+
+	values, err := db.ListRange("list", 0, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, values)
+
+	members, err := db.SortedSetMembers("zset")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"one"}, members)
+}
+
+func TestEmbeddedDB_Log(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tyk.db")
+
+	db, err := OpenEmbeddedDB(path, time.Hour)
+	require.NoError(t, err)
+
+	// the writes are replayed at the time they were made
+	past := time.Now().Add(-10 * time.Second)
+	db.now = func() time.Time { return past }
+
+	db.Set("counter", "1", time.Minute)
+	_, err = db.IncrBy("counter", 2)
+	require.NoError(t, err)
+	db.Set("expired", "value", time.Second)
+	assert.NoError(t, db.SortedSetAdd("zset", "forever", math.Inf(1)))
+	assert.NoError(t, db.SortedSetAdd("zset", "one", 1))
+	_, err = db.SortedSetRemoveRangeByScore("zset", "-inf", "1")
+	require.NoError(t, err)
+
+	// the writes made during a compaction are kept
+	require.NoError(t, db.compact())
+	assert.NoError(t, db.SetAdd("set", "a"))
+	assert.Equal(t, 1, db.Delete("missing", "set"))
+	assert.NoError(t, db.SetAdd("set", "b"))
+
+	assert.NoError(t, db.Close())
+
+	// a truncated write at the end of the log is ignored
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{0x20, 0x01})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	db = newTestEmbeddedDB(t, path)
+
+	value, err := db.Get("counter")
+	assert.NoError(t, err)
+	assert.Equal(t, "3", value)
+	assert.Greater(t, db.TTL("counter"), int64(0), "the counter keeps its expiry")
+	assert.False(t, db.Exists("expired"))
+
+	members, scores, err := db.SortedSetRangeByScore("zset", "-inf", "+inf")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"forever"}, members)
+	assert.Equal(t, []float64{math.Inf(1)}, scores)
+
+	members, err = db.SetMembers("set")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b"}, members)
+}
+
+func TestMatchGlob(t *testing.T) {
+	assert.True(t, matchGlob("*", ""))
+	assert.True(t, matchGlob("apikey-*", "apikey-123"))
+	assert.True(t, matchGlob("a?c", "abc"))
+	assert.True(t, matchGlob("*b*", "abc"))
+	assert.False(t, matchGlob("apikey-*", "oauth-123"))
+	assert.False(t, matchGlob("a?c", "ac"))
+}
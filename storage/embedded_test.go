@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/TykTechnologies/storage/temporal/model"
+)
+
+func TestEmbeddedStorage(t *testing.T) {
+	store := &EmbeddedStorage{KeyPrefix: "prefix-", DB: newTestEmbeddedDB(t, "")}
+
+	t.Run("keys", func(t *testing.T) {
+		assert.NoError(t, store.SetKey("key", "value", 0))
+
+		value, err := store.GetKey("key")
+		assert.NoError(t, err)
+		assert.Equal(t, "value", value)
+
+		value, err = store.GetRawKey("prefix-key")
+		assert.NoError(t, err)
+		assert.Equal(t, "value", value)
+
+		values, err := store.GetMultiKey([]string{"missing", "key"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"", "value"}, values)
+
+		_, err = store.GetMultiKey([]string{"missing"})
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+
+		assert.Equal(t, []string{"key"}, store.GetKeys(""))
+		assert.Equal(t, map[string]string{"key": "value"}, store.GetKeysAndValuesWithFilter("k"))
+
+		assert.True(t, store.DeleteKey("key"))
+		_, err = store.GetKey("key")
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+	})
+
+	t.Run("hashed keys", func(t *testing.T) {
+		hashed := &EmbeddedStorage{KeyPrefix: "prefix-", HashKeys: true, DB: store.DB}
+		assert.NoError(t, hashed.SetKey("secret", "value", 0))
+
+		exists, err := store.Exists(HashStr("secret"))
+		assert.NoError(t, err)
+		assert.True(t, exists)
+	})
+
+	t.Run("increment with expire", func(t *testing.T) {
+		assert.Equal(t, int64(1), store.IncrememntWithExpire("counter", 60))
+		assert.Equal(t, int64(2), store.IncrememntWithExpire("counter", 60))
+
+		ttl, err := store.GetKeyTTL("counter")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(-2), ttl, "IncrememntWithExpire uses raw keys")
+		assert.Equal(t, int64(60), store.DB.TTL("counter"))
+	})
+
+	t.Run("rolling window", func(t *testing.T) {
+		count, _ := store.SetRollingWindow("window", 60, "-1", false)
+		assert.Equal(t, 0, count)
+		count, _ = store.SetRollingWindow("window", 60, "-1", false)
+		assert.Equal(t, 1, count)
+
+		count, values := store.GetRollingWindow("window", 60, false)
+		assert.Equal(t, 2, count)
+		assert.Len(t, values, 2)
+	})
+
+	t.Run("lists and sets", func(t *testing.T) {
+		store.AppendToSetPipelined("list", [][]byte{[]byte("a"), []byte("b")})
+		store.AppendToSet("list", "c")
+
+		values, err := store.GetListRange("list", 0, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a", "b", "c"}, values)
+		assert.Equal(t, []interface{}{"a", "b", "c"}, store.GetAndDeleteSet("list"))
+
+		store.AddToSet("set", "member")
+		assert.True(t, store.IsMemberOfSet("set", "member"))
+		store.RemoveFromSet("set", "member")
+		assert.False(t, store.IsMemberOfSet("set", "member"))
+
+		store.AddToSortedSet("zset", "one", 1)
+		store.AddToSortedSet("zset", "two", 2)
+		members, scores, err := store.GetSortedSetRange("zset", "2", "+inf")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"two"}, members)
+		assert.Equal(t, []float64{2}, scores)
+	})
+
+	t.Run("pub/sub", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		received := make(chan model.Message, 1)
+		go func() {
+			_ = store.StartPubSubHandler(ctx, "channel", func(msg interface{}) {
+				received <- msg.(model.Message)
+			})
+		}()
+
+		assert.Eventually(t, func() bool {
+			return store.DB.Publish("channel", "hello") > 0
+		}, time.Second, 10*time.Millisecond)
+
+		msg := <-received
+		assert.Equal(t, model.MessageTypeMessage, msg.Type())
+		payload, err := msg.Payload()
+		assert.NoError(t, err)
+		assert.Equal(t, "hello", payload)
+	})
+}
+
+func TestConnectionHandler_NewHandler(t *testing.T) {
+	rc := NewConnectionHandler(context.Background())
+
+	cluster := &RedisCluster{KeyPrefix: "prefix-", HashKeys: true}
+	assert.Same(t, cluster, rc.NewHandler(cluster))
+	assert.Same(t, rc, cluster.ConnectionHandler)
+
+	rc.embedded = newTestEmbeddedDB(t, "")
+
+	handler := rc.NewHandler(&RedisCluster{KeyPrefix: "prefix-", HashKeys: true})
+	assert.Equal(t, &EmbeddedStorage{KeyPrefix: "prefix-", HashKeys: true, DB: rc.embedded}, handler)
+}
@@ -459,6 +459,41 @@ func (r *RedisCluster) IncrememntWithExpire(keyName string, expire int64) int64
 	return val
 }
 
+// incrementBelowScript increments KEYS[1] unless it reached ARGV[1], a
+// negative value counts as zero. The expiry ARGV[2] is set when the key has
+// none.
+var incrementBelowScript = redis.NewScript(`
+local cur = tonumber(redis.call('GET', KEYS[1]) or '0')
+local val = math.max(cur, 0)
+if val >= tonumber(ARGV[1]) then
+	return 0
+end
+redis.call('INCRBY', KEYS[1], val + 1 - cur)
+if tonumber(ARGV[2]) > 0 and redis.call('TTL', KEYS[1]) < 0 then
+	redis.call('EXPIRE', KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+// IncrementBelow increments a raw key unless it reached limit, in a single
+// round trip, setting its expiry when it's created. It reports whether the
+// key was incremented.
+func (r *RedisCluster) IncrementBelow(keyName string, limit, expire int64) (bool, error) {
+	client, err := r.Client()
+	if err != nil {
+		return false, err
+	}
+
+	// This function uses a raw key, so we shouldn't call fixKey
+	res, err := incrementBelowScript.Run(context.Background(), client, []string{keyName}, limit, expire).Int64()
+	if err != nil {
+		log.WithError(err).Error("Error trying to increment value")
+		return false, err
+	}
+
+	return res == 1, nil
+}
+
 // GetKeys will return all keys according to the filter (filter is a prefix - e.g. tyk.keys.*)
 func (r *RedisCluster) GetKeys(filter string) []string {
 	filterHash := ""
@@ -1093,6 +1128,30 @@ func (r *RedisCluster) RemoveSortedSetRange(keyName, scoreFrom, scoreTo string)
 	return nil
 }
 
+// RemoveFromSortedSet removes value from sorted set identified by keyName
+func (r *RedisCluster) RemoveFromSortedSet(keyName, value string) error {
+	fixedKey := r.fixKey(keyName)
+	logEntry := logrus.Fields{
+		"keyName":  keyName,
+		"fixedKey": fixedKey,
+		"value":    value,
+	}
+	log.WithFields(logEntry).Debug("Removing value from sorted set")
+
+	client, err := r.Client()
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	if err := client.ZRem(context.Background(), fixedKey, value).Err(); err != nil {
+		log.WithFields(logEntry).WithError(err).Error("ZREM command failed")
+		return err
+	}
+
+	return nil
+}
+
 func (r *RedisCluster) ControllerInitiated() bool {
 	return r.getConnectionHandler() != nil
 }
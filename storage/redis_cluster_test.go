@@ -1962,6 +1962,20 @@ func TestRemoveSortedSetRange(t *testing.T) {
 	})
 }
 
+func TestRemoveFromSortedSet(t *testing.T) {
+	storage := &RedisCluster{KeyPrefix: "test-zrem-", ConnectionHandler: rc}
+	storage.AddToSortedSet("set", "one", 1)
+	storage.AddToSortedSet("set", "two", 1)
+	defer storage.DeleteKey("set")
+
+	assert.NoError(t, storage.RemoveFromSortedSet("set", "one"))
+	assert.NoError(t, storage.RemoveFromSortedSet("set", "missing"))
+
+	values, _, err := storage.GetSortedSetRange("set", "-inf", "+inf")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"two"}, values)
+}
+
 func TestScanKeys(t *testing.T) {
 	t.Run("scan keys success", func(t *testing.T) {
 		storage := &RedisCluster{ConnectionHandler: rc}
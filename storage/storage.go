@@ -2,6 +2,7 @@ package storage
 
 import (
 	"errors"
+	"time"
 
 	logger "github.com/TykTechnologies/tyk/log"
 )
@@ -179,6 +180,10 @@ type RemoveSortedSetRangeHandler interface {
 	RemoveSortedSetRange(string, string, string) error
 }
 
+type RemoveFromSortedSetHandler interface {
+	RemoveFromSortedSet(string, string) error
+}
+
 type GetListRangeHandler interface {
 	GetListRange(string, int64, int64) ([]string, error)
 }
@@ -195,6 +200,15 @@ type ExistsHandler interface {
 	Exists(string) (bool, error)
 }
 
+type LockHandler interface {
+	// Lock sets key if it doesn't exist, returning true if it was set.
+	Lock(string, time.Duration) (bool, error)
+}
+
+type ScanKeysHandler interface {
+	ScanKeys(string) ([]string, error)
+}
+
 type SetKeyExHandler interface {
 	// SetKeyEx sets key if key already exists.
 	SetKeyEx(string, string, int64) error